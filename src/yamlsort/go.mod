@@ -1,9 +1,10 @@
-module yamlsort
+module github.com/keita69/yamlsort/src/yamlsort
 
 require (
 	github.com/ghodss/yaml v1.0.0
 	github.com/inconshreveable/mousetrap v1.0.0 // indirect
 	github.com/spf13/cobra v0.0.3
-	github.com/spf13/pflag v1.0.3 // indirect
-	gopkg.in/yaml.v2 v2.2.2 // indirect
+	github.com/spf13/pflag v1.0.3
+	go.starlark.net v0.0.0-20230525235612-a134d8f9ddca
+	gopkg.in/yaml.v2 v2.4.0
 )
@@ -0,0 +1,17 @@
+//
+// yamlsort - command
+//
+// sorter is package github.com/keita69/yamlsort/src/yamlsort/pkg/yamlsort. this package is command line of it.
+//
+package main
+
+import (
+	"github.com/keita69/yamlsort/src/yamlsort/pkg/yamlsort"
+)
+
+// version string set by ldflags (git describe)
+var version string
+
+func main() {
+	yamlsort.Main(version)
+}
@@ -0,0 +1,165 @@
+//
+// yamlsort - alias expansion report (stats --aliases)
+//
+// report how much each alias expands the document , to find templates where
+// anchor reuse hides an enormous effective config.
+//   yamlsort stats --aliases values.yaml
+//     [0] keys=12 depth=4 longest-line=40 duplicate-keys=0 types: map=5 string=8
+//         anchors=2 aliases=6 bytes=420 expanded=2310 (5.5x)
+//         &defaults  aliases=4 size=310 expanded=1240
+// size is bytes of anchored node written as yaml , and expanded is size * aliases.
+// nested aliases are counted in size of outer anchor.
+//
+package yamlsort
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	yamlv2 "gopkg.in/yaml.v2"
+)
+
+// &name or *name at start of node (line start , after "key: " , "- " , "? " , or flow indicator)
+var anchorRegexp = regexp.MustCompile(`(^\s*|[:?-]\s+|[\[\{,]\s*)([&*])([^\s\[\]\{\},]+)`)
+
+// alias usage of one anchor name
+type aliasUsage struct {
+	name     string
+	anchors  int
+	aliases  int
+	size     int
+	expanded int
+}
+
+// alias report of one document
+type aliasReport struct {
+	usages   []*aliasUsage
+	bytes    int
+	expanded int
+}
+
+// count anchors and aliases in document text , and compute expanded sizes
+func newAliasReport(text []byte) (*aliasReport, error) {
+	report := &aliasReport{bytes: len(text)}
+	usages := map[string]*aliasUsage{}
+	for _, line := range strings.Split(string(text), "\n") {
+		for _, m := range anchorRegexp.FindAllStringSubmatch(stripQuotedText(line), -1) {
+			usage, ok := usages[m[3]]
+			if !ok {
+				usage = &aliasUsage{name: m[3]}
+				usages[m[3]] = usage
+				report.usages = append(report.usages, usage)
+			}
+			if m[2] == "&" {
+				usage.anchors++
+			} else {
+				usage.aliases++
+			}
+		}
+	}
+
+	var data interface{}
+	err := unmarshalYAMLv2(text, &data)
+	if err != nil {
+		return nil, err
+	}
+	expandedtext, err := yamlv2.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	report.expanded = len(expandedtext)
+	if len(report.usages) == 0 {
+		return report, nil
+	}
+
+	// document is nested under one key , and each anchor is referred from other key.
+	// (alias refers to last anchor of the name)
+	wrapped := []byte("__yamlsort_document__:\n")
+	for _, line := range strings.Split(string(text), "\n") {
+		wrapped = append(wrapped, "  "+line+"\n"...)
+	}
+	for i, usage := range report.usages {
+		if usage.anchors > 0 {
+			wrapped = append(wrapped, fmt.Sprintf("__yamlsort_alias_%d__: *%s\n", i, usage.name)...)
+		}
+	}
+	values := map[string]interface{}{}
+	err = unmarshalYAMLv2(wrapped, &values)
+	if err != nil {
+		return nil, err
+	}
+	for i, usage := range report.usages {
+		v, ok := values[fmt.Sprintf("__yamlsort_alias_%d__", i)]
+		if !ok {
+			continue
+		}
+		nodetext, err := yamlv2.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		usage.size = len(nodetext)
+		usage.expanded = usage.size * usage.aliases
+	}
+	sort.SliceStable(report.usages, func(i, j int) bool {
+		return report.usages[i].expanded > report.usages[j].expanded
+	})
+	return report, nil
+}
+
+// remove quoted strings and comment of line
+func stripQuotedText(line string) string {
+	text, _ := splitQuotedText(line)
+	return text
+}
+
+// line without quoted strings and comment , and whether line has comment
+func splitQuotedText(line string) (string, bool) {
+	text, idx := scanQuotedText(line)
+	return text, idx >= 0
+}
+
+// line without quoted strings and comment , and index of comment (-1 when line has no comment)
+func scanQuotedText(line string) (string, int) {
+	result := make([]byte, 0, len(line))
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		ch := line[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			} else if ch == '\\' && quote == '"' {
+				i++
+			}
+			continue
+		case (ch == '"' || ch == '\'') && (i == 0 || strings.IndexByte(" \t[{,:", line[i-1]) >= 0):
+			// quote in middle of plain scalar (like don't) is not quote
+			quote = ch
+			result = append(result, ' ')
+			continue
+		case ch == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return string(result), i
+		}
+		result = append(result, ch)
+	}
+	return string(result), -1
+}
+
+// print report lines under stats line
+func (r *aliasReport) print(c *yamlsortCmd) {
+	anchors, aliases := 0, 0
+	for _, usage := range r.usages {
+		anchors += usage.anchors
+		aliases += usage.aliases
+	}
+	ratio := 1.0
+	if r.bytes > 0 {
+		ratio = float64(r.expanded) / float64(r.bytes)
+	}
+	fmt.Fprintf(c.stdout, "      anchors=%d aliases=%d bytes=%d expanded=%d (%.1fx)\n", anchors, aliases, r.bytes, r.expanded, ratio)
+	for _, usage := range r.usages {
+		fmt.Fprintf(c.stdout, "      &%s  aliases=%d size=%d expanded=%d\n", usage.name, usage.aliases, usage.size, usage.expanded)
+	}
+}
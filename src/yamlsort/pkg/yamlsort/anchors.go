@@ -0,0 +1,231 @@
+//
+// yamlsort - anchor and alias validation
+//
+// parsers differ for anchors defined twice (last one wins , first one wins , or error) ,
+// so they are reported as warning with line and path.
+//   Warning: anchor &defaults at line 7 (prod) is defined again , first at line 2 (base)
+// alias to undefined anchor (or anchor defined after alias) , and alias in node of its own
+// anchor (cycle) are reported with line and path , instead of errors of parser.
+//   Error: alias *default at line 9 (prod.<<) refers undefined anchor
+// lines in block scalars (| , >) are not checked.
+// nodes expanded from aliases are limited by --max-alias-expansion before parse , so
+// nested aliases (billion laughs) are an error instead of exhausting memory.
+//
+package yamlsort
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// default of --max-alias-expansion
+const defaultMaxAliasExpansion = 100000
+
+// anchor (&name) or alias (*name) in document text
+type anchorRef struct {
+	name      string
+	blnAnchor bool
+	line      int      // line number in input , 1 origin
+	path      string   // key path of node , like spec.ports[0]
+	ancestor  string   // location of anchor of same name , which contains this alias (cycle)
+	within    []string // names of anchors whose nodes contain this alias
+}
+
+// location for messages , like "line 3 (spec.ports[0])"
+func (r anchorRef) location() string {
+	if len(r.path) == 0 {
+		return fmt.Sprintf("line %d", r.line)
+	}
+	return fmt.Sprintf("line %d (%s)", r.line, r.path)
+}
+
+// anchors and aliases in document text. firstline is line number of first line.
+func scanAnchorRefs(text []byte, firstline int) []anchorRef {
+	result := []anchorRef{}
+	if !bytes.ContainsAny(text, "&*") {
+		return result
+	}
+	// anchors whose nodes contain current line
+	type openAnchor struct {
+		ref    anchorRef
+		indent int
+		blnKey bool
+	}
+	opens := []openAnchor{}
+	tracker := newLinePathTracker()
+	blockindent := -1 // indent of node which has block scalar
+	for i, line := range strings.Split(string(text), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		body := strings.TrimLeft(line, " ")
+		indent := len(line) - len(body)
+		if len(body) == 0 {
+			continue
+		}
+		if blockindent >= 0 {
+			if indent > blockindent {
+				continue
+			}
+			blockindent = -1
+		}
+		if body[0] == '#' {
+			continue
+		}
+		blnItem := body == "-" || strings.HasPrefix(body, "- ")
+		for len(opens) > 0 {
+			o := opens[len(opens)-1]
+			// list items can have same indent as key of anchor
+			if indent > o.indent || (indent == o.indent && blnItem && o.blnKey) {
+				break
+			}
+			opens = opens[:len(opens)-1]
+		}
+		level, value := tracker.next(indent, body)
+		if isBlockScalarValue(value) {
+			blockindent = indent
+		}
+		for _, m := range anchorRegexp.FindAllStringSubmatch(stripQuotedText(line), -1) {
+			ref := anchorRef{name: m[3], blnAnchor: m[2] == "&", line: firstline + i, path: level.path}
+			if ref.blnAnchor {
+				opens = append(opens, openAnchor{ref: ref, indent: level.indent, blnKey: !level.blnItem})
+			} else {
+				for j := len(opens) - 1; j >= 0; j-- {
+					if opens[j].ref.name == ref.name {
+						if len(ref.ancestor) == 0 {
+							ref.ancestor = opens[j].ref.location()
+						}
+					} else {
+						ref.within = append(ref.within, opens[j].ref.name)
+					}
+				}
+			}
+			result = append(result, ref)
+		}
+	}
+	return result
+}
+
+// value starts block scalar , like "|" , "&anchor >-" or "!!str |"
+func isBlockScalarValue(value string) bool {
+	for _, field := range strings.Fields(value) {
+		switch field[0] {
+		case '|', '>':
+			return true
+		case '&', '!':
+			continue
+		}
+		return false
+	}
+	return false
+}
+
+// warnings of anchors defined again , and first alias to undefined anchor
+func checkAnchorRefs(refs []anchorRef) ([]string, error) {
+	warnings := []string{}
+	anchors := map[string]anchorRef{}
+	var err error
+	for _, ref := range refs {
+		first, defined := anchors[ref.name]
+		if ref.blnAnchor {
+			if defined {
+				warnings = append(warnings, fmt.Sprintf("anchor &%s at %s is defined again , first at %s", ref.name, ref.location(), first.location()))
+			} else {
+				anchors[ref.name] = ref
+			}
+		} else if len(ref.ancestor) > 0 && err == nil {
+			err = fmt.Errorf("alias *%s at %s refers anchor &%s at %s , which contains the alias (cycle)", ref.name, ref.location(), ref.name, ref.ancestor)
+		} else if !defined && err == nil {
+			err = fmt.Errorf("alias *%s at %s refers undefined anchor", ref.name, ref.location())
+		}
+	}
+	return warnings, err
+}
+
+// nodes which aliases of document expand to. anchored node counts 1 , and alias in it
+// adds nodes of its anchor. counting stops above limit.
+func aliasExpansion(refs []anchorRef, limit int) int {
+	nodes := map[string]int{}
+	total := 0
+	for _, ref := range refs {
+		if ref.blnAnchor {
+			nodes[ref.name] = 1
+			continue
+		}
+		n := nodes[ref.name]
+		total += n
+		if total > limit {
+			return total
+		}
+		for _, name := range ref.within {
+			nodes[name] += n
+			if nodes[name] > limit {
+				nodes[name] = limit + 1
+			}
+		}
+	}
+	return total
+}
+
+// --max-alias-expansion , or default when it is not set
+func (c *yamlsortCmd) maxAliasExpansion() int {
+	if c.maxaliasexpansion <= 0 {
+		return defaultMaxAliasExpansion
+	}
+	return c.maxaliasexpansion
+}
+
+//---------------------------------------------------------------------
+//  linePathTracker class
+// key path of each line in block style yaml , by indent of lines
+//
+type linePathLevel struct {
+	indent  int
+	path    string
+	items   int // count of list items under this node
+	blnItem bool
+}
+
+type linePathTracker struct {
+	stack []linePathLevel
+}
+
+func newLinePathTracker() *linePathTracker {
+	return &linePathTracker{stack: []linePathLevel{{indent: -1}}}
+}
+
+// node at line , and value text after key or "- "
+func (t *linePathTracker) next(indent int, body string) (linePathLevel, string) {
+	blnItem := body == "-" || strings.HasPrefix(body, "- ")
+	for len(t.stack) > 1 {
+		top := t.stack[len(t.stack)-1]
+		// list items can have same indent as parent key
+		if top.indent > indent || (top.indent == indent && (top.blnItem || !blnItem)) {
+			t.stack = t.stack[:len(t.stack)-1]
+			continue
+		}
+		break
+	}
+	parent := &t.stack[len(t.stack)-1]
+	if blnItem {
+		item := linePathLevel{indent: indent, path: fmt.Sprintf("%s[%d]", parent.path, parent.items), blnItem: true}
+		parent.items++
+		t.stack = append(t.stack, item)
+		rest := strings.TrimLeft(body[1:], " ")
+		if _, key, value, ok := parseKeyLine(rest); ok {
+			level := linePathLevel{indent: indent + len(body) - len(rest), path: item.path + "." + key}
+			t.stack = append(t.stack, level)
+			return level, value
+		}
+		return item, rest
+	}
+	if _, key, value, ok := parseKeyLine(body); ok {
+		level := linePathLevel{indent: indent, path: key}
+		if len(parent.path) > 0 {
+			level.path = parent.path + "." + key
+		}
+		t.stack = append(t.stack, level)
+		return level, value
+	}
+	// continued value
+	return *parent, body
+}
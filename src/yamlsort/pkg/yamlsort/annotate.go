@@ -0,0 +1,28 @@
+//
+// yamlsort - --annotate-source option
+//
+// write source file and document index as comment after header of each output document ,
+// so bundled multi-document output can be traced back to its origin.
+//   yamlsort --annotate-source base/*.yaml overlays/prod/*.yaml > bundle.yaml
+//   ---
+//   # source: base/deployment.yaml (document 1)
+//
+package yamlsort
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+)
+
+// write "# source: file (document N)" comment
+func (c *yamlsortCmd) writeSourceComment(outputWriter io.Writer, doc *Document) {
+	if !c.blnAnnotateSource {
+		return
+	}
+	source := "-"
+	if len(c.inputfilename) > 0 {
+		source = filepath.ToSlash(c.inputfilename)
+	}
+	fmt.Fprintf(outputWriter, "# source: %s (document %d)\n", source, doc.Index)
+}
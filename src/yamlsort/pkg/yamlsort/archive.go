@@ -0,0 +1,330 @@
+//
+// yamlsort - archive subcommand
+//
+// sort every yaml file in .tar.gz (.tgz) , .tar or .zip archive , like helm chart package.
+// other files are copied as is. yaml files which can not be parsed (like helm templates)
+// are copied as is , with warning.
+//   yamlsort archive mychart-1.0.0.tgz -o sorted.tgz        write new archive
+//   yamlsort archive mychart-1.0.0.tgz --output-dir sorted/ extract into directory
+//
+package yamlsort
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// one file of archive
+type archiveEntry struct {
+	name    string
+	mode    os.FileMode
+	modtime time.Time
+	isdir   bool
+	data    []byte
+}
+
+// kinds of archive
+const (
+	archiveTarGz = "tar.gz"
+	archiveTar   = "tar"
+	archiveZip   = "zip"
+)
+
+// kind of archive from file name. "" when it is not archive.
+func archiveKind(filename string) string {
+	name := strings.ToLower(filename)
+	switch {
+	case strings.HasSuffix(name, ".tar.gz") || strings.HasSuffix(name, ".tgz"):
+		return archiveTarGz
+	case strings.HasSuffix(name, ".tar"):
+		return archiveTar
+	case strings.HasSuffix(name, ".zip"):
+		return archiveZip
+	}
+	return ""
+}
+
+//---------------------------------------------------------------------
+//  archiveCmd class
+//
+type archiveCmd struct {
+	yamlsort  *yamlsortCmd
+	output    string
+	outputdir string
+}
+
+func newArchiveCmd(yamlsort *yamlsortCmd) *cobra.Command {
+	archive := &archiveCmd{
+		yamlsort: yamlsort,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "archive file.tgz|file.zip (-o output | --output-dir dir)",
+		Short: "sort every yaml file in .tar.gz , .tar or .zip archive",
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("archive requires one archive file name")
+			}
+			return archive.run(args[0])
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&archive.output, "output", "o", "", "path to output archive (.tar.gz , .tgz , .tar , .zip)")
+	f.StringVar(&archive.outputdir, "output-dir", "", "extract sorted files into this directory")
+	f.StringArrayVar(&yamlsort.priorkeys, "key", []string{}, "set prior key name in sort. default prior key is name")
+
+	return cmd
+}
+
+func (a *archiveCmd) run(filename string) error {
+	c := a.yamlsort
+	if (len(a.output) == 0) == (len(a.outputdir) == 0) {
+		return fmt.Errorf("archive requires one of -o or --output-dir")
+	}
+	kind := archiveKind(filename)
+	if len(kind) == 0 {
+		return fmt.Errorf("%s: unknown archive type (.tar.gz , .tgz , .tar , .zip)", filename)
+	}
+	outputkind := kind
+	if len(a.output) > 0 {
+		outputkind = archiveKind(a.output)
+		if len(outputkind) == 0 {
+			return fmt.Errorf("%s: unknown archive type (.tar.gz , .tgz , .tar , .zip)", a.output)
+		}
+	}
+	c.maxlinesize = defaultMaxLineSize
+	err := c.prepareOptions()
+	if err != nil {
+		return err
+	}
+
+	var entries []*archiveEntry
+	if kind == archiveZip {
+		entries, err = readZipEntries(filename)
+	} else {
+		entries, err = readTarEntries(filename, kind == archiveTarGz)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %v", filename, err)
+	}
+
+	for _, entry := range entries {
+		if entry.isdir || !c.isYamlFilename(entry.name) {
+			continue
+		}
+		c.inputfilename = entry.name
+		output, err := c.sortBytes(entry.data, nil)
+		c.inputfilename = ""
+		if err != nil {
+			fmt.Fprintf(c.stderr, "warning: %s is copied as is: %v\n", entry.name, err)
+			continue
+		}
+		entry.data = output.Bytes()
+	}
+
+	if len(a.outputdir) > 0 {
+		return extractEntries(a.outputdir, entries)
+	}
+	if outputkind == archiveZip {
+		return writeZipEntries(a.output, entries)
+	}
+	return writeTarEntries(a.output, entries, outputkind == archiveTarGz)
+}
+
+func readTarEntries(filename string, blnGzip bool) ([]*archiveEntry, error) {
+	fp, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+	var reader io.Reader = fp
+	if blnGzip {
+		gz, err := gzip.NewReader(fp)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		reader = gz
+	}
+	tr := tar.NewReader(reader)
+	entries := []*archiveEntry{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg && header.Typeflag != tar.TypeDir {
+			// links and special files are not supported
+			continue
+		}
+		entry := &archiveEntry{
+			name:    header.Name,
+			mode:    os.FileMode(header.Mode).Perm(),
+			modtime: header.ModTime,
+			isdir:   header.Typeflag == tar.TypeDir,
+		}
+		if !entry.isdir {
+			entry.data, err = ioutil.ReadAll(tr)
+			if err != nil {
+				return nil, err
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func readZipEntries(filename string) ([]*archiveEntry, error) {
+	zr, err := zip.OpenReader(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	entries := []*archiveEntry{}
+	for _, file := range zr.File {
+		entry := &archiveEntry{
+			name:    file.Name,
+			mode:    file.Mode().Perm(),
+			modtime: file.Modified,
+			isdir:   file.FileInfo().IsDir(),
+		}
+		if !entry.isdir {
+			rc, err := file.Open()
+			if err != nil {
+				return nil, err
+			}
+			entry.data, err = ioutil.ReadAll(rc)
+			rc.Close()
+			if err != nil {
+				return nil, err
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func writeTarEntries(filename string, entries []*archiveEntry, blnGzip bool) error {
+	buf := new(bytes.Buffer)
+	var writer io.Writer = buf
+	var gz *gzip.Writer
+	if blnGzip {
+		gz = gzip.NewWriter(buf)
+		writer = gz
+	}
+	tw := tar.NewWriter(writer)
+	for _, entry := range entries {
+		header := &tar.Header{
+			Name:     entry.name,
+			Mode:     int64(entry.mode),
+			ModTime:  entry.modtime,
+			Typeflag: tar.TypeReg,
+			Size:     int64(len(entry.data)),
+		}
+		if entry.isdir {
+			header.Typeflag = tar.TypeDir
+			header.Size = 0
+		}
+		err := tw.WriteHeader(header)
+		if err == nil && !entry.isdir {
+			_, err = tw.Write(entry.data)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	err := tw.Close()
+	if err == nil && gz != nil {
+		err = gz.Close()
+	}
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, buf.Bytes(), 0644)
+}
+
+func writeZipEntries(filename string, entries []*archiveEntry) error {
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	for _, entry := range entries {
+		header := &zip.FileHeader{
+			Name:     entry.name,
+			Method:   zip.Deflate,
+			Modified: entry.modtime,
+		}
+		header.SetMode(entry.mode)
+		if entry.isdir {
+			header.Name = strings.TrimSuffix(entry.name, "/") + "/"
+			header.Method = zip.Store
+			header.SetMode(entry.mode | os.ModeDir)
+		}
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if !entry.isdir {
+			_, err = w.Write(entry.data)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	err := zw.Close()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, buf.Bytes(), 0644)
+}
+
+// write entries into directory. names out of directory (like ../x) are refused.
+func extractEntries(dir string, entries []*archiveEntry) error {
+	for _, entry := range entries {
+		name := strings.Replace(entry.name, "\\", "/", -1)
+		for _, elem := range strings.Split(name, "/") {
+			if elem == ".." {
+				return fmt.Errorf("invalid file name in archive: %s", entry.name)
+			}
+		}
+		name = path.Clean("/" + name)
+		if name == "/" {
+			continue
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if entry.isdir {
+			err := os.MkdirAll(target, 0755)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		err := os.MkdirAll(filepath.Dir(target), 0755)
+		if err != nil {
+			return err
+		}
+		mode := entry.mode
+		if mode == 0 {
+			mode = 0644
+		}
+		err = ioutil.WriteFile(target, entry.data, mode)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+//
+// yamlsort - audit subcommand
+//
+// config drift report of many files.
+//   type mismatch       same key path has different types in files
+//   missing in files    key path is in some files , but not in other files (parent path exists)
+// list elements are aggregated as [*]. --usage prints which files each key path appears in.
+//
+package yamlsort
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// usage of one key path
+type keyUsage struct {
+	files map[string]bool
+	types map[string][]string // type name -> files
+}
+
+//---------------------------------------------------------------------
+//  auditCmd class
+//
+type auditCmd struct {
+	yamlsort *yamlsortCmd
+	blnUsage bool
+	usages   map[string]*keyUsage
+	files    []string
+}
+
+func newAuditCmd(yamlsort *yamlsortCmd) *cobra.Command {
+	audit := &auditCmd{
+		yamlsort: yamlsort,
+		usages:   map[string]*keyUsage{},
+	}
+
+	cmd := &cobra.Command{
+		Use:   "audit file|dir...",
+		Short: "report keys with inconsistent types , or only in some files",
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("audit requires input file or directory names")
+			}
+			return audit.run(args)
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&audit.blnUsage, "usage", false, "print files of every key path")
+
+	return cmd
+}
+
+func (a *auditCmd) run(args []string) error {
+	c := a.yamlsort
+	c.maxlinesize = defaultMaxLineSize
+	filenames, err := c.collectFiles(args)
+	if err != nil {
+		return err
+	}
+	for _, filename := range filenames {
+		input, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		err = c.processDocuments(bytes.NewReader(input), func(doc *Document) error {
+			if doc.Data != nil {
+				a.add(filename, "", doc.Data)
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %v", filename, err)
+		}
+		a.files = append(a.files, filename)
+	}
+	a.report()
+	return nil
+}
+
+// record key paths of data in file
+func (a *auditCmd) add(filename string, path string, data interface{}) {
+	if len(path) > 0 {
+		usage, ok := a.usages[path]
+		if !ok {
+			usage = &keyUsage{files: map[string]bool{}, types: map[string][]string{}}
+			a.usages[path] = usage
+		}
+		typename := valueTypeName(data)
+		if !usage.files[filename] {
+			usage.types[typename] = append(usage.types[typename], filename)
+		}
+		usage.files[filename] = true
+	}
+	if m, ok := data.(map[string]interface{}); ok {
+		for k, v := range m {
+			childpath := k
+			if len(path) > 0 {
+				childpath = path + "." + k
+			}
+			a.add(filename, childpath, v)
+		}
+	} else if l, ok := data.([]interface{}); ok {
+		for _, v := range l {
+			a.add(filename, path+"[*]", v)
+		}
+	}
+}
+
+// type name of value for reports
+func valueTypeName(data interface{}) string {
+	switch data.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "map"
+	case []interface{}:
+		return "list"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "bool"
+	}
+	return "string"
+}
+
+// parent of key path. "" for top level key.
+func parentKeyPath(path string) string {
+	idx := strings.LastIndex(path, ".")
+	if idx < 0 {
+		return strings.TrimSuffix(path, "[*]")
+	}
+	return path[:idx]
+}
+
+func (a *auditCmd) report() {
+	w := a.yamlsort.stdout
+	paths := make([]string, 0, len(a.usages))
+	for path := range a.usages {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	fmt.Fprintf(w, "%d key paths in %d files\n", len(paths), len(a.files))
+
+	if a.blnUsage {
+		fmt.Fprintln(w, "\nkey usage:")
+		for _, path := range paths {
+			fmt.Fprintf(w, "  %s  %d/%d  %s\n", path, len(a.usages[path].files), len(a.files), strings.Join(sortedFiles(a.usages[path].files), " , "))
+		}
+	}
+
+	header := false
+	for _, path := range paths {
+		usage := a.usages[path]
+		if len(usage.types) < 2 {
+			continue
+		}
+		if !header {
+			fmt.Fprintln(w, "\ntype mismatch:")
+			header = true
+		}
+		typenames := []string{}
+		for typename := range usage.types {
+			typenames = append(typenames, typename)
+		}
+		sort.Strings(typenames)
+		details := []string{}
+		for _, typename := range typenames {
+			details = append(details, typename+" ("+strings.Join(usage.types[typename], " , ")+")")
+		}
+		fmt.Fprintf(w, "  %s  %s\n", path, strings.Join(details, " "))
+	}
+
+	header = false
+	for _, path := range paths {
+		usage := a.usages[path]
+		// files which have parent path , but not this path
+		missing := []string{}
+		parent := parentKeyPath(path)
+		for _, filename := range a.files {
+			if usage.files[filename] {
+				continue
+			}
+			if parentusage, ok := a.usages[parent]; (ok && parentusage.files[filename] && len(parentusage.types["map"]) > 0) || parent == "" {
+				missing = append(missing, filename)
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		if !header {
+			fmt.Fprintln(w, "\nmissing in files:")
+			header = true
+		}
+		fmt.Fprintf(w, "  %s  in: %s  missing: %s\n", path, strings.Join(sortedFiles(usage.files), " , "), strings.Join(missing, " , "))
+	}
+}
+
+func sortedFiles(files map[string]bool) []string {
+	result := []string{}
+	for filename := range files {
+		result = append(result, filename)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,131 @@
+//
+// yamlsort - in-place write
+//   --backup[=suffix]  save original file as file.yaml.orig before writing
+//   --no-clobber       refuse to overwrite file , which is modified since read
+//   --chmod=mode       set mode of written file (octal , like 0644)
+// file is replaced atomically (signal.go) , and mode , owner and extended attributes are kept.
+// read-only file stays read-only.
+// backup file gets mode , owner (when possible) and extended attributes of original file.
+//
+package yamlsort
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// default suffix of --backup
+const defaultBackupSuffix = ".orig"
+
+// modification time and size of file , when it is read
+type fileSnapshot struct {
+	modtime time.Time
+	size    int64
+	found   bool
+}
+
+func snapshotFile(filename string) fileSnapshot {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return fileSnapshot{}
+	}
+	return fileSnapshot{modtime: info.ModTime(), size: info.Size(), found: true}
+}
+
+// write output into the file which input is read from
+func (c *yamlsortCmd) writeInPlace(filename string, input []byte, output []byte, snapshot fileSnapshot) error {
+	if isObjectURI(filename) {
+		return c.writeObjectInPlace(filename, input, output)
+	}
+	if c.blnNoClobber {
+		now := snapshotFile(filename)
+		if now.found != snapshot.found || !now.modtime.Equal(snapshot.modtime) || now.size != snapshot.size {
+			return fmt.Errorf("%s is modified since read. (--no-clobber)", filename)
+		}
+	}
+	perm := os.FileMode(0644)
+	info, err := os.Stat(filename)
+	if err == nil {
+		perm = info.Mode().Perm()
+	}
+	if len(c.backupsuffix) > 0 {
+		backupname := filename + c.backupsuffix
+		err := atomicWriteFile(backupname, input, perm)
+		if err != nil {
+			return err
+		}
+		if info != nil {
+			// existing backup file may differ from original file
+			os.Chmod(backupname, perm)
+			copyFileAttributes(filename, backupname, info)
+		}
+	}
+	err = atomicWriteFile(filename, output, perm)
+	if err != nil {
+		return err
+	}
+	if c.filemode != 0 {
+		return os.Chmod(filename, c.filemode)
+	}
+	return nil
+}
+
+// write existing file directly. read-only file is made writable while writing.
+func writeFileKeepMode(filename string, data []byte, perm os.FileMode) error {
+	if perm&0200 != 0 {
+		return writeFileRetry(filename, data, perm)
+	}
+	err := os.Chmod(filename, perm|0200)
+	if err != nil {
+		return err
+	}
+	err = writeFileRetry(filename, data, perm)
+	err2 := os.Chmod(filename, perm)
+	if err == nil {
+		err = err2
+	}
+	return err
+}
+
+// mode of --chmod , 0 when not specified
+func parseFileMode(text string) (os.FileMode, error) {
+	if len(text) == 0 {
+		return 0, nil
+	}
+	mode, err := strconv.ParseUint(text, 8, 32)
+	if err != nil || mode == 0 || mode > 07777 {
+		return 0, fmt.Errorf("invalid --chmod %q , octal mode like 0644", text)
+	}
+	return fileModeOf(uint32(mode)), nil
+}
+
+// os.FileMode of unix mode bits (setuid , setgid , sticky and permission)
+func fileModeOf(mode uint32) os.FileMode {
+	result := os.FileMode(mode & 0777)
+	if mode&04000 != 0 {
+		result |= os.ModeSetuid
+	}
+	if mode&02000 != 0 {
+		result |= os.ModeSetgid
+	}
+	if mode&01000 != 0 {
+		result |= os.ModeSticky
+	}
+	return result
+}
+
+// write output into object which input is read from
+func (c *yamlsortCmd) writeObjectInPlace(uri string, input []byte, output []byte) error {
+	if c.blnNoClobber {
+		return fmt.Errorf("%s: --no-clobber is not supported for object storage", uri)
+	}
+	if len(c.backupsuffix) > 0 {
+		err := writeObject(uri+c.backupsuffix, input)
+		if err != nil {
+			return err
+		}
+	}
+	return writeObject(uri, output)
+}
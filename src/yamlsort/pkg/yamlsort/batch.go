@@ -0,0 +1,273 @@
+//
+// yamlsort - file and directory arguments
+//
+//   yamlsort [options] file|dir ...        sort files , and output to stdout
+//   yamlsort -w [options] file|dir ...     sort files , and write them in place
+//   yamlsort -w --dry-run file|dir ...     show unified diff , and write nothing
+//   yamlsort --check file|dir ...          check that files are sorted (see check.go)
+//   yamlsort --output-template T file|dir  write result of each file into path of template T
+//
+// yaml files (.yaml , .yml , or --ext) in directories are sorted recursively.
+// with --sniff , files without extension are sorted when the content looks like yaml.
+// directories whose name starts with "." (like .git) are skipped , and files
+// matched by .gitignore and .yamlsortignore are skipped (unless --no-ignore).
+// symbolic links are skipped (unless --follow-symlinks).
+//
+package yamlsort
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// default of --ext
+const defaultExtensions = "yaml,yml"
+
+// files of arguments. directories are walked.
+func (c *yamlsortCmd) collectFiles(args []string) ([]string, error) {
+	walker := &fileWalker{
+		c:       c,
+		visited: map[string]bool{},
+		seen:    map[string]bool{},
+	}
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			walker.result = append(walker.result, arg)
+			continue
+		}
+		walker.matcher = nil
+		if !c.blnNoIgnore {
+			walker.matcher = newIgnoreMatcher(arg)
+		}
+		err = walker.walk(arg)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return walker.result, nil
+}
+
+//---------------------------------------------------------------------
+//  fileWalker class
+// walk directories. symbolic links are skipped , or followed with --follow-symlinks.
+// directories already visited (by real path) are skipped , so link cycles end.
+//
+type fileWalker struct {
+	c       *yamlsortCmd
+	matcher *ignoreMatcher
+	visited map[string]bool // real path of directories
+	seen    map[string]bool // real path of files
+	result  []string
+}
+
+func (w *fileWalker) walk(dir string) error {
+	if real, err := filepath.EvalSymlinks(dir); err == nil {
+		if w.visited[real] {
+			return nil
+		}
+		w.visited[real] = true
+	}
+	if w.matcher != nil {
+		w.matcher.load(dir)
+	}
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, info := range entries {
+		path := filepath.Join(dir, info.Name())
+		isdir := info.IsDir()
+		if info.Mode()&os.ModeSymlink != 0 {
+			if !w.c.blnFollowSymlinks {
+				continue
+			}
+			target, err := os.Stat(path)
+			if err != nil {
+				// broken link
+				continue
+			}
+			isdir = target.IsDir()
+		}
+		if w.matcher != nil && w.matcher.ignored(path, isdir) {
+			continue
+		}
+		if isdir {
+			if strings.HasPrefix(info.Name(), ".") {
+				continue
+			}
+			err := w.walk(path)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if !w.c.isYamlFilename(path) {
+			continue
+		}
+		if real, err := filepath.EvalSymlinks(path); err == nil {
+			if w.seen[real] {
+				continue
+			}
+			w.seen[real] = true
+		}
+		w.result = append(w.result, path)
+	}
+	return nil
+}
+
+// file in directory is yaml file
+func (c *yamlsortCmd) isYamlFilename(path string) bool {
+	name := strings.ToLower(filepath.Base(path))
+	for _, ext := range strings.Split(c.extensions, ",") {
+		ext = strings.TrimPrefix(strings.TrimSpace(strings.ToLower(ext)), ".")
+		if len(ext) > 0 && strings.HasSuffix(name, "."+ext) {
+			return true
+		}
+	}
+	if c.blnSniff && !strings.Contains(name, ".") {
+		return sniffYaml(path)
+	}
+	return false
+}
+
+// content of file looks like yaml
+//   starts with "%YAML" or "---" , or shebang line has "yaml"
+//   or text of map or list. (scripts with other shebang are not yaml)
+func sniffYaml(path string) bool {
+	fp, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer fp.Close()
+	head := make([]byte, 4096)
+	n, _ := io.ReadFull(fp, head)
+	head = head[:n]
+	if n == 0 || bytes.IndexByte(head, 0) >= 0 {
+		// empty or binary
+		return false
+	}
+	firstline := string(head)
+	if idx := strings.IndexByte(firstline, '\n'); idx >= 0 {
+		firstline = firstline[:idx]
+	}
+	if strings.HasPrefix(firstline, "#!") {
+		return strings.Contains(strings.ToLower(firstline), "yaml")
+	}
+	if strings.HasPrefix(firstline, "%YAML") || strings.HasPrefix(firstline, "---") {
+		return true
+	}
+	input, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var data interface{}
+	err = yaml.Unmarshal(input, &data)
+	if err != nil {
+		return false
+	}
+	switch data.(type) {
+	case map[string]interface{}, []interface{}:
+		return true
+	}
+	return false
+}
+
+// sort files of arguments
+func (c *yamlsortCmd) runFiles(args []string) error {
+	if len(c.inputfilename) > 0 || len(c.outputfilename) > 0 || len(c.inputoutputfilename) > 0 {
+		return fmt.Errorf("file arguments can not be used with -f , -i , -o")
+	}
+	if c.blnFollowSymlinks && c.blnNoFollowSymlinks {
+		return fmt.Errorf("--follow-symlinks and --no-follow-symlinks can not be used together")
+	}
+	if c.blnWrite && len(c.outputtemplate) > 0 {
+		return fmt.Errorf("-w and --output-template can not be used together")
+	}
+	if c.blnCheck && (c.blnWrite || len(c.outputtemplate) > 0) {
+		return fmt.Errorf("--check can not be used with -w or --output-template")
+	}
+	if c.blnDryRun && !c.blnWrite {
+		return fmt.Errorf("--dry-run requires -w with file arguments")
+	}
+	err := c.checkOutputMode()
+	if err != nil {
+		return err
+	}
+	err = c.prepareOptions()
+	if err != nil {
+		return err
+	}
+	c.outputtmpl, err = parseOutputTemplate(c.outputtemplate)
+	if err != nil {
+		return err
+	}
+	filenames, err := c.collectFiles(args)
+	if err != nil {
+		return err
+	}
+	// progress lines for many files , or large files
+	progress := newFilesProgressReporter(c.stderr, !c.blnNoProgress, filenames)
+	if c.blnCheck {
+		return c.runCheck(filenames, progress)
+	}
+	for _, filename := range filenames {
+		err := c.sortFile(filename, progress)
+		if err != nil {
+			return fmt.Errorf("%s: %v", filename, err)
+		}
+	}
+	progress.finish()
+	return nil
+}
+
+// sort one file. write in place with -w , into --output-template path , or output to stdout.
+func (c *yamlsortCmd) sortFile(filename string, progress *progressReporter) error {
+	snapshot := snapshotFile(filename)
+	input, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	c.inputfilename = filename
+	defer func() { c.inputfilename = "" }()
+	sortInput, decoded, err := c.decodeInput(filename, input)
+	if err != nil {
+		return err
+	}
+	if len(decoded) > 0 && c.blnWrite {
+		return fmt.Errorf("-w can not write yaml into %s input , use --output-template", decoded)
+	}
+	output, err := c.sortBytes(sortInput, progress)
+	if err != nil {
+		return err
+	}
+	output, err = c.formatOutput(filename, output)
+	if err != nil {
+		return err
+	}
+	if c.outputtmpl != nil {
+		return c.writeOutputTemplate(filename, output.Bytes())
+	}
+	if !c.blnWrite {
+		_, err = c.stdout.Write(output.Bytes())
+		return err
+	}
+	if c.blnDryRun {
+		_, err = fmt.Fprint(c.stdout, unifiedDiff(filepath.ToSlash(filename), string(input), output.String()))
+		return err
+	}
+	if output.String() == string(input) {
+		return nil
+	}
+	return c.writeInPlace(filename, input, output.Bytes(), snapshot)
+}
@@ -0,0 +1,108 @@
+//
+// yamlsort - bench subcommand
+//
+package yamlsort
+
+import (
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+)
+
+//---------------------------------------------------------------------
+//  benchCmd class
+// measure unmarshal and sort (myMarshal) throughput of input files.
+//
+type benchCmd struct {
+	yamlsort *yamlsortCmd
+	count    int
+}
+
+func newBenchCmd(yamlsort *yamlsortCmd) *cobra.Command {
+	bench := &benchCmd{
+		yamlsort: yamlsort,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "bench file...",
+		Short: "measure sort throughput on input files",
+		RunE: func(c *cobra.Command, args []string) error {
+			return bench.run(args)
+		},
+	}
+
+	f := cmd.Flags()
+	f.IntVar(&bench.count, "count", 10, "number of iterations per file")
+
+	return cmd
+}
+
+func (b *benchCmd) run(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("bench requires input file names")
+	}
+	if b.count < 1 {
+		return fmt.Errorf("--count must be 1 or more")
+	}
+	if len(globalpriorkeys) == 0 {
+		globalpriorkeys = []string{"name"}
+	}
+	for _, filename := range args {
+		err := b.benchOneFile(filename)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *benchCmd) benchOneFile(filename string) error {
+	input, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	docs, err := splitDocuments(input, defaultMaxLineSize)
+	if err != nil {
+		return fmt.Errorf("%s: %v", filename, err)
+	}
+
+	var unmarshalTime time.Duration
+	var marshalTime time.Duration
+	for i := 0; i < b.count; i++ {
+		for _, doc := range docs {
+			var data interface{}
+			start := time.Now()
+			err := yaml.Unmarshal(doc.data, &data)
+			if err != nil {
+				return fmt.Errorf("%s: %v", filename, err)
+			}
+			unmarshalTime += time.Since(start)
+
+			start = time.Now()
+			_, err = b.yamlsort.myMarshal(data)
+			if err != nil {
+				return fmt.Errorf("%s: %v", filename, err)
+			}
+			marshalTime += time.Since(start)
+		}
+	}
+
+	out := b.yamlsort.stdout
+	fmt.Fprintf(out, "%s: %d bytes, %d documents, %d iterations\n", filename, len(input), len(docs), b.count)
+	b.printResult("unmarshal", len(input), unmarshalTime)
+	b.printResult("myMarshal", len(input), marshalTime)
+	b.printResult("total", len(input), unmarshalTime+marshalTime)
+	return nil
+}
+
+func (b *benchCmd) printResult(title string, size int, total time.Duration) {
+	perOp := total / time.Duration(b.count)
+	mbps := 0.0
+	if perOp > 0 {
+		mbps = float64(size) / perOp.Seconds() / 1000000
+	}
+	fmt.Fprintf(b.yamlsort.stdout, "  %-10s %12v/op %10.2f MB/s\n", title, perOp, mbps)
+}
@@ -0,0 +1,130 @@
+//
+// yamlsort - blank lines between sections
+//   --blank-lines=none              no blank line in document (default)
+//   --blank-lines=keep              blank line before map key , which has blank line in input
+//   --blank-lines=between-top-level blank line between top level keys
+//
+// blank line in input belongs to the next key , so it moves with the key in sorting.
+// keys in lists are not tracked in keep mode.
+//
+package yamlsort
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	blankLinesNone            = "none"
+	blankLinesKeep            = "keep"
+	blankLinesBetweenToplevel = "between-top-level"
+)
+
+func checkBlankLines(mode string) error {
+	switch mode {
+	case "", blankLinesNone, blankLinesKeep, blankLinesBetweenToplevel:
+		return nil
+	}
+	return fmt.Errorf("unknown --blank-lines %q. (keep , none , between-top-level)", mode)
+}
+
+// key line in block map. "  key: value" -> indent 2 , key , value
+func parseKeyLine(line string) (int, string, string, bool) {
+	body := strings.TrimLeft(line, " ")
+	indent := len(line) - len(body)
+	if len(body) == 0 || body[0] == '#' || body[0] == '-' {
+		return 0, "", "", false
+	}
+	key := ""
+	rest := ""
+	if body[0] == '\'' || body[0] == '"' {
+		// quoted key
+		end := strings.IndexByte(body[1:], body[0])
+		if end < 0 || !strings.HasPrefix(body[end+2:], ":") {
+			return 0, "", "", false
+		}
+		key = body[1 : end+1]
+		rest = body[end+3:]
+	} else {
+		idx := strings.Index(body, ":")
+		if idx <= 0 || (idx+1 < len(body) && body[idx+1] != ' ') {
+			return 0, "", "", false
+		}
+		key = strings.TrimRight(body[:idx], " ")
+		rest = body[idx+1:]
+	}
+	return indent, key, strings.TrimSpace(rest), true
+}
+
+// paths of map keys , which have blank lines before them in yaml text
+func blankLinePaths(data []byte) map[string]bool {
+	type pathLevel struct {
+		indent int
+		path   string
+	}
+	result := map[string]bool{}
+	stack := []pathLevel{}
+	blank := false
+	first := true
+	blockindent := -1 // indent of key which has block scalar (| or >)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		body := strings.TrimLeft(line, " ")
+		indent := len(line) - len(body)
+		if len(body) == 0 {
+			blank = true
+			continue
+		}
+		if blockindent >= 0 {
+			if indent > blockindent {
+				blank = false
+				continue
+			}
+			blockindent = -1
+		}
+		if body[0] == '#' {
+			continue
+		}
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		keyindent, key, value, ok := parseKeyLine(line)
+		if !ok {
+			// list item or continued value. keys below are not tracked.
+			stack = append(stack, pathLevel{indent: indent, path: ""})
+			blank = false
+			first = false
+			continue
+		}
+		path := key
+		if len(stack) > 0 {
+			parent := stack[len(stack)-1].path
+			if len(parent) == 0 {
+				path = ""
+			} else {
+				path = parent + "." + key
+			}
+		}
+		if blank && !first && len(path) > 0 {
+			result[path] = true
+		}
+		stack = append(stack, pathLevel{indent: keyindent, path: path})
+		if strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">") {
+			blockindent = keyindent
+		}
+		blank = false
+		first = false
+	}
+	return result
+}
+
+// blank line is written before the key
+func (c *yamlsortCmd) blankLineBefore(parentpath string, path string) bool {
+	switch c.blanklines {
+	case blankLinesKeep:
+		return c.blanklinepaths[path]
+	case blankLinesBetweenToplevel:
+		return len(parentpath) == 0
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+//
+// yamlsort - --canonical-version option
+//
+// pin exact emission rules of output , so later formatting improvements (quoting , wrapping)
+// do not change output , and --check results and checksums of sorted files.
+//   yamlsort --canonical-version=1 -w k8s/
+// default (0) is latest rules. rules which change output are added as new version ,
+// and old versions are kept as is.
+//   1   rules of yamlsort 0.1.x
+//   2   map keys which start with indicators (like @name , #text) or contain ": " are quoted
+//
+package yamlsort
+
+import (
+	"fmt"
+)
+
+// latest canonical version , used when --canonical-version is not set
+const latestCanonicalVersion = 2
+
+// emission rules of canonical version
+type emissionRules struct {
+	quoteWords    []string // strings which are quoted , like "true"
+	quotePrefixes []string // strings which start with these are quoted
+	quoteKeys     bool     // map keys which can not be plain scalar are quoted
+}
+
+// rules of each canonical version. never change rules of released version.
+var canonicalRules = map[int]*emissionRules{
+	1: {
+		quoteWords:    []string{"true", "false", "yes", "no", "on", "off"},
+		quotePrefixes: []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9", ",", "!", "@", "#", "%", "&", "*", "|", "`", "[", "]", "{", "}"},
+	},
+	2: {
+		quoteWords:    []string{"true", "false", "yes", "no", "on", "off"},
+		quotePrefixes: []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9", ",", "!", "@", "#", "%", "&", "*", "|", "`", "[", "]", "{", "}"},
+		quoteKeys:     true,
+	},
+}
+
+// check --canonical-version , and set rules
+func (c *yamlsortCmd) prepareCanonicalVersion() error {
+	version := c.canonicalversion
+	if version == 0 {
+		version = latestCanonicalVersion
+	}
+	rules, ok := canonicalRules[version]
+	if !ok {
+		return fmt.Errorf("unknown --canonical-version %d , 1 to %d", c.canonicalversion, latestCanonicalVersion)
+	}
+	c.rules = rules
+	return nil
+}
+
+// rules of output. latest rules , when options are not prepared.
+func (c *yamlsortCmd) emissionRules() *emissionRules {
+	if c.rules == nil {
+		return canonicalRules[latestCanonicalVersion]
+	}
+	return c.rules
+}
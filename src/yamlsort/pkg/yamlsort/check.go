@@ -0,0 +1,449 @@
+//
+// yamlsort - --check option
+//
+// check that files are sorted , and write nothing. exit 1 when some files are not sorted.
+//   yamlsort --check k8s/
+//   yamlsort --check --format=github k8s/          annotations of GitHub Actions
+//   yamlsort --check --format=gitlab k8s/ > gl.json code quality report of GitLab CI
+//   yamlsort --check --format=junit k8s/ > report.xml
+//   yamlsort --check --format=sarif k8s/ > yamlsort.sarif   SARIF 2.1.0 for code scanning
+// finding has file , line and message.
+//   unsorted       first line which differs from sorted output
+//   duplicate-key  key which appears twice in one map (parser keeps only last value)
+//   parse-error    file can not be parsed
+//   policy         violation of --rules (see rules.go)
+//
+package yamlsort
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// values of --format
+const (
+	checkFormatText   = "text"
+	checkFormatGithub = "github"
+	checkFormatGitlab = "gitlab"
+	checkFormatJunit  = "junit"
+	checkFormatSarif  = "sarif"
+)
+
+// rule names of findings
+const (
+	checkRuleUnsorted     = "unsorted"
+	checkRuleDuplicateKey = "duplicate-key"
+	checkRuleParseError   = "parse-error"
+)
+
+// description of rules , in report order
+var checkRules = []struct {
+	id          string
+	description string
+}{
+	{checkRuleUnsorted, "file is not sorted by yamlsort"},
+	{checkRuleDuplicateKey, "map has duplicate key , and only last value is used"},
+	{checkRuleParseError, "file can not be parsed as yaml"},
+	{checkRulePolicy, "document violates rule of --rules file"},
+}
+
+// returned by run when --check has findings. message is not printed.
+var errCheckFailed = errors.New("check found problems")
+
+// error with exit status of command other than 1 , like 2 of equal and diff-dir (1 is "differ")
+type exitStatusError struct {
+	status int
+	err    error
+}
+
+func (e *exitStatusError) Error() string {
+	return e.err.Error()
+}
+
+// exit status of command for error of run
+func exitStatus(err error) int {
+	if e, ok := err.(*exitStatusError); ok {
+		return e.status
+	}
+	return 1
+}
+
+// errors of command exit with status 2 , and nil is kept
+func withExitStatus2(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &exitStatusError{status: 2, err: err}
+}
+
+// "line 12" in parser error message
+var errorLineRegexp = regexp.MustCompile(`line ([0-9]+)`)
+
+// one problem of file
+type checkFinding struct {
+	file    string
+	line    int
+	rule    string
+	message string
+}
+
+func checkFormat(format string) error {
+	switch format {
+	case checkFormatText, checkFormatGithub, checkFormatGitlab, checkFormatJunit, checkFormatSarif:
+		return nil
+	}
+	return fmt.Errorf("unknown --format %q. (text , github , gitlab , junit , sarif)", format)
+}
+
+// check files , and write report
+func (c *yamlsortCmd) runCheck(filenames []string, progress *progressReporter) error {
+	err := checkFormat(c.checkformat)
+	if err != nil {
+		return err
+	}
+	findings := []checkFinding{}
+	for _, filename := range filenames {
+		filefindings, err := c.checkFile(filename, progress)
+		if err != nil {
+			return fmt.Errorf("%s: %v", filename, err)
+		}
+		findings = append(findings, filefindings...)
+	}
+	progress.finish()
+	err = c.writeCheckReport(filenames, findings)
+	if err != nil {
+		return err
+	}
+	if len(findings) > 0 {
+		return errCheckFailed
+	}
+	return nil
+}
+
+// check one file. empty when file is sorted.
+func (c *yamlsortCmd) checkFile(filename string, progress *progressReporter) ([]checkFinding, error) {
+	input, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	c.inputfilename = filename
+	defer func() { c.inputfilename = "" }()
+	name := filepath.ToSlash(filename)
+	findings := []checkFinding{}
+	for _, dup := range duplicateKeyLines(input) {
+		findings = append(findings, checkFinding{
+			file:    name,
+			line:    dup.line,
+			rule:    checkRuleDuplicateKey,
+			message: fmt.Sprintf("duplicate key %q", dup.key),
+		})
+	}
+	c.violations = nil
+	output, err := c.sortBytes(input, progress)
+	findings = append(findings, violationFindings(name, c.violations)...)
+	if err != nil {
+		line := 1
+		if m := errorLineRegexp.FindStringSubmatch(err.Error()); m != nil {
+			line, _ = strconv.Atoi(m[1])
+		}
+		return append(findings, checkFinding{file: name, line: line, rule: checkRuleParseError, message: err.Error()}), nil
+	}
+	if output.String() != string(input) {
+		findings = append(findings, checkFinding{
+			file:    name,
+			line:    firstDifferentLine(string(input), output.String()),
+			rule:    checkRuleUnsorted,
+			message: "file is not sorted by yamlsort",
+		})
+	}
+	return findings, nil
+}
+
+// duplicate key and its line
+type duplicateKey struct {
+	key  string
+	line int
+}
+
+// keys which appear twice in one block map. "- key:" starts new map in list.
+func duplicateKeyLines(input []byte) []duplicateKey {
+	type mapLevel struct {
+		indent int
+		keys   map[string]bool
+	}
+	result := []duplicateKey{}
+	stack := []mapLevel{}
+	blockindent := -1 // indent of key which has block scalar (| or >)
+	for i, line := range strings.Split(string(input), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		body := strings.TrimLeft(line, " ")
+		indent := len(line) - len(body)
+		if len(body) == 0 || body[0] == '#' {
+			continue
+		}
+		if blockindent >= 0 {
+			if indent > blockindent {
+				continue
+			}
+			blockindent = -1
+		}
+		if marker, _ := documentMarker([]byte(line)); marker != noMarker {
+			stack = stack[:0]
+			continue
+		}
+		newmap := false
+		for strings.HasPrefix(body, "- ") || body == "-" {
+			// element of list. map in element is new map.
+			newmap = true
+			rest := strings.TrimLeft(strings.TrimPrefix(body, "-"), " ")
+			indent += len(body) - len(rest)
+			body = rest
+		}
+		for len(stack) > 0 && (stack[len(stack)-1].indent > indent || (newmap && stack[len(stack)-1].indent == indent)) {
+			stack = stack[:len(stack)-1]
+		}
+		_, key, value, ok := parseKeyLine(strings.Repeat(" ", indent) + body)
+		if !ok {
+			continue
+		}
+		if len(stack) == 0 || stack[len(stack)-1].indent < indent {
+			stack = append(stack, mapLevel{indent: indent, keys: map[string]bool{}})
+		}
+		top := stack[len(stack)-1]
+		if top.keys[key] && key != "<<" {
+			result = append(result, duplicateKey{key: key, line: i + 1})
+		}
+		top.keys[key] = true
+		if strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">") {
+			blockindent = indent
+		}
+	}
+	return result
+}
+
+// first line number (1 origin) which differs
+func firstDifferentLine(a string, b string) int {
+	lines1 := strings.Split(a, "\n")
+	lines2 := strings.Split(b, "\n")
+	for i := range lines1 {
+		if i >= len(lines2) || lines1[i] != lines2[i] {
+			return i + 1
+		}
+	}
+	return len(lines1)
+}
+
+// write findings in --format
+func (c *yamlsortCmd) writeCheckReport(filenames []string, findings []checkFinding) (err error) {
+	defer c.bufferStdout()(&err)
+	switch c.checkformat {
+	case checkFormatGithub:
+		for _, f := range findings {
+			fmt.Fprintf(c.stdout, "::error file=%s,line=%d,title=yamlsort %s::%s\n",
+				githubEscapeProperty(f.file), f.line, f.rule, githubEscapeData(f.message))
+		}
+	case checkFormatGitlab:
+		return c.writeGitlabReport(findings)
+	case checkFormatJunit:
+		return c.writeJunitReport(filenames, findings)
+	case checkFormatSarif:
+		return c.writeSarifReport(findings)
+	default:
+		for _, f := range findings {
+			fmt.Fprintf(c.stdout, "%s:%d: %s\n", f.file, f.line, f.message)
+		}
+	}
+	return nil
+}
+
+// escape message of workflow command
+func githubEscapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escape property of workflow command
+func githubEscapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// code quality report of GitLab CI
+func (c *yamlsortCmd) writeGitlabReport(findings []checkFinding) error {
+	type gitlabLines struct {
+		Begin int `json:"begin"`
+	}
+	type gitlabLocation struct {
+		Path  string      `json:"path"`
+		Lines gitlabLines `json:"lines"`
+	}
+	type gitlabIssue struct {
+		Description string         `json:"description"`
+		CheckName   string         `json:"check_name"`
+		Fingerprint string         `json:"fingerprint"`
+		Severity    string         `json:"severity"`
+		Location    gitlabLocation `json:"location"`
+	}
+	issues := []gitlabIssue{}
+	for _, f := range findings {
+		sum := md5.Sum([]byte(f.file + ":" + f.rule + ":" + f.message))
+		severity := "minor"
+		if f.rule == checkRuleParseError {
+			severity = "major"
+		}
+		issues = append(issues, gitlabIssue{
+			Description: f.message,
+			CheckName:   "yamlsort-" + f.rule,
+			Fingerprint: hex.EncodeToString(sum[:]),
+			Severity:    severity,
+			Location:    gitlabLocation{Path: f.file, Lines: gitlabLines{Begin: f.line}},
+		})
+	}
+	output, err := json.MarshalIndent(issues, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(c.stdout, string(output))
+	return nil
+}
+
+// junit xml report. one test case per file.
+func (c *yamlsortCmd) writeJunitReport(filenames []string, findings []checkFinding) error {
+	type junitFailure struct {
+		Message string `xml:"message,attr"`
+		Type    string `xml:"type,attr"`
+		Text    string `xml:",chardata"`
+	}
+	type junitTestCase struct {
+		Name      string        `xml:"name,attr"`
+		ClassName string        `xml:"classname,attr"`
+		Failure   *junitFailure `xml:"failure,omitempty"`
+	}
+	type junitTestSuite struct {
+		XMLName   xml.Name        `xml:"testsuite"`
+		Name      string          `xml:"name,attr"`
+		Tests     int             `xml:"tests,attr"`
+		Failures  int             `xml:"failures,attr"`
+		TestCases []junitTestCase `xml:"testcase"`
+	}
+	type junitTestSuites struct {
+		XMLName xml.Name `xml:"testsuites"`
+		Suites  []junitTestSuite
+	}
+	byfile := map[string][]checkFinding{}
+	for _, f := range findings {
+		byfile[f.file] = append(byfile[f.file], f)
+	}
+	suite := junitTestSuite{Name: "yamlsort", Tests: len(filenames)}
+	for _, filename := range filenames {
+		name := filepath.ToSlash(filename)
+		testcase := junitTestCase{Name: name, ClassName: "yamlsort"}
+		if filefindings, ok := byfile[name]; ok {
+			// first finding is message , and all findings are text
+			lines := []string{}
+			for _, f := range filefindings {
+				lines = append(lines, fmt.Sprintf("%s:%d: %s", f.file, f.line, f.message))
+			}
+			testcase.Failure = &junitFailure{
+				Message: filefindings[0].message,
+				Type:    filefindings[0].rule,
+				Text:    strings.Join(lines, "\n"),
+			}
+			suite.Failures++
+		}
+		suite.TestCases = append(suite.TestCases, testcase)
+	}
+	output, err := xml.MarshalIndent(junitTestSuites{Suites: []junitTestSuite{suite}}, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(c.stdout, xml.Header)
+	fmt.Fprintln(c.stdout, string(output))
+	return nil
+}
+
+// SARIF 2.1.0 log for code scanning dashboards
+func (c *yamlsortCmd) writeSarifReport(findings []checkFinding) error {
+	type sarifText struct {
+		Text string `json:"text"`
+	}
+	type sarifRule struct {
+		ID               string    `json:"id"`
+		ShortDescription sarifText `json:"shortDescription"`
+	}
+	type sarifDriver struct {
+		Name           string      `json:"name"`
+		InformationURI string      `json:"informationUri"`
+		Version        string      `json:"version,omitempty"`
+		Rules          []sarifRule `json:"rules"`
+	}
+	type sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	type sarifRegion struct {
+		StartLine int `json:"startLine"`
+	}
+	type sarifArtifact struct {
+		URI string `json:"uri"`
+	}
+	type sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifact `json:"artifactLocation"`
+		Region           sarifRegion   `json:"region"`
+	}
+	type sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+	type sarifResult struct {
+		RuleID    string          `json:"ruleId"`
+		Level     string          `json:"level"`
+		Message   sarifText       `json:"message"`
+		Locations []sarifLocation `json:"locations"`
+	}
+	type sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+	type sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+	driver := sarifDriver{Name: "yamlsort", InformationURI: "https://github.com/keita69/yamlsort", Version: c.version}
+	for _, rule := range checkRules {
+		driver.Rules = append(driver.Rules, sarifRule{ID: rule.id, ShortDescription: sarifText{Text: rule.description}})
+	}
+	results := []sarifResult{}
+	for _, f := range findings {
+		level := "error"
+		if f.rule == checkRuleUnsorted {
+			level = "warning"
+		}
+		results = append(results, sarifResult{
+			RuleID:  f.rule,
+			Level:   level,
+			Message: sarifText{Text: f.message},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifact{URI: f.file},
+				Region:           sarifRegion{StartLine: f.line},
+			}}},
+		})
+	}
+	log := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}
+	output, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(c.stdout, string(output))
+	return nil
+}
@@ -0,0 +1,99 @@
+//
+// yamlsort - --checksum-files option
+//
+// for values which refer external files (like files of kustomize configMapGenerator) ,
+// write sha256 of file contents as annotation , so sorted manifests carry change detection metadata.
+//   yamlsort --checksum-files 'configMapGenerator[*].files[*]' -w kustomization.yaml
+//   metadata:
+//     annotations:
+//       checksum/files: 3a7bd3e2360a3d29eea436fcfb7e44c735d117c42d1c1835420b6b9942dd4f1b
+// file path is relative to directory of input file. "key=path" form is also accepted.
+// annotation is metadata.annotations.<--checksum-annotation> , and updated when files change.
+//
+package yamlsort
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// default of --checksum-annotation
+const defaultChecksumAnnotation = "checksum/files"
+
+// parse --checksum-files
+func (c *yamlsortCmd) prepareChecksum() error {
+	c.checksumsegs = nil
+	for _, path := range c.checksumpaths {
+		segs, err := parsePath(path)
+		if err != nil {
+			return fmt.Errorf("--checksum-files: %v", err)
+		}
+		c.checksumsegs = append(c.checksumsegs, segs)
+	}
+	if len(c.checksumannotation) == 0 {
+		c.checksumannotation = defaultChecksumAnnotation
+	}
+	return nil
+}
+
+// file names referred at --checksum-files paths , in document order of paths
+func (c *yamlsortCmd) checksumReferences(data interface{}) []string {
+	result := []string{}
+	for _, segs := range c.checksumsegs {
+		mapPathNodes(data, segs, func(node interface{}) (interface{}, error) {
+			if s, ok := node.(string); ok && len(s) > 0 {
+				// "key=path" of configMapGenerator
+				if idx := strings.Index(s, "="); idx >= 0 {
+					s = s[idx+1:]
+				}
+				result = append(result, s)
+			}
+			return node, nil
+		})
+	}
+	return result
+}
+
+// sha256 of referred files. hash of each file is combined with its name.
+func (c *yamlsortCmd) filesChecksum(references []string) (string, error) {
+	dir := "."
+	if len(c.inputfilename) > 0 && !isObjectURI(c.inputfilename) {
+		dir = filepath.Dir(c.inputfilename)
+	}
+	total := sha256.New()
+	for _, reference := range references {
+		filename := reference
+		if !filepath.IsAbs(filename) {
+			filename = filepath.Join(dir, filename)
+		}
+		content, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return "", fmt.Errorf("--checksum-files: %v", err)
+		}
+		sum := sha256.Sum256(content)
+		fmt.Fprintf(total, "%s\n%s\n", reference, hex.EncodeToString(sum[:]))
+	}
+	return hex.EncodeToString(total.Sum(nil)), nil
+}
+
+// write checksum annotation of referred files
+func (c *yamlsortCmd) applyChecksum(data interface{}) (interface{}, error) {
+	references := c.checksumReferences(data)
+	if len(references) == 0 {
+		return data, nil
+	}
+	checksum, err := c.filesChecksum(references)
+	if err != nil {
+		return data, err
+	}
+	segs := []pathSegment{
+		{kind: segKey, key: "metadata"},
+		{kind: segKey, key: "annotations"},
+		{kind: segKey, key: c.checksumannotation},
+	}
+	return setPath(data, segs, checksum)
+}
@@ -0,0 +1,59 @@
+//
+// yamlsort - system clipboard
+//
+// clipboard is used by commands of each system.
+//   copy   pbcopy (macOS) , wl-copy (wayland) , xclip , xsel (X11) , clip (windows)
+//   paste  pbpaste (macOS) , wl-paste (wayland) , xclip , xsel (X11) , powershell Get-Clipboard (windows)
+//   yamlsort --clipboard-in --clipboard-out    sort yaml text in clipboard
+//
+package yamlsort
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// copy text to clipboard with pbcopy , wl-copy , xclip , xsel or clip
+func copyToClipboard(text string) error {
+	candidates := [][]string{
+		{"pbcopy"},
+		{"wl-copy"},
+		{"xclip", "-selection", "clipboard"},
+		{"xsel", "--clipboard", "--input"},
+		{"clip"},
+	}
+	for _, candidate := range candidates {
+		path, err := exec.LookPath(candidate[0])
+		if err != nil {
+			continue
+		}
+		cmd := exec.Command(path, candidate[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		return cmd.Run()
+	}
+	return fmt.Errorf("clipboard command (pbcopy , wl-copy , xclip , xsel , clip) is not found")
+}
+
+// text of clipboard with pbpaste , wl-paste , xclip , xsel or powershell
+func readClipboard() ([]byte, error) {
+	candidates := [][]string{
+		{"pbpaste"},
+		{"wl-paste", "--no-newline"},
+		{"xclip", "-selection", "clipboard", "-o"},
+		{"xsel", "--clipboard", "--output"},
+		{"powershell", "-NoProfile", "-Command", "Get-Clipboard -Raw"},
+	}
+	for _, candidate := range candidates {
+		path, err := exec.LookPath(candidate[0])
+		if err != nil {
+			continue
+		}
+		out, err := exec.Command(path, candidate[1:]...).Output()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", candidate[0], err)
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("clipboard command (pbpaste , wl-paste , xclip , xsel , powershell) is not found")
+}
@@ -0,0 +1,52 @@
+//
+// yamlsort - comment normalization
+//   --comment-space , --comment-column , --drop-comment
+//
+package yamlsort
+
+import (
+	"regexp"
+	"strings"
+)
+
+// normalize one comment line. return false when the line is dropped.
+func (c *yamlsortCmd) normalizeComment(line string) (string, bool) {
+	body := strings.TrimLeft(line, " \t")
+	indent := line[:len(line)-len(body)]
+	if c.dropcommentregexp != nil && c.dropcommentregexp.MatchString(body) {
+		return "", false
+	}
+	if c.blnCommentSpace && len(body) > 1 && body[0] == '#' {
+		// "#comment" -> "# comment". "##" and "#!" are kept.
+		if body[1] != ' ' && body[1] != '#' && body[1] != '!' {
+			body = "# " + body[1:]
+		}
+	}
+	return indent + body, true
+}
+
+// first line comment of document , which is followed by "# powered by ..." comment
+func (c *yamlsortCmd) normalizeHeaderComment(firstlinestr string) string {
+	comment := strings.TrimRight(firstlinestr, " ")
+	if len(comment) == 0 {
+		return firstlinestr
+	}
+	comment, keep := c.normalizeComment(comment)
+	if !keep {
+		return ""
+	}
+	// align "# powered by" comment to column
+	padding := 2
+	if c.commentcolumn > len(comment)+padding {
+		padding = c.commentcolumn - len(comment)
+	}
+	return comment + strings.Repeat(" ", padding)
+}
+
+// compile --drop-comment regexp
+func compileDropComment(expr string) (*regexp.Regexp, error) {
+	if len(expr) == 0 {
+		return nil, nil
+	}
+	return regexp.Compile(expr)
+}
@@ -0,0 +1,152 @@
+//
+// yamlsort - comparator plugins
+//
+// comparator plugin is executable file yamlsort-comparator-<name> on PATH. it orders keys of
+// maps , instead of (after) order of yamlsort. --comparator name , or comparator: name in preset.
+// plugin is started once , and reads one request in json per line from stdin.
+//
+//   {"path":"spec.template","keys":["metadata","spec"]}
+//
+// it writes keys of request in new order , as json list in one line to stdout.
+//
+//   ["spec","metadata"]
+//
+// keys in request are sorted by yamlsort (with --key). plugin exits when stdin is closed ,
+// and yamlsort waits for it at the end of command (or daemon request , lsp , library Options.Close).
+//
+package yamlsort
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// prefix of comparator plugin executable name
+const comparatorCommandPrefix = "yamlsort-comparator-"
+
+// request line to comparator plugin
+type comparatorRequest struct {
+	Path string   `json:"path"`
+	Keys []string `json:"keys"`
+}
+
+//---------------------------------------------------------------------
+//  keyComparator class
+// running comparator plugin
+//
+type keyComparator struct {
+	name   string
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+}
+
+// start comparator plugin
+func newKeyComparator(name string, version string, environ []string) (*keyComparator, error) {
+	path, err := exec.LookPath(comparatorCommandPrefix + name)
+	if err != nil {
+		return nil, fmt.Errorf("comparator %q is not found. (%s%s is not in PATH)", name, comparatorCommandPrefix, name)
+	}
+	cmd := exec.Command(path)
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(environ, "YAMLSORT_VERSION="+version)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	err = cmd.Start()
+	if err != nil {
+		return nil, fmt.Errorf("comparator %q failed: %v", name, err)
+	}
+	return &keyComparator{name: name, cmd: cmd, stdin: stdin, stdout: bufio.NewReader(stdout)}, nil
+}
+
+// order keys of map at path by plugin
+func (k *keyComparator) order(path string, keylist []string) error {
+	if len(keylist) < 2 {
+		return nil
+	}
+	request, err := json.Marshal(comparatorRequest{Path: path, Keys: keylist})
+	if err != nil {
+		return err
+	}
+	_, err = k.stdin.Write(append(request, '\n'))
+	if err != nil {
+		return fmt.Errorf("comparator %q: %v", k.name, err)
+	}
+	line, err := k.stdout.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("comparator %q: no answer for %s: %v", k.name, pathOrTop(path), err)
+	}
+	result := []string{}
+	err = json.Unmarshal([]byte(strings.TrimSpace(line)), &result)
+	if err != nil {
+		return fmt.Errorf("comparator %q: answer for %s: %v", k.name, pathOrTop(path), err)
+	}
+	// answer must have same keys
+	rest := map[string]bool{}
+	for _, key := range keylist {
+		rest[key] = true
+	}
+	for _, key := range result {
+		if !rest[key] {
+			return fmt.Errorf("comparator %q: answer for %s has unknown or repeated key %q", k.name, pathOrTop(path), key)
+		}
+		delete(rest, key)
+	}
+	if len(rest) > 0 {
+		return fmt.Errorf("comparator %q: answer for %s has not all keys", k.name, pathOrTop(path))
+	}
+	copy(keylist, result)
+	return nil
+}
+
+// close stdin of plugin , and wait for exit
+func (k *keyComparator) close() error {
+	k.stdin.Close()
+	return k.cmd.Wait()
+}
+
+// close stdin of running plugin , and wait for exit. commands , daemon requests ,
+// lsp and library options call it after their run.
+func (c *yamlsortCmd) closeComparator() error {
+	if c.comparator == nil {
+		return nil
+	}
+	comparator := c.comparator
+	c.comparator = nil
+	err := comparator.close()
+	if err != nil {
+		return fmt.Errorf("comparator %q: %v", comparator.name, err)
+	}
+	return nil
+}
+
+// start plugin of --comparator. plugin which is running is used again.
+func (c *yamlsortCmd) prepareComparator() error {
+	if len(c.comparatorname) == 0 {
+		return nil
+	}
+	if c.comparator != nil {
+		if c.comparator.name == c.comparatorname {
+			return nil
+		}
+		c.comparator.close()
+		c.comparator = nil
+	}
+	comparator, err := newKeyComparator(c.comparatorname, c.version, c.environment())
+	if err != nil {
+		return err
+	}
+	c.comparator = comparator
+	return nil
+}
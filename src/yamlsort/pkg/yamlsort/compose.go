@@ -0,0 +1,269 @@
+//
+// yamlsort - compose-config subcommand
+//
+// merge docker-compose.yml and override files with compose merge rules , and output
+// effective canonical configuration sorted. like "docker compose config" , but offline.
+//   yamlsort compose-config                                   docker-compose.yml (+ docker-compose.override.yml)
+//   yamlsort compose-config -f compose.yml -f compose.prod.yml
+//
+// merge rules of services
+//   ports , expose , external_links , dns , dns_search , tmpfs   concatenated (duplicates removed)
+//   environment , labels                                         merged by name (list form becomes map)
+//   volumes , devices                                            merged by mount path in container
+//   command , entrypoint , and other single values               replaced by override
+//   other maps                                                   merged recursively
+// with --envsubst , ${VAR} is expanded (--env-file .env for compose .env file).
+//
+package yamlsort
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+)
+
+// default compose files , in priority order
+var composeDefaultFiles = []string{"compose.yaml", "compose.yml", "docker-compose.yml", "docker-compose.yaml"}
+
+// default override files
+var composeOverrideFiles = []string{"compose.override.yaml", "compose.override.yml", "docker-compose.override.yml", "docker-compose.override.yaml"}
+
+// service keys which are concatenated
+var composeConcatKeys = map[string]bool{
+	"ports": true, "expose": true, "external_links": true, "dns": true, "dns_search": true, "tmpfs": true,
+}
+
+//---------------------------------------------------------------------
+//  compose-config subcommand
+//
+func newComposeConfigCmd(yamlsort *yamlsortCmd) *cobra.Command {
+	var filenames []string
+
+	cmd := &cobra.Command{
+		Use:   "compose-config [-f file]...",
+		Short: "merge compose file and override files , and output effective configuration sorted",
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(filenames) == 0 {
+				filenames = composeFiles()
+				if len(filenames) == 0 {
+					return fmt.Errorf("compose file (%s) is not found", strings.Join(composeDefaultFiles, " , "))
+				}
+			}
+			return yamlsort.composeConfig(filenames)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringArrayVarP(&filenames, "file", "f", []string{}, "compose file. later file overrides. (can specify multiple files)")
+	f.BoolVar(&yamlsort.blnEnvsubst, "envsubst", false, "expand ${VAR} and ${VAR:-default} with environment variables")
+	f.StringArrayVar(&yamlsort.envfilenames, "env-file", []string{}, "path to env file (KEY=VALUE lines) for --envsubst")
+
+	return cmd
+}
+
+// default compose file and override file in current directory
+func composeFiles() []string {
+	result := []string{}
+	for _, names := range [][]string{composeDefaultFiles, composeOverrideFiles} {
+		for _, name := range names {
+			if _, err := os.Stat(name); err == nil {
+				result = append(result, name)
+				break
+			}
+		}
+		if len(result) == 0 {
+			// override file is used only with compose file
+			return result
+		}
+	}
+	return result
+}
+
+func (c *yamlsortCmd) composeConfig(filenames []string) error {
+	var merged map[string]interface{}
+	for _, filename := range filenames {
+		input, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		var data map[string]interface{}
+		err = yaml.Unmarshal(input, &data)
+		if err != nil {
+			return fmt.Errorf("%s: %v", filename, err)
+		}
+		normalizeComposeFile(data)
+		if merged == nil {
+			merged = data
+		} else {
+			merged = mergeComposeFile(merged, data)
+		}
+	}
+
+	text, err := yaml.Marshal(merged)
+	if err != nil {
+		return err
+	}
+	c.maxlinesize = defaultMaxLineSize
+	err = c.prepareOptions()
+	if err != nil {
+		return err
+	}
+	output, err := c.sortBytes(text, nil)
+	if err != nil {
+		return err
+	}
+	_, err = c.stdout.Write(output.Bytes())
+	return err
+}
+
+// canonical form of compose file
+//   environment and labels in list form become map , build string becomes build.context ,
+//   single string of dns , dns_search and tmpfs becomes list
+func normalizeComposeFile(data map[string]interface{}) {
+	services, ok := data["services"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	for _, s := range services {
+		service, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, key := range []string{"environment", "labels"} {
+			if list, ok := service[key].([]interface{}); ok {
+				service[key] = composeListToMap(list)
+			}
+		}
+		if build, ok := service["build"].(string); ok {
+			service["build"] = map[string]interface{}{"context": build}
+		}
+		for _, key := range []string{"dns", "dns_search", "tmpfs"} {
+			if str, ok := service[key].(string); ok {
+				service[key] = []interface{}{str}
+			}
+		}
+	}
+}
+
+// ["KEY=VALUE" , "KEY"] -> {KEY: VALUE , KEY: null}
+func composeListToMap(list []interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for _, item := range list {
+		str := fmt.Sprint(item)
+		if idx := strings.Index(str, "="); idx >= 0 {
+			result[str[:idx]] = str[idx+1:]
+		} else {
+			result[str] = nil
+		}
+	}
+	return result
+}
+
+// merge override into base compose file
+func mergeComposeFile(base map[string]interface{}, override map[string]interface{}) map[string]interface{} {
+	for k, v := range override {
+		if k != "services" {
+			base[k] = mergeComposeMap(base[k], v)
+			continue
+		}
+		baseservices, ok1 := base[k].(map[string]interface{})
+		services, ok2 := v.(map[string]interface{})
+		if !ok1 || !ok2 {
+			base[k] = v
+			continue
+		}
+		for name, service := range services {
+			baseservice, ok1 := baseservices[name].(map[string]interface{})
+			overrideservice, ok2 := service.(map[string]interface{})
+			if ok1 && ok2 {
+				baseservices[name] = mergeComposeService(baseservice, overrideservice)
+			} else {
+				baseservices[name] = service
+			}
+		}
+	}
+	return base
+}
+
+// maps are merged recursively , and other values are replaced
+func mergeComposeMap(base interface{}, override interface{}) interface{} {
+	bm, ok1 := base.(map[string]interface{})
+	om, ok2 := override.(map[string]interface{})
+	if !ok1 || !ok2 {
+		return override
+	}
+	for k, v := range om {
+		bm[k] = mergeComposeMap(bm[k], v)
+	}
+	return bm
+}
+
+// merge override service into base service
+func mergeComposeService(base map[string]interface{}, override map[string]interface{}) map[string]interface{} {
+	for k, v := range override {
+		bl, ok1 := base[k].([]interface{})
+		ol, ok2 := v.([]interface{})
+		switch {
+		case composeConcatKeys[k] && ok1 && ok2:
+			for _, item := range ol {
+				if !composeContains(bl, item) {
+					bl = append(bl, item)
+				}
+			}
+			base[k] = bl
+		case (k == "volumes" || k == "devices") && ok1 && ok2:
+			base[k] = mergeComposeMounts(bl, ol)
+		case k == "command" || k == "entrypoint":
+			base[k] = v
+		default:
+			base[k] = mergeComposeMap(base[k], v)
+		}
+	}
+	return base
+}
+
+func composeContains(list []interface{}, value interface{}) bool {
+	for _, item := range list {
+		if reflect.DeepEqual(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// merge volumes or devices by mount path in container
+func mergeComposeMounts(base []interface{}, override []interface{}) []interface{} {
+	result := append([]interface{}{}, base...)
+	for _, item := range override {
+		replaced := false
+		for i, baseitem := range result {
+			if composeMountPath(baseitem) == composeMountPath(item) {
+				result[i] = item
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// mount path in container of "source:target[:mode]" , "target" , or {target: ...}
+func composeMountPath(item interface{}) string {
+	if m, ok := item.(map[string]interface{}); ok {
+		return fmt.Sprint(m["target"])
+	}
+	parts := strings.Split(fmt.Sprint(item), ":")
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	return parts[1]
+}
+
@@ -0,0 +1,152 @@
+//
+// yamlsort - --configmap-data option
+//
+// normalize data values of ConfigMap , so diffs of app config stay clean.
+//   yamlsort --configmap-data -i configmap.yaml
+//   yamlsort --configmap-data=sort -i configmap.yaml
+// modes
+//   format   multi-line values are written as block scalar ( | ) , and end with one line break
+//   sort     format , and lines of .properties and .env values are sorted by key
+// in sort mode , comment lines stay with next entry , and blank lines are removed. continuation
+// lines (ending with \) stay with their entry. items of List are changed. binaryData is not changed.
+//
+package yamlsort
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// values of --configmap-data
+const (
+	configMapDataFormat = "format"
+	configMapDataSort   = "sort"
+)
+
+// extensions of data keys , which are sorted in sort mode
+var configMapPropertiesExts = []string{".properties", ".env"}
+
+func checkConfigMapData(mode string) error {
+	switch mode {
+	case "", configMapDataFormat, configMapDataSort:
+		return nil
+	}
+	return fmt.Errorf("unknown --configmap-data %q. (format , sort)", mode)
+}
+
+// normalize data of ConfigMap (and items of List)
+func (c *yamlsortCmd) applyConfigMapData(data interface{}) {
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return
+	}
+	kind := k8sString(m, "kind")
+	if items, ok := m["items"].([]interface{}); ok && strings.HasSuffix(kind, "List") {
+		for _, item := range items {
+			c.applyConfigMapData(item)
+		}
+		return
+	}
+	if kind != "ConfigMap" {
+		return
+	}
+	values := k8sMap(m, "data")
+	for k, v := range values {
+		s, ok := v.(string)
+		if !ok || !strings.Contains(strings.TrimRight(s, "\n"), "\n") {
+			continue
+		}
+		if c.configmapdata == configMapDataSort && hasExtension(k, configMapPropertiesExts) {
+			s = sortPropertiesLines(s)
+		}
+		// one line break at end
+		s = strings.TrimRight(s, "\n") + "\n"
+		c.markBlockString(s)
+		values[k] = s
+	}
+}
+
+// name ends with one of extensions
+func hasExtension(name string, exts []string) bool {
+	for _, ext := range exts {
+		if strings.HasSuffix(strings.ToLower(name), ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// key of properties line , like "key=value" , "key: value" , "key value" , "export KEY=value"
+func propertiesKey(line string) string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "export ")
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '=', ':', ' ', '\t':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// line continues to next line (odd number of \ at end)
+func continuesLine(line string) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// string-number-string order without prior keys
+func naturalLess(s1 string, s2 string) bool {
+	slice1, err1 := convertStringToUint64Slice(s1)
+	slice2, err2 := convertStringToUint64Slice(s2)
+	if err1 != nil || err2 != nil {
+		return s1 < s2
+	}
+	return compairUint64Slice(slice1, slice2)
+}
+
+// sort entries of properties text by key
+func sortPropertiesLines(text string) string {
+	type entry struct {
+		key   string
+		lines []string
+	}
+	entries := []entry{}
+	pending := []string{} // comment lines before entry
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if len(trimmed) == 0 {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "!") {
+			pending = append(pending, line)
+			continue
+		}
+		e := entry{key: propertiesKey(line), lines: append(pending, line)}
+		pending = []string{}
+		for continuesLine(line) && i+1 < len(lines) {
+			i++
+			line = lines[i]
+			e.lines = append(e.lines, line)
+		}
+		entries = append(entries, e)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return naturalLess(entries[i].key, entries[j].key)
+	})
+	result := []string{}
+	for _, e := range entries {
+		result = append(result, e.lines...)
+	}
+	// comments at end
+	result = append(result, pending...)
+	return strings.Join(result, "\n") + "\n"
+}
@@ -0,0 +1,36 @@
+// +build !windows
+
+//
+// yamlsort - console of unix like systems
+//
+package yamlsort
+
+import (
+	"fmt"
+)
+
+// terminal device for prompts , when stdin is not terminal
+const terminalInputPath = "/dev/tty"
+
+// console needs no setup
+func prepareConsole() {
+}
+
+// console needs no restore
+func restoreConsole() {
+}
+
+// files are not locked by other processes
+func isFileLockError(err error) bool {
+	return false
+}
+
+// raw mode of windows console (stty is used on other systems)
+func consoleRawMode() (func(), error) {
+	return nil, fmt.Errorf("console raw mode is only for windows")
+}
+
+// size of windows console (stty is used on other systems)
+func consoleSize() (int, int, bool) {
+	return 0, 0, false
+}
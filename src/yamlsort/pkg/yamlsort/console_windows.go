@@ -0,0 +1,139 @@
+// +build windows
+
+//
+// yamlsort - windows console
+//
+// output is utf-8 , so code page of console is set to utf-8 (65001).
+// escape sequences of tui are enabled with virtual terminal processing of console.
+// code page and modes of console are restored on exit , so the shell keeps its settings.
+//
+package yamlsort
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// console device for prompts , when stdin is not console
+const terminalInputPath = "CONIN$"
+
+// console modes
+const (
+	enableProcessedInput            = 0x0001
+	enableLineInput                 = 0x0002
+	enableEchoInput                 = 0x0004
+	enableVirtualTerminalInput      = 0x0200
+	enableVirtualTerminalProcessing = 0x0004
+	utf8CodePage                    = 65001
+)
+
+// windows error codes of locked file
+const (
+	errorSharingViolation syscall.Errno = 32
+	errorLockViolation    syscall.Errno = 33
+)
+
+var (
+	kernel32                       = syscall.NewLazyDLL("kernel32.dll")
+	procSetConsoleMode             = kernel32.NewProc("SetConsoleMode")
+	procSetConsoleOutputCP         = kernel32.NewProc("SetConsoleOutputCP")
+	procGetConsoleOutputCP         = kernel32.NewProc("GetConsoleOutputCP")
+	procGetConsoleScreenBufferInfo = kernel32.NewProc("GetConsoleScreenBufferInfo")
+)
+
+// settings of console before prepareConsole
+var savedConsole struct {
+	codepage uintptr // 0 when code page is not changed
+	modes    map[syscall.Handle]uint32
+}
+
+// utf-8 code page and escape sequences for console output
+func prepareConsole() {
+	savedConsole.modes = map[syscall.Handle]uint32{}
+	for _, f := range []*os.File{os.Stdout, os.Stderr} {
+		var mode uint32
+		handle := syscall.Handle(f.Fd())
+		if syscall.GetConsoleMode(handle, &mode) != nil {
+			// redirected to file or pipe
+			continue
+		}
+		if savedConsole.codepage == 0 {
+			codepage, _, _ := procGetConsoleOutputCP.Call()
+			if codepage != 0 && codepage != utf8CodePage {
+				if r, _, _ := procSetConsoleOutputCP.Call(utf8CodePage); r != 0 {
+					savedConsole.codepage = codepage
+				}
+			}
+		}
+		if _, ok := savedConsole.modes[handle]; !ok {
+			savedConsole.modes[handle] = mode
+			procSetConsoleMode.Call(uintptr(handle), uintptr(mode|enableVirtualTerminalProcessing))
+		}
+	}
+}
+
+// restore code page and modes of console
+func restoreConsole() {
+	if savedConsole.codepage != 0 {
+		procSetConsoleOutputCP.Call(savedConsole.codepage)
+		savedConsole.codepage = 0
+	}
+	for handle, mode := range savedConsole.modes {
+		procSetConsoleMode.Call(uintptr(handle), uintptr(mode))
+	}
+	savedConsole.modes = nil
+}
+
+// file is locked by other process (editor , virus scanner , indexer)
+func isFileLockError(err error) bool {
+	if pathErr, ok := err.(*os.PathError); ok {
+		err = pathErr.Err
+	}
+	return err == errorSharingViolation || err == errorLockViolation
+}
+
+// raw mode of console input. returns function to restore.
+func consoleRawMode() (func(), error) {
+	handle := syscall.Handle(os.Stdin.Fd())
+	var mode uint32
+	err := syscall.GetConsoleMode(handle, &mode)
+	if err != nil {
+		return nil, fmt.Errorf("stdin is not a console: %v", err)
+	}
+	raw := mode&^(enableProcessedInput|enableLineInput|enableEchoInput) | enableVirtualTerminalInput
+	if r, _, err := procSetConsoleMode.Call(uintptr(handle), uintptr(raw)); r == 0 {
+		return nil, err
+	}
+	return func() {
+		procSetConsoleMode.Call(uintptr(handle), uintptr(mode))
+	}, nil
+}
+
+// CONSOLE_SCREEN_BUFFER_INFO
+type consoleScreenBufferInfo struct {
+	size              [2]int16
+	cursorPosition    [2]int16
+	attributes        uint16
+	window            [4]int16 // left , top , right , bottom
+	maximumWindowSize [2]int16
+}
+
+// size of console window (width , height)
+func consoleSize() (int, int, bool) {
+	var info consoleScreenBufferInfo
+	r, _, _ := procGetConsoleScreenBufferInfo.Call(os.Stdout.Fd(), uintptr(unsafe.Pointer(&info)))
+	if r == 0 {
+		return 0, 0, false
+	}
+	width := int(info.window[2]-info.window[0]) + 1
+	height := int(info.window[3]-info.window[1]) + 1
+	return width, height, width > 0 && height > 1
+}
+
+// file is console. NUL and other devices are not console.
+func isTerminalFile(fp *os.File) bool {
+	var mode uint32
+	return syscall.GetConsoleMode(syscall.Handle(fp.Fd()), &mode) == nil
+}
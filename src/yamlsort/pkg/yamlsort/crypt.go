@@ -0,0 +1,374 @@
+//
+// yamlsort - encrypt / decrypt subcommands
+//
+// encrypt scalar values at paths in place , and keep other values sorted and readable.
+//   yamlsort encrypt --key-path key.txt --paths 'data.*' -w secret.yaml
+//   yamlsort decrypt --key-path key.txt secret.yaml
+// encrypted value is ENC[method,data:base64,type:str|num|bool]. type is restored by decrypt.
+// path and type of value are authenticated (additional data of AES-GCM , and header of age
+// plain text) , so value moved to other path or type can not be decrypted.
+// only strings , numbers and bools are encrypted. null is kept.
+// key file
+//   age identity (AGE-SECRET-KEY-... , by age-keygen)   age command on PATH is used
+//   32 bytes key in hex or base64 (openssl rand -hex 32)  AES-256-GCM
+// values already encrypted are kept , so encrypting again does not change file.
+// decrypt decrypts all encrypted values , or values at --paths.
+//
+package yamlsort
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// encryption methods
+const (
+	cryptAESGCM = "AES256_GCM"
+	cryptAge    = "AGE"
+)
+
+// ENC[method,data:...,type:...]
+var encryptedValueRegexp = regexp.MustCompile(`^ENC\[([A-Z0-9_]+),data:([A-Za-z0-9+/=]*),type:(str|num|bool)\]$`)
+
+//---------------------------------------------------------------------
+//  valueCrypter class
+// encrypt and decrypt scalar values with key file
+//
+type valueCrypter struct {
+	method   string
+	keypath  string
+	aead     cipher.AEAD // AES256_GCM
+	paths    [][]pathSegment
+	blnCrypt bool // encrypt (true) or decrypt
+}
+
+// crypter of key file
+func newValueCrypter(keypath string, paths []string) (*valueCrypter, error) {
+	if len(keypath) == 0 {
+		return nil, fmt.Errorf("--key-path is required")
+	}
+	keytext, err := ioutil.ReadFile(keypath)
+	if err != nil {
+		return nil, err
+	}
+	v := &valueCrypter{keypath: keypath}
+	for _, path := range paths {
+		segs, err := parsePath(path)
+		if err != nil {
+			return nil, err
+		}
+		v.paths = append(v.paths, segs)
+	}
+	if bytes.Contains(keytext, []byte("AGE-SECRET-KEY-")) {
+		if _, err := exec.LookPath("age"); err != nil {
+			return nil, fmt.Errorf("age key requires age command on PATH")
+		}
+		v.method = cryptAge
+		return v, nil
+	}
+	key, err := parseAESKey(strings.TrimSpace(string(keytext)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", keypath, err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	v.aead, err = cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	v.method = cryptAESGCM
+	return v, nil
+}
+
+// 32 bytes key of hex or base64 text
+func parseAESKey(text string) ([]byte, error) {
+	if key, err := hex.DecodeString(text); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(text); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	return nil, fmt.Errorf("key must be age identity , or 32 bytes in hex or base64")
+}
+
+// encrypt or decrypt values of document data
+func (v *valueCrypter) apply(data interface{}) (interface{}, error) {
+	if len(v.paths) == 0 {
+		if v.blnCrypt {
+			return data, fmt.Errorf("--paths is required")
+		}
+		return v.walk(data, nil)
+	}
+	var err error
+	for _, segs := range v.paths {
+		data, err = mapPathNodesAt(data, segs, nil, v.walk)
+		if err != nil {
+			return data, err
+		}
+	}
+	return data, nil
+}
+
+// encrypt or decrypt scalar values under node at path
+func (v *valueCrypter) walk(node interface{}, at []pathSegment) (interface{}, error) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for k, child := range n {
+			result, err := v.walk(child, appendSegment(at, pathSegment{kind: segKey, key: k}))
+			if err != nil {
+				return node, err
+			}
+			n[k] = result
+		}
+		return n, nil
+	case []interface{}:
+		for i, child := range n {
+			result, err := v.walk(child, appendSegment(at, pathSegment{kind: segIndex, index: i}))
+			if err != nil {
+				return node, err
+			}
+			n[i] = result
+		}
+		return n, nil
+	case nil:
+		return node, nil
+	}
+	if v.blnCrypt {
+		return v.encryptValue(node, cryptPathString(at))
+	}
+	return v.decryptValue(node, cryptPathString(at))
+}
+
+// path in authenticated data. keys are not quoted (like ["a.b"]) , so values
+// encrypted by older versions can be decrypted.
+func cryptPathString(segs []pathSegment) string {
+	result := ""
+	for i, s := range segs {
+		if s.kind == segKey {
+			if i > 0 {
+				result = result + "."
+			}
+			result = result + s.key
+		} else {
+			result = result + s.String()
+		}
+	}
+	return result
+}
+
+// authenticated data of value , like "data.password:str"
+func cryptAdditionalData(path string, valuetype string) []byte {
+	return []byte(path + ":" + valuetype)
+}
+
+// ENC[...] text of scalar value. encrypted value is kept.
+func (v *valueCrypter) encryptValue(value interface{}, path string) (interface{}, error) {
+	text := ""
+	valuetype := "str"
+	switch s := value.(type) {
+	case string:
+		if encryptedValueRegexp.MatchString(s) {
+			return value, nil
+		}
+		text = s
+	case float64:
+		text = strconv.FormatFloat(s, 'g', -1, 64)
+		valuetype = "num"
+	case bool:
+		text = strconv.FormatBool(s)
+		valuetype = "bool"
+	default:
+		return value, fmt.Errorf("%s: value of type %T can not be encrypted", pathOrTop(path), value)
+	}
+	ad := cryptAdditionalData(path, valuetype)
+	var ciphertext []byte
+	if v.method == cryptAge {
+		// age has no additional data. it is header line of plain text.
+		var err error
+		ciphertext, err = v.runAge([]string{"--encrypt", "-i", v.keypath}, append(append(ad, '\n'), text...))
+		if err != nil {
+			return value, err
+		}
+	} else {
+		nonce := make([]byte, v.aead.NonceSize())
+		if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+			return value, err
+		}
+		ciphertext = v.aead.Seal(nonce, nonce, []byte(text), ad)
+	}
+	return fmt.Sprintf("ENC[%s,data:%s,type:%s]", v.method, base64.StdEncoding.EncodeToString(ciphertext), valuetype), nil
+}
+
+// value of ENC[...] text. other value is kept.
+func (v *valueCrypter) decryptValue(value interface{}, path string) (interface{}, error) {
+	s, ok := value.(string)
+	if !ok {
+		return value, nil
+	}
+	match := encryptedValueRegexp.FindStringSubmatch(s)
+	if match == nil {
+		return value, nil
+	}
+	if match[1] != v.method {
+		return value, fmt.Errorf("value is encrypted with %s , but key is for %s", match[1], v.method)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(match[2])
+	if err != nil {
+		return value, err
+	}
+	ad := cryptAdditionalData(path, match[3])
+	var plaintext []byte
+	if v.method == cryptAge {
+		plaintext, err = v.runAge([]string{"--decrypt", "-i", v.keypath}, ciphertext)
+		if err != nil {
+			return value, err
+		}
+		header := append(ad, '\n')
+		if !bytes.HasPrefix(plaintext, header) {
+			return value, fmt.Errorf("%s: encrypted value is not for this path and type", pathOrTop(path))
+		}
+		plaintext = plaintext[len(header):]
+	} else {
+		size := v.aead.NonceSize()
+		if len(ciphertext) < size {
+			return value, fmt.Errorf("encrypted value is too short")
+		}
+		plaintext, err = v.aead.Open(nil, ciphertext[:size], ciphertext[size:], ad)
+		if err != nil {
+			return value, fmt.Errorf("%s: decrypt: %v (value is moved , or key is wrong)", pathOrTop(path), err)
+		}
+	}
+	switch match[3] {
+	case "num":
+		return strconv.ParseFloat(string(plaintext), 64)
+	case "bool":
+		return strconv.ParseBool(string(plaintext))
+	}
+	return string(plaintext), nil
+}
+
+// path in error messages
+func pathOrTop(path string) string {
+	if len(path) == 0 {
+		return "top"
+	}
+	return path
+}
+
+// run age command with input
+func (v *valueCrypter) runAge(args []string, input []byte) ([]byte, error) {
+	cmd := exec.Command("age", args...)
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	err := cmd.Run()
+	if err != nil {
+		return nil, fmt.Errorf("age: %v %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+//---------------------------------------------------------------------
+//  encrypt , decrypt subcommands
+//
+func newEncryptCmd(yamlsort *yamlsortCmd) *cobra.Command {
+	return newCryptCmd(yamlsort, true)
+}
+
+func newDecryptCmd(yamlsort *yamlsortCmd) *cobra.Command {
+	return newCryptCmd(yamlsort, false)
+}
+
+func newCryptCmd(yamlsort *yamlsortCmd, blnCrypt bool) *cobra.Command {
+	var keypath string
+	var paths []string
+
+	name := "decrypt"
+	short := "decrypt encrypted values of yaml files"
+	if blnCrypt {
+		name = "encrypt"
+		short = "encrypt scalar values at paths of yaml files (age or AES-GCM)"
+	}
+	cmd := &cobra.Command{
+		Use:   name + " --key-path file [--paths path]... file...",
+		Short: short,
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("%s requires input file names", name)
+			}
+			crypter, err := newValueCrypter(keypath, paths)
+			if err != nil {
+				return err
+			}
+			crypter.blnCrypt = blnCrypt
+			yamlsort.maxlinesize = defaultMaxLineSize
+			err = yamlsort.prepareOptions()
+			if err != nil {
+				return err
+			}
+			for _, filename := range args {
+				err = yamlsort.cryptFile(crypter, filename)
+				if err != nil {
+					return fmt.Errorf("%s: %v", filename, err)
+				}
+			}
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&keypath, "key-path", "", "key file. age identity , or 32 bytes key in hex or base64 (AES-256-GCM)")
+	f.StringArrayVar(&paths, "paths", []string{}, "path of values , like data.* (can specify multiple times)")
+	f.BoolVarP(&yamlsort.blnWrite, "write", "w", false, "write files in place , instead of stdout")
+
+	return cmd
+}
+
+// encrypt or decrypt values of file , and write sorted result
+func (c *yamlsortCmd) cryptFile(crypter *valueCrypter, filename string) error {
+	snapshot := snapshotFile(filename)
+	input, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	docs, err := c.readDocuments(filename)
+	if err != nil {
+		return err
+	}
+	output := new(bytes.Buffer)
+	for _, doc := range docs {
+		doc.Data, err = crypter.apply(doc.Data)
+		if err != nil {
+			return err
+		}
+		err = c.writeDocument(output, doc)
+		if err != nil {
+			return err
+		}
+	}
+	if !c.blnWrite {
+		_, err = c.stdout.Write(output.Bytes())
+		return err
+	}
+	if output.String() == string(input) {
+		return nil
+	}
+	return c.writeInPlace(filename, input, output.Bytes(), snapshot)
+}
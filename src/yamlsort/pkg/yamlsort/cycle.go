@@ -0,0 +1,78 @@
+//
+// yamlsort - cycle detection and depth limit of marshal
+//
+// data of documents can be replaced by fn of ProcessDocuments , and map or list which
+// refers its ancestor (cycle) makes infinite output. marshal keeps maps and lists which
+// contain current node , and reports cycle with key paths.
+// nesting of maps and lists is limited by --max-depth , so deeply nested input is an error
+// with key path , instead of using large stack of recursive marshal.
+//
+package yamlsort
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// default of --max-depth
+const defaultMaxDepth = 1000
+
+// map or list which contains current node
+type marshalAncestor struct {
+	id   uintptr
+	path string
+}
+
+// identity of map or list , 0 for other values and empty ones
+func nodeIdentity(data interface{}) uintptr {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		if len(v) > 0 {
+			return reflect.ValueOf(v).Pointer()
+		}
+	case []interface{}:
+		if len(v) > 0 {
+			return reflect.ValueOf(v).Pointer()
+		}
+	}
+	return 0
+}
+
+// start marshal of node. error when node is one of its ancestors , or too deep.
+func (c *yamlsortCmd) enterNode(path string, id uintptr) error {
+	for _, ancestor := range c.ancestors {
+		if ancestor.id == id {
+			return fmt.Errorf("node at %s refers its ancestor at %s (cycle)", displayPath(path), displayPath(ancestor.path))
+		}
+	}
+	if len(c.ancestors) >= c.maxDepth() {
+		// path of deep node is long , and its end is written
+		if len(path) > 80 {
+			path = "..." + path[len(path)-80:]
+		}
+		return fmt.Errorf("nesting depth at %s exceeds --max-depth %d", displayPath(path), c.maxDepth())
+	}
+	c.ancestors = append(c.ancestors, marshalAncestor{id: id, path: path})
+	return nil
+}
+
+// --max-depth , or default when it is not set
+func (c *yamlsortCmd) maxDepth() int {
+	if c.maxdepth <= 0 {
+		return defaultMaxDepth
+	}
+	return c.maxdepth
+}
+
+// end marshal of node
+func (c *yamlsortCmd) leaveNode() {
+	c.ancestors = c.ancestors[:len(c.ancestors)-1]
+}
+
+// "." for root path
+func displayPath(path string) string {
+	if len(path) == 0 {
+		return "."
+	}
+	return path
+}
@@ -0,0 +1,329 @@
+//
+// yamlsort - daemon and client subcommands
+//
+// daemon listens on unix socket , and sorts yaml text sent by client.
+// editors run client for format-on-save without process startup cost of options , presets and scripts.
+//   yamlsort daemon &
+//   yamlsort client --stdin -- --key name --lint-profile=prettier < values.yaml
+//
+// request  : {"args": [options], "dir": "cwd of client", "env": [environment of client]} line ,
+//            and yaml text until end of stream
+// response : {"error": "...", "stderr": "..."} line , and sorted yaml text
+// file paths of options are resolved in directory of client. options which write files or run
+// commands (-o , -f , -w , --output-template , --transform , --preset , --comparator , --policy ,
+// --git-changed , s3:// and gs:// URIs , clipboard) are refused.
+// request is limited by --max-request-size , and aliases of documents by --max-alias-expansion
+// (default of command , and client can not raise it).
+//
+package yamlsort
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+type daemonRequest struct {
+	Args []string `json:"args"`
+	Dir  string   `json:"dir"`
+	Env  []string `json:"env"`
+}
+
+type daemonResponse struct {
+	Error  string `json:"error,omitempty"`
+	Stderr string `json:"stderr,omitempty"`
+}
+
+// default of --max-request-size
+const defaultMaxRequestSize = 64 * 1024 * 1024
+
+// default path of unix socket
+func defaultSocketPath() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); len(dir) > 0 {
+		return filepath.Join(dir, "yamlsort.sock")
+	}
+	return filepath.Join(os.TempDir(), "yamlsort-"+strconv.Itoa(os.Getuid())+".sock")
+}
+
+// sort input with options. same as command line "yamlsort [options] < input".
+func sortWithArgs(args []string, input []byte, stdout io.Writer, stderr io.Writer) error {
+	c := &yamlsortCmd{
+		version: version,
+		stdin:   bytes.NewReader(input),
+		stdout:  stdout,
+		stderr:  stderr,
+	}
+	return sortWithCmd(c, args, "")
+}
+
+// sort input of client request , in directory and environment of client.
+func sortRequest(request daemonRequest, input []byte, stdout io.Writer, stderr io.Writer) error {
+	if !filepath.IsAbs(request.Dir) {
+		return fmt.Errorf("directory of client is not absolute path: %q", request.Dir)
+	}
+	c := &yamlsortCmd{
+		version:   version,
+		stdin:     bytes.NewReader(input),
+		stdout:    stdout,
+		stderr:    stderr,
+		environ:   request.Env,
+		blnRemote: true,
+	}
+	if c.environ == nil {
+		// environment of daemon is not used for client
+		c.environ = []string{}
+	}
+	return sortWithCmd(c, request.Args, request.Dir)
+}
+
+// sort with options in directory dir (empty for current directory)
+func sortWithCmd(c *yamlsortCmd, args []string, dir string) (err error) {
+	// panic for one request does not stop daemon
+	defer func() {
+		if r := recover(); r != nil {
+			err = recoveredError(r)
+		}
+	}()
+	globalsortmutex.Lock()
+	defer globalsortmutex.Unlock()
+	defer c.closeComparator()
+	if len(dir) > 0 {
+		// current directory is state of process , and sorts are processed one by one
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		err = os.Chdir(dir)
+		if err != nil {
+			return err
+		}
+		defer os.Chdir(wd)
+	}
+	cmd := newRootCommand(c)
+	cmd.ResetCommands()
+	cmd.PersistentPreRunE = nil
+	cmd.SetArgs(args)
+	cmd.SetOutput(c.stderr)
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	return cmd.Execute()
+}
+
+//---------------------------------------------------------------------
+//  daemonCmd class
+//
+type daemonCmd struct {
+	yamlsort       *yamlsortCmd
+	socket         string
+	maxrequestsize int
+}
+
+func newDaemonCmd(yamlsort *yamlsortCmd) *cobra.Command {
+	daemon := &daemonCmd{
+		yamlsort: yamlsort,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "listen on unix socket , and sort yaml text sent by client subcommand",
+		RunE: func(c *cobra.Command, args []string) error {
+			return daemon.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&daemon.socket, "socket", defaultSocketPath(), "path to unix socket")
+	f.IntVar(&daemon.maxrequestsize, "max-request-size", defaultMaxRequestSize, "maximum size of request (options and yaml text) in bytes")
+
+	return cmd
+}
+
+func (d *daemonCmd) run() error {
+	// requests change current directory of daemon
+	socket, err := filepath.Abs(d.socket)
+	if err != nil {
+		return err
+	}
+	d.socket = socket
+	if _, err := os.Stat(d.socket); err == nil {
+		conn, err := net.DialTimeout("unix", d.socket, time.Second)
+		if err == nil {
+			conn.Close()
+			return fmt.Errorf("daemon is already running on %s", d.socket)
+		}
+		// socket file of dead daemon
+		os.Remove(d.socket)
+	}
+	listener, err := listenUnixSocket(d.socket)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(d.socket)
+
+	// stop on signal , and remove socket file
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		listener.Close()
+	}()
+
+	fmt.Fprintln(d.yamlsort.stderr, "yamlsort daemon is listening on", d.socket)
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			// listener is closed
+			return nil
+		}
+		go d.handle(conn)
+	}
+}
+
+// process one request
+func (d *daemonCmd) handle(conn net.Conn) {
+	defer conn.Close()
+	// large request is refused , instead of reading it into memory
+	limited := &io.LimitedReader{R: conn, N: int64(d.maxrequestsize) + 1}
+	reader := bufio.NewReader(limited)
+	response := daemonResponse{}
+	output := new(bytes.Buffer)
+	line, err := reader.ReadBytes('\n')
+	request := daemonRequest{}
+	if err == nil {
+		err = json.Unmarshal(line, &request)
+	}
+	var input []byte
+	if err == nil {
+		input, err = ioutil.ReadAll(reader)
+	}
+	if limited.N <= 0 {
+		err = fmt.Errorf("request exceeds --max-request-size %d of daemon", d.maxrequestsize)
+	}
+	if err == nil {
+		stderr := new(bytes.Buffer)
+		err = sortRequest(request, input, output, stderr)
+		response.Stderr = stderr.String()
+	}
+	if err != nil {
+		response.Error = err.Error()
+		output.Reset()
+	}
+	header, _ := json.Marshal(response)
+	conn.Write(append(header, '\n'))
+	conn.Write(output.Bytes())
+}
+
+//---------------------------------------------------------------------
+//  clientCmd class
+// send stdin to daemon , and write sorted text to stdout.
+// when daemon is not running , sort in this process.
+//
+type clientCmd struct {
+	yamlsort *yamlsortCmd
+	socket   string
+	blnStdin bool
+}
+
+func newClientCmd(yamlsort *yamlsortCmd) *cobra.Command {
+	client := &clientCmd{
+		yamlsort: yamlsort,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "client --stdin [-- options]",
+		Short: "sort stdin with daemon subcommand",
+		RunE: func(c *cobra.Command, args []string) error {
+			return client.run(args)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&client.socket, "socket", defaultSocketPath(), "path to unix socket")
+	f.BoolVar(&client.blnStdin, "stdin", false, "read yaml text from stdin")
+
+	return cmd
+}
+
+func (cl *clientCmd) run(args []string) error {
+	c := cl.yamlsort
+	if !cl.blnStdin {
+		return fmt.Errorf("client requires --stdin")
+	}
+	input, err := ioutil.ReadAll(c.stdin)
+	if err != nil {
+		return err
+	}
+	conn, err := net.DialTimeout("unix", cl.socket, time.Second)
+	if err != nil {
+		// daemon is not running
+		return sortWithArgs(args, input, c.stdout, c.stderr)
+	}
+	defer conn.Close()
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	header, _ := json.Marshal(daemonRequest{Args: args, Dir: dir, Env: os.Environ()})
+	_, writeerr := conn.Write(append(header, '\n'))
+	if writeerr == nil {
+		_, writeerr = conn.Write(input)
+	}
+	if unixconn, ok := conn.(*net.UnixConn); ok {
+		unixconn.CloseWrite()
+	}
+
+	// daemon responds error , and closes connection before end of large request
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadBytes('\n')
+	if err != nil && writeerr != nil {
+		return writeerr
+	}
+	if err != nil {
+		return fmt.Errorf("daemon response error: %v", err)
+	}
+	response := daemonResponse{}
+	err = json.Unmarshal(line, &response)
+	if err != nil {
+		return fmt.Errorf("daemon response error: %v", err)
+	}
+	fmt.Fprint(c.stderr, response.Stderr)
+	if len(response.Error) > 0 {
+		return fmt.Errorf("%s", response.Error)
+	}
+	_, err = io.Copy(c.stdout, reader)
+	return err
+}
+
+// options of client request , which write files or run commands ,
+// are refused by daemon. sorted text is written to client.
+func (c *yamlsortCmd) checkRemoteOptions() error {
+	if len(c.outputfilename) > 0 || len(c.inputoutputfilename) > 0 || c.blnWrite || len(c.outputtemplate) > 0 {
+		return fmt.Errorf("-o , -f , -w and --output-template can not be used with daemon")
+	}
+	if len(c.transformcommands) > 0 || len(c.presets) > 0 || len(c.comparatorname) > 0 || len(c.policydirs) > 0 || c.blnGitChanged {
+		return fmt.Errorf("--transform , --preset , --comparator , --policy and --git-changed can not be used with daemon")
+	}
+	if isObjectURI(c.inputfilename) {
+		return fmt.Errorf("s3:// and gs:// URIs can not be used with daemon")
+	}
+	if c.maxAliasExpansion() > defaultMaxAliasExpansion {
+		return fmt.Errorf("--max-alias-expansion larger than %d can not be used with daemon", defaultMaxAliasExpansion)
+	}
+	if c.blnClipboardIn || c.blnClipboardOut {
+		return fmt.Errorf("--clipboard-in and --clipboard-out can not be used with daemon")
+	}
+	return nil
+}
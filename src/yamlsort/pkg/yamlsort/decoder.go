@@ -0,0 +1,113 @@
+//
+// yamlsort - input decoders
+//
+// input in other formats is decoded , and sorted as yaml.
+//   yamlsort -i config.toml --output-format yaml
+//   yamlsort --input-format toml < config.toml
+//   yamlsort -i main.tf -o main.yaml
+//   yamlsort -i settings.xml --xml-attributes merge
+// format is --input-format , or extension of input file (.toml , .hcl , .tf , .xml , .json) , or sniffed from content
+// (stdin and other extensions). yaml is default. json is yaml , so it is parsed as is (--input-format
+// json is same as --jsoninput). decoded data of other formats is passed to yaml parser as json text ,
+// so all options work same as yaml input. comments of other formats are not kept.
+// -f and -w can not write yaml into input file of other formats , so use -o or --output-template.
+//
+package yamlsort
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// decoder of input format
+type inputDecoder struct {
+	name   string
+	exts   []string
+	sniff  func(input []byte) bool                                 // content looks like this format
+	decode func(c *yamlsortCmd, input []byte) (interface{}, error) // nil for yaml parser
+}
+
+// input formats. add new decoder here.
+var inputDecoders = []inputDecoder{
+	{name: "yaml", exts: []string{".yaml", ".yml"}},
+	{name: "json", exts: []string{".json"}},
+	{name: "toml", exts: []string{".toml"}, sniff: sniffTOML, decode: (*yamlsortCmd).decodeTOML},
+	{name: "hcl", exts: []string{".hcl", ".tf", ".tfvars"}, sniff: sniffHCL, decode: (*yamlsortCmd).decodeHCL},
+	{name: "xml", exts: []string{".xml"}, sniff: sniffXML, decode: (*yamlsortCmd).decodeXML},
+}
+
+// first line of toml , like "[table]" , "[[array]]" or "key = value"
+var tomlFirstLineRegexp = regexp.MustCompile(`^(\[\[?[A-Za-z0-9_.\-"' ]+\]\]?|[A-Za-z0-9_\-"']+(\s*\.\s*[A-Za-z0-9_\-"']+)*\s*=)`)
+
+func checkInputFormat(format string) error {
+	if len(format) == 0 {
+		return nil
+	}
+	names := []string{}
+	for _, d := range inputDecoders {
+		if d.name == format {
+			return nil
+		}
+		names = append(names, d.name)
+	}
+	return fmt.Errorf("unknown --input-format %q. (%s)", format, strings.Join(names, " , "))
+}
+
+// decoder of input. --input-format , extension of file name , or content.
+func (c *yamlsortCmd) inputDecoder(filename string, input []byte) inputDecoder {
+	for _, d := range inputDecoders {
+		if d.name == c.inputformat {
+			return d
+		}
+	}
+	if len(c.inputformat) == 0 {
+		ext := strings.ToLower(filepath.Ext(filename))
+		for _, d := range inputDecoders {
+			if hasExtension(ext, d.exts) {
+				return d
+			}
+		}
+		for _, d := range inputDecoders {
+			if d.sniff != nil && d.sniff(input) {
+				return d
+			}
+		}
+	}
+	return inputDecoders[0]
+}
+
+// decode input into yaml text (json). yaml input is returned as is , and decoded is empty.
+func (c *yamlsortCmd) decodeInput(filename string, input []byte) (output []byte, decoded string, err error) {
+	d := c.inputDecoder(filename, input)
+	if d.decode == nil || c.blnInputJSON {
+		return input, "", nil
+	}
+	data, err := d.decode(c, input)
+	if err != nil {
+		return nil, d.name, err
+	}
+	buf := new(bytes.Buffer)
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	err = encoder.Encode(data)
+	if err != nil {
+		return nil, d.name, fmt.Errorf("%s: %v", d.name, err)
+	}
+	return buf.Bytes(), d.name, nil
+}
+
+// first line (without comments and blank lines) looks like toml
+func sniffTOML(input []byte) bool {
+	for _, line := range strings.Split(string(input), "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		return tomlFirstLineRegexp.MatchString(line)
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+//
+// yamlsort - --dedupe-anchors option
+//
+// identical maps and lists (json size >= N bytes) in one document are written once
+// with anchor , and aliases are written at other places.
+//   yamlsort --dedupe-anchors=128 < generated.yaml
+// anchor name is key of first place (like &resources , &resources_2). anchors are
+// not shared between documents.
+//
+// marshal runs twice. first pass counts repeated subtrees in output order ,
+// and second pass writes anchors and aliases.
+//
+package yamlsort
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// default size of --dedupe-anchors without value
+const defaultDedupeSize = 64
+
+// characters which are not used in anchor name
+var anchorNameRegexp = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// state of one document
+type dedupeState struct {
+	counting bool
+	seen     map[string]bool   // counting pass. subtree is seen
+	aliased  map[string]bool   // subtree has aliases
+	names    map[string]string // writing pass. subtree -> anchor name
+	used     map[string]bool   // anchor names
+}
+
+// marshal data with anchors and aliases of repeated subtrees
+func (c *yamlsortCmd) myMarshalDedupe(writer *bytes.Buffer, data interface{}) error {
+	c.dedupe = &dedupeState{
+		counting: true,
+		seen:     map[string]bool{},
+		aliased:  map[string]bool{},
+		names:    map[string]string{},
+		used:     map[string]bool{},
+	}
+	defer func() { c.dedupe = nil }()
+	// first pass counts only. blank line paths and long strings do not change order.
+	err := c.myMershalRecursive(new(bytes.Buffer), 0, "", false, data)
+	if err != nil {
+		return err
+	}
+	c.dedupe.counting = false
+	return c.myMershalRecursive(writer, 0, "", false, data)
+}
+
+// check subtree at path. return alias name when it is written before ,
+// or anchor name when it is written first time and has aliases.
+func (c *yamlsortCmd) dedupeNode(path string, data interface{}) (string, string) {
+	d := c.dedupe
+	if d == nil {
+		return "", ""
+	}
+	switch v := data.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return "", ""
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			return "", ""
+		}
+	default:
+		return "", ""
+	}
+	canonical, err := json.Marshal(data)
+	if err != nil || len(canonical) < c.dedupeanchors {
+		return "", ""
+	}
+	key := string(canonical)
+	if d.counting {
+		if d.seen[key] {
+			d.aliased[key] = true
+			// children of alias are not counted
+			return "counted", ""
+		}
+		d.seen[key] = true
+		return "", ""
+	}
+	if name, ok := d.names[key]; ok {
+		return name, ""
+	}
+	if !d.aliased[key] {
+		return "", ""
+	}
+	name := anchorName(path, d.used)
+	d.names[key] = name
+	return "", name
+}
+
+// anchor name from last key of path (list element uses key of list).
+// number is added when it is used.
+func anchorName(path string, used map[string]bool) string {
+	name := path
+	for strings.HasSuffix(name, "]") && strings.Contains(name, "[") {
+		name = name[:strings.LastIndex(name, "[")]
+	}
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		name = name[idx+1:]
+	}
+	name = strings.Trim(anchorNameRegexp.ReplaceAllString(name, "_"), "_")
+	if len(name) == 0 || (name[0] >= '0' && name[0] <= '9') {
+		name = "anchor" + name
+	}
+	result := name
+	for i := 2; used[result]; i++ {
+		result = name + "_" + strconv.Itoa(i)
+	}
+	used[result] = true
+	return result
+}
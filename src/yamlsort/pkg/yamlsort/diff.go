@@ -0,0 +1,232 @@
+//
+// yamlsort - line diff
+//
+// myers diff of lines. git-merge subcommand uses it for conflict markers ,
+// and --dry-run for unified diff. memory is linear in number of lines , and
+// ranges with too many differences (like shuffled lines) are replaced as a whole.
+//
+package yamlsort
+
+import (
+	"fmt"
+	"strings"
+)
+
+// one line of diff. kind is ' ' (same) , '-' (only in a) , '+' (only in b)
+type diffLine struct {
+	kind byte
+	text string
+}
+
+// edit script from a to b. it is shortest , unless differences are more than diffMaxCost lines
+// in a range (like shuffled lines) , and the range is written as removed and added lines.
+func diffLines(a []string, b []string) []diffLine {
+	result := make([]diffLine, 0, len(a)+len(b))
+	return appendDiff(result, a, b)
+}
+
+// differences searched for one middle snake. search costs O((N+M)*D) time.
+const diffMaxCost = 4096
+
+// append edit script from a to b. myers diff in linear space , divided at middle snake.
+func appendDiff(result []diffLine, a []string, b []string) []diffLine {
+	// common prefix and suffix are not searched
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	for _, s := range a[:prefix] {
+		result = append(result, diffLine{kind: ' ', text: s})
+	}
+	ma := a[prefix : len(a)-suffix]
+	mb := b[prefix : len(b)-suffix]
+	x, y, ok := -1, -1, false
+	if len(ma) > 0 && len(mb) > 0 {
+		x, y, ok = middleSnake(ma, mb)
+	}
+	if ok {
+		result = appendDiff(result, ma[:x], mb[:y])
+		result = appendDiff(result, ma[x:], mb[y:])
+	} else {
+		for _, s := range ma {
+			result = append(result, diffLine{kind: '-', text: s})
+		}
+		for _, s := range mb {
+			result = append(result, diffLine{kind: '+', text: s})
+		}
+	}
+	for _, s := range a[len(a)-suffix:] {
+		result = append(result, diffLine{kind: ' ', text: s})
+	}
+	return result
+}
+
+// point where forward and backward paths of shortest edit script meet.
+// a and b have different first lines and different last lines , so the point divides
+// them into smaller ranges. false when they have no common line , or it costs more than diffMaxCost.
+func middleSnake(a []string, b []string) (int, int, bool) {
+	n := len(a)
+	m := len(b)
+	maxd := (n + m + 1) / 2
+	if maxd > diffMaxCost {
+		maxd = diffMaxCost
+	}
+	offset := maxd + 1
+	// furthest x of each diagonal k , forward from start and backward from end
+	vf := make([]int, 2*offset+1)
+	vb := make([]int, 2*offset+1)
+	for i := range vf {
+		vf[i] = -1
+		vb[i] = -1
+	}
+	vf[offset+1] = 0
+	vb[offset+1] = 0
+	delta := n - m
+	// paths meet in forward search when delta is odd
+	front := delta%2 != 0
+	// diagonals which went out of range
+	kfstart, kfend, kbstart, kbend := 0, 0, 0, 0
+	for d := 0; d < maxd; d++ {
+		for k := -d + kfstart; k <= d-kfend; k += 2 {
+			i := offset + k
+			x := 0
+			if k == -d || (k != d && vf[i-1] < vf[i+1]) {
+				x = vf[i+1]
+			} else {
+				x = vf[i-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			vf[i] = x
+			if x > n {
+				kfend += 2
+			} else if y > m {
+				kfstart += 2
+			} else if front {
+				j := offset + delta - k
+				if j >= 0 && j < len(vb) && vb[j] != -1 && x >= n-vb[j] {
+					return x, y, true
+				}
+			}
+		}
+		for k := -d + kbstart; k <= d-kbend; k += 2 {
+			i := offset + k
+			x := 0
+			if k == -d || (k != d && vb[i-1] < vb[i+1]) {
+				x = vb[i+1]
+			} else {
+				x = vb[i-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[n-x-1] == b[m-y-1] {
+				x++
+				y++
+			}
+			vb[i] = x
+			if x > n {
+				kbend += 2
+			} else if y > m {
+				kbstart += 2
+			} else if !front {
+				j := offset + delta - k
+				if j >= 0 && j < len(vf) && vf[j] != -1 {
+					fx := vf[j]
+					fy := fx - (j - offset)
+					if fx >= n-x {
+						return fx, fy, true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// unified diff with 3 context lines. "" when a and b are same.
+func unifiedDiff(name string, a string, b string) string {
+	if a == b {
+		return ""
+	}
+	lines := diffLines(splitLines(a), splitLines(b))
+	const context = 3
+	result := new(strings.Builder)
+	fmt.Fprintf(result, "--- a/%s\n+++ b/%s\n", name, name)
+	for i := 0; i < len(lines); {
+		if lines[i].kind == ' ' {
+			i++
+			continue
+		}
+		// hunk from i-context , until context lines after last change
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for {
+			for end < len(lines) && lines[end].kind != ' ' {
+				end++
+			}
+			same := 0
+			for end+same < len(lines) && lines[end+same].kind == ' ' {
+				same++
+			}
+			if end+same == len(lines) || same > 2*context {
+				if same > context {
+					same = context
+				}
+				end += same
+				break
+			}
+			end += same
+		}
+		// line numbers of hunk
+		aline, bline := 1, 1
+		for _, l := range lines[:start] {
+			if l.kind != '+' {
+				aline++
+			}
+			if l.kind != '-' {
+				bline++
+			}
+		}
+		acount, bcount := 0, 0
+		for _, l := range lines[start:end] {
+			if l.kind != '+' {
+				acount++
+			}
+			if l.kind != '-' {
+				bcount++
+			}
+		}
+		if acount == 0 {
+			aline--
+		}
+		if bcount == 0 {
+			bline--
+		}
+		fmt.Fprintf(result, "@@ -%d,%d +%d,%d @@\n", aline, acount, bline, bcount)
+		for _, l := range lines[start:end] {
+			result.WriteByte(l.kind)
+			result.WriteString(l.text)
+			result.WriteByte('\n')
+		}
+		i = end
+	}
+	return result.String()
+}
+
+// lines without line feed
+func splitLines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if len(s) == 0 {
+		return []string{}
+	}
+	return strings.Split(s, "\n")
+}
@@ -0,0 +1,92 @@
+package yamlsort
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// lines of a and b in edit script
+func diffSides(lines []diffLine) ([]string, []string) {
+	a := []string{}
+	b := []string{}
+	for _, l := range lines {
+		if l.kind != '+' {
+			a = append(a, l.text)
+		}
+		if l.kind != '-' {
+			b = append(b, l.text)
+		}
+	}
+	return a, b
+}
+
+// length of longest common subsequence
+func lcsLength(a []string, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else if cur[j] > prev[j+1] {
+				cur[j+1] = cur[j]
+			} else {
+				cur[j+1] = prev[j+1]
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func randomLines(r *rand.Rand, n int, alphabet int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprint(r.Intn(alphabet))
+	}
+	return lines
+}
+
+// edit script has lines of a and b , and it is shortest
+func TestDiffLinesShortest(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		a := randomLines(r, r.Intn(30), 1+r.Intn(6))
+		b := randomLines(r, r.Intn(30), 1+r.Intn(6))
+		lines := diffLines(a, b)
+		da, db := diffSides(lines)
+		if fmt.Sprint(da) != fmt.Sprint(a) || fmt.Sprint(db) != fmt.Sprint(b) {
+			t.Fatalf("diff of %v and %v has other lines: %v", a, b, lines)
+		}
+		same := 0
+		for _, l := range lines {
+			if l.kind == ' ' {
+				same++
+			}
+		}
+		if same != lcsLength(a, b) {
+			t.Fatalf("diff of %v and %v is not shortest: %d same lines , lcs %d", a, b, same, lcsLength(a, b))
+		}
+	}
+}
+
+// shuffled large file is compared in linear memory
+func TestDiffLinesLarge(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	a := make([]string, 20000)
+	for i := range a {
+		a[i] = fmt.Sprintf("key%05d: value", i)
+	}
+	b := append([]string{}, a...)
+	r.Shuffle(len(b), func(i, j int) { b[i], b[j] = b[j], b[i] })
+	da, db := diffSides(diffLines(a, b))
+	if fmt.Sprint(da) != fmt.Sprint(a) || fmt.Sprint(db) != fmt.Sprint(b) {
+		t.Fatal("diff of shuffled lines has other lines")
+	}
+	text := unifiedDiff("large.yaml", strings.Join(a, "\n"), strings.Join(b, "\n"))
+	if len(text) == 0 {
+		t.Fatal("no unified diff of shuffled lines")
+	}
+}
@@ -0,0 +1,281 @@
+//
+// yamlsort - diff-dir subcommand
+//
+// compare directory trees of manifests. files are paired by relative path ,
+// and compared semantically (key order and formatting are ignored).
+//   yamlsort diff-dir envs/staging envs/prod
+// with --diff , unified diff of sorted text is printed for each differing file.
+// exit 0 when all files are same , exit 1 when some files differ (or are only in one directory) ,
+// and exit 2 on error (like diff -r).
+//
+package yamlsort
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// returned by run when files differ. message is not printed.
+var errDirsDiffer = errors.New("directories differ")
+
+//---------------------------------------------------------------------
+//  diffDirCmd class
+//
+type diffDirCmd struct {
+	yamlsort *yamlsortCmd
+	blnDiff  bool
+}
+
+func newDiffDirCmd(yamlsort *yamlsortCmd) *cobra.Command {
+	diffdir := &diffDirCmd{
+		yamlsort: yamlsort,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "diff-dir dir1 dir2",
+		Short: "compare files of two directories semantically , paired by relative path",
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return withExitStatus2(fmt.Errorf("diff-dir requires two directory names"))
+			}
+			err := diffdir.run(args[0], args[1])
+			if err == errDirsDiffer {
+				// difference is result , not error message
+				c.SilenceErrors = true
+				c.SilenceUsage = true
+				return err
+			}
+			return withExitStatus2(err)
+		},
+	}
+	cmd.SetFlagErrorFunc(func(c *cobra.Command, err error) error {
+		return withExitStatus2(err)
+	})
+
+	f := cmd.Flags()
+	f.BoolVar(&diffdir.blnDiff, "diff", false, "print unified diff of sorted text")
+
+	return cmd
+}
+
+// relative path -> file path of files in dir
+func (d *diffDirCmd) relativeFiles(dir string) (map[string]string, error) {
+	filenames, err := d.yamlsort.collectFiles([]string{dir})
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]string{}
+	for _, filename := range filenames {
+		rel, err := filepath.Rel(dir, filename)
+		if err != nil {
+			return nil, err
+		}
+		result[filepath.ToSlash(rel)] = filename
+	}
+	return result, nil
+}
+
+func (d *diffDirCmd) run(dir1 string, dir2 string) error {
+	c := d.yamlsort
+	c.maxlinesize = defaultMaxLineSize
+	err := c.prepareOptions()
+	if err != nil {
+		return err
+	}
+	files1, err := d.relativeFiles(dir1)
+	if err != nil {
+		return err
+	}
+	files2, err := d.relativeFiles(dir2)
+	if err != nil {
+		return err
+	}
+	rels := []string{}
+	for rel := range files1 {
+		rels = append(rels, rel)
+	}
+	for rel := range files2 {
+		if _, ok := files1[rel]; !ok {
+			rels = append(rels, rel)
+		}
+	}
+	sort.Strings(rels)
+
+	same, differ, only1, only2 := 0, 0, 0, 0
+	w := c.stdout
+	for _, rel := range rels {
+		filename1, ok1 := files1[rel]
+		filename2, ok2 := files2[rel]
+		if !ok2 {
+			fmt.Fprintf(w, "only in %s: %s\n", dir1, rel)
+			only1++
+			continue
+		}
+		if !ok1 {
+			fmt.Fprintf(w, "only in %s: %s\n", dir2, rel)
+			only2++
+			continue
+		}
+		changes, err := c.compareFiles(filename1, filename2)
+		if err != nil {
+			return err
+		}
+		if len(changes) == 0 {
+			same++
+			continue
+		}
+		differ++
+		fmt.Fprintf(w, "differ: %s (%d values)\n", rel, len(changes))
+		for _, change := range changes {
+			fmt.Fprintf(w, "  %s\n", change)
+		}
+		if d.blnDiff {
+			text, err := c.sortedDiff(rel, filename1, filename2)
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(w, text)
+		}
+	}
+	fmt.Fprintf(w, "%d same , %d differ , %d only in %s , %d only in %s\n", same, differ, only1, dir1, only2, dir2)
+	if differ > 0 || only1 > 0 || only2 > 0 {
+		return errDirsDiffer
+	}
+	return nil
+}
+
+// semantic differences of two files. empty when they are same.
+func (c *yamlsortCmd) compareFiles(filename1 string, filename2 string) ([]string, error) {
+	docs1, err := c.readDocuments(filename1)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename1, err)
+	}
+	docs2, err := c.readDocuments(filename2)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename2, err)
+	}
+	changes := []string{}
+	for i := 0; i < len(docs1) || i < len(docs2); i++ {
+		prefix := ""
+		if len(docs1) > 1 || len(docs2) > 1 {
+			prefix = fmt.Sprintf("[doc %d] ", i)
+		}
+		if i >= len(docs2) {
+			changes = append(changes, prefix+"- (document)")
+			continue
+		}
+		if i >= len(docs1) {
+			changes = append(changes, prefix+"+ (document)")
+			continue
+		}
+		for _, change := range diffValues("", docs1[i].Data, docs2[i].Data) {
+			changes = append(changes, prefix+change)
+		}
+	}
+	return changes, nil
+}
+
+// changed paths between a and b.
+//   - path          only in a
+//   + path          only in b
+//   ~ path: a -> b  changed
+func diffValues(path string, a interface{}, b interface{}) []string {
+	if reflect.DeepEqual(a, b) {
+		return nil
+	}
+	// .nan is same as .nan
+	if af, ok := a.(float64); ok && math.IsNaN(af) {
+		if bf, ok := b.(float64); ok && math.IsNaN(bf) {
+			return nil
+		}
+	}
+	name := path
+	if len(name) == 0 {
+		name = "(root)"
+	}
+	am, ok1 := a.(map[string]interface{})
+	bm, ok2 := b.(map[string]interface{})
+	if ok1 && ok2 {
+		keys := []string{}
+		for k := range am {
+			keys = append(keys, k)
+		}
+		for k := range bm {
+			if _, ok := am[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		result := []string{}
+		for _, k := range keys {
+			childpath := k
+			if len(path) > 0 {
+				childpath = path + "." + k
+			}
+			av, aok := am[k]
+			bv, bok := bm[k]
+			if !bok {
+				result = append(result, "- "+childpath)
+			} else if !aok {
+				result = append(result, "+ "+childpath)
+			} else {
+				result = append(result, diffValues(childpath, av, bv)...)
+			}
+		}
+		return result
+	}
+	al, ok1 := a.([]interface{})
+	bl, ok2 := b.([]interface{})
+	if ok1 && ok2 && len(al) == len(bl) {
+		result := []string{}
+		for i := range al {
+			result = append(result, diffValues(fmt.Sprintf("%s[%d]", path, i), al[i], bl[i])...)
+		}
+		return result
+	}
+	return []string{fmt.Sprintf("~ %s: %s -> %s", name, shortValue(a), shortValue(b))}
+}
+
+// one line text of value for reports
+func shortValue(data interface{}) string {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		return fmt.Sprintf("(map of %d keys)", len(v))
+	case []interface{}:
+		return fmt.Sprintf("(list of %d items)", len(v))
+	case nil:
+		return "null"
+	case string:
+		s := strings.Replace(v, "\n", "\\n", -1)
+		if len(s) > 40 {
+			s = s[:37] + "..."
+		}
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprint(data)
+}
+
+// unified diff of sorted text of two files
+func (c *yamlsortCmd) sortedDiff(name string, filename1 string, filename2 string) (string, error) {
+	texts := []string{}
+	for _, filename := range []string{filename1, filename2} {
+		input, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return "", err
+		}
+		output, err := c.sortBytes(input, nil)
+		if err != nil {
+			return "", fmt.Errorf("%s: %v", filename, err)
+		}
+		texts = append(texts, output.String())
+	}
+	return unifiedDiff(name, texts[0], texts[1]), nil
+}
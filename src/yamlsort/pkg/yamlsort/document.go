@@ -0,0 +1,261 @@
+//
+// yamlsort - split multi-document yaml text by document marker lines
+//
+package yamlsort
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// default of --max-line-size.
+// large enough for base64 blobs in Secrets or long annotations.
+const defaultMaxLineSize = 64 * 1024 * 1024
+
+// one document in multi-document yaml text
+type yamlDocument struct {
+	firstline  string   // first line comment of document with trailing "  ", or ""
+	directives []string // directive lines before "---" , like "%YAML 1.1"
+	data       []byte
+	startline  int // line number of first line of data in input , 1 origin
+}
+
+// data for parser. %TAG directives are needed to parse tag handles.
+func (doc yamlDocument) parseData() []byte {
+	tagdirectives := doc.directivesByName("%TAG")
+	if len(tagdirectives) == 0 {
+		return doc.data
+	}
+	result := new(bytes.Buffer)
+	for _, s := range tagdirectives {
+		result.WriteString(s)
+		result.WriteByte('\n')
+	}
+	result.WriteString("---\n")
+	result.Write(doc.data)
+	return result.Bytes()
+}
+
+// document has no content, only comment lines or blank lines
+func (doc yamlDocument) isEmpty() bool {
+	for _, line := range strings.Split(string(doc.data), "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) > 0 && !strings.HasPrefix(line, "#") {
+			return false
+		}
+	}
+	return true
+}
+
+// comment lines in document
+func (doc yamlDocument) commentLines() []string {
+	result := []string{}
+	for _, line := range strings.Split(string(doc.data), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			result = append(result, strings.TrimRight(line, " \t\r"))
+		}
+	}
+	return result
+}
+
+// directive lines of name ("%YAML" , "%TAG") , or other directives when name is ""
+func (doc yamlDocument) directivesByName(name string) []string {
+	result := []string{}
+	for _, s := range doc.directives {
+		dname := strings.Fields(s)[0]
+		if dname == name || (len(name) == 0 && dname != "%YAML" && dname != "%TAG") {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+//---------------------------------------------------------------------
+//  documentScanner class
+// read documents one by one. the buffer of document data is reused,
+// so Document().data is valid until next Scan() call.
+//
+type documentScanner struct {
+	scanner     *bufio.Scanner
+	buffer      *bytes.Buffer
+	doc         yamlDocument
+	pending     []byte
+	directives  []string
+	maxlinesize int
+	lineno      int // line number of last read line
+	startline   int // line number of first line of document
+	pendingline int // line number of pending content
+}
+
+func newDocumentScanner(reader io.Reader, maxlinesize int) *documentScanner {
+	scanner := bufio.NewScanner(reader)
+	initialsize := 4096
+	if maxlinesize < initialsize {
+		initialsize = maxlinesize
+	}
+	scanner.Buffer(make([]byte, initialsize), maxlinesize)
+	return &documentScanner{
+		scanner:     scanner,
+		buffer:      new(bytes.Buffer),
+		maxlinesize: maxlinesize,
+	}
+}
+
+// read next document. return false at end of input.
+func (d *documentScanner) Scan() bool {
+	d.buffer.Reset()
+	d.doc = yamlDocument{}
+	linecount := 0
+	firstlinestr := ""
+	addLine := func(line []byte, lineno int) {
+		if d.buffer.Len() == 0 {
+			d.startline = lineno
+		}
+		linecount++
+		if linecount == 1 {
+			if len(line) > 0 && line[0] == '#' {
+				firstlinestr = string(line) + "  "
+			}
+		}
+		d.buffer.Write(line)
+		d.buffer.WriteByte('\n')
+	}
+
+	// inline content of "--- content" line
+	if d.pending != nil {
+		addLine(d.pending, d.pendingline)
+		d.pending = nil
+	}
+	for d.scanner.Scan() {
+		d.lineno++
+		line := d.scanner.Bytes()
+		if len(line) > 0 && line[0] == '%' {
+			// directive line. it belongs to next document.
+			directive := string(bytes.TrimRight(line, " \t"))
+			if d.buffer.Len() > 0 {
+				d.flush(firstlinestr)
+				d.directives = []string{directive}
+				return true
+			}
+			d.directives = append(d.directives, directive)
+			continue
+		}
+		marker, rest := documentMarker(line)
+		if marker == noMarker {
+			addLine(line, d.lineno)
+			continue
+		}
+		if marker == startMarker && len(rest) > 0 {
+			if d.buffer.Len() > 0 {
+				// content of next document
+				d.pending = append([]byte{}, rest...)
+				d.pendingline = d.lineno
+			} else {
+				addLine(rest, d.lineno)
+				continue
+			}
+		}
+		// flush document
+		if d.buffer.Len() > 0 {
+			d.flush(firstlinestr)
+			return true
+		}
+		linecount = 0
+	}
+	// flush last document
+	if d.buffer.Len() > 0 {
+		d.flush(firstlinestr)
+		return true
+	}
+	return false
+}
+
+// set current document
+func (d *documentScanner) flush(firstlinestr string) {
+	d.doc = yamlDocument{firstline: firstlinestr, directives: d.directives, data: d.buffer.Bytes(), startline: d.startline}
+	d.directives = nil
+}
+
+// kind of document marker line
+const (
+	noMarker    = iota
+	startMarker // "---"
+	endMarker   // "..."
+)
+
+// check document marker line. "---" , "--- # comment" , "--- content" , "..." , "... # comment"
+// return marker kind and rest of line (after marker and spaces).
+func documentMarker(line []byte) (int, []byte) {
+	if len(line) < 3 {
+		return noMarker, nil
+	}
+	marker := noMarker
+	if line[0] == '-' && line[1] == '-' && line[2] == '-' {
+		marker = startMarker
+	} else if line[0] == '.' && line[1] == '.' && line[2] == '.' {
+		marker = endMarker
+	} else {
+		return noMarker, nil
+	}
+	// marker must be followed by end of line or white space
+	if len(line) > 3 && line[3] != ' ' && line[3] != '\t' {
+		return noMarker, nil
+	}
+	return marker, bytes.TrimLeft(line[3:], " \t")
+}
+
+// current document
+func (d *documentScanner) Document() yamlDocument {
+	return d.doc
+}
+
+// first error in reading input
+func (d *documentScanner) Err() error {
+	err := d.scanner.Err()
+	if err == bufio.ErrTooLong {
+		return fmt.Errorf("input line is too long. (over --max-line-size %d bytes)", d.maxlinesize)
+	}
+	return err
+}
+
+// split all documents. each document has own data.
+func splitDocuments(input []byte, maxlinesize int) ([]yamlDocument, error) {
+	result := []yamlDocument{}
+	docscanner := newDocumentScanner(bytes.NewReader(input), maxlinesize)
+	for docscanner.Scan() {
+		doc := docscanner.Document()
+		data := make([]byte, len(doc.data))
+		copy(data, doc.data)
+		result = append(result, yamlDocument{firstline: doc.firstline, directives: doc.directives, data: data})
+	}
+	return result, docscanner.Err()
+}
+
+//---------------------------------------------------------------------
+//  output buffer pool
+// reuse marshal output buffers between documents.
+//
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// do not keep very large buffer in pool
+const maxPooledBufferSize = 16 * 1024 * 1024
+
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+func putBuffer(buffer *bytes.Buffer) {
+	if buffer.Cap() > maxPooledBufferSize {
+		return
+	}
+	buffer.Reset()
+	bufferPool.Put(buffer)
+}
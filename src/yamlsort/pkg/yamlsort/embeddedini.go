@@ -0,0 +1,149 @@
+//
+// yamlsort - --sort-embedded option
+//
+// sort lines of ini , properties and conf text in string values by key within sections , when map
+// key matches glob (like data entries of ConfigMap).
+//   yamlsort --sort-embedded '*.properties' --sort-embedded '*.ini' --sort-embedded '*.conf' -i configmap.yaml
+// .properties and .env values are sorted as properties (see --configmap-data=sort). other values are
+// sorted as ini. order of [sections] is kept , and keys in each section are sorted. comment lines
+// (# , ;) stay with next entry or section , and indented lines stay with entry above (multi-line value).
+// values with other lines (like nginx.conf with { }) are not changed. sorted multi-line values are
+// written as block scalar ( | ).
+//
+package yamlsort
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+func checkEmbeddedGlobs(globs []string) error {
+	for _, glob := range globs {
+		if _, err := path.Match(glob, ""); err != nil {
+			return fmt.Errorf("invalid --sort-embedded glob %q , %v", glob, err)
+		}
+	}
+	return nil
+}
+
+// key matches one of globs
+func (c *yamlsortCmd) matchEmbeddedGlob(key string) bool {
+	for _, glob := range c.embeddedglobs {
+		if matched, _ := path.Match(glob, key); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// sort embedded text of values , whose key matches glob
+func (c *yamlsortCmd) sortEmbeddedRecursive(data interface{}) {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if s, ok := child.(string); ok && c.matchEmbeddedGlob(k) {
+				if text, ok := sortEmbeddedText(k, s); ok {
+					c.markBlockString(text)
+					v[k] = text
+				}
+				continue
+			}
+			c.sortEmbeddedRecursive(child)
+		}
+	case []interface{}:
+		for _, child := range v {
+			c.sortEmbeddedRecursive(child)
+		}
+	}
+}
+
+// sorted text of value. false when text can not be sorted.
+func sortEmbeddedText(name string, text string) (string, bool) {
+	if len(strings.TrimSpace(text)) == 0 {
+		return "", false
+	}
+	var result string
+	if hasExtension(name, configMapPropertiesExts) {
+		result = sortPropertiesLines(text)
+	} else {
+		var ok bool
+		result, ok = sortINILines(text)
+		if !ok {
+			return "", false
+		}
+	}
+	if !strings.HasSuffix(text, "\n") {
+		result = strings.TrimSuffix(result, "\n")
+	}
+	return result, true
+}
+
+// key of ini line , like "key = value" , "key: value"
+func iniKey(line string) (string, bool) {
+	idx := strings.IndexAny(line, "=:")
+	if idx <= 0 {
+		return "", false
+	}
+	return strings.TrimSpace(line[:idx]), true
+}
+
+// sort entries of ini text by key within sections. false when text has lines which are not ini.
+func sortINILines(text string) (string, bool) {
+	type entry struct {
+		key   string
+		lines []string
+	}
+	type section struct {
+		header  []string // comments and [section] line
+		entries []entry
+	}
+	sections := []*section{{}}
+	current := sections[0]
+	pending := []string{} // comment lines before entry or section
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case len(trimmed) == 0:
+			continue
+		case strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";"):
+			pending = append(pending, line)
+		case strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]"):
+			current = &section{header: append(pending, line)}
+			sections = append(sections, current)
+			pending = []string{}
+		case (line[0] == ' ' || line[0] == '\t') && len(current.entries) > 0 && len(pending) == 0:
+			// multi-line value
+			last := &current.entries[len(current.entries)-1]
+			last.lines = append(last.lines, line)
+		default:
+			key, ok := iniKey(trimmed)
+			if !ok {
+				return "", false
+			}
+			current.entries = append(current.entries, entry{key: key, lines: append(pending, line)})
+			pending = []string{}
+		}
+	}
+	result := []string{}
+	for _, s := range sections {
+		if len(s.header) == 0 && len(s.entries) == 0 {
+			continue
+		}
+		if len(result) > 0 && len(s.header) > 0 {
+			// blank line between sections
+			result = append(result, "")
+		}
+		result = append(result, s.header...)
+		sort.SliceStable(s.entries, func(i, j int) bool {
+			return naturalLess(s.entries[i].key, s.entries[j].key)
+		})
+		for _, e := range s.entries {
+			result = append(result, e.lines...)
+		}
+	}
+	// comments at end
+	result = append(result, pending...)
+	return strings.Join(result, "\n") + "\n", true
+}
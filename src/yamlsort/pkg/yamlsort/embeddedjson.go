@@ -0,0 +1,169 @@
+//
+// yamlsort - --sort-embedded-json option
+//
+// sort keys of json text in string values (annotations , fluentd and envoy configs ...) in same
+// order as yaml keys , and re-embed it.
+//   yamlsort --sort-embedded-json -i deploy.yaml
+//   yamlsort --sort-embedded-json=pretty -i configmap.yaml
+// formats
+//   keep      pretty when value has line breaks (like block scalar | ) , compact otherwise (default)
+//   compact   one line without spaces
+//   pretty    indent 2 spaces
+// only string values which are json object or array are changed. trailing line break of value is kept.
+//
+package yamlsort
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// values of --sort-embedded-json
+const (
+	embeddedJSONKeep    = "keep"
+	embeddedJSONCompact = "compact"
+	embeddedJSONPretty  = "pretty"
+)
+
+func checkEmbeddedJSON(format string) error {
+	switch format {
+	case "", embeddedJSONKeep, embeddedJSONCompact, embeddedJSONPretty:
+		return nil
+	}
+	return fmt.Errorf("unknown --sort-embedded-json %q. (keep , compact , pretty)", format)
+}
+
+// sort embedded json of all string values in data
+func (c *yamlsortCmd) sortEmbeddedJSONRecursive(data interface{}) interface{} {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			v[k] = c.sortEmbeddedJSONRecursive(child)
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = c.sortEmbeddedJSONRecursive(child)
+		}
+	case string:
+		if text, ok := c.sortEmbeddedJSON(v); ok {
+			return text
+		}
+	}
+	return data
+}
+
+// sorted json text of string value. false when value is not json object or array.
+func (c *yamlsortCmd) sortEmbeddedJSON(s string) (string, bool) {
+	trimmed := strings.TrimSpace(s)
+	if len(trimmed) < 2 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return "", false
+	}
+	decoder := json.NewDecoder(strings.NewReader(trimmed))
+	decoder.UseNumber()
+	var value interface{}
+	err := decoder.Decode(&value)
+	if err != nil || decoder.More() {
+		return "", false
+	}
+	indent := ""
+	if c.embeddedjson == embeddedJSONPretty || (c.embeddedjson != embeddedJSONCompact && strings.Contains(trimmed, "\n")) {
+		indent = "  "
+	}
+	buf := new(bytes.Buffer)
+	err = writeSortedJSON(buf, value, indent, "")
+	if err != nil {
+		return "", false
+	}
+	if strings.HasSuffix(s, "\n") {
+		buf.WriteString("\n")
+	}
+	return buf.String(), true
+}
+
+// write json value with keys in sortKeys order. no indent when indent is empty.
+func writeSortedJSON(buf *bytes.Buffer, value interface{}, indent string, prefix string) error {
+	newline := func(prefix string) {
+		if len(indent) > 0 {
+			buf.WriteString("\n" + prefix)
+		}
+	}
+	separator := ":"
+	if len(indent) > 0 {
+		separator = ": "
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+		keylist := make([]string, 0, len(v))
+		for k := range v {
+			keylist = append(keylist, k)
+		}
+		sortKeys(keylist)
+		buf.WriteString("{")
+		for i, k := range keylist {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			newline(prefix + indent)
+			err := writeJSONString(buf, k)
+			if err != nil {
+				return err
+			}
+			buf.WriteString(separator)
+			err = writeSortedJSON(buf, v[k], indent, prefix+indent)
+			if err != nil {
+				return err
+			}
+		}
+		newline(prefix)
+		buf.WriteString("}")
+	case []interface{}:
+		if len(v) == 0 {
+			buf.WriteString("[]")
+			return nil
+		}
+		buf.WriteString("[")
+		for i, child := range v {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			newline(prefix + indent)
+			err := writeSortedJSON(buf, child, indent, prefix+indent)
+			if err != nil {
+				return err
+			}
+		}
+		newline(prefix)
+		buf.WriteString("]")
+	case string:
+		return writeJSONString(buf, v)
+	case json.Number:
+		buf.WriteString(v.String())
+	default:
+		// bool , null
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+	}
+	return nil
+}
+
+// json string without html escape (<>& are kept)
+func writeJSONString(buf *bytes.Buffer, s string) error {
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	err := encoder.Encode(s)
+	if err != nil {
+		return err
+	}
+	// Encode writes line break
+	buf.Truncate(buf.Len() - 1)
+	return nil
+}
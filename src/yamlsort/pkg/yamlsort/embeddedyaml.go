@@ -0,0 +1,124 @@
+//
+// yamlsort - --sort-embedded-yaml option
+//
+// sort yaml documents in string values (like data of ConfigMap) , and write them as block
+// scalar ( | ) with indent.
+//   yamlsort --sort-embedded-yaml -i configmap.yaml
+//     data:
+//       config.yaml: |
+//         logging:
+//           level: info
+//         server:
+//           port: 8080
+// only multi-line string values which are one yaml map or list are changed. values with comments
+// (comments would be lost) , json text (see --sort-embedded-json) and multiple documents are not changed.
+//
+package yamlsort
+
+import (
+	"bytes"
+	"strings"
+)
+
+// sort embedded yaml of all string values in data
+func (c *yamlsortCmd) sortEmbeddedYAMLRecursive(data interface{}) interface{} {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			v[k] = c.sortEmbeddedYAMLRecursive(child)
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = c.sortEmbeddedYAMLRecursive(child)
+		}
+	case string:
+		if text, ok := c.sortEmbeddedYAML(v); ok {
+			c.markBlockString(text)
+			return text
+		}
+	}
+	return data
+}
+
+// sorted yaml text of string value. false when value is not yaml map or list.
+func (c *yamlsortCmd) sortEmbeddedYAML(s string) (string, bool) {
+	trimmed := strings.TrimSpace(s)
+	if !strings.Contains(trimmed, "\n") || strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		return "", false
+	}
+	for _, line := range strings.Split(trimmed, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "---" || strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "#") || strings.Contains(line, " #") {
+			return "", false
+		}
+	}
+	data, err := c.unmarshalYAML([]byte(s))
+	if err != nil {
+		return "", false
+	}
+	switch v := data.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return "", false
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			return "", false
+		}
+	default:
+		return "", false
+	}
+	// yaml in embedded yaml
+	data = c.sortEmbeddedYAMLRecursive(data)
+	output, err := c.myMarshal(data)
+	if err != nil {
+		return "", false
+	}
+	text := string(output)
+	if !strings.HasSuffix(s, "\n") {
+		text = strings.TrimSuffix(text, "\n")
+	}
+	return text, true
+}
+
+// write string value as block scalar ( | ) in output. return false when value can not be block scalar.
+func (c *yamlsortCmd) markBlockString(value string) bool {
+	first := strings.TrimLeft(value, "\n")
+	if !strings.Contains(strings.TrimSuffix(value, "\n"), "\n") || strings.HasSuffix(value, "\n\n") {
+		return false
+	}
+	if strings.HasPrefix(first, " ") || strings.HasPrefix(first, "\t") {
+		// indentation indicator is required
+		return false
+	}
+	for _, r := range value {
+		if r != '\n' && r != '\t' && (r < ' ' || r == 0x7f || r == '\ufeff') {
+			return false
+		}
+	}
+	if c.blockstrings == nil {
+		c.blockstrings = map[string]bool{}
+	}
+	c.blockstrings[value] = true
+	return true
+}
+
+// write string value of markBlockString as block scalar. return false when value is not marked.
+func (c *yamlsortCmd) writeBlockString(writer *bytes.Buffer, indent int, value string) bool {
+	if !c.blockstrings[value] {
+		return false
+	}
+	if strings.HasSuffix(value, "\n") {
+		writer.WriteString(" |\n")
+	} else {
+		writer.WriteString(" |-\n")
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(value, "\n"), "\n") {
+		if len(line) > 0 {
+			writer.WriteString(c.indentstr(indent))
+			writer.WriteString(line)
+		}
+		writer.WriteString("\n")
+	}
+	return true
+}
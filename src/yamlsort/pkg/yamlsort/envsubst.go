@@ -0,0 +1,140 @@
+//
+// yamlsort - environment variable substitution (--envsubst)
+//
+package yamlsort
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+//-------------------------------------------------------------------------
+// load environment variables. --env-file values, overridden by process environment (or client environment in daemon).
+//
+func (c *yamlsortCmd) loadEnv() (map[string]string, error) {
+	env := map[string]string{}
+	for _, filename := range c.envfilenames {
+		fp, err := os.Open(filename)
+		if err != nil {
+			return nil, err
+		}
+		scanner := bufio.NewScanner(fp)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if len(line) == 0 || strings.HasPrefix(line, "#") {
+				continue
+			}
+			line = strings.TrimPrefix(line, "export ")
+			idx := strings.Index(line, "=")
+			if idx <= 0 {
+				continue
+			}
+			key := strings.TrimSpace(line[:idx])
+			value := strings.TrimSpace(line[idx+1:])
+			if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+				value = value[1 : len(value)-1]
+			}
+			env[key] = value
+		}
+		err = scanner.Err()
+		fp.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, s := range c.environment() {
+		idx := strings.Index(s, "=")
+		if idx > 0 {
+			env[s[:idx]] = s[idx+1:]
+		}
+	}
+	return env, nil
+}
+
+// environment of process , or environment of client in daemon
+func (c *yamlsortCmd) environment() []string {
+	if c.environ != nil {
+		return c.environ
+	}
+	return os.Environ()
+}
+
+// expand ${VAR} and ${VAR:-default} in string. undefined variable is empty string.
+func expandEnv(s string, env map[string]string) string {
+	if !strings.Contains(s, "${") {
+		return s
+	}
+	result := new(strings.Builder)
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(s[start:], "}")
+		if end < 0 {
+			break
+		}
+		end += start
+		result.WriteString(s[:start])
+		name := s[start+2 : end]
+		defaultvalue := ""
+		blnDefault := false
+		if idx := strings.Index(name, ":-"); idx >= 0 {
+			defaultvalue = name[idx+2:]
+			name = name[:idx]
+			blnDefault = true
+		}
+		if !isEnvName(name) {
+			// not a variable reference. keep as is.
+			result.WriteString(s[start : end+1])
+		} else if value, ok := env[name]; ok && (len(value) > 0 || !blnDefault) {
+			result.WriteString(value)
+		} else {
+			result.WriteString(defaultvalue)
+		}
+		s = s[end+1:]
+	}
+	result.WriteString(s)
+	return result.String()
+}
+
+func isEnvName(name string) bool {
+	if len(name) == 0 {
+		return false
+	}
+	for i, r := range name {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (i > 0 && r >= '0' && r <= '9') {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// expand variables in all string values.
+// value which is only one reference like "${REPLICAS}" is read as yaml scalar (number, bool),
+// same as envsubst | yamlsort pipeline.
+func expandEnvRecursive(data interface{}, env map[string]string) interface{} {
+	if m, ok := data.(map[string]interface{}); ok {
+		for k, v := range m {
+			m[k] = expandEnvRecursive(v, env)
+		}
+		return m
+	} else if a, ok := data.([]interface{}); ok {
+		for i, v := range a {
+			a[i] = expandEnvRecursive(v, env)
+		}
+		return a
+	} else if s, ok := data.(string); ok {
+		expanded := expandEnv(s, env)
+		if expanded == s {
+			return s
+		}
+		if strings.HasPrefix(s, "${") && strings.Index(s, "}") == len(s)-1 {
+			return readScalar(expanded)
+		}
+		return expanded
+	}
+	return data
+}
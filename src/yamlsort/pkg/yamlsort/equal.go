@@ -0,0 +1,56 @@
+//
+// yamlsort - equal subcommand
+//
+// exit 0 when two files are semantically same (key order and formatting are ignored) ,
+// exit 1 when they differ , and exit 2 on error (like cmp and diff).
+//   if yamlsort equal a.yaml b.yaml; then echo same; fi
+//
+package yamlsort
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+//---------------------------------------------------------------------
+//  equal subcommand
+//
+func newEqualCmd(yamlsort *yamlsortCmd) *cobra.Command {
+	var blnVerbose bool
+
+	cmd := &cobra.Command{
+		Use:   "equal file1 file2",
+		Short: "exit 0 if two files are semantically same , 1 if they differ , 2 on error",
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return withExitStatus2(fmt.Errorf("equal requires two file names"))
+			}
+			yamlsort.maxlinesize = defaultMaxLineSize
+			changes, err := yamlsort.compareFiles(args[0], args[1])
+			if err != nil {
+				return withExitStatus2(err)
+			}
+			if len(changes) == 0 {
+				return nil
+			}
+			if blnVerbose {
+				for _, change := range changes {
+					fmt.Fprintln(yamlsort.stdout, change)
+				}
+			}
+			// difference is result , not error message
+			c.SilenceErrors = true
+			return fmt.Errorf("%s and %s differ", args[0], args[1])
+		},
+	}
+	cmd.SilenceUsage = true
+	cmd.SetFlagErrorFunc(func(c *cobra.Command, err error) error {
+		return withExitStatus2(err)
+	})
+
+	f := cmd.Flags()
+	f.BoolVarP(&blnVerbose, "verbose", "v", false, "print differing paths")
+
+	return cmd
+}
@@ -0,0 +1,44 @@
+//
+// yamlsort - owner and extended attributes of files on linux
+//
+package yamlsort
+
+import (
+	"os"
+	"syscall"
+)
+
+// copy owner and extended attributes of src into dst. errors are ignored , because
+// only root can change owner , and file systems may not support extended attributes.
+func copyFileAttributes(src string, dst string, info os.FileInfo) {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		os.Lchown(dst, int(st.Uid), int(st.Gid))
+	}
+	size, err := syscall.Listxattr(src, nil)
+	if err != nil || size <= 0 {
+		return
+	}
+	names := make([]byte, size)
+	size, err = syscall.Listxattr(src, names)
+	if err != nil {
+		return
+	}
+	start := 0
+	for i := 0; i < size; i++ {
+		if names[i] != 0 {
+			continue
+		}
+		name := string(names[start:i])
+		start = i + 1
+		vsize, err := syscall.Getxattr(src, name, nil)
+		if err != nil || vsize < 0 {
+			continue
+		}
+		value := make([]byte, vsize)
+		vsize, err = syscall.Getxattr(src, name, value)
+		if err != nil {
+			continue
+		}
+		syscall.Setxattr(dst, name, value[:vsize], 0)
+	}
+}
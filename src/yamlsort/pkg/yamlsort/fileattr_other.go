@@ -0,0 +1,14 @@
+// +build !linux
+
+//
+// yamlsort - owner and extended attributes of files on other systems
+//
+package yamlsort
+
+import (
+	"os"
+)
+
+// owner and extended attributes are kept only on linux
+func copyFileAttributes(src string, dst string, info os.FileInfo) {
+}
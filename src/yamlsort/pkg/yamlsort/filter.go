@@ -0,0 +1,289 @@
+//
+// yamlsort - --filter option
+//
+// select subtree of each document by JSONPath , and output only that portion.
+//   $.spec.template.spec.containers[0]     one node is output as document
+//   $..image                               many nodes are output as list
+//   $.items[?(@.kind == 'Deployment')]     filter by value (== , !=) or existence [?(@.key)]
+// supported syntax
+//   $ , .key , ['key'] , [N] (negative from end) , [*] , .* , ..key , ..* , [?(...)]
+// document without matching node is dropped.
+//
+package yamlsort
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// kind of JSONPath step
+const (
+	jpKey = iota
+	jpIndex
+	jpWildcard
+	jpDescend // ..key or ..*
+	jpFilter
+)
+
+type jsonPathStep struct {
+	kind    int
+	key     string // key of jpKey , jpDescend ("*" for all) , key path of jpFilter
+	index   int
+	op      string // "==" , "!=" , "" (existence) of jpFilter
+	operand string
+}
+
+// parse JSONPath expression
+func parseJSONPath(expr string) ([]jsonPathStep, error) {
+	rest := strings.TrimSpace(expr)
+	rest = strings.TrimPrefix(rest, "$")
+	steps := []jsonPathStep{}
+	for len(rest) > 0 {
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			rest = rest[2:]
+			key, n := jsonPathName(rest)
+			if n == 0 {
+				return nil, fmt.Errorf("--filter %q: name is required after '..'", expr)
+			}
+			steps = append(steps, jsonPathStep{kind: jpDescend, key: key})
+			rest = rest[n:]
+		case rest[0] == '.':
+			rest = rest[1:]
+			key, n := jsonPathName(rest)
+			if n == 0 {
+				return nil, fmt.Errorf("--filter %q: name is required after '.'", expr)
+			}
+			if key == "*" {
+				steps = append(steps, jsonPathStep{kind: jpWildcard})
+			} else {
+				steps = append(steps, jsonPathStep{kind: jpKey, key: key})
+			}
+			rest = rest[n:]
+		case rest[0] == '[':
+			end := strings.Index(rest, "]")
+			if strings.HasPrefix(rest, "[?(") {
+				end = strings.Index(rest, ")]") + 1
+			}
+			if end <= 0 {
+				return nil, fmt.Errorf("--filter %q: ']' is not found", expr)
+			}
+			step, err := parseJSONPathBracket(rest[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("--filter %q: %v", expr, err)
+			}
+			steps = append(steps, step)
+			rest = rest[end+1:]
+		default:
+			if len(steps) > 0 {
+				return nil, fmt.Errorf("--filter %q: unexpected %q", expr, rest)
+			}
+			// path without leading $.
+			rest = "." + rest
+		}
+	}
+	return steps, nil
+}
+
+// name after '.' or '..'. returns name and length.
+func jsonPathName(s string) (string, int) {
+	if strings.HasPrefix(s, "*") {
+		return "*", 1
+	}
+	end := strings.IndexAny(s, ".[")
+	if end < 0 {
+		end = len(s)
+	}
+	return s[:end], end
+}
+
+// step of [...]
+func parseJSONPathBracket(inner string) (jsonPathStep, error) {
+	inner = strings.TrimSpace(inner)
+	if inner == "*" {
+		return jsonPathStep{kind: jpWildcard}, nil
+	}
+	if key, ok := jsonPathQuoted(inner); ok {
+		return jsonPathStep{kind: jpKey, key: key}, nil
+	}
+	if strings.HasPrefix(inner, "?(") && strings.HasSuffix(inner, ")") {
+		cond := strings.TrimSpace(inner[2 : len(inner)-1])
+		step := jsonPathStep{kind: jpFilter}
+		for _, op := range []string{"==", "!="} {
+			if idx := strings.Index(cond, op); idx > 0 {
+				step.op = op
+				operand := strings.TrimSpace(cond[idx+2:])
+				if s, ok := jsonPathQuoted(operand); ok {
+					operand = s
+				}
+				step.operand = operand
+				cond = strings.TrimSpace(cond[:idx])
+				break
+			}
+		}
+		if !strings.HasPrefix(cond, "@.") {
+			return step, fmt.Errorf("filter must start with @. : %s", inner)
+		}
+		step.key = cond[2:]
+		return step, nil
+	}
+	i, err := strconv.Atoi(inner)
+	if err != nil {
+		return jsonPathStep{}, fmt.Errorf("invalid [%s]", inner)
+	}
+	return jsonPathStep{kind: jpIndex, index: i}, nil
+}
+
+// 'text' or "text"
+func jsonPathQuoted(s string) (string, bool) {
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1], true
+	}
+	return "", false
+}
+
+// path selects at most one node (no wildcard , descent and filter)
+func jsonPathIsDefinite(steps []jsonPathStep) bool {
+	for _, step := range steps {
+		if step.kind != jpKey && step.kind != jpIndex {
+			return false
+		}
+	}
+	return true
+}
+
+// nodes selected by path
+func evalJSONPath(data interface{}, steps []jsonPathStep) []interface{} {
+	nodes := []interface{}{data}
+	for _, step := range steps {
+		next := []interface{}{}
+		for _, node := range nodes {
+			next = append(next, jsonPathApply(node, step)...)
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+// children of node selected by one step
+func jsonPathApply(node interface{}, step jsonPathStep) []interface{} {
+	result := []interface{}{}
+	switch step.kind {
+	case jpKey:
+		if m, ok := node.(map[string]interface{}); ok {
+			if v, ok := m[step.key]; ok {
+				result = append(result, v)
+			}
+		}
+	case jpIndex:
+		if a, ok := node.([]interface{}); ok {
+			i := step.index
+			if i < 0 {
+				i += len(a)
+			}
+			if i >= 0 && i < len(a) {
+				result = append(result, a[i])
+			}
+		}
+	case jpWildcard:
+		result = append(result, jsonPathChildren(node)...)
+	case jpFilter:
+		for _, child := range jsonPathChildren(node) {
+			if jsonPathMatch(child, step) {
+				result = append(result, child)
+			}
+		}
+	case jpDescend:
+		// node itself and all descendants
+		stack := []interface{}{node}
+		for len(stack) > 0 {
+			n := stack[0]
+			stack = stack[1:]
+			if step.key == "*" {
+				children := jsonPathChildren(n)
+				result = append(result, children...)
+				stack = append(stack, children...)
+				continue
+			}
+			if m, ok := n.(map[string]interface{}); ok {
+				if v, ok := m[step.key]; ok {
+					result = append(result, v)
+				}
+			}
+			stack = append(stack, jsonPathChildren(n)...)
+		}
+	}
+	return result
+}
+
+// values of map in key order , or elements of slice
+func jsonPathChildren(node interface{}) []interface{} {
+	result := []interface{}{}
+	if m, ok := node.(map[string]interface{}); ok {
+		keylist := make([]string, 0, len(m))
+		for k := range m {
+			keylist = append(keylist, k)
+		}
+		sortKeys(keylist)
+		for _, k := range keylist {
+			result = append(result, m[k])
+		}
+	} else if a, ok := node.([]interface{}); ok {
+		result = append(result, a...)
+	}
+	return result
+}
+
+// node matches [?(@.key op operand)]
+func jsonPathMatch(node interface{}, step jsonPathStep) bool {
+	segs, err := parsePath(step.key)
+	if err != nil {
+		return false
+	}
+	value, found := getPath(node, segs)
+	switch step.op {
+	case "==":
+		return found && scalarEqualsText(value, step.operand)
+	case "!=":
+		return !found || !scalarEqualsText(value, step.operand)
+	}
+	return found
+}
+
+// text of scalar value to compare , like 1000000 (not 1e+06)
+func scalarText(value interface{}) string {
+	if f, ok := value.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// value equals operand text. number is compared as number , so 1e6 equals 1000000.
+func scalarEqualsText(value interface{}, text string) bool {
+	if f, ok := value.(float64); ok {
+		if g, err := strconv.ParseFloat(text, 64); err == nil {
+			return f == g
+		}
+	}
+	return scalarText(value) == text
+}
+
+// apply --filter to document data. return false when nothing is selected.
+func (c *yamlsortCmd) applyFilter(data interface{}) (interface{}, bool, error) {
+	if c.filtersteps == nil {
+		steps, err := parseJSONPath(c.filter)
+		if err != nil {
+			return nil, false, err
+		}
+		c.filtersteps = steps
+	}
+	nodes := evalJSONPath(data, c.filtersteps)
+	if len(nodes) == 0 {
+		return nil, false, nil
+	}
+	if jsonPathIsDefinite(c.filtersteps) {
+		return nodes[0], true, nil
+	}
+	return nodes, true, nil
+}
@@ -0,0 +1,136 @@
+//
+// yamlsort - --float-format option
+//
+// numbers are float64 after parse , and written like %v (1.10 becomes 1.1 , and big
+// values have exponent like 1e+21). --float-format selects how they are written.
+//   g          shortest representation , like %v (default)
+//   f          no exponent , like 12345678901234567000
+//   preserve   text of input as is (1.10 , 1e3 , 0x1F). changed value is written like g.
+//   %.2f       printf format for non-integer values. integers are written like f.
+//
+package yamlsort
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// values of --float-format
+const (
+	floatFormatG        = "g"
+	floatFormatF        = "f"
+	floatFormatPreserve = "preserve"
+)
+
+// check --float-format
+func checkFloatFormat(format string) error {
+	switch format {
+	case "", floatFormatG, floatFormatF, floatFormatPreserve:
+		return nil
+	}
+	if strings.Count(format, "%") != 1 || strings.Contains(fmt.Sprintf(format, 1.5), "%!") {
+		return fmt.Errorf("unknown --float-format %q , g , f , preserve , or printf format like %%.2f", format)
+	}
+	return nil
+}
+
+// text of number
+func (c *yamlsortCmd) formatFloat(path string, f float64) string {
+	if text, ok := specialFloatText(f); ok {
+		return text
+	}
+	switch c.floatformat {
+	case floatFormatPreserve:
+		if number, ok := c.numbertexts[path]; ok && number.value == f {
+			return number.text
+		}
+	case floatFormatF:
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	case "", floatFormatG:
+	default:
+		if f == math.Trunc(f) {
+			return strconv.FormatFloat(f, 'f', -1, 64)
+		}
+		return fmt.Sprintf(c.floatformat, f)
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// text and value of number in input
+type numberText struct {
+	text  string
+	value float64
+}
+
+//---------------------------------------------------------------------
+//  rawScalarNode class
+// node of document , which keeps text of scalars
+//
+type rawScalarNode struct {
+	value interface{} // map[string]*rawScalarNode , []*rawScalarNode , or string
+}
+
+func (r *rawScalarNode) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	// yaml.v2 panics on merge key (<<) into nil map
+	m := map[string]*rawScalarNode{}
+	if err := unmarshal(&m); err == nil {
+		r.value = m
+		return nil
+	}
+	var l []*rawScalarNode
+	if err := unmarshal(&l); err == nil {
+		r.value = l
+		return nil
+	}
+	var s string
+	if err := unmarshal(&s); err == nil {
+		r.value = s
+	}
+	return nil
+}
+
+// text of numbers in document by path , for --float-format=preserve
+func (c *yamlsortCmd) numberTexts(text []byte, data interface{}) map[string]numberText {
+	var raw rawScalarNode
+	if err := unmarshalYAMLv2(text, &raw); err != nil {
+		return nil
+	}
+	result := map[string]numberText{}
+	c.collectNumberTexts(result, "", data, &raw)
+	return result
+}
+
+// walk data and raw node together. path is same as myMershalRecursive.
+func (c *yamlsortCmd) collectNumberTexts(result map[string]numberText, path string, data interface{}, raw *rawScalarNode) {
+	if raw == nil {
+		return
+	}
+	switch v := data.(type) {
+	case map[string]interface{}:
+		m, _ := raw.value.(map[string]*rawScalarNode)
+		for k, child := range v {
+			c.collectNumberTexts(result, c.calcPathMap(path, k), child, m[k])
+		}
+	case []interface{}:
+		l, _ := raw.value.([]*rawScalarNode)
+		for i, child := range v {
+			if i < len(l) {
+				c.collectNumberTexts(result, c.calcPathSliceElement(path, i, child), child, l[i])
+			}
+		}
+	case float64:
+		text, ok := raw.value.(string)
+		if !ok {
+			return
+		}
+		// text is kept only when it is same value
+		var parsed interface{}
+		if err := yaml.Unmarshal([]byte(text), &parsed); err == nil && parsed == v {
+			result[path] = numberText{text: text, value: v}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+//
+// yamlsort - framed stdin protocol
+//
+// with --framed , many buffers are sorted through one process without touching disk.
+// each frame is header line and content of length bytes.
+//   request  : "frame <length> <filename>\n" and yaml text
+//   response : "frame <length> <filename>\n" and sorted yaml text ,
+//              or "error <length> <filename>\n" and error message
+// responses are written in order of requests. error of one frame does not stop others.
+//
+package yamlsort
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// read one frame. io.EOF at end of input.
+func readFrame(reader *bufio.Reader) (string, []byte, error) {
+	line, err := reader.ReadString('\n')
+	if err == io.EOF && len(line) == 0 {
+		return "", nil, io.EOF
+	}
+	if err != nil && err != io.EOF {
+		return "", nil, err
+	}
+	fields := strings.SplitN(strings.TrimRight(line, "\r\n"), " ", 3)
+	if len(fields) != 3 || fields[0] != "frame" {
+		return "", nil, fmt.Errorf("invalid frame header %q", line)
+	}
+	length, err := strconv.Atoi(fields[1])
+	if err != nil || length < 0 {
+		return "", nil, fmt.Errorf("invalid frame length %q", fields[1])
+	}
+	content := make([]byte, length)
+	_, err = io.ReadFull(reader, content)
+	if err != nil {
+		return "", nil, fmt.Errorf("frame %s: %v", fields[2], err)
+	}
+	return fields[2], content, nil
+}
+
+// write one frame
+func writeFrame(w io.Writer, kind string, filename string, content []byte) error {
+	_, err := fmt.Fprintf(w, "%s %d %s\n", kind, len(content), filename)
+	if err == nil {
+		_, err = w.Write(content)
+	}
+	return err
+}
+
+// sort frames of stdin , and write framed results to stdout
+func (c *yamlsortCmd) runFramed() (err error) {
+	if len(c.inputfilename) > 0 || len(c.outputfilename) > 0 || len(c.inputoutputfilename) > 0 {
+		return fmt.Errorf("--framed can not be used with -f , -i , -o")
+	}
+	err = c.prepareOptions()
+	if err != nil {
+		return err
+	}
+	reader := bufio.NewReader(c.stdin)
+	writer := newBufferedStdout(c.stdout)
+	defer closeOutput(writer, &err)
+	for {
+		filename, content, err := readFrame(reader)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		c.inputfilename = filename
+		output, err := c.sortBytes(content, nil)
+		c.inputfilename = ""
+		if err != nil {
+			err = writeFrame(writer, "error", filename, []byte(err.Error()))
+		} else {
+			err = writeFrame(writer, "frame", filename, output.Bytes())
+		}
+		if err != nil {
+			return err
+		}
+		// client may wait for response before next request
+		err = writer.Flush()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
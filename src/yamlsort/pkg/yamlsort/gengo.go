@@ -0,0 +1,158 @@
+//
+// yamlsort - gen-go subcommand
+//
+// generate go type definitions with json and yaml tags from structure of yaml files.
+//   yamlsort gen-go --package config --type Config config.yaml > config_types.go
+// fields are in sorted key order. map becomes struct (type name from key) , and
+// list of maps becomes slice of struct (singular name , like Containers -> Container).
+// key which is not in every sample has omitempty , and value which can be null is pointer.
+// all documents of all files are merged into one type.
+//
+package yamlsort
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+//---------------------------------------------------------------------
+//  goGenerator class
+// generate struct definitions of inferred type
+//
+type goGenerator struct {
+	names   typeNameSet
+	structs []string
+}
+
+// go type expression of inferred type. struct is generated for object.
+func (g *goGenerator) goType(t *inferredType, name string, parent string) string {
+	if t == nil {
+		return "interface{}"
+	}
+	result := ""
+	switch t.kind {
+	case typeBool:
+		result = "bool"
+	case typeInt:
+		result = "int"
+	case typeFloat:
+		result = "float64"
+	case typeString:
+		result = "string"
+	case typeArray:
+		return "[]" + g.goType(t.elem, singularName(name), parent)
+	case typeObject:
+		if len(t.fields) == 0 {
+			return "map[string]interface{}"
+		}
+		result = g.goStruct(t, g.names.unique(name, parent))
+	default:
+		return "interface{}"
+	}
+	if t.nullable {
+		return "*" + result
+	}
+	return result
+}
+
+// generate struct , and return its name
+func (g *goGenerator) goStruct(t *inferredType, name string) string {
+	index := len(g.structs)
+	g.structs = append(g.structs, "")
+	body := new(bytes.Buffer)
+	fieldnames := map[string]bool{}
+	for _, k := range t.sortedKeys() {
+		fieldname := typeName(k)
+		for i := 2; fieldnames[fieldname]; i++ {
+			fieldname = typeName(k) + strconv.Itoa(i)
+		}
+		fieldnames[fieldname] = true
+		tag := k
+		if t.optional(k) {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(body, "\t%s %s `json:%s yaml:%s`\n", fieldname, g.goType(t.fields[k].typ, fieldname, name), strconv.Quote(tag), strconv.Quote(tag))
+	}
+	g.structs[index] = fmt.Sprintf("type %s struct {\n%s}\n", name, body.String())
+	return name
+}
+
+// go source of type definitions
+func generateGoTypes(t *inferredType, packagename string, rootname string, sources []string) ([]byte, error) {
+	g := &goGenerator{names: typeNameSet{}}
+	root := g.goType(t, rootname, "")
+	output := new(bytes.Buffer)
+	fmt.Fprintf(output, "// Code generated by yamlsort gen-go from %s. DO NOT EDIT.\n\n", strings.Join(sources, " , "))
+	fmt.Fprintf(output, "package %s\n\n", packagename)
+	if root != rootname {
+		// root is not struct
+		fmt.Fprintf(output, "type %s %s\n\n", rootname, root)
+	}
+	for _, s := range g.structs {
+		output.WriteString(s)
+		output.WriteString("\n")
+	}
+	return format.Source(output.Bytes())
+}
+
+// merged type of all documents of files
+func (c *yamlsortCmd) inferFiles(filenames []string) (*inferredType, error) {
+	var result *inferredType
+	for _, filename := range filenames {
+		docs, err := c.readDocuments(filename)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", filename, err)
+		}
+		for _, doc := range docs {
+			result = mergeTypes(result, inferType(doc.Data))
+		}
+	}
+	if result == nil {
+		return nil, fmt.Errorf("no document in %s", strings.Join(filenames, " , "))
+	}
+	return result, nil
+}
+
+//---------------------------------------------------------------------
+//  gen-go subcommand
+//
+func newGenGoCmd(yamlsort *yamlsortCmd) *cobra.Command {
+	var packagename string
+	var rootname string
+
+	cmd := &cobra.Command{
+		Use:   "gen-go [--package name] [--type name] file...",
+		Short: "generate go type definitions with json and yaml tags from structure of yaml files",
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("gen-go requires input file names")
+			}
+			yamlsort.maxlinesize = defaultMaxLineSize
+			err := yamlsort.prepareOptions()
+			if err != nil {
+				return err
+			}
+			t, err := yamlsort.inferFiles(args)
+			if err != nil {
+				return err
+			}
+			source, err := generateGoTypes(t, packagename, typeName(rootname), args)
+			if err != nil {
+				return err
+			}
+			_, err = yamlsort.stdout.Write(source)
+			return err
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&packagename, "package", "config", "package name of generated source")
+	f.StringVar(&rootname, "type", "Config", "name of root type")
+
+	return cmd
+}
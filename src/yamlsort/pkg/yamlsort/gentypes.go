@@ -0,0 +1,255 @@
+//
+// yamlsort - gen-types subcommand
+//
+// generate type definitions of other languages from structure of yaml files , like gen-go ,
+// so programs which read same config in other languages stay in sync.
+//   yamlsort gen-types --lang ts --type Config config.yaml > config.ts
+//   yamlsort gen-types --lang python --type Config config.yaml > config_types.py
+// ts : interfaces. key which is not in every sample is optional (?) , null is "| null".
+// python : TypedDict classes (python 3.11 or later , for NotRequired).
+//   key which is not in every sample is NotRequired , null is Optional.
+//
+package yamlsort
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// names which can be used as keys without quote
+var (
+	tsIdentifierRegexp     = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+	pythonIdentifierRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// python keywords , which can not be field names of class syntax
+var pythonKeywords = map[string]bool{
+	"False": true, "None": true, "True": true, "and": true, "as": true, "assert": true, "async": true,
+	"await": true, "break": true, "class": true, "continue": true, "def": true, "del": true, "elif": true,
+	"else": true, "except": true, "finally": true, "for": true, "from": true, "global": true, "if": true,
+	"import": true, "in": true, "is": true, "lambda": true, "nonlocal": true, "not": true, "or": true,
+	"pass": true, "raise": true, "return": true, "try": true, "while": true, "with": true, "yield": true,
+}
+
+//---------------------------------------------------------------------
+//  tsGenerator class
+// generate typescript interfaces of inferred type
+//
+type tsGenerator struct {
+	names      typeNameSet
+	interfaces []string
+}
+
+// typescript type expression of inferred type. interface is generated for object.
+func (g *tsGenerator) tsType(t *inferredType, name string, parent string) string {
+	if t == nil {
+		return "unknown"
+	}
+	result := ""
+	switch t.kind {
+	case typeNull:
+		return "null"
+	case typeBool:
+		result = "boolean"
+	case typeInt, typeFloat:
+		result = "number"
+	case typeString:
+		result = "string"
+	case typeArray:
+		elem := g.tsType(t.elem, singularName(name), parent)
+		if strings.Contains(elem, " ") {
+			elem = "(" + elem + ")"
+		}
+		result = elem + "[]"
+	case typeObject:
+		if len(t.fields) == 0 {
+			result = "Record<string, unknown>"
+		} else {
+			result = g.tsInterface(t, g.names.unique(name, parent))
+		}
+	default:
+		return "unknown"
+	}
+	if t.nullable {
+		return result + " | null"
+	}
+	return result
+}
+
+// generate interface , and return its name
+func (g *tsGenerator) tsInterface(t *inferredType, name string) string {
+	index := len(g.interfaces)
+	g.interfaces = append(g.interfaces, "")
+	body := new(bytes.Buffer)
+	for _, k := range t.sortedKeys() {
+		key := k
+		if !tsIdentifierRegexp.MatchString(k) {
+			key = strconv.Quote(k)
+		}
+		if t.optional(k) {
+			key += "?"
+		}
+		fmt.Fprintf(body, "  %s: %s;\n", key, g.tsType(t.fields[k].typ, typeName(k), name))
+	}
+	g.interfaces[index] = fmt.Sprintf("export interface %s {\n%s}\n", name, body.String())
+	return name
+}
+
+// typescript source of type definitions
+func generateTsTypes(t *inferredType, rootname string, sources []string) []byte {
+	g := &tsGenerator{names: typeNameSet{}}
+	root := g.tsType(t, rootname, "")
+	output := new(bytes.Buffer)
+	fmt.Fprintf(output, "// Code generated by yamlsort gen-types from %s. DO NOT EDIT.\n\n", strings.Join(sources, " , "))
+	if root != rootname {
+		// root is not interface
+		fmt.Fprintf(output, "export type %s = %s;\n\n", rootname, root)
+	}
+	output.WriteString(strings.Join(g.interfaces, "\n"))
+	return output.Bytes()
+}
+
+//---------------------------------------------------------------------
+//  pythonGenerator class
+// generate python TypedDict classes of inferred type
+//
+type pythonGenerator struct {
+	names   typeNameSet
+	classes []string
+}
+
+// python type expression of inferred type. class is generated for object.
+func (g *pythonGenerator) pythonType(t *inferredType, name string, parent string) string {
+	if t == nil {
+		return "Any"
+	}
+	result := ""
+	switch t.kind {
+	case typeNull:
+		return "None"
+	case typeBool:
+		result = "bool"
+	case typeInt:
+		result = "int"
+	case typeFloat:
+		result = "float"
+	case typeString:
+		result = "str"
+	case typeArray:
+		result = "List[" + g.pythonType(t.elem, singularName(name), parent) + "]"
+	case typeObject:
+		if len(t.fields) == 0 {
+			result = "Dict[str, Any]"
+		} else {
+			result = g.pythonClass(t, g.names.unique(name, parent))
+		}
+	default:
+		return "Any"
+	}
+	if t.nullable {
+		return "Optional[" + result + "]"
+	}
+	return result
+}
+
+// generate class , and return its name.
+// functional syntax is used when some key is not python identifier.
+func (g *pythonGenerator) pythonClass(t *inferredType, name string) string {
+	index := len(g.classes)
+	g.classes = append(g.classes, "")
+	keylist := t.sortedKeys()
+	types := make([]string, len(keylist))
+	blnFunctional := false
+	for i, k := range keylist {
+		types[i] = g.pythonType(t.fields[k].typ, typeName(k), name)
+		if t.optional(k) {
+			types[i] = "NotRequired[" + types[i] + "]"
+		}
+		if !pythonIdentifierRegexp.MatchString(k) || pythonKeywords[k] {
+			blnFunctional = true
+		}
+	}
+	body := new(bytes.Buffer)
+	if blnFunctional {
+		fmt.Fprintf(body, "%s = TypedDict(%s, {\n", name, strconv.Quote(name))
+		for i, k := range keylist {
+			fmt.Fprintf(body, "    %s: %s,\n", strconv.Quote(k), types[i])
+		}
+		body.WriteString("})\n")
+	} else {
+		fmt.Fprintf(body, "class %s(TypedDict):\n", name)
+		for i, k := range keylist {
+			fmt.Fprintf(body, "    %s: %s\n", k, types[i])
+		}
+	}
+	g.classes[index] = body.String()
+	return name
+}
+
+// python source of type definitions
+func generatePythonTypes(t *inferredType, rootname string, sources []string) []byte {
+	g := &pythonGenerator{names: typeNameSet{}}
+	root := g.pythonType(t, rootname, "")
+	output := new(bytes.Buffer)
+	fmt.Fprintf(output, "# Code generated by yamlsort gen-types from %s. DO NOT EDIT.\n\n", strings.Join(sources, " , "))
+	output.WriteString("from typing import Any, Dict, List, NotRequired, Optional, TypedDict\n")
+	// class is defined before it is used. children are generated after parent.
+	for i := len(g.classes) - 1; i >= 0; i-- {
+		output.WriteString("\n\n")
+		output.WriteString(g.classes[i])
+	}
+	if root != rootname {
+		// root is not class
+		fmt.Fprintf(output, "\n\n%s = %s\n", rootname, root)
+	}
+	return output.Bytes()
+}
+
+//---------------------------------------------------------------------
+//  gen-types subcommand
+//
+func newGenTypesCmd(yamlsort *yamlsortCmd) *cobra.Command {
+	var lang string
+	var rootname string
+
+	cmd := &cobra.Command{
+		Use:   "gen-types --lang ts|python [--type name] file...",
+		Short: "generate typescript interfaces or python TypedDicts from structure of yaml files",
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("gen-types requires input file names")
+			}
+			if lang != "ts" && lang != "python" {
+				return fmt.Errorf("unknown --lang %q , ts or python", lang)
+			}
+			yamlsort.maxlinesize = defaultMaxLineSize
+			err := yamlsort.prepareOptions()
+			if err != nil {
+				return err
+			}
+			t, err := yamlsort.inferFiles(args)
+			if err != nil {
+				return err
+			}
+			var source []byte
+			if lang == "ts" {
+				source = generateTsTypes(t, typeName(rootname), args)
+			} else {
+				source = generatePythonTypes(t, typeName(rootname), args)
+			}
+			_, err = yamlsort.stdout.Write(source)
+			return err
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&lang, "lang", "ts", "language of generated source (ts , python)")
+	f.StringVar(&rootname, "type", "Config", "name of root type")
+
+	return cmd
+}
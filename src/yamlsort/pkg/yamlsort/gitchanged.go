@@ -0,0 +1,137 @@
+//
+// yamlsort - --git-changed option
+//
+// sort only documents which contain lines changed in working tree (git diff HEAD) ,
+// and write other documents as is. git blame of untouched regions stays useful ,
+// so big repositories can adopt yamlsort incrementally.
+//   yamlsort --git-changed -w k8s/*.yaml
+// whole file is sorted when it is not tracked by git (new file) , or repository has no commit.
+// with --minimal , keys of changed documents are reordered keeping text of lines.
+//
+package yamlsort
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// "@@ -1,2 +3,4 @@" hunk header of unified diff
+var hunkHeaderRegexp = regexp.MustCompile(`^@@ -[0-9,]+ \+([0-9]+)(?:,([0-9]+))? @@`)
+
+// changed line range (1 origin , from <= to)
+type lineRange struct {
+	from int
+	to   int
+}
+
+// changed lines of file in working tree. nil means whole file.
+func gitChangedLines(filename string) ([]lineRange, error) {
+	dir := filepath.Dir(filename)
+	base := filepath.Base(filename)
+	if err := exec.Command("git", "-C", dir, "rev-parse", "--verify", "-q", "HEAD").Run(); err != nil {
+		// not in repository , or no commit
+		if err := exec.Command("git", "-C", dir, "rev-parse", "--git-dir").Run(); err != nil {
+			return nil, fmt.Errorf("--git-changed: %s is not in git repository", filename)
+		}
+		return nil, nil
+	}
+	if err := exec.Command("git", "-C", dir, "ls-files", "--error-unmatch", "--", base).Run(); err != nil {
+		// untracked file
+		return nil, nil
+	}
+
+	cmd := exec.Command("git", "-C", dir, "diff", "--no-color", "--no-ext-diff", "-U0", "HEAD", "--", base)
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("git diff %s: %v %s", filename, err, strings.TrimSpace(stderr.String()))
+	}
+	return parseHunkRanges(stdout.String()), nil
+}
+
+// line ranges of new file in hunk headers
+func parseHunkRanges(diff string) []lineRange {
+	result := []lineRange{}
+	for _, line := range strings.Split(diff, "\n") {
+		m := hunkHeaderRegexp.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		from, _ := strconv.Atoi(m[1])
+		count := 1
+		if len(m[2]) > 0 {
+			count, _ = strconv.Atoi(m[2])
+		}
+		if count == 0 {
+			// only deleted. lines around deleted place are touched.
+			result = append(result, lineRange{from: from, to: from + 1})
+			continue
+		}
+		result = append(result, lineRange{from: from, to: from + count - 1})
+	}
+	return result
+}
+
+// sort documents which contain changed lines of input file
+func (c *yamlsortCmd) sortGitChanged(input []byte, progress *progressReporter) (*bytes.Buffer, error) {
+	if len(c.inputfilename) == 0 || isObjectURI(c.inputfilename) {
+		return nil, fmt.Errorf("--git-changed requires input file in git repository")
+	}
+	ranges, err := gitChangedLines(c.inputfilename)
+	if err != nil {
+		return nil, err
+	}
+	sortfunc := c.sortDocuments
+	if c.blnMinimal {
+		// changed documents are reordered with --minimal
+		sortfunc = func(input []byte, progress *progressReporter) (*bytes.Buffer, error) {
+			return c.sortMinimal(input)
+		}
+	}
+	if ranges == nil {
+		return sortfunc(input, progress)
+	}
+
+	lines := strings.SplitAfter(string(input), "\n")
+	result := new(bytes.Buffer)
+	pos := 0
+	for _, r := range ranges {
+		from := r.from - 1
+		to := r.to - 1
+		if from < 0 {
+			from = 0
+		}
+		if to >= len(lines) {
+			to = len(lines) - 1
+		}
+		if from > to || to < pos {
+			continue
+		}
+		start, end := documentRange(lines, from, to)
+		if start < pos {
+			// document is sorted already with previous hunk
+			start = pos
+		}
+		if start >= end {
+			continue
+		}
+		result.WriteString(strings.Join(lines[pos:start], ""))
+		c.blnPartialInput = start > 0
+		output, err := sortfunc([]byte(strings.Join(lines[start:end], "")), progress)
+		c.blnPartialInput = false
+		if err != nil {
+			return nil, err
+		}
+		result.Write(output.Bytes())
+		pos = end
+	}
+	result.WriteString(strings.Join(lines[pos:], ""))
+	return result, nil
+}
@@ -0,0 +1,274 @@
+//
+// yamlsort - graph subcommand
+//
+// key tree of documents in graphviz (dot) or mermaid , for architecture documents.
+//   yamlsort graph --format dot deploy.yaml | dot -Tsvg > deploy.svg
+//   yamlsort graph --format mermaid --refs manifests/
+// each document is root node (kind/name) , maps and lists are nodes , and scalar values are
+// leaf nodes (key: value). with --refs , string values of keys like name , serviceName ,
+// configMapRef.name , which equal name of other document , are drawn as dashed edges.
+//
+package yamlsort
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// values of graph --format
+const (
+	graphFormatDot     = "dot"
+	graphFormatMermaid = "mermaid"
+)
+
+// max length of leaf value in label
+const graphValueWidth = 40
+
+//---------------------------------------------------------------------
+//  graph subcommand
+//
+func newGraphCmd(yamlsort *yamlsortCmd) *cobra.Command {
+	builder := &graphBuilder{yamlsort: yamlsort}
+	format := graphFormatDot
+
+	cmd := &cobra.Command{
+		Use:   "graph file...",
+		Short: "output key tree of documents in graphviz (dot) or mermaid , with references between documents",
+		RunE: func(c *cobra.Command, args []string) (err error) {
+			if len(args) == 0 {
+				return fmt.Errorf("graph requires input file names")
+			}
+			if format != graphFormatDot && format != graphFormatMermaid {
+				return fmt.Errorf("unknown --format %q. (dot , mermaid)", format)
+			}
+			defer yamlsort.bufferStdout()(&err)
+			yamlsort.maxlinesize = defaultMaxLineSize
+			if len(globalpriorkeys) == 0 {
+				globalpriorkeys = []string{"name"}
+			}
+			filenames, err := yamlsort.collectFiles(args)
+			if err != nil {
+				return err
+			}
+			for _, filename := range filenames {
+				err := yamlsort.addGraphFile(builder, filename, len(filenames) > 1)
+				if err != nil {
+					return fmt.Errorf("%s: %v", filename, err)
+				}
+			}
+			if format == graphFormatMermaid {
+				builder.writeMermaid(yamlsort.stdout)
+			} else {
+				builder.writeDot(yamlsort.stdout)
+			}
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&format, "format", graphFormatDot, "output format. dot (graphviz) , or mermaid")
+	f.BoolVar(&builder.blnRefs, "refs", false, "draw references between documents by name (like serviceName: api)")
+	f.IntVar(&builder.depth, "depth", 0, "maximum depth of keys under each document (0 is unlimited)")
+
+	return cmd
+}
+
+func (c *yamlsortCmd) addGraphFile(builder *graphBuilder, filename string, blnFileLabel bool) error {
+	input, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	return c.processDocuments(bytes.NewReader(input), func(doc *Document) error {
+		if doc.Data == nil && doc.raw.isEmpty() {
+			return nil
+		}
+		label := fmt.Sprintf("document %d", doc.Index)
+		if blnFileLabel {
+			label = fmt.Sprintf("%s [%d]", filename, doc.Index)
+		}
+		builder.addDocument(label, doc.Data)
+		return nil
+	})
+}
+
+//---------------------------------------------------------------------
+//  graphBuilder class
+// nodes and edges of documents
+//
+type graphNode struct {
+	id      string
+	label   string
+	blnRoot bool
+}
+
+type graphEdge struct {
+	from string
+	to   string
+}
+
+// string value , which may refer other document
+type graphRefCandidate struct {
+	from  string // node id of value
+	value string
+	root  string // node id of document
+}
+
+type graphBuilder struct {
+	yamlsort   *yamlsortCmd
+	blnRefs    bool
+	depth      int
+	nodes      []graphNode
+	edges      []graphEdge
+	names      map[string]string // name of document -> node id
+	candidates []graphRefCandidate
+}
+
+// add document as root node. label is used when document has no kind and name.
+func (b *graphBuilder) addDocument(label string, data interface{}) {
+	if b.names == nil {
+		b.names = map[string]string{}
+	}
+	name := ""
+	if m, ok := data.(map[string]interface{}); ok {
+		name, _ = m["name"].(string)
+		if meta, ok := m["metadata"].(map[string]interface{}); ok {
+			if s, ok := meta["name"].(string); ok {
+				name = s
+			}
+		}
+		if kind, ok := m["kind"].(string); ok && len(name) > 0 {
+			label = kind + "/" + name
+		} else if len(name) > 0 {
+			label = name
+		}
+	}
+	root := b.addNode(label, true)
+	if len(name) > 0 {
+		if _, ok := b.names[name]; !ok {
+			b.names[name] = root
+		}
+	}
+	b.walk(root, root, data, 1)
+}
+
+func (b *graphBuilder) addNode(label string, blnRoot bool) string {
+	id := fmt.Sprintf("n%d", len(b.nodes))
+	b.nodes = append(b.nodes, graphNode{id: id, label: label, blnRoot: blnRoot})
+	return id
+}
+
+// add children of data under parent node
+func (b *graphBuilder) walk(root string, parent string, data interface{}, depth int) {
+	if b.depth > 0 && depth > b.depth {
+		return
+	}
+	switch v := data.(type) {
+	case map[string]interface{}:
+		keylist := make([]string, 0, len(v))
+		for k := range v {
+			keylist = append(keylist, k)
+		}
+		sortKeys(keylist)
+		for _, k := range keylist {
+			b.addChild(root, parent, k, k, v[k], depth)
+		}
+	case []interface{}:
+		for i, child := range v {
+			label := fmt.Sprintf("[%d]", i)
+			if m, ok := child.(map[string]interface{}); ok {
+				if name, ok := m["name"].(string); ok {
+					label = fmt.Sprintf("[%d] %s", i, name)
+				}
+			}
+			b.addChild(root, parent, "", label, child, depth)
+		}
+	}
+}
+
+// add node of key (or list element) and its value
+func (b *graphBuilder) addChild(root string, parent string, key string, label string, value interface{}, depth int) {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		id := b.addNode(label, false)
+		b.edges = append(b.edges, graphEdge{from: parent, to: id})
+		b.walk(root, id, value, depth+1)
+		return
+	}
+	text := b.yamlsort.scalarString(value)
+	if len(text) > graphValueWidth {
+		text = text[:graphValueWidth-3] + "..."
+	}
+	id := b.addNode(label+": "+text, false)
+	b.edges = append(b.edges, graphEdge{from: parent, to: id})
+	if s, ok := value.(string); ok && strings.Contains(strings.ToLower(key), "name") {
+		b.candidates = append(b.candidates, graphRefCandidate{from: id, value: s, root: root})
+	}
+}
+
+// edges of references between documents
+func (b *graphBuilder) refEdges() []graphEdge {
+	result := []graphEdge{}
+	if !b.blnRefs {
+		return result
+	}
+	for _, candidate := range b.candidates {
+		if to, ok := b.names[candidate.value]; ok && to != candidate.root {
+			result = append(result, graphEdge{from: candidate.from, to: to})
+		}
+	}
+	return result
+}
+
+// write graph in graphviz dot language
+func (b *graphBuilder) writeDot(w io.Writer) {
+	fmt.Fprintln(w, "digraph yamlsort {")
+	fmt.Fprintln(w, "  rankdir=LR;")
+	fmt.Fprintln(w, "  node [shape=box, fontname=\"monospace\"];")
+	for _, node := range b.nodes {
+		style := ""
+		if node.blnRoot {
+			style = ", style=bold"
+		}
+		fmt.Fprintf(w, "  %s [label=%s%s];\n", node.id, dotQuote(node.label), style)
+	}
+	for _, edge := range b.edges {
+		fmt.Fprintf(w, "  %s -> %s;\n", edge.from, edge.to)
+	}
+	for _, edge := range b.refEdges() {
+		fmt.Fprintf(w, "  %s -> %s [style=dashed, label=\"ref\"];\n", edge.from, edge.to)
+	}
+	fmt.Fprintln(w, "}")
+}
+
+// write graph in mermaid flowchart
+func (b *graphBuilder) writeMermaid(w io.Writer) {
+	fmt.Fprintln(w, "graph LR")
+	for _, node := range b.nodes {
+		if node.blnRoot {
+			fmt.Fprintf(w, "  %s[[%s]]\n", node.id, mermaidQuote(node.label))
+		} else {
+			fmt.Fprintf(w, "  %s[%s]\n", node.id, mermaidQuote(node.label))
+		}
+	}
+	for _, edge := range b.edges {
+		fmt.Fprintf(w, "  %s --> %s\n", edge.from, edge.to)
+	}
+	for _, edge := range b.refEdges() {
+		fmt.Fprintf(w, "  %s -.->|ref| %s\n", edge.from, edge.to)
+	}
+}
+
+// quoted label of dot
+func dotQuote(text string) string {
+	return "\"" + strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "\n", "\\n").Replace(text) + "\""
+}
+
+// quoted label of mermaid. quotes are entity code.
+func mermaidQuote(text string) string {
+	return "\"" + strings.NewReplacer("\"", "#quot;", "\n", " ").Replace(text) + "\""
+}
@@ -0,0 +1,552 @@
+//
+// yamlsort - hcl decoder (experimental)
+//
+// decode hcl (terraform , packer , nomad config) text into map[string]interface{}.
+//   attributes (name = value) , blocks (type "label" { ... }) , comments (# , // , /* */) ,
+//   strings , heredocs (<<EOT , <<-EOT) , numbers , booleans , null , tuples [ ] and objects { }
+// labels of blocks are nested keys , and blocks with same type and labels are list.
+//   resource "aws_instance" "web" { ami = "x" }  ->  resource: {aws_instance: {web: {ami: x}}}
+// templates (${ } , %{ }) in strings are kept as is. other expressions (references , function
+// calls , operators , for) are strings of template , like "${var.region}". numbers keep their text.
+//
+package yamlsort
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	hclNumberRegexp    = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?([eE][+-]?[0-9]+)?`)
+	hclForRegexp       = regexp.MustCompile(`^\s*for\s`)
+	hclFirstLineRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_\-]*(\s+("[^"]*"|[A-Za-z_][A-Za-z0-9_\-]*))*\s*\{`)
+)
+
+//---------------------------------------------------------------------
+//  hclParser class
+//
+type hclParser struct {
+	text string
+	pos  int
+}
+
+// decode hcl text
+func (c *yamlsortCmd) decodeHCL(input []byte) (interface{}, error) {
+	p := &hclParser{text: strings.Replace(string(input), "\r\n", "\n", -1)}
+	p.text = strings.TrimPrefix(p.text, "\ufeff")
+	body, err := p.parseBody(false)
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// first line (without comments and blank lines) opens block , like `resource "a" "b" {`
+func sniffHCL(input []byte) bool {
+	for _, line := range strings.Split(string(input), "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+		return hclFirstLineRegexp.MatchString(line)
+	}
+	return false
+}
+
+func (p *hclParser) eof() bool {
+	return p.pos >= len(p.text)
+}
+
+func (p *hclParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.text[p.pos]
+}
+
+func (p *hclParser) rest() string {
+	return p.text[p.pos:]
+}
+
+// error with line number
+func (p *hclParser) errorf(format string, args ...interface{}) error {
+	line := strings.Count(p.text[:p.pos], "\n") + 1
+	return fmt.Errorf("hcl: line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+// skip spaces and tabs. newlines are skipped too when newline is true.
+// comments are skipped (// and # until line break , /* */).
+func (p *hclParser) skipSpaces(newline bool) {
+	for !p.eof() {
+		rest := p.rest()
+		switch {
+		case p.peek() == ' ' || p.peek() == '\t' || p.peek() == '\r':
+			p.pos++
+		case p.peek() == '\n' && newline:
+			p.pos++
+		case strings.HasPrefix(rest, "#") || strings.HasPrefix(rest, "//"):
+			if idx := strings.Index(rest, "\n"); idx >= 0 {
+				p.pos += idx
+			} else {
+				p.pos = len(p.text)
+			}
+		case strings.HasPrefix(rest, "/*"):
+			if idx := strings.Index(rest[2:], "*/"); idx >= 0 {
+				p.pos += idx + 4
+			} else {
+				p.pos = len(p.text)
+			}
+		default:
+			return
+		}
+	}
+}
+
+// identifier , like resource , aws_instance
+func (p *hclParser) parseIdentifier() string {
+	start := p.pos
+	for !p.eof() && isHCLIdentifierChar(p.peek(), p.pos == start) {
+		p.pos++
+	}
+	return p.text[start:p.pos]
+}
+
+func isHCLIdentifierChar(b byte, first bool) bool {
+	if b >= 'A' && b <= 'Z' || b >= 'a' && b <= 'z' || b == '_' || b >= 0x80 {
+		return true
+	}
+	return !first && (b >= '0' && b <= '9' || b == '-')
+}
+
+// attributes and blocks until } (block) or end of text
+func (p *hclParser) parseBody(block bool) (map[string]interface{}, error) {
+	body := map[string]interface{}{}
+	attributes := map[string]bool{}
+	for {
+		p.skipSpaces(true)
+		if p.eof() {
+			if block {
+				return nil, p.errorf("'}' is expected")
+			}
+			return body, nil
+		}
+		if p.peek() == '}' && block {
+			p.pos++
+			return body, nil
+		}
+		name := p.parseIdentifier()
+		if len(name) == 0 {
+			return nil, p.errorf("unexpected %q", p.peek())
+		}
+		p.skipSpaces(false)
+		if p.peek() == '=' && !strings.HasPrefix(p.rest(), "==") {
+			// attribute
+			p.pos++
+			if _, ok := body[name]; ok {
+				return nil, p.errorf("duplicate attribute %s", name)
+			}
+			value, err := p.parseExpression(false)
+			if err != nil {
+				return nil, err
+			}
+			body[name] = value
+			attributes[name] = true
+		} else {
+			if attributes[name] {
+				return nil, p.errorf("block %s conflicts with attribute", name)
+			}
+			err := p.parseBlock(body, name)
+			if err != nil {
+				return nil, err
+			}
+		}
+		// end of line , or } of one-line block
+		p.skipSpaces(false)
+		if !p.eof() && p.peek() != '\n' && p.peek() != '}' {
+			return nil, p.errorf("unexpected %q after %s", p.peek(), name)
+		}
+	}
+}
+
+// type "label" ... { body } into body of parent
+func (p *hclParser) parseBlock(parent map[string]interface{}, name string) error {
+	keys := []string{name}
+	for {
+		p.skipSpaces(false)
+		switch {
+		case p.peek() == '{':
+			p.pos++
+			body, err := p.parseBody(true)
+			if err != nil {
+				return err
+			}
+			return p.addBlock(parent, keys, body)
+		case p.peek() == '"':
+			label, err := p.parseString()
+			if err != nil {
+				return err
+			}
+			keys = append(keys, label)
+		default:
+			label := p.parseIdentifier()
+			if len(label) == 0 {
+				return p.errorf("'=' or '{' is expected after %s", name)
+			}
+			keys = append(keys, label)
+		}
+	}
+}
+
+// body of block at type and labels. blocks with same keys are list.
+func (p *hclParser) addBlock(parent map[string]interface{}, keys []string, body map[string]interface{}) error {
+	m := parent
+	for _, key := range keys[:len(keys)-1] {
+		child, ok := m[key]
+		if !ok {
+			child = map[string]interface{}{}
+			m[key] = child
+		}
+		m, ok = child.(map[string]interface{})
+		if !ok {
+			return p.errorf("block %s conflicts with %s", strings.Join(keys, " "), key)
+		}
+	}
+	last := keys[len(keys)-1]
+	switch existing := m[last].(type) {
+	case nil:
+		m[last] = body
+	case map[string]interface{}:
+		m[last] = []interface{}{existing, body}
+	case []interface{}:
+		m[last] = append(existing, body)
+	default:
+		return p.errorf("block %s conflicts with %s", strings.Join(keys, " "), last)
+	}
+	return nil
+}
+
+// value of attribute , or element of tuple and object (nested is true).
+// expressions which are not literal are template string of their text.
+func (p *hclParser) parseExpression(nested bool) (interface{}, error) {
+	p.skipSpaces(nested)
+	start := p.pos
+	value, err := p.parseLiteral()
+	if err == nil && p.endOfExpression() {
+		return value, nil
+	}
+	p.pos = start
+	text, rawErr := p.parseRawExpression()
+	if rawErr != nil {
+		if err != nil {
+			return nil, err
+		}
+		return nil, rawErr
+	}
+	return "${" + text + "}", nil
+}
+
+// expression ends with line break , , ] } ) or comment
+func (p *hclParser) endOfExpression() bool {
+	p.skipSpaces(false)
+	return p.eof() || strings.ContainsRune("\n,]})#", rune(p.peek())) || strings.HasPrefix(p.rest(), "//") || strings.HasPrefix(p.rest(), "/*")
+}
+
+// string , heredoc , number , true , false , null , tuple or object
+func (p *hclParser) parseLiteral() (interface{}, error) {
+	rest := p.rest()
+	switch {
+	case strings.HasPrefix(rest, "<<"):
+		return p.parseHeredoc()
+	case p.peek() == '"':
+		return p.parseString()
+	case p.peek() == '[':
+		return p.parseTuple()
+	case p.peek() == '{':
+		return p.parseObject()
+	}
+	if number := hclNumberRegexp.FindString(rest); len(number) > 0 {
+		p.pos += len(number)
+		if _, err := strconv.ParseFloat(number, 64); err != nil {
+			return nil, p.errorf("invalid number %s", number)
+		}
+		return json.Number(number), nil
+	}
+	word := p.parseIdentifier()
+	switch word {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	return nil, p.errorf("literal is expected")
+}
+
+// [ value , ... ]. for expression is not literal.
+func (p *hclParser) parseTuple() (interface{}, error) {
+	p.pos++
+	if hclForRegexp.MatchString(p.rest()) {
+		return nil, p.errorf("for is not literal")
+	}
+	result := []interface{}{}
+	for {
+		p.skipSpaces(true)
+		if p.peek() == ']' {
+			p.pos++
+			return result, nil
+		}
+		if p.eof() {
+			return nil, p.errorf("']' is expected")
+		}
+		value, err := p.parseExpression(true)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, value)
+		p.skipSpaces(true)
+		if p.peek() == ',' {
+			p.pos++
+		} else if p.peek() != ']' {
+			return nil, p.errorf("',' or ']' is expected in tuple")
+		}
+	}
+}
+
+// { key = value , ... }. keys are identifiers or strings , and : can be used instead of =.
+func (p *hclParser) parseObject() (interface{}, error) {
+	p.pos++
+	if hclForRegexp.MatchString(p.rest()) {
+		return nil, p.errorf("for is not literal")
+	}
+	result := map[string]interface{}{}
+	for {
+		p.skipSpaces(true)
+		if p.peek() == '}' {
+			p.pos++
+			return result, nil
+		}
+		if p.eof() {
+			return nil, p.errorf("'}' is expected")
+		}
+		var key string
+		if p.peek() == '"' {
+			var err error
+			key, err = p.parseString()
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			key = p.parseIdentifier()
+			if len(key) == 0 {
+				return nil, p.errorf("key is expected in object")
+			}
+		}
+		p.skipSpaces(false)
+		if p.peek() != '=' && p.peek() != ':' {
+			return nil, p.errorf("'=' is expected after key %s", key)
+		}
+		p.pos++
+		if _, ok := result[key]; ok {
+			return nil, p.errorf("duplicate key %s", key)
+		}
+		value, err := p.parseExpression(true)
+		if err != nil {
+			return nil, err
+		}
+		result[key] = value
+		p.skipSpaces(false)
+		if p.peek() == ',' {
+			p.pos++
+		} else if p.peek() != '\n' && p.peek() != '}' {
+			return nil, p.errorf("',' or '}' is expected in object")
+		}
+	}
+}
+
+// "..." with escapes. templates are kept as is.
+func (p *hclParser) parseString() (string, error) {
+	p.pos++
+	buf := new(strings.Builder)
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", p.errorf("string is not closed")
+		}
+		rest := p.rest()
+		switch {
+		case p.peek() == '"':
+			p.pos++
+			return buf.String(), nil
+		case strings.HasPrefix(rest, "$${") || strings.HasPrefix(rest, "%%{"):
+			buf.WriteString(rest[:3])
+			p.pos += 3
+		case strings.HasPrefix(rest, "${") || strings.HasPrefix(rest, "%{"):
+			text, err := p.parseTemplate()
+			if err != nil {
+				return "", err
+			}
+			buf.WriteString(text)
+		case p.peek() == '\\':
+			err := p.parseEscape(buf)
+			if err != nil {
+				return "", err
+			}
+		default:
+			buf.WriteByte(p.peek())
+			p.pos++
+		}
+	}
+}
+
+// ${ ... } or %{ ... } with nested braces and strings
+func (p *hclParser) parseTemplate() (string, error) {
+	start := p.pos
+	p.pos += 2
+	depth := 1
+	for depth > 0 {
+		if p.eof() {
+			return "", p.errorf("template is not closed")
+		}
+		switch p.peek() {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case '"':
+			if _, err := p.parseString(); err != nil {
+				return "", err
+			}
+			continue
+		}
+		p.pos++
+	}
+	return p.text[start:p.pos], nil
+}
+
+// \n , \" , \uXXXX ...
+func (p *hclParser) parseEscape(buf *strings.Builder) error {
+	p.pos++
+	if p.eof() {
+		return p.errorf("invalid escape")
+	}
+	b := p.peek()
+	p.pos++
+	switch b {
+	case 'n':
+		buf.WriteByte('\n')
+	case 'r':
+		buf.WriteByte('\r')
+	case 't':
+		buf.WriteByte('\t')
+	case '"', '\\':
+		buf.WriteByte(b)
+	case 'u', 'U':
+		n := 4
+		if b == 'U' {
+			n = 8
+		}
+		if p.pos+n > len(p.text) {
+			return p.errorf("invalid escape \\%c", b)
+		}
+		code, err := strconv.ParseUint(p.text[p.pos:p.pos+n], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return p.errorf("invalid escape \\%c%s", b, p.text[p.pos:p.pos+n])
+		}
+		buf.WriteRune(rune(code))
+		p.pos += n
+	default:
+		return p.errorf("invalid escape \\%c", b)
+	}
+	return nil
+}
+
+// <<EOT ... EOT , or <<-EOT which removes common indent of lines
+func (p *hclParser) parseHeredoc() (string, error) {
+	p.pos += 2
+	strip := false
+	if p.peek() == '-' {
+		strip = true
+		p.pos++
+	}
+	marker := p.parseIdentifier()
+	if len(marker) == 0 || p.peek() != '\n' {
+		return "", p.errorf("heredoc marker is expected")
+	}
+	p.pos++
+	lines := []string{}
+	for {
+		if p.eof() {
+			return "", p.errorf("heredoc %s is not closed", marker)
+		}
+		line := p.rest()
+		if idx := strings.Index(line, "\n"); idx >= 0 {
+			line = line[:idx]
+		}
+		p.pos += len(line)
+		if strings.TrimSpace(line) == marker {
+			break
+		}
+		lines = append(lines, line)
+		p.pos++
+	}
+	if strip {
+		indent := -1
+		for _, line := range lines {
+			if len(strings.TrimSpace(line)) == 0 {
+				continue
+			}
+			n := len(line) - len(strings.TrimLeft(line, " \t"))
+			if indent < 0 || n < indent {
+				indent = n
+			}
+		}
+		for i, line := range lines {
+			if len(line) >= indent && indent > 0 {
+				lines[i] = line[indent:]
+			} else {
+				lines[i] = strings.TrimLeft(line, " \t")
+			}
+		}
+	}
+	if len(lines) == 0 {
+		return "", nil
+	}
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// text of expression until end of expression (outside of brackets and strings)
+func (p *hclParser) parseRawExpression() (string, error) {
+	start := p.pos
+	depth := 0
+	for !p.eof() {
+		b := p.peek()
+		rest := p.rest()
+		if depth == 0 && (strings.ContainsRune("\n,]})#", rune(b)) || strings.HasPrefix(rest, "//") || strings.HasPrefix(rest, "/*")) {
+			break
+		}
+		switch b {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case '"':
+			if _, err := p.parseString(); err != nil {
+				return "", err
+			}
+			continue
+		}
+		p.pos++
+	}
+	if depth != 0 {
+		return "", p.errorf("brackets are not closed")
+	}
+	text := strings.TrimSpace(p.text[start:p.pos])
+	if len(text) == 0 {
+		return "", p.errorf("expression is expected")
+	}
+	return text, nil
+}
@@ -0,0 +1,313 @@
+//
+// yamlsort - --output-format hcl (experimental)
+//
+// write sorted yaml as hcl attributes and blocks , to move config between yaml and hcl tools.
+//   yamlsort --output-format hcl -i config.yaml -o config.hcl
+//     name     = "web"
+//     replicas = 3
+//
+//     server {
+//       port = 8080
+//     }
+// maps with identifier keys are blocks , and lists of them are repeated blocks. maps with other
+// keys (like labels) are object attributes. attributes come before blocks , and both are sorted
+// same as yaml. blocks are written without labels. multi-line strings are heredoc (<<EOT). ${ } in
+// strings is template of hcl , so it is written as is. each document must be map , and documents
+// are separated by blank line. comments are not kept.
+//
+package yamlsort
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// identifier of hcl , which can be attribute name and block type
+var hclIdentifierRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_\-]*$`)
+
+// hcl text of sorted yaml text
+func (c *yamlsortCmd) renderHCL(input []byte) ([]byte, error) {
+	docs, err := splitDocuments(input, defaultMaxLineSize)
+	if err != nil {
+		return nil, err
+	}
+	buf := new(bytes.Buffer)
+	for _, doc := range docs {
+		if doc.isEmpty() {
+			continue
+		}
+		data, err := c.unmarshalYAML(doc.parseData())
+		if err != nil {
+			return nil, err
+		}
+		body, ok := data.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("hcl: document must be map , not %s", hclTypeName(data))
+		}
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		err = c.writeHCLBody(buf, body, 0, "")
+		if err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// map , or list of maps with identifier keys
+func isHCLBlock(value interface{}) bool {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k := range v {
+			if !hclIdentifierRegexp.MatchString(k) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		if len(v) == 0 {
+			return false
+		}
+		for _, child := range v {
+			if _, ok := child.(map[string]interface{}); !ok || !isHCLBlock(child) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// attributes and blocks of map
+func (c *yamlsortCmd) writeHCLBody(buf *bytes.Buffer, body map[string]interface{}, indent int, path string) error {
+	attributes := []string{}
+	blocks := []string{}
+	for k, v := range body {
+		if !hclIdentifierRegexp.MatchString(k) {
+			return fmt.Errorf("hcl: key %q at %s is not identifier", k, hclPath(path))
+		}
+		if isHCLBlock(v) {
+			blocks = append(blocks, k)
+		} else {
+			attributes = append(attributes, k)
+		}
+	}
+	sortKeys(attributes)
+	sortKeys(blocks)
+	prefix := strings.Repeat(" ", indent)
+
+	// consecutive one-line attributes are aligned by =
+	texts := make([]string, len(attributes))
+	for i, k := range attributes {
+		text, err := c.hclValue(body[k], indent, path+"."+k, true)
+		if err != nil {
+			return err
+		}
+		texts[i] = text
+	}
+	for i := 0; i < len(attributes); {
+		end := i + 1
+		width := len(attributes[i])
+		if !strings.Contains(texts[i], "\n") {
+			for end < len(attributes) && !strings.Contains(texts[end], "\n") {
+				if len(attributes[end]) > width {
+					width = len(attributes[end])
+				}
+				end++
+			}
+		}
+		for ; i < end; i++ {
+			fmt.Fprintf(buf, "%s%-*s = %s\n", prefix, width, attributes[i], texts[i])
+		}
+	}
+
+	for i, k := range blocks {
+		if i > 0 || len(attributes) > 0 {
+			buf.WriteString("\n")
+		}
+		children, ok := body[k].([]interface{})
+		if !ok {
+			children = []interface{}{body[k]}
+		}
+		for j, child := range children {
+			if j > 0 {
+				buf.WriteString("\n")
+			}
+			m := child.(map[string]interface{})
+			if len(m) == 0 {
+				fmt.Fprintf(buf, "%s%s {}\n", prefix, k)
+				continue
+			}
+			fmt.Fprintf(buf, "%s%s {\n", prefix, k)
+			err := c.writeHCLBody(buf, m, indent+2, path+"."+k)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(buf, "%s}\n", prefix)
+		}
+	}
+	return nil
+}
+
+// expression of value. heredoc is used only for value of attribute.
+func (c *yamlsortCmd) hclValue(value interface{}, indent int, path string, heredoc bool) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "null", nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		if _, ok := specialFloatText(v); ok {
+			return "", fmt.Errorf("hcl: %v at %s can not be hcl number", v, hclPath(path))
+		}
+		if v == math.Trunc(v) && math.Abs(v) < 1e21 {
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		}
+		return c.formatFloat("", v), nil
+	case string:
+		if heredoc && strings.HasSuffix(v, "\n") && strings.Contains(strings.TrimSuffix(v, "\n"), "\n") {
+			return hclHeredoc(v, indent), nil
+		}
+		buf := new(bytes.Buffer)
+		writeJSONString(buf, v)
+		return buf.String(), nil
+	case []interface{}:
+		return c.hclTuple(v, indent, path)
+	case map[string]interface{}:
+		return c.hclObject(v, indent, path)
+	}
+	return fmt.Sprintf("%q", fmt.Sprint(value)), nil
+}
+
+// [ value , ... ] in one line when all values are scalars
+func (c *yamlsortCmd) hclTuple(list []interface{}, indent int, path string) (string, error) {
+	if len(list) == 0 {
+		return "[]", nil
+	}
+	texts := []string{}
+	oneline := true
+	for i, child := range list {
+		text, err := c.hclValue(child, indent+2, fmt.Sprintf("%s[%d]", path, i), false)
+		if err != nil {
+			return "", err
+		}
+		switch child.(type) {
+		case []interface{}, map[string]interface{}:
+			oneline = false
+		}
+		texts = append(texts, text)
+	}
+	if oneline {
+		return "[" + strings.Join(texts, ", ") + "]", nil
+	}
+	prefix := strings.Repeat(" ", indent+2)
+	return "[\n" + prefix + strings.Join(texts, ",\n"+prefix) + ",\n" + strings.Repeat(" ", indent) + "]", nil
+}
+
+// { key = value ... }. keys which are not identifier are quoted.
+func (c *yamlsortCmd) hclObject(m map[string]interface{}, indent int, path string) (string, error) {
+	if len(m) == 0 {
+		return "{}", nil
+	}
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sortKeys(keys)
+	names := make([]string, len(keys))
+	width := 0
+	for i, k := range keys {
+		names[i] = k
+		if !hclIdentifierRegexp.MatchString(k) {
+			buf := new(bytes.Buffer)
+			writeJSONString(buf, k)
+			names[i] = buf.String()
+		}
+		if len(names[i]) > width {
+			width = len(names[i])
+		}
+	}
+	prefix := strings.Repeat(" ", indent+2)
+	buf := new(bytes.Buffer)
+	buf.WriteString("{\n")
+	for i, k := range keys {
+		text, err := c.hclValue(m[k], indent+2, path+"."+k, false)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(buf, "%s%-*s = %s\n", prefix, width, names[i], text)
+	}
+	buf.WriteString(strings.Repeat(" ", indent) + "}")
+	return buf.String(), nil
+}
+
+// <<-EOT heredoc with indent (<<EOT when all lines are indented). marker is changed when text has EOT line.
+func hclHeredoc(text string, indent int) string {
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	marker := "EOT"
+	for n := 1; ; n++ {
+		used := false
+		for _, line := range lines {
+			if strings.TrimSpace(line) == marker {
+				used = true
+				break
+			}
+		}
+		if !used {
+			break
+		}
+		marker = fmt.Sprintf("EOT%d", n)
+	}
+	prefix := strings.Repeat(" ", indent+2)
+	buf := new(strings.Builder)
+	if hclIndented(lines) {
+		// <<- would remove indent of text
+		buf.WriteString("<<" + marker + "\n" + strings.Join(lines, "\n") + "\n" + marker)
+		return buf.String()
+	}
+	buf.WriteString("<<-" + marker + "\n")
+	for _, line := range lines {
+		if len(line) > 0 {
+			buf.WriteString(prefix)
+		}
+		buf.WriteString(line + "\n")
+	}
+	buf.WriteString(strings.Repeat(" ", indent) + marker)
+	return buf.String()
+}
+
+// all lines (except blank lines) start with space or tab
+func hclIndented(lines []string) bool {
+	for _, line := range lines {
+		if len(line) > 0 && line[0] != ' ' && line[0] != '\t' {
+			return false
+		}
+	}
+	return true
+}
+
+// path in error messages
+func hclPath(path string) string {
+	if len(path) == 0 {
+		return "top"
+	}
+	return strings.TrimPrefix(path, ".")
+}
+
+// type name of value in error messages
+func hclTypeName(value interface{}) string {
+	switch value.(type) {
+	case []interface{}:
+		return "list"
+	case string:
+		return "string"
+	case nil:
+		return "null"
+	}
+	return "scalar"
+}
@@ -0,0 +1,266 @@
+//
+// yamlsort - helm-values subcommand
+//
+// sort values.yaml of helm chart in property order of values.schema.json ,
+// and warn about values which are not covered by the schema.
+//   yamlsort helm-values mychart/        output sorted values.yaml
+//   yamlsort helm-values -w mychart/     write values.yaml in place
+// keys in schema "properties" come first in schema order , and other keys follow in normal order.
+// local $ref (#/definitions/... , #/$defs/...) is followed.
+//
+package yamlsort
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+)
+
+// json value , which keeps key order of objects
+type jsonNode struct {
+	keys   []string
+	fields map[string]*jsonNode
+	items  []*jsonNode
+	value  interface{}
+}
+
+// parse json text , keeping key order
+func parseOrderedJSON(data []byte) (*jsonNode, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	node, err := readJSONNode(decoder)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("extra data after json value")
+	}
+	return node, nil
+}
+
+func readJSONNode(decoder *json.Decoder) (*jsonNode, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	node := &jsonNode{}
+	switch token {
+	case json.Delim('{'):
+		node.fields = map[string]*jsonNode{}
+		for decoder.More() {
+			keytoken, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			key := fmt.Sprint(keytoken)
+			child, err := readJSONNode(decoder)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := node.fields[key]; !ok {
+				node.keys = append(node.keys, key)
+			}
+			node.fields[key] = child
+		}
+		_, err = decoder.Token()
+	case json.Delim('['):
+		node.items = []*jsonNode{}
+		for decoder.More() {
+			child, err := readJSONNode(decoder)
+			if err != nil {
+				return nil, err
+			}
+			node.items = append(node.items, child)
+		}
+		_, err = decoder.Token()
+	default:
+		node.value = token
+	}
+	return node, err
+}
+
+//---------------------------------------------------------------------
+//  helmSchema class
+// walk values data with json schema , and compute key order and uncovered values
+//
+type helmSchema struct {
+	root      *jsonNode
+	keyorders map[string][]string
+	uncovered []string
+}
+
+// follow local $ref of schema node
+func (h *helmSchema) resolve(node *jsonNode) *jsonNode {
+	for i := 0; node != nil && node.fields != nil && i < 32; i++ {
+		ref, ok := node.fields["$ref"]
+		if !ok {
+			return node
+		}
+		refstr, _ := ref.value.(string)
+		if !strings.HasPrefix(refstr, "#") {
+			// remote reference is not followed
+			return nil
+		}
+		node = h.root
+		for _, name := range strings.Split(strings.TrimPrefix(refstr, "#"), "/") {
+			if len(name) == 0 {
+				continue
+			}
+			name = strings.Replace(strings.Replace(name, "~1", "/", -1), "~0", "~", -1)
+			if node == nil || node.fields == nil {
+				return nil
+			}
+			node = node.fields[name]
+		}
+	}
+	return node
+}
+
+// walk data at path with schema node
+func (h *helmSchema) walk(c *yamlsortCmd, schema *jsonNode, path string, data interface{}) {
+	schema = h.resolve(schema)
+	if schema == nil || schema.fields == nil {
+		// free form value
+		return
+	}
+	switch v := data.(type) {
+	case map[string]interface{}:
+		properties := h.resolve(schema.fields["properties"])
+		if properties == nil || properties.fields == nil {
+			// object without properties , like annotations
+			additional := schema.fields["additionalProperties"]
+			for k, child := range v {
+				h.walk(c, additional, c.calcPathMap(path, k), child)
+			}
+			return
+		}
+		h.keyorders[path] = properties.keys
+		additional := schema.fields["additionalProperties"]
+		blnAdditional := additional != nil && (additional.fields != nil || additional.value == true)
+		for k, child := range v {
+			childpath := c.calcPathMap(path, k)
+			if property, ok := properties.fields[k]; ok {
+				h.walk(c, property, childpath, child)
+			} else if blnAdditional {
+				h.walk(c, additional, childpath, child)
+			} else {
+				h.uncovered = append(h.uncovered, childpath)
+			}
+		}
+	case []interface{}:
+		items := schema.fields["items"]
+		for i, child := range v {
+			h.walk(c, items, c.calcPathSliceElement(path, i, child), child)
+		}
+	}
+}
+
+// order keys at path. keys of schema come first in schema order.
+func (c *yamlsortCmd) orderKeys(path string, keylist []string) {
+	order, ok := c.keyorders[path]
+	if !ok {
+		return
+	}
+	exists := map[string]bool{}
+	for _, k := range keylist {
+		exists[k] = true
+	}
+	result := make([]string, 0, len(keylist))
+	inschema := map[string]bool{}
+	for _, k := range order {
+		if exists[k] && !inschema[k] {
+			result = append(result, k)
+			inschema[k] = true
+		}
+	}
+	for _, k := range keylist {
+		if !inschema[k] {
+			result = append(result, k)
+		}
+	}
+	copy(keylist, result)
+}
+
+//---------------------------------------------------------------------
+//  helm-values subcommand
+//
+func newHelmValuesCmd(yamlsort *yamlsortCmd) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "helm-values chart-dir",
+		Short: "sort values.yaml of helm chart in property order of values.schema.json",
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("helm-values requires chart directory")
+			}
+			return yamlsort.helmValues(args[0])
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVarP(&yamlsort.blnWrite, "write", "w", false, "write values.yaml in place , instead of stdout")
+
+	return cmd
+}
+
+func (c *yamlsortCmd) helmValues(dir string) error {
+	valuesfilename := filepath.Join(dir, "values.yaml")
+	if _, err := os.Stat(valuesfilename); err != nil {
+		valuesfilename = filepath.Join(dir, "values.yml")
+	}
+	snapshot := snapshotFile(valuesfilename)
+	input, err := ioutil.ReadFile(valuesfilename)
+	if err != nil {
+		return fmt.Errorf("values.yaml is not found in %s", dir)
+	}
+	c.maxlinesize = defaultMaxLineSize
+	err = c.prepareOptions()
+	if err != nil {
+		return err
+	}
+
+	schemafilename := filepath.Join(dir, "values.schema.json")
+	schematext, err := ioutil.ReadFile(schemafilename)
+	if err == nil {
+		root, err := parseOrderedJSON(schematext)
+		if err != nil {
+			return fmt.Errorf("%s: %v", schemafilename, err)
+		}
+		var data interface{}
+		err = yaml.Unmarshal(input, &data)
+		if err != nil {
+			return fmt.Errorf("%s: %v", valuesfilename, err)
+		}
+		schema := &helmSchema{root: root, keyorders: map[string][]string{}}
+		schema.walk(c, root, "", data)
+		c.keyorders = schema.keyorders
+		sort.Strings(schema.uncovered)
+		for _, path := range schema.uncovered {
+			fmt.Fprintf(c.stderr, "warning: %s: %s is not covered by values.schema.json\n", valuesfilename, path)
+		}
+	} else {
+		fmt.Fprintf(c.stderr, "warning: %s is not found , values.yaml is sorted in normal order\n", schemafilename)
+	}
+
+	c.inputfilename = valuesfilename
+	output, err := c.sortBytes(input, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %v", valuesfilename, err)
+	}
+	if !c.blnWrite {
+		_, err = c.stdout.Write(output.Bytes())
+		return err
+	}
+	if output.String() == string(input) {
+		return nil
+	}
+	return c.writeInPlace(valuesfilename, input, output.Bytes(), snapshot)
+}
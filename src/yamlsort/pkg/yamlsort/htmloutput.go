@@ -0,0 +1,202 @@
+//
+// yamlsort - --output-format html
+//
+// write sorted yaml as standalone html page , with syntax highlight. maps and lists are
+// collapsible (<details>) , and each document can be folded. no script and no external files ,
+// so the page can be attached to tickets and mails.
+//   yamlsort --output-format html -i deploy.yaml -o deploy.html
+//   yamlsort --output-format html --output-template 'html/{{.Name}}.html' manifests/
+//
+package yamlsort
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// plain scalars which are not string
+var (
+	htmlNumberRegexp  = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9][0-9_]*(\.[0-9_]*)?)([eE][-+]?[0-9]+)?$|^0x[0-9a-fA-F]+$|^0o[0-7]+$|^[-+]?\.(inf|Inf|INF)$|^\.(nan|NaN|NAN)$`)
+	htmlLiteralRegexp = regexp.MustCompile(`^(true|True|TRUE|false|False|FALSE|null|Null|NULL|~)$`)
+)
+
+// style of html page
+const htmlStyle = `body { font-family: sans-serif; margin: 1em 2em; background: #fafafa; color: #24292e; }
+h1 { font-size: 1.2em; }
+.yaml { font-family: monospace; font-size: 13px; background: #fff; border: 1px solid #ddd; padding: 8px; }
+.yaml div, .yaml summary { white-space: pre; min-height: 1.2em; }
+.yaml details > summary { list-style: none; cursor: pointer; }
+.yaml details > summary::-webkit-details-marker { display: none; }
+.yaml details > summary::after { content: " \25BE"; color: #aaa; }
+.yaml details:not([open]) > summary::after { content: " \25B8 \2026"; }
+.k { color: #005cc5; } .s { color: #032f62; } .n { color: #d73a49; } .l { color: #6f42c1; }
+.c { color: #6a737d; font-style: italic; } .a { color: #e36209; } .t { color: #22863a; }
+.d { color: #999; font-weight: bold; } .p { color: #999; }
+`
+
+// standalone html page of sorted yaml text
+func renderHTML(title string, text []byte) []byte {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n<style>\n%s</style>\n</head>\n<body>\n", html.EscapeString(title), htmlStyle)
+	fmt.Fprintf(buf, "<h1>%s</h1>\n<div class=\"yaml\">\n", html.EscapeString(title))
+
+	// open nodes (details)
+	type openNode struct {
+		indent      int
+		blnList     bool // key whose list items have same indent
+		blnDocument bool
+	}
+	opens := []openNode{}
+	closeNode := func() {
+		buf.WriteString("</details>\n")
+		opens = opens[:len(opens)-1]
+	}
+	lines := strings.Split(strings.TrimSuffix(string(text), "\n"), "\n")
+	blockindent := -1 // indent of line which has block scalar
+	for i, line := range lines {
+		body := strings.TrimLeft(line, " ")
+		indent := len(line) - len(body)
+		if len(body) == 0 {
+			buf.WriteString("<div></div>\n")
+			continue
+		}
+		if blockindent >= 0 {
+			if indent > blockindent {
+				fmt.Fprintf(buf, "<div>%s</div>\n", htmlSpan("s", line))
+				continue
+			}
+			blockindent = -1
+		}
+		if indent == 0 && (body == "---" || strings.HasPrefix(body, "--- ")) {
+			for len(opens) > 0 {
+				closeNode()
+			}
+			fmt.Fprintf(buf, "<details open><summary>%s</summary>\n", htmlSpan("d", line))
+			opens = append(opens, openNode{indent: -1, blnDocument: true})
+			continue
+		}
+		blnItem := body == "-" || strings.HasPrefix(body, "- ")
+		for len(opens) > 0 {
+			o := opens[len(opens)-1]
+			if o.blnDocument || indent > o.indent || (indent == o.indent && o.blnList && blnItem) {
+				break
+			}
+			closeNode()
+		}
+		highlighted, value := highlightYAMLLine(line)
+		if isBlockScalarValue(value) {
+			blockindent = indent
+		}
+		// next line is child of this line
+		next := nextYAMLLine(lines, i+1)
+		nextbody := strings.TrimLeft(next, " ")
+		nextindent := len(next) - len(nextbody)
+		_, _, _, blnKey := parseKeyLine(line)
+		blnListChild := blnKey && len(value) == 0 && nextindent == indent && (nextbody == "-" || strings.HasPrefix(nextbody, "- "))
+		if len(nextbody) > 0 && body[0] != '#' && (nextindent > indent || blnListChild) {
+			fmt.Fprintf(buf, "<details open><summary>%s</summary>\n", highlighted)
+			opens = append(opens, openNode{indent: indent, blnList: blnListChild})
+			continue
+		}
+		fmt.Fprintf(buf, "<div>%s</div>\n", highlighted)
+	}
+	for len(opens) > 0 {
+		closeNode()
+	}
+	buf.WriteString("</div>\n</body>\n</html>\n")
+	return buf.Bytes()
+}
+
+// next line which is not blank or comment
+func nextYAMLLine(lines []string, start int) string {
+	for _, line := range lines[start:] {
+		body := strings.TrimSpace(line)
+		if len(body) > 0 && body[0] != '#' {
+			return line
+		}
+	}
+	return ""
+}
+
+// highlighted html of one line , and value text after key or "- "
+func highlightYAMLLine(line string) (string, string) {
+	body := strings.TrimLeft(line, " ")
+	result := line[:len(line)-len(body)]
+	if strings.HasPrefix(body, "#") {
+		return result + htmlSpan("c", body), ""
+	}
+	comment := ""
+	if i := commentIndex(body); i >= 0 {
+		comment = body[i:]
+		body = body[:i]
+	}
+	trailing := body[len(strings.TrimRight(body, " ")):]
+	body = strings.TrimRight(body, " ")
+	// list item markers
+	for body == "-" || strings.HasPrefix(body, "- ") {
+		rest := strings.TrimLeft(body[1:], " ")
+		result += htmlSpan("p", "-") + body[1:len(body)-len(rest)]
+		body = rest
+	}
+	value := body
+	if _, _, v, ok := parseKeyLine(body); ok {
+		value = v
+		keypart := body[:len(body)-len(value)]
+		colon := strings.LastIndex(keypart, ":")
+		result += htmlSpan("k", keypart[:colon]) + htmlSpan("p", ":") + keypart[colon+1:]
+	}
+	result += highlightYAMLValue(value) + trailing
+	if len(comment) > 0 {
+		result += htmlSpan("c", comment)
+	}
+	return result, value
+}
+
+// highlighted html of scalar or flow value
+func highlightYAMLValue(value string) string {
+	if len(value) == 0 {
+		return ""
+	}
+	class := "s"
+	switch {
+	case value[0] == '&' || value[0] == '*':
+		class = "a"
+	case value[0] == '!':
+		class = "t"
+	case value[0] == '|' || value[0] == '>' || value[0] == '{' || value[0] == '[':
+		class = "p"
+	case htmlNumberRegexp.MatchString(value):
+		class = "n"
+	case htmlLiteralRegexp.MatchString(value):
+		class = "l"
+	}
+	return htmlSpan(class, value)
+}
+
+// index of comment (#) in line , which is not in quoted text. -1 when no comment.
+func commentIndex(line string) int {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		ch := line[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			} else if ch == '\\' && quote == '"' {
+				i++
+			}
+		case (ch == '"' || ch == '\'') && (i == 0 || strings.IndexByte(" \t[{,:", line[i-1]) >= 0):
+			quote = ch
+		case ch == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return i
+		}
+	}
+	return -1
+}
+
+func htmlSpan(class string, text string) string {
+	return "<span class=\"" + class + "\">" + html.EscapeString(text) + "</span>"
+}
@@ -0,0 +1,180 @@
+//
+// yamlsort - ignore files in directory traversal
+//
+// patterns of .gitignore and .yamlsortignore are used (same syntax as .gitignore).
+//   vendor/           directory at any depth
+//   /generated        relative to the directory of ignore file
+//   charts/**/*.yaml  ** matches any directories
+//   !keep.yaml        negation
+// .gitignore files of parent directories up to the git repository root are read too.
+//
+package yamlsort
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// names of ignore files
+var ignoreFilenames = []string{".gitignore", ".yamlsortignore"}
+
+type ignoreRule struct {
+	base    string // absolute directory of ignore file
+	re      *regexp.Regexp
+	negate  bool
+	dironly bool
+}
+
+//---------------------------------------------------------------------
+//  ignoreMatcher class
+//
+type ignoreMatcher struct {
+	rules []ignoreRule
+}
+
+// matcher with ignore files of dir and its parents in git repository
+func newIgnoreMatcher(dir string) *ignoreMatcher {
+	m := &ignoreMatcher{}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return m
+	}
+	dirs := []string{}
+	for d := filepath.Dir(abs); ; d = filepath.Dir(d) {
+		dirs = append([]string{d}, dirs...)
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			break
+		}
+		if d == filepath.Dir(d) {
+			// not in git repository
+			dirs = []string{}
+			break
+		}
+	}
+	if _, err := os.Stat(filepath.Join(abs, ".git")); err == nil {
+		dirs = []string{}
+	}
+	for _, d := range dirs {
+		m.load(d)
+	}
+	return m
+}
+
+// read ignore files in dir
+func (m *ignoreMatcher) load(dir string) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return
+	}
+	for _, name := range ignoreFilenames {
+		fp, err := os.Open(filepath.Join(abs, name))
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(fp)
+		for scanner.Scan() {
+			if rule, ok := parseIgnorePattern(abs, scanner.Text()); ok {
+				m.rules = append(m.rules, rule)
+			}
+		}
+		fp.Close()
+	}
+}
+
+// path is ignored. last matched rule wins.
+func (m *ignoreMatcher) ignored(path string, isdir bool) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	result := false
+	for _, rule := range m.rules {
+		if rule.dironly && !isdir {
+			continue
+		}
+		rel, err := filepath.Rel(rule.base, abs)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		if rule.re.MatchString(filepath.ToSlash(rel)) {
+			result = !rule.negate
+		}
+	}
+	return result
+}
+
+// one line of ignore file
+func parseIgnorePattern(base string, line string) (ignoreRule, bool) {
+	rule := ignoreRule{base: base}
+	line = strings.TrimRight(line, " \t\r")
+	if len(line) == 0 || strings.HasPrefix(line, "#") {
+		return rule, false
+	}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dironly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	// pattern with "/" is relative to base directory
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	if len(line) == 0 {
+		return rule, false
+	}
+	expr := globToRegexp(line)
+	if anchored {
+		expr = "^" + expr + "$"
+	} else {
+		expr = "^(.*/)?" + expr + "$"
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return rule, false
+	}
+	rule.re = re
+	return rule, true
+}
+
+// regexp of glob pattern. * and ? do not match "/". ** matches directories.
+func globToRegexp(pattern string) string {
+	result := new(strings.Builder)
+	for i := 0; i < len(pattern); i++ {
+		ch := pattern[i]
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			result.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			result.WriteString(".*")
+			i++
+		case ch == '*':
+			result.WriteString("[^/]*")
+		case ch == '?':
+			result.WriteString("[^/]")
+		case ch == '[':
+			end := strings.IndexByte(pattern[i:], ']')
+			if end < 0 {
+				result.WriteString(regexp.QuoteMeta(string(ch)))
+				continue
+			}
+			class := pattern[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			result.WriteString("[" + class + "]")
+			i += end
+		case ch == '\\' && i+1 < len(pattern):
+			i++
+			result.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		default:
+			result.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	return result.String()
+}
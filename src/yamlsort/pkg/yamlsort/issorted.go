@@ -0,0 +1,130 @@
+//
+// yamlsort - is-sorted subcommand
+//
+// check key order only (formatting is not checked) , and report first out-of-order key path.
+// exit 0 when all files are sorted , and exit 1 otherwise.
+//   yamlsort is-sorted --key name values.yaml
+//
+package yamlsort
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/spf13/cobra"
+	yamlv2 "gopkg.in/yaml.v2"
+)
+
+//---------------------------------------------------------------------
+//  is-sorted subcommand
+//
+func newIsSortedCmd(yamlsort *yamlsortCmd) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "is-sorted file...",
+		Short: "check key order only , and report first out-of-order key path",
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("is-sorted requires input file names")
+			}
+			yamlsort.maxlinesize = defaultMaxLineSize
+			globalpriorkeys = yamlsort.priorkeys
+			if len(globalpriorkeys) == 0 {
+				globalpriorkeys = []string{"name"}
+			}
+			filenames, err := yamlsort.collectFiles(args)
+			if err != nil {
+				return err
+			}
+			unsorted := 0
+			for _, filename := range filenames {
+				message, err := yamlsort.checkSorted(filename)
+				if err != nil {
+					return fmt.Errorf("%s: %v", filename, err)
+				}
+				if len(message) > 0 {
+					fmt.Fprintf(yamlsort.stdout, "%s: %s\n", filename, message)
+					unsorted++
+				}
+			}
+			if unsorted > 0 {
+				// reported above
+				c.SilenceErrors = true
+				return fmt.Errorf("%d files are not sorted", unsorted)
+			}
+			return nil
+		},
+	}
+	cmd.SilenceUsage = true
+
+	f := cmd.Flags()
+	f.StringArrayVar(&yamlsort.priorkeys, "key", []string{}, "set prior key name in sort. default prior key is name")
+
+	return cmd
+}
+
+// message of first out-of-order key in file. "" when file is sorted.
+func (c *yamlsortCmd) checkSorted(filename string) (string, error) {
+	input, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+	docscanner := newDocumentScanner(bytes.NewReader(input), c.maxlinesize)
+	index := 0
+	for docscanner.Scan() {
+		rawdoc := docscanner.Document()
+		if rawdoc.isEmpty() {
+			continue
+		}
+		node := statsNode{}
+		err := unmarshalYAMLv2(rawdoc.parseData(), &node)
+		if err != nil {
+			return "", err
+		}
+		if message := firstUnsortedKey("", node); len(message) > 0 {
+			if index > 0 {
+				message = fmt.Sprintf("document %d: %s", index, message)
+			}
+			return message, nil
+		}
+		index++
+	}
+	return "", docscanner.Err()
+}
+
+// first key which is out of order in data , in document order
+func firstUnsortedKey(path string, data interface{}) string {
+	switch v := data.(type) {
+	case statsNode:
+		return firstUnsortedKey(path, v.value)
+	case yamlv2.MapSlice:
+		prev := ""
+		for i, item := range v {
+			key := fmt.Sprint(item.Key)
+			childpath := key
+			if len(path) > 0 {
+				childpath = path + "." + key
+			}
+			if i > 0 && compairString(key, prev) {
+				return fmt.Sprintf("%s is out of order (should be before %s)", childpath, prev)
+			}
+			if message := firstUnsortedKey(childpath, item.Value); len(message) > 0 {
+				return message
+			}
+			prev = key
+		}
+	case []statsNode:
+		for i, item := range v {
+			if message := firstUnsortedKey(fmt.Sprintf("%s[%d]", path, i), item); len(message) > 0 {
+				return message
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			if message := firstUnsortedKey(fmt.Sprintf("%s[%d]", path, i), item); len(message) > 0 {
+				return message
+			}
+		}
+	}
+	return ""
+}
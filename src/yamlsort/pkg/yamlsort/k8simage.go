@@ -0,0 +1,133 @@
+//
+// yamlsort - set-image subcommand
+//
+// rewrite container images of kubernetes workloads in stream , and output sorted.
+//   yamlsort set-image nginx=nginx:1.27 -i bundle.yaml
+//   yamlsort set-image example/api=:2.0 redis=redis@sha256:... -f deploy.yaml
+// left side is image name without tag and digest (registry/repository). right side is new
+// image , or :tag (@digest) which keeps image name. images of containers , initContainers and
+// ephemeralContainers of Pod , Deployment , StatefulSet , DaemonSet , ReplicaSet , Job , CronJob
+// (and items of List) are rewritten. names which match no image are reported to stderr.
+//
+package yamlsort
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// rewrite of image
+type imageEdit struct {
+	name    string // image name without tag and digest
+	image   string // new image , or :tag , @digest
+	matched bool
+}
+
+//---------------------------------------------------------------------
+//  set-image subcommand
+//
+func newSetImageCmd(yamlsort *yamlsortCmd) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set-image name=image...",
+		Short: "rewrite container images of kubernetes workloads (like nginx=nginx:1.27) , and output sorted",
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("set-image requires name=image arguments")
+			}
+			for _, arg := range args {
+				err := yamlsort.addImageEdit(arg)
+				if err != nil {
+					return err
+				}
+			}
+			err := yamlsort.run(nil)
+			if err != nil {
+				return err
+			}
+			for _, edit := range yamlsort.imageedits {
+				if !edit.matched {
+					fmt.Fprintf(yamlsort.stderr, "Warning: set-image: no image matches %s\n", edit.name)
+				}
+			}
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	addInputOutputFlags(f, yamlsort)
+
+	return cmd
+}
+
+// parse name=image , and keep it
+func (c *yamlsortCmd) addImageEdit(text string) error {
+	idx := strings.Index(text, "=")
+	if idx <= 0 || idx == len(text)-1 {
+		return fmt.Errorf("set-image argument must be name=image : %s", text)
+	}
+	edit := &imageEdit{name: text[:idx], image: text[idx+1:]}
+	if edit.name != imageName(edit.name) {
+		return fmt.Errorf("set-image: %s must be image name without tag and digest", edit.name)
+	}
+	c.imageedits = append(c.imageedits, edit)
+	return nil
+}
+
+// image name without tag and digest. "registry:5000/app:1.0@sha256:..." -> "registry:5000/app"
+func imageName(image string) string {
+	if idx := strings.Index(image, "@"); idx >= 0 {
+		image = image[:idx]
+	}
+	if idx := strings.LastIndex(image, ":"); idx > strings.LastIndex(image, "/") {
+		image = image[:idx]
+	}
+	return image
+}
+
+// new image. :tag and @digest are added to image name.
+func (edit *imageEdit) newImage() string {
+	if strings.HasPrefix(edit.image, ":") || strings.HasPrefix(edit.image, "@") {
+		return edit.name + edit.image
+	}
+	return edit.image
+}
+
+// rewrite images of kubernetes object (and items of List)
+func (c *yamlsortCmd) applyImageEdits(data interface{}) {
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return
+	}
+	if items, ok := m["items"].([]interface{}); ok && strings.HasSuffix(k8sString(m, "kind"), "List") {
+		for _, item := range items {
+			c.applyImageEdits(item)
+		}
+		return
+	}
+	podspec, _ := k8sPodSpec(m)
+	if podspec == nil {
+		return
+	}
+	for _, field := range []string{"initContainers", "containers", "ephemeralContainers"} {
+		containers, _ := podspec[field].([]interface{})
+		for _, container := range containers {
+			cm, ok := container.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			image, ok := cm["image"].(string)
+			if !ok {
+				continue
+			}
+			for _, edit := range c.imageedits {
+				if imageName(image) == edit.name {
+					cm["image"] = edit.newImage()
+					edit.matched = true
+					break
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,176 @@
+//
+// yamlsort - k8s-label subcommand
+//
+// add and remove labels and annotations of all kubernetes documents in stream , and output sorted.
+//   yamlsort k8s-label --add team=payments --remove legacy-owner -i bundle.yaml
+//   yamlsort k8s-label --add-annotation owner=platform --pod-template -f deploy.yaml
+//   yamlsort k8s-label --add team=payments -w manifests/
+// documents without kind are not changed. items of List are changed.
+// with --pod-template , labels and annotations of pod templates (Deployment , Job ...) are changed too.
+// removing label from pod template may break selector of workload and Service , so check it with --check-refs.
+//
+package yamlsort
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// key and value of labels
+var (
+	k8sLabelNameRegexp   = regexp.MustCompile(`^([A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?)?$`)
+	k8sLabelPrefixRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+)
+
+// change of metadata.labels or metadata.annotations
+type k8sMetadataEdit struct {
+	field     string // labels , annotations
+	key       string
+	value     string
+	blnRemove bool
+}
+
+//---------------------------------------------------------------------
+//  k8s-label subcommand
+//
+func newK8sLabelCmd(yamlsort *yamlsortCmd) *cobra.Command {
+	var adds []string
+	var removes []string
+	var addannotations []string
+	var removeannotations []string
+
+	cmd := &cobra.Command{
+		Use:   "k8s-label [file or directory...]",
+		Short: "add and remove labels and annotations of kubernetes documents , and output sorted",
+		RunE: func(c *cobra.Command, args []string) error {
+			for _, item := range []struct {
+				field     string
+				values    []string
+				blnRemove bool
+			}{
+				{"labels", adds, false},
+				{"labels", removes, true},
+				{"annotations", addannotations, false},
+				{"annotations", removeannotations, true},
+			} {
+				for _, value := range item.values {
+					err := yamlsort.addMetadataEdit(item.field, value, item.blnRemove)
+					if err != nil {
+						return err
+					}
+				}
+			}
+			if len(yamlsort.metadataedits) == 0 {
+				return fmt.Errorf("k8s-label requires --add , --remove , --add-annotation or --remove-annotation")
+			}
+			return yamlsort.run(args)
+		},
+	}
+
+	f := cmd.Flags()
+	addInputOutputFlags(f, yamlsort)
+	f.BoolVarP(&yamlsort.blnWrite, "write", "w", false, "write result to file arguments in place , instead of stdout")
+	f.BoolVar(&yamlsort.blnDryRun, "dry-run", false, "with -w or -f , show unified diff and write nothing")
+	f.StringArrayVar(&adds, "add", []string{}, "add label key=value. (can specify multiple labels)")
+	f.StringArrayVar(&removes, "remove", []string{}, "remove label of key. (can specify multiple labels)")
+	f.StringArrayVar(&addannotations, "add-annotation", []string{}, "add annotation key=value. (can specify multiple annotations)")
+	f.StringArrayVar(&removeannotations, "remove-annotation", []string{}, "remove annotation of key. (can specify multiple annotations)")
+	f.BoolVar(&yamlsort.blnPodTemplate, "pod-template", false, "change labels and annotations of pod templates too")
+
+	return cmd
+}
+
+// parse key=value (or key of remove) , and keep it
+func (c *yamlsortCmd) addMetadataEdit(field string, text string, blnRemove bool) error {
+	edit := k8sMetadataEdit{field: field, key: text, blnRemove: blnRemove}
+	if !blnRemove {
+		idx := strings.Index(text, "=")
+		if idx < 0 {
+			return fmt.Errorf("%s must be key=value : %s", field, text)
+		}
+		edit.key, edit.value = text[:idx], text[idx+1:]
+	}
+	err := checkLabelKey(edit.key)
+	if err != nil {
+		return err
+	}
+	if field == "labels" && (len(edit.value) > 63 || !k8sLabelNameRegexp.MatchString(edit.value)) {
+		return fmt.Errorf("invalid label value %q of %s , 63 characters of alphanumerics , '-' , '_' , '.'", edit.value, edit.key)
+	}
+	c.metadataedits = append(c.metadataedits, edit)
+	return nil
+}
+
+// key of label and annotation , like app.kubernetes.io/name
+func checkLabelKey(key string) error {
+	name := key
+	if idx := strings.LastIndex(key, "/"); idx >= 0 {
+		prefix := key[:idx]
+		name = key[idx+1:]
+		if len(prefix) == 0 || len(prefix) > 253 || !k8sLabelPrefixRegexp.MatchString(prefix) {
+			return fmt.Errorf("invalid prefix of key %q , dns subdomain like example.com", key)
+		}
+	}
+	if len(name) == 0 || len(name) > 63 || !k8sLabelNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid key %q , 63 characters of alphanumerics , '-' , '_' , '.'", key)
+	}
+	return nil
+}
+
+// apply label and annotation changes to kubernetes object (and items of List)
+func (c *yamlsortCmd) applyMetadataEdits(data interface{}) {
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return
+	}
+	if _, ok := m["kind"].(string); !ok {
+		return
+	}
+	if items, ok := m["items"].([]interface{}); ok && strings.HasSuffix(k8sString(m, "kind"), "List") {
+		for _, item := range items {
+			c.applyMetadataEdits(item)
+		}
+		return
+	}
+	applyMetadataEditsTo(m, c.metadataedits)
+	if c.blnPodTemplate {
+		if keys, ok := k8sPodSpecPaths[k8sString(m, "kind")]; ok && len(keys) > 1 {
+			// template.spec -> template
+			if template := k8sMap(m, keys[:len(keys)-1]...); template != nil {
+				applyMetadataEditsTo(template, c.metadataedits)
+			}
+		}
+	}
+}
+
+// change metadata of object or pod template
+func applyMetadataEditsTo(object map[string]interface{}, edits []k8sMetadataEdit) {
+	metadata, ok := object["metadata"].(map[string]interface{})
+	if !ok {
+		metadata = map[string]interface{}{}
+		object["metadata"] = metadata
+	}
+	for _, edit := range edits {
+		fields, ok := metadata[edit.field].(map[string]interface{})
+		if edit.blnRemove {
+			if ok {
+				delete(fields, edit.key)
+				if len(fields) == 0 {
+					delete(metadata, edit.field)
+				}
+			}
+			continue
+		}
+		if !ok {
+			fields = map[string]interface{}{}
+			metadata[edit.field] = fields
+		}
+		fields[edit.key] = edit.value
+	}
+	if len(metadata) == 0 {
+		delete(object, "metadata")
+	}
+}
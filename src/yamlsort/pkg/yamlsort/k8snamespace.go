@@ -0,0 +1,93 @@
+//
+// yamlsort - --set-namespace option
+//
+// set metadata.namespace of all namespaced kubernetes documents in stream , for quick
+// environment stamping (micro kustomize).
+//   yamlsort --set-namespace staging -i bundle.yaml
+// cluster-scoped kinds (Namespace , ClusterRole , CustomResourceDefinition ...) are skipped.
+// custom resources are namespaced , unless CustomResourceDefinition with scope: Cluster is before
+// them in stream. items of List are changed. documents without kind are not changed.
+//
+package yamlsort
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// namespace name (dns label)
+var k8sNamespaceRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// built-in kinds which are not namespaced
+var k8sClusterScopedKinds = map[string]bool{
+	"APIService":                     true,
+	"CertificateSigningRequest":      true,
+	"ClusterIssuer":                  true,
+	"ClusterRole":                    true,
+	"ClusterRoleBinding":             true,
+	"ComponentStatus":                true,
+	"CSIDriver":                      true,
+	"CSINode":                        true,
+	"CustomResourceDefinition":       true,
+	"FlowSchema":                     true,
+	"IngressClass":                   true,
+	"MutatingWebhookConfiguration":   true,
+	"Namespace":                      true,
+	"Node":                           true,
+	"PersistentVolume":               true,
+	"PodSecurityPolicy":              true,
+	"PriorityClass":                  true,
+	"PriorityLevelConfiguration":     true,
+	"RuntimeClass":                   true,
+	"StorageClass":                   true,
+	"ValidatingAdmissionPolicy":      true,
+	"ValidatingWebhookConfiguration": true,
+	"VolumeAttachment":               true,
+}
+
+func checkNamespace(namespace string) error {
+	if len(namespace) == 0 {
+		return nil
+	}
+	if len(namespace) > 63 || !k8sNamespaceRegexp.MatchString(namespace) {
+		return fmt.Errorf("invalid --set-namespace %q , 63 characters of lowercase alphanumerics and '-'", namespace)
+	}
+	return nil
+}
+
+// set namespace of kubernetes object (and items of List)
+func (c *yamlsortCmd) applyNamespace(data interface{}) {
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return
+	}
+	kind, ok := m["kind"].(string)
+	if !ok {
+		return
+	}
+	if items, ok := m["items"].([]interface{}); ok && strings.HasSuffix(kind, "List") {
+		for _, item := range items {
+			c.applyNamespace(item)
+		}
+		return
+	}
+	if kind == "CustomResourceDefinition" && k8sString(m, "spec", "scope") == "Cluster" {
+		// custom resources of this definition are cluster-scoped
+		if crdkind := k8sString(m, "spec", "names", "kind"); len(crdkind) > 0 {
+			if c.clusterkinds == nil {
+				c.clusterkinds = map[string]bool{}
+			}
+			c.clusterkinds[crdkind] = true
+		}
+	}
+	if k8sClusterScopedKinds[kind] || c.clusterkinds[kind] {
+		return
+	}
+	metadata, ok := m["metadata"].(map[string]interface{})
+	if !ok {
+		metadata = map[string]interface{}{}
+		m["metadata"] = metadata
+	}
+	metadata["namespace"] = c.namespace
+}
@@ -0,0 +1,156 @@
+//
+// yamlsort - --normalize-quantities option
+//
+// normalize kubernetes resource quantities into canonical form (like kubectl get -o yaml) , so
+// semantically same resources produce no diffs.
+//   1024Mi -> 1Gi   0.5Gi -> 512Mi   0.5 -> 500m   1000m -> 1   2000 -> 2k   1e3 -> 1k
+// binary suffixes (Ki , Mi ...) stay binary , and others are decimal (m , k , M ...). quantities
+// which can not be exact in these forms are not changed.
+// values of resources.requests , resources.limits , overhead , ResourceQuota spec.hard ,
+// LimitRange spec.limits and PersistentVolume spec.capacity of kubernetes documents are normalized.
+//
+package yamlsort
+
+import (
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// quantity text , number and suffix
+var k8sQuantityRegexp = regexp.MustCompile(`^([+-]?(?:[0-9]+(?:\.[0-9]*)?|\.[0-9]+))(Ki|Mi|Gi|Ti|Pi|Ei|n|u|m|k|M|G|T|P|E|[eE][+-]?[0-9]+)?$`)
+
+// maps of quantities in LimitRange spec.limits[*]
+var k8sLimitRangeFields = []string{"default", "defaultRequest", "max", "min", "maxLimitRequestRatio"}
+
+// suffixes of binary and decimal forms , from largest
+var (
+	k8sBinarySuffixes  = []string{"Ei", "Pi", "Ti", "Gi", "Mi", "Ki", ""}
+	k8sDecimalSuffixes = []string{"E", "P", "T", "G", "M", "k", "", "m", "u", "n"}
+)
+
+// multiplier of suffix
+func quantityMultiplier(suffix string) *big.Rat {
+	r := new(big.Rat)
+	switch suffix {
+	case "Ki", "Mi", "Gi", "Ti", "Pi", "Ei":
+		n := map[string]uint{"Ki": 10, "Mi": 20, "Gi": 30, "Ti": 40, "Pi": 50, "Ei": 60}[suffix]
+		return r.SetInt(new(big.Int).Lsh(big.NewInt(1), n))
+	case "n", "u", "m", "", "k", "M", "G", "T", "P", "E":
+		exp := map[string]int64{"n": -9, "u": -6, "m": -3, "": 0, "k": 3, "M": 6, "G": 9, "T": 12, "P": 15, "E": 18}[suffix]
+		return decimalMultiplier(exp)
+	}
+	// exponent , like e3
+	exp, _ := strconv.ParseInt(suffix[1:], 10, 64)
+	return decimalMultiplier(exp)
+}
+
+// 10^exp
+func decimalMultiplier(exp int64) *big.Rat {
+	if exp < 0 {
+		return new(big.Rat).SetFrac(big.NewInt(1), new(big.Int).Exp(big.NewInt(10), big.NewInt(-exp), nil))
+	}
+	return new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(exp), nil))
+}
+
+// canonical text of quantity. false when text is not quantity , or has no exact canonical form.
+func normalizeQuantity(text string) (string, bool) {
+	m := k8sQuantityRegexp.FindStringSubmatch(text)
+	if m == nil || len(text) > 64 {
+		return "", false
+	}
+	value, ok := new(big.Rat).SetString(m[1])
+	if !ok {
+		return "", false
+	}
+	value.Mul(value, quantityMultiplier(m[2]))
+	if value.Sign() == 0 {
+		return "0", true
+	}
+	suffixes := k8sDecimalSuffixes
+	if len(m[2]) == 2 && m[2][1] == 'i' {
+		suffixes = k8sBinarySuffixes
+	}
+	for _, suffix := range suffixes {
+		scaled := new(big.Rat).Quo(value, quantityMultiplier(suffix))
+		if scaled.IsInt() {
+			return scaled.Num().String() + suffix, true
+		}
+	}
+	if len(m[2]) == 2 {
+		// binary quantity with fraction , like 0.1Ki
+		for _, suffix := range k8sDecimalSuffixes {
+			scaled := new(big.Rat).Quo(value, quantityMultiplier(suffix))
+			if scaled.IsInt() {
+				return scaled.Num().String() + suffix, true
+			}
+		}
+	}
+	return "", false
+}
+
+// normalize quantities under resources.requests , resources.limits and overhead
+func normalizeQuantitiesRecursive(data interface{}, parent string) {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if (parent == "resources" && (k == "requests" || k == "limits")) || k == "overhead" {
+				normalizeQuantityMap(child)
+				continue
+			}
+			normalizeQuantitiesRecursive(child, k)
+		}
+	case []interface{}:
+		for _, child := range v {
+			normalizeQuantitiesRecursive(child, "")
+		}
+	}
+}
+
+// normalize values of map (and maps in it , like overhead.podFixed)
+func normalizeQuantityMap(data interface{}) {
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return
+	}
+	for k, child := range m {
+		if _, ok := child.(map[string]interface{}); ok {
+			normalizeQuantityMap(child)
+			continue
+		}
+		m[k] = normalizeScalar(child, normalizeQuantity)
+	}
+}
+
+// normalize quantities of kubernetes object (documents without kind are not changed)
+func (c *yamlsortCmd) applyQuantities(data interface{}) {
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return
+	}
+	kind, ok := m["kind"].(string)
+	if !ok {
+		return
+	}
+	switch kind {
+	case "ResourceQuota":
+		normalizeQuantityMap(k8sMap(m, "spec", "hard"))
+	case "PersistentVolume":
+		normalizeQuantityMap(k8sMap(m, "spec", "capacity"))
+	case "LimitRange":
+		limits, _ := k8sMap(m, "spec")["limits"].([]interface{})
+		for _, limit := range limits {
+			for _, field := range k8sLimitRangeFields {
+				normalizeQuantityMap(k8sMap(limit, field))
+			}
+		}
+	}
+	if items, ok := m["items"].([]interface{}); ok && strings.HasSuffix(kind, "List") {
+		for _, item := range items {
+			c.applyQuantities(item)
+		}
+		return
+	}
+	normalizeQuantitiesRecursive(m, "")
+}
@@ -0,0 +1,257 @@
+//
+// yamlsort - --check-refs option
+//
+// check references between kubernetes documents of one input (bundle) , while sorting.
+//   Service selector              labels of pod template of Deployment , StatefulSet , Job ...
+//   configMapKeyRef , configMapRef , configMap volume     ConfigMap (and key in data)
+//   secretKeyRef , secretRef , secret volume , imagePullSecrets    Secret (and key in data)
+// dangling references are policy violations (check-refs) , like --rules.
+//   yamlsort --check-refs -i bundle.yaml
+//   policy violation: bundle.yaml (document 1) check-refs: Deployment api: spec.template.spec.containers[0].envFrom[0].configMapRef.name refers undefined ConfigMap api-conf
+// references with optional: true are not checked. documents without namespace match any namespace.
+//
+package yamlsort
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// rule name of dangling references
+const checkRefsRule = "check-refs"
+
+// path of pod spec in workloads
+var k8sPodSpecPaths = map[string][]string{
+	"Pod":                   {"spec"},
+	"Deployment":            {"spec", "template", "spec"},
+	"StatefulSet":           {"spec", "template", "spec"},
+	"DaemonSet":             {"spec", "template", "spec"},
+	"ReplicaSet":            {"spec", "template", "spec"},
+	"ReplicationController": {"spec", "template", "spec"},
+	"Job":                   {"spec", "template", "spec"},
+	"CronJob":               {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// map at path of keys. nil when it is not map.
+func k8sMap(data interface{}, keys ...string) map[string]interface{} {
+	for _, key := range keys {
+		m, ok := data.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		data = m[key]
+	}
+	m, _ := data.(map[string]interface{})
+	return m
+}
+
+// string at path of keys. empty when it is not string.
+func k8sString(data interface{}, keys ...string) string {
+	if len(keys) == 0 {
+		s, _ := data.(string)
+		return s
+	}
+	s, _ := k8sMap(data, keys[:len(keys)-1]...)[keys[len(keys)-1]].(string)
+	return s
+}
+
+// pod spec of workload , and its path. nil when document is not workload.
+func k8sPodSpec(data interface{}) (map[string]interface{}, string) {
+	keys, ok := k8sPodSpecPaths[k8sString(data, "kind")]
+	if !ok {
+		return nil, ""
+	}
+	return k8sMap(data, keys...), strings.Join(keys, ".")
+}
+
+// labels of pods of workload
+func k8sPodLabels(data interface{}) map[string]interface{} {
+	keys, ok := k8sPodSpecPaths[k8sString(data, "kind")]
+	if !ok {
+		return nil
+	}
+	if len(keys) == 1 {
+		return k8sMap(data, "metadata", "labels")
+	}
+	// template.spec -> template.metadata.labels
+	meta := append(append([]string{}, keys[:len(keys)-1]...), "metadata", "labels")
+	return k8sMap(data, meta...)
+}
+
+//---------------------------------------------------------------------
+//  refChecker class
+// objects and references in documents of one input
+//
+type k8sObject struct {
+	kind      string
+	name      string
+	namespace string
+	keys      map[string]bool // keys of data (ConfigMap , Secret)
+}
+
+type k8sRef struct {
+	index     int // document index
+	source    string
+	path      string
+	kind      string // ConfigMap , Secret
+	name      string
+	key       string // key of configMapKeyRef , secretKeyRef
+	namespace string
+}
+
+type k8sSelector struct {
+	index     int
+	source    string
+	namespace string
+	selector  map[string]interface{}
+}
+
+type refChecker struct {
+	objects   []k8sObject
+	podlabels []k8sObject // namespace and labels (in keys as "name=value")
+	refs      []k8sRef
+	selectors []k8sSelector
+}
+
+func newRefChecker() *refChecker {
+	return &refChecker{}
+}
+
+// collect objects and references of document
+func (r *refChecker) add(doc *Document) {
+	data := doc.Data
+	kind := k8sString(data, "kind")
+	name := k8sString(data, "metadata", "name")
+	namespace := k8sString(data, "metadata", "namespace")
+	if len(kind) == 0 {
+		return
+	}
+	source := kind + " " + name
+	switch kind {
+	case "ConfigMap", "Secret":
+		object := k8sObject{kind: kind, name: name, namespace: namespace, keys: map[string]bool{}}
+		for _, field := range []string{"data", "binaryData", "stringData"} {
+			for k := range k8sMap(data, field) {
+				object.keys[k] = true
+			}
+		}
+		r.objects = append(r.objects, object)
+	case "Service":
+		if selector := k8sMap(data, "spec", "selector"); len(selector) > 0 {
+			r.selectors = append(r.selectors, k8sSelector{index: doc.Index, source: source, namespace: namespace, selector: selector})
+		}
+	}
+	if labels := k8sPodLabels(data); labels != nil {
+		object := k8sObject{namespace: namespace, keys: map[string]bool{}}
+		for k, v := range labels {
+			object.keys[k+"="+fmt.Sprint(v)] = true
+		}
+		r.podlabels = append(r.podlabels, object)
+	}
+	podspec, path := k8sPodSpec(data)
+	if podspec == nil {
+		return
+	}
+	add := func(path string, kind string, name string, key string, optional interface{}) {
+		if optional == true || len(name) == 0 {
+			return
+		}
+		r.refs = append(r.refs, k8sRef{index: doc.Index, source: source, path: path, kind: kind, name: name, key: key, namespace: namespace})
+	}
+	for _, field := range []string{"initContainers", "containers"} {
+		containers, _ := podspec[field].([]interface{})
+		for i, container := range containers {
+			cpath := fmt.Sprintf("%s.%s[%d]", path, field, i)
+			envs, _ := k8sMap(container)["env"].([]interface{})
+			for j, env := range envs {
+				epath := fmt.Sprintf("%s.env[%d].valueFrom", cpath, j)
+				if ref := k8sMap(env, "valueFrom", "configMapKeyRef"); ref != nil {
+					add(epath+".configMapKeyRef.name", "ConfigMap", k8sString(ref, "name"), k8sString(ref, "key"), ref["optional"])
+				}
+				if ref := k8sMap(env, "valueFrom", "secretKeyRef"); ref != nil {
+					add(epath+".secretKeyRef.name", "Secret", k8sString(ref, "name"), k8sString(ref, "key"), ref["optional"])
+				}
+			}
+			envfroms, _ := k8sMap(container)["envFrom"].([]interface{})
+			for j, envfrom := range envfroms {
+				epath := fmt.Sprintf("%s.envFrom[%d]", cpath, j)
+				if ref := k8sMap(envfrom, "configMapRef"); ref != nil {
+					add(epath+".configMapRef.name", "ConfigMap", k8sString(ref, "name"), "", ref["optional"])
+				}
+				if ref := k8sMap(envfrom, "secretRef"); ref != nil {
+					add(epath+".secretRef.name", "Secret", k8sString(ref, "name"), "", ref["optional"])
+				}
+			}
+		}
+	}
+	volumes, _ := podspec["volumes"].([]interface{})
+	for i, volume := range volumes {
+		vpath := fmt.Sprintf("%s.volumes[%d]", path, i)
+		if ref := k8sMap(volume, "configMap"); ref != nil {
+			add(vpath+".configMap.name", "ConfigMap", k8sString(ref, "name"), "", ref["optional"])
+		}
+		if ref := k8sMap(volume, "secret"); ref != nil {
+			add(vpath+".secret.secretName", "Secret", k8sString(ref, "secretName"), "", ref["optional"])
+		}
+	}
+	secrets, _ := podspec["imagePullSecrets"].([]interface{})
+	for i, secret := range secrets {
+		add(fmt.Sprintf("%s.imagePullSecrets[%d].name", path, i), "Secret", k8sString(secret, "name"), "", nil)
+	}
+}
+
+// namespaces match. empty namespace matches any namespace.
+func sameNamespace(a string, b string) bool {
+	return a == b || len(a) == 0 || len(b) == 0
+}
+
+// report dangling references as violations
+func (c *yamlsortCmd) reportRefs(r *refChecker) {
+	for _, ref := range r.refs {
+		var found *k8sObject
+		for i := range r.objects {
+			o := &r.objects[i]
+			if o.kind == ref.kind && o.name == ref.name && sameNamespace(o.namespace, ref.namespace) {
+				found = o
+				break
+			}
+		}
+		message := ""
+		if found == nil {
+			message = fmt.Sprintf("%s: %s refers undefined %s %s", ref.source, ref.path, ref.kind, ref.name)
+		} else if len(ref.key) > 0 && !found.keys[ref.key] {
+			message = fmt.Sprintf("%s: %s refers undefined key %s of %s %s", ref.source, ref.path, ref.key, ref.kind, ref.name)
+		}
+		if len(message) > 0 {
+			c.addViolation(&Document{Index: ref.index}, checkRefsRule, message)
+		}
+	}
+	for _, s := range r.selectors {
+		matched := false
+		for _, pod := range r.podlabels {
+			if !sameNamespace(pod.namespace, s.namespace) {
+				continue
+			}
+			matched = true
+			for k, v := range s.selector {
+				if !pod.keys[k+"="+fmt.Sprint(v)] {
+					matched = false
+					break
+				}
+			}
+			if matched {
+				break
+			}
+		}
+		if !matched {
+			pairs := []string{}
+			for k, v := range s.selector {
+				pairs = append(pairs, k+"="+fmt.Sprint(v))
+			}
+			sort.Strings(pairs)
+			message := fmt.Sprintf("%s: selector %s matches no pod template", s.source, strings.Join(pairs, ","))
+			c.addViolation(&Document{Index: s.index}, checkRefsRule, message)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+//
+// yamlsort - key case conversion (--key-case)
+//
+package yamlsort
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// check --key-case option value
+func checkKeyCase(keycase string) error {
+	switch keycase {
+	case "", "preserve", "camel", "snake", "kebab":
+		return nil
+	}
+	return fmt.Errorf("--key-case must be camel , snake , kebab or preserve : %s", keycase)
+}
+
+// split key into lower case words. "imagePullPolicy" , "image_pull_policy" , "HTTPServer"
+func splitKeyWords(key string) []string {
+	words := []string{}
+	word := []rune{}
+	runes := []rune(key)
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, strings.ToLower(string(word)))
+			word = []rune{}
+		}
+	}
+	for i, r := range runes {
+		if r == '_' || r == '-' || r == ' ' {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(r) && len(word) > 0 {
+			prev := runes[i-1]
+			// aB -> a B ,  ABc -> A Bc
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				flush()
+			}
+		}
+		word = append(word, r)
+	}
+	flush()
+	return words
+}
+
+// convert key to camel , snake , kebab case
+func convertKeyCase(key string, keycase string) string {
+	words := splitKeyWords(key)
+	if len(words) == 0 {
+		return key
+	}
+	switch keycase {
+	case "camel":
+		result := words[0]
+		for _, w := range words[1:] {
+			runes := []rune(w)
+			result = result + string(unicode.ToUpper(runes[0])) + string(runes[1:])
+		}
+		return result
+	case "snake":
+		return strings.Join(words, "_")
+	case "kebab":
+		return strings.Join(words, "-")
+	}
+	return key
+}
+
+// convert all map keys in data
+func (c *yamlsortCmd) convertKeyCaseRecursive(path string, data interface{}) (interface{}, error) {
+	if m, ok := data.(map[string]interface{}); ok {
+		result := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			newkey := convertKeyCase(k, c.keycase)
+			if _, ok := result[newkey]; ok {
+				return data, fmt.Errorf("--key-case %s: key %s conflicts at %s", c.keycase, newkey, c.calcPathMap(path, k))
+			}
+			child, err := c.convertKeyCaseRecursive(c.calcPathMap(path, k), v)
+			if err != nil {
+				return data, err
+			}
+			result[newkey] = child
+		}
+		return result, nil
+	} else if a, ok := data.([]interface{}); ok {
+		for i, v := range a {
+			child, err := c.convertKeyCaseRecursive(c.calcPathSlice(path, i), v)
+			if err != nil {
+				return data, err
+			}
+			a[i] = child
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,136 @@
+//
+// yamlsort - kube get subcommand
+//
+// fetch live objects with kubectl (same kubeconfig and context) , strip server side
+// fields , and output clean sorted manifests.
+//   yamlsort kube get deploy/my-app -n prod
+// arguments are passed to "kubectl get ... -o json" as is. List is split into documents.
+// client-go is not linked (it is large dependency , and needs newer go) , so kubectl is required.
+// removed fields
+//   status , metadata.managedFields , resourceVersion , uid , generation ,
+//   creationTimestamp , selfLink , and annotations written by kubectl and controllers
+//
+package yamlsort
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+)
+
+// metadata fields written by api server
+var kubeServerMetadata = []string{
+	"managedFields", "resourceVersion", "uid", "generation", "creationTimestamp", "selfLink",
+}
+
+// annotations written by kubectl and controllers
+var kubeServerAnnotations = []string{
+	"kubectl.kubernetes.io/last-applied-configuration",
+	"deployment.kubernetes.io/revision",
+}
+
+//---------------------------------------------------------------------
+//  kube subcommand
+//
+func newKubeCmd(yamlsort *yamlsortCmd) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "kube",
+		Short: "read live objects of kubernetes cluster",
+	}
+
+	get := &cobra.Command{
+		Use:   "get [kubectl get arguments]",
+		Short: "output objects of kubectl get without server side fields , sorted",
+		// all arguments are for kubectl
+		DisableFlagParsing: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("kube get requires resource (like deploy/my-app)")
+			}
+			if args[0] == "-h" || args[0] == "--help" {
+				return c.Help()
+			}
+			return yamlsort.kubeGet(args)
+		},
+	}
+	cmd.AddCommand(get)
+
+	return cmd
+}
+
+func (c *yamlsortCmd) kubeGet(args []string) error {
+	if _, err := exec.LookPath("kubectl"); err != nil {
+		return fmt.Errorf("kubectl command is required for kube get")
+	}
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd := exec.Command("kubectl", append(append([]string{"get"}, args...), "-o", "json")...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	err := cmd.Run()
+	if err != nil {
+		return fmt.Errorf("kubectl get: %v %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var data map[string]interface{}
+	err = json.Unmarshal(stdout.Bytes(), &data)
+	if err != nil {
+		return fmt.Errorf("kubectl get: %v", err)
+	}
+	items := []interface{}{data}
+	if list, ok := data["items"].([]interface{}); ok && strings.HasSuffix(fmt.Sprint(data["kind"]), "List") {
+		items = list
+	}
+
+	input := new(bytes.Buffer)
+	for _, item := range items {
+		object, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		cleanKubeObject(object)
+		text, err := yaml.Marshal(object)
+		if err != nil {
+			return err
+		}
+		input.WriteString("---\n")
+		input.Write(text)
+	}
+
+	c.maxlinesize = defaultMaxLineSize
+	err = c.prepareOptions()
+	if err != nil {
+		return err
+	}
+	output, err := c.sortBytes(input.Bytes(), nil)
+	if err != nil {
+		return err
+	}
+	_, err = c.stdout.Write(output.Bytes())
+	return err
+}
+
+// remove server side fields of live object
+func cleanKubeObject(object map[string]interface{}) {
+	delete(object, "status")
+	metadata, ok := object["metadata"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	for _, key := range kubeServerMetadata {
+		delete(metadata, key)
+	}
+	if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+		for _, key := range kubeServerAnnotations {
+			delete(annotations, key)
+		}
+		if len(annotations) == 0 {
+			delete(metadata, "annotations")
+		}
+	}
+}
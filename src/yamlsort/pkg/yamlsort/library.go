@@ -0,0 +1,139 @@
+//
+// yamlsort - library API
+//
+// package yamlsort is sorter of yamlsort command. other go programs (in this module) sort yaml
+// text in process with options of command line.
+//   opts, err := yamlsort.NewOptions("--key", "kind", "--lint-profile=prettier")
+//   output, err := opts.Sort(input)
+// documents can be inspected and transformed one by one with ProcessDocuments and WriteDocument.
+// options which read or write files (-i , -o , -f , -w , --check , --output-template) and file
+// arguments are for command line only. order of --key is state of package , so Sort and
+// ProcessDocuments of all options are processed one by one.
+//
+package yamlsort
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"runtime/debug"
+	"sync"
+)
+
+// module path of yamlsort , to find version in build info
+const modulePath = "github.com/keita69/yamlsort/src/yamlsort"
+
+// sorts are processed one by one (globalpriorkeys)
+var globalsortmutex sync.Mutex
+
+//---------------------------------------------------------------------
+//  Options class
+// parsed options of command line for library use
+//
+type Options struct {
+	c *yamlsortCmd
+}
+
+// NewOptions parses options of command line , like "--key", "kind".
+func NewOptions(args ...string) (*Options, error) {
+	c := &yamlsortCmd{
+		version: Version(),
+		stdin:   bytes.NewReader(nil),
+		stdout:  ioutil.Discard,
+		stderr:  ioutil.Discard,
+	}
+	cmd := newRootCommand(c)
+	cmd.SetOutput(ioutil.Discard)
+	err := cmd.ParseFlags(args)
+	if err != nil {
+		return nil, err
+	}
+	if len(cmd.Flags().Args()) > 0 {
+		return nil, fmt.Errorf("file arguments can not be used with options of library")
+	}
+	if len(c.inputfilename) > 0 || len(c.outputfilename) > 0 || len(c.inputoutputfilename) > 0 ||
+		c.blnWrite || c.blnCheck || len(c.outputtemplate) > 0 {
+		return nil, fmt.Errorf("-i , -o , -f , -w , --check and --output-template can not be used with options of library")
+	}
+	err = c.checkOutputMode()
+	if err != nil {
+		return nil, err
+	}
+	globalsortmutex.Lock()
+	defer globalsortmutex.Unlock()
+	err = c.prepareOptions()
+	if err != nil {
+		c.closeComparator()
+		return nil, err
+	}
+	return &Options{c: c}, nil
+}
+
+// Close stops comparator plugin (--comparator , or comparator of --preset) of options , and waits for it.
+// options can not be used after Close.
+func (o *Options) Close() error {
+	globalsortmutex.Lock()
+	defer globalsortmutex.Unlock()
+	return o.c.closeComparator()
+}
+
+// Sort returns sorted text of input. panics in parsers are returned as error.
+func (o *Options) Sort(input []byte) (output []byte, err error) {
+	globalsortmutex.Lock()
+	defer globalsortmutex.Unlock()
+	defer func() {
+		if r := recover(); r != nil {
+			output, err = nil, recoveredError(r)
+		}
+	}()
+	globalpriorkeys = o.c.priorkeys
+	sortInput, _, err := o.c.decodeInput("", input)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := o.c.sortBytes(sortInput, nil)
+	if err != nil {
+		return nil, err
+	}
+	buf, err = o.c.formatOutput("", buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ProcessDocuments reads multi-document yaml (or json) text from r, and calls fn
+// for each parsed document in order. fn can inspect, filter or transform the document,
+// and emit it with WriteDocument. doc is valid only in fn call.
+func (o *Options) ProcessDocuments(r io.Reader, fn func(doc *Document) error) error {
+	globalsortmutex.Lock()
+	defer globalsortmutex.Unlock()
+	globalpriorkeys = o.c.priorkeys
+	return o.c.processDocuments(r, fn)
+}
+
+// WriteDocument writes sorted text of doc (with "---" and header comment) to w.
+// it is called in fn of ProcessDocuments.
+func (o *Options) WriteDocument(w io.Writer, doc *Document) error {
+	return o.c.writeDocument(w, doc)
+}
+
+// Version returns version of yamlsort , like "v0.1.14". it is version of command (set by Main) ,
+// or version of yamlsort module in build info. empty for development build.
+func Version() string {
+	if len(version) > 0 {
+		return version
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	modules := append([]*debug.Module{&info.Main}, info.Deps...)
+	for _, m := range modules {
+		if m.Path == modulePath && m.Version != "(devel)" {
+			return m.Version
+		}
+	}
+	return ""
+}
@@ -0,0 +1,62 @@
+//
+// yamlsort - partial formatting of line range
+//
+// with --start-line and --end-line , only documents (--- ... ---) which contain
+// the lines are sorted. other lines are written as is.
+// editors use it for "format selection".
+//
+package yamlsort
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// check --start-line and --end-line
+func checkLineRange(startline int, endline int) error {
+	if startline < 0 || endline < 0 {
+		return fmt.Errorf("--start-line and --end-line must be positive line numbers")
+	}
+	if startline > 0 && endline > 0 && startline > endline {
+		return fmt.Errorf("--start-line %d is after --end-line %d", startline, endline)
+	}
+	return nil
+}
+
+// sort documents which contain lines from --start-line until --end-line
+func (c *yamlsortCmd) sortLineRange(input []byte, progress *progressReporter) (*bytes.Buffer, error) {
+	lines := strings.SplitAfter(string(input), "\n")
+	numlines := len(lines)
+	if len(lines[numlines-1]) == 0 {
+		// no line after last newline
+		numlines--
+	}
+	if c.startline > numlines {
+		return nil, fmt.Errorf("--start-line %d is after last line %d", c.startline, numlines)
+	}
+	if c.endline > numlines {
+		return nil, fmt.Errorf("--end-line %d is after last line %d", c.endline, numlines)
+	}
+	from := 0
+	if c.startline > 0 {
+		from = c.startline - 1
+	}
+	to := len(lines) - 1
+	if c.endline > 0 {
+		to = c.endline - 1
+	}
+	start, end := documentRange(lines, from, to)
+	c.blnPartialInput = start > 0
+	defer func() { c.blnPartialInput = false }()
+	output, err := c.sortDocuments([]byte(strings.Join(lines[start:end], "")), progress)
+	if err != nil {
+		return nil, err
+	}
+
+	result := new(bytes.Buffer)
+	result.WriteString(strings.Join(lines[:start], ""))
+	result.Write(output.Bytes())
+	result.WriteString(strings.Join(lines[end:], ""))
+	return result, nil
+}
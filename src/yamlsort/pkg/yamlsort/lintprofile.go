@@ -0,0 +1,171 @@
+//
+// yamlsort - lint compatible output profile
+//   --lint-profile=yamllint-default , --lint-profile=prettier
+//
+// output of profile passes the default rules of yamllint.
+//   document-start  "---" is always written
+//   indentation     sequences are indented (same as --array-indent-plus-2)
+//   comments        "#comment" is written as "# comment" (same as --comment-space)
+//   truthy          strings like yes , Off , y are quoted. keys too.
+//   line-length     long strings are moved to next line or folded within 80 columns
+//   empty-lines     no blank line at end of file
+//
+// output of prettier profile is not changed by prettier (yaml parser).
+//   indentation     sequences are indented
+//   quoting         double quote is preferred , unless the string has more " than '
+//   empty values    [] and {} are written after key
+//   blank lines     no blank line between documents and at end of file
+//
+package yamlsort
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// names of --lint-profile
+var lintProfiles = map[string]func(c *yamlsortCmd){
+	"yamllint-default": func(c *yamlsortCmd) {
+		c.blnArrayIndentPlus2 = true
+		c.blnCommentSpace = true
+		c.blnQuoteTruthy = true
+		c.blnFlowEmptyList = true
+		c.blnNoTrailingBlank = true
+		c.linewidth = 80
+	},
+	"prettier": func(c *yamlsortCmd) {
+		c.blnArrayIndentPlus2 = true
+		c.blnDoubleQuote = true
+		c.blnFlowEmptyList = true
+		c.blnFlowEmptyMap = true
+		c.blnNoDocumentBlank = true
+		c.blnNoTrailingBlank = true
+	},
+}
+
+// YAML 1.1 boolean words. yamllint truthy rule reports them except true , false.
+var truthyWords = map[string]bool{
+	"y": true, "Y": true, "yes": true, "Yes": true, "YES": true,
+	"n": true, "N": true, "no": true, "No": true, "NO": true,
+	"true": true, "True": true, "TRUE": true,
+	"false": true, "False": true, "FALSE": true,
+	"on": true, "On": true, "ON": true,
+	"off": true, "Off": true, "OFF": true,
+}
+
+// set output options of --lint-profile
+func (c *yamlsortCmd) applyLintProfile() error {
+	if len(c.lintprofile) == 0 {
+		return nil
+	}
+	profile, ok := lintProfiles[c.lintprofile]
+	if !ok {
+		names := []string{}
+		for name := range lintProfiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown lint profile %q. (%s)", c.lintprofile, strings.Join(names, " , "))
+	}
+	profile(c)
+	return nil
+}
+
+// map key in yaml output. truthy key like "on" , and key which can not be plain scalar are quoted.
+func (c *yamlsortCmd) escapeKey(key string) string {
+	if c.blnQuoteTruthy && truthyWords[key] {
+		return "'" + key + "'"
+	}
+	if c.emissionRules().quoteKeys && !isPlainKey(key) {
+		if strings.ContainsAny(key, "\n\t\r") {
+			return "\"" + escapeDoubleQuoted(key) + "\""
+		}
+		return "'" + strings.Replace(key, "'", "''", -1) + "'"
+	}
+	return key
+}
+
+// key can be written as plain scalar. it does not start with indicator , and does not contain ": " or " #".
+func isPlainKey(key string) bool {
+	if len(key) == 0 || strings.TrimSpace(key) != key || strings.ContainsAny(key, "\n\t\r") {
+		return false
+	}
+	if strings.ContainsAny(key[:1], "@#`!&*|>%[]{},'\"") || strings.HasPrefix(key, "- ") || strings.HasPrefix(key, "? ") || strings.HasPrefix(key, ": ") {
+		return false
+	}
+	return !strings.Contains(key, ": ") && !strings.Contains(key, " #") && !strings.HasSuffix(key, ":")
+}
+
+// write string value , which starts at column. return false when it fits in line width.
+// long value is written in next line with indent , and folded at spaces.
+func (c *yamlsortCmd) writeLongString(writer *bytes.Buffer, column int, indent int, value string) bool {
+	escaped := c.escapeString(value)
+	if c.linewidth <= 0 || column+len(escaped) <= c.linewidth {
+		return false
+	}
+	breaks := foldPoints(value)
+	if len(breaks) == 0 {
+		// non-breakable word. yamllint allows it in own line.
+		writer.WriteString("\n")
+		writer.WriteString(c.indentstr(indent))
+		writer.WriteString(escaped)
+		writer.WriteString("\n")
+		return true
+	}
+	// double quoted string. line break between words is folded into one space.
+	writer.WriteString("\n")
+	writer.WriteString(c.indentstr(indent))
+	writer.WriteString("\"")
+	width := indent + 1
+	start := 0
+	for i, pos := range append(breaks, len(value)) {
+		word := escapeDoubleQuoted(value[start:pos])
+		if i > 0 {
+			if width+1+len(word) > c.linewidth {
+				writer.WriteString("\n")
+				writer.WriteString(c.indentstr(indent))
+				width = indent
+			} else {
+				writer.WriteString(" ")
+				width++
+			}
+		}
+		writer.WriteString(word)
+		width += len(word)
+		start = pos + 1
+	}
+	writer.WriteString("\"\n")
+	return true
+}
+
+// positions of single spaces between words. the string can be folded at them.
+func foldPoints(value string) []int {
+	result := []int{}
+	for i := 1; i < len(value)-1; i++ {
+		if value[i] == ' ' && value[i-1] != ' ' && value[i+1] != ' ' {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+// contents of "..." string
+func escapeDoubleQuoted(value string) string {
+	result := value
+	result = strings.Replace(result, "\\", "\\\\", -1)
+	result = strings.Replace(result, "\"", "\\\"", -1)
+	result = strings.Replace(result, "\t", "\\t", -1)
+	result = strings.Replace(result, "\n", "\\n", -1)
+	result = strings.Replace(result, "\r", "\\r", -1)
+	return result
+}
+
+// header comment line of document. "# powered by ..." goes to next line when it is too long.
+func (c *yamlsortCmd) headerLine(firstlinestr string, powered string) string {
+	if c.linewidth > 0 && len(firstlinestr)+len(powered) > c.linewidth && len(strings.TrimSpace(firstlinestr)) > 0 {
+		return strings.TrimRight(firstlinestr, " ") + "\n" + powered
+	}
+	return firstlinestr + powered
+}
@@ -0,0 +1,273 @@
+//
+// yamlsort - list merge strategies of git-merge
+//
+// lists are one value in 3-way merge by default , so changes of both sides conflict.
+// strategy of lists at path can be specified , because no single rule suits all documents.
+//   yamlsort git-merge --list-strategy 'spec.template.spec.containers=merge-by-key=name' %O %A %B
+//   yamlsort git-merge --list-strategy-file strategies.yaml %O %A %B
+// strategies
+//   replace              list is one value (default)
+//   append               elements added by other side are appended to current list
+//   union                set merge. elements added by either side are kept , and removed by either side are removed
+//   merge-by-key=field   maps are matched by field , and merged one by one. order of current comes first.
+// path can have wildcards , like spec.*.ports or items[*].env. strategy file is yaml map of path and strategy.
+//   spec.template.spec.containers: merge-by-key=name
+//   metadata.finalizers: union
+//
+package yamlsort
+
+import (
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// kind of list strategy
+const (
+	listReplace = iota
+	listAppend
+	listUnion
+	listMergeByKey
+)
+
+// strategy of lists at path
+type listStrategy struct {
+	segs []pathSegment
+	kind int
+	key  string // listMergeByKey
+}
+
+// parse "strategy" of path
+func parseListStrategy(path string, strategy string) (listStrategy, error) {
+	segs, err := parsePath(path)
+	if err != nil || len(path) == 0 {
+		return listStrategy{}, fmt.Errorf("invalid path of list strategy %q", path)
+	}
+	result := listStrategy{segs: segs}
+	switch {
+	case strategy == "replace":
+		result.kind = listReplace
+	case strategy == "append":
+		result.kind = listAppend
+	case strategy == "union":
+		result.kind = listUnion
+	case strings.HasPrefix(strategy, "merge-by-key=") && len(strategy) > len("merge-by-key="):
+		result.kind = listMergeByKey
+		result.key = strings.TrimPrefix(strategy, "merge-by-key=")
+	default:
+		return listStrategy{}, fmt.Errorf("unknown list strategy %q of %s , replace , append , union or merge-by-key=field", strategy, path)
+	}
+	return result, nil
+}
+
+// strategies of --list-strategy-file and --list-strategy. later one wins.
+func (c *yamlsortCmd) listStrategies() ([]listStrategy, error) {
+	result := []listStrategy{}
+	if len(c.liststrategyfile) > 0 {
+		input, err := ioutil.ReadFile(c.liststrategyfile)
+		if err != nil {
+			return nil, err
+		}
+		strategies := map[string]string{}
+		err = yaml.Unmarshal(input, &strategies)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", c.liststrategyfile, err)
+		}
+		paths := make([]string, 0, len(strategies))
+		for path := range strategies {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			strategy, err := parseListStrategy(path, strategies[path])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", c.liststrategyfile, err)
+			}
+			result = append(result, strategy)
+		}
+	}
+	for _, arg := range c.liststrategyargs {
+		idx := strings.Index(arg, "=")
+		if idx < 0 {
+			return nil, fmt.Errorf("--list-strategy requires path=strategy , but %q", arg)
+		}
+		strategy, err := parseListStrategy(arg[:idx], arg[idx+1:])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, strategy)
+	}
+	return result, nil
+}
+
+// path matches pattern of strategy
+func matchPathPattern(pattern []pathSegment, segs []pathSegment) bool {
+	if len(pattern) != len(segs) {
+		return false
+	}
+	for i, p := range pattern {
+		s := segs[i]
+		switch p.kind {
+		case segWildcardKey:
+			if s.kind != segKey {
+				return false
+			}
+		case segWildcardIndex:
+			if s.kind != segIndex && s.kind != segMatch {
+				return false
+			}
+		default:
+			if p != s {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// strategy of lists at path. nil for replace.
+func (m *merger) listStrategy(segs []pathSegment) *listStrategy {
+	var result *listStrategy
+	for i := range m.strategies {
+		if matchPathPattern(m.strategies[i].segs, segs) {
+			result = &m.strategies[i]
+		}
+	}
+	if result != nil && result.kind == listReplace {
+		return nil
+	}
+	return result
+}
+
+// index of element in list , or -1
+func indexOfElement(list []interface{}, v interface{}) int {
+	for i, e := range list {
+		if reflect.DeepEqual(e, v) {
+			return i
+		}
+	}
+	return -1
+}
+
+// 3-way merge of lists with strategy
+func (m *merger) mergeList(strategy *listStrategy, base interface{}, current []interface{}, other []interface{}, segs []pathSegment) (interface{}, interface{}, int) {
+	bl, _ := base.([]interface{})
+	switch strategy.kind {
+	case listAppend:
+		result := append([]interface{}{}, current...)
+		for _, v := range other {
+			if indexOfElement(bl, v) < 0 && indexOfElement(current, v) < 0 {
+				result = append(result, v)
+			}
+		}
+		return result, result, 0
+	case listUnion:
+		result := []interface{}{}
+		for _, list := range [][]interface{}{current, other} {
+			for _, v := range list {
+				if indexOfElement(result, v) >= 0 {
+					continue
+				}
+				// removed by either side
+				if indexOfElement(bl, v) >= 0 && (indexOfElement(current, v) < 0 || indexOfElement(other, v) < 0) {
+					continue
+				}
+				result = append(result, v)
+			}
+		}
+		return result, result, 0
+	}
+	return m.mergeByKey(strategy.key, bl, current, other, segs)
+}
+
+// key value of list element , for merge-by-key
+func elementKey(v interface{}, key string) (string, bool) {
+	element, ok := v.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	value, ok := element[key]
+	if !ok || value == nil {
+		return "", false
+	}
+	if _, ok := value.(map[string]interface{}); ok {
+		return "", false
+	}
+	return fmt.Sprint(value), true
+}
+
+// elements of list by key. ok is false , when some element has no key or key is duplicated.
+func elementsByKey(list []interface{}, key string) ([]string, map[string]interface{}, bool) {
+	keys := []string{}
+	elements := map[string]interface{}{}
+	for _, v := range list {
+		k, ok := elementKey(v, key)
+		if !ok {
+			return nil, nil, false
+		}
+		if _, ok := elements[k]; ok {
+			return nil, nil, false
+		}
+		keys = append(keys, k)
+		elements[k] = v
+	}
+	return keys, elements, true
+}
+
+// merge elements which have same key. keys of current come first , and new keys of other follow.
+func (m *merger) mergeByKey(key string, base []interface{}, current []interface{}, other []interface{}, segs []pathSegment) (interface{}, interface{}, int) {
+	_, bm, ok1 := elementsByKey(base, key)
+	ckeys, cm, ok2 := elementsByKey(current, key)
+	okeys, om, ok3 := elementsByKey(other, key)
+	if !ok1 || !ok2 || !ok3 {
+		// list is one value
+		if v, _, ok := m.resolveConflict(segs, base, current, true, other, true); ok {
+			return v, v, 0
+		}
+		return current, other, 1
+	}
+	keylist := append([]string{}, ckeys...)
+	for _, k := range okeys {
+		if _, ok := cm[k]; !ok {
+			keylist = append(keylist, k)
+		}
+	}
+	resultcurrent := []interface{}{}
+	resultother := []interface{}{}
+	conflicts := 0
+	for _, k := range keylist {
+		childsegs := append(append([]pathSegment{}, segs...), pathSegment{kind: segMatch, key: key, matchvalue: k})
+		bv, bok := bm[k]
+		cv, cok := cm[k]
+		ov, ook := om[k]
+		if cok == ook && reflect.DeepEqual(cv, ov) {
+			// same change
+		} else if bok == ook && reflect.DeepEqual(bv, ov) {
+			// only current changed
+			ov, ook = cv, cok
+		} else if bok == cok && reflect.DeepEqual(bv, cv) {
+			// only other changed
+			cv, cok = ov, ook
+		} else if cok && ook {
+			var n int
+			cv, ov, n = m.merge3(bv, cv, ov, childsegs)
+			conflicts += n
+		} else if v, ok, resolved := m.resolveConflict(childsegs, bv, cv, cok, ov, ook); resolved {
+			// deleted in one side , and changed in other side
+			cv, cok, ov, ook = v, ok, v, ok
+		} else {
+			conflicts++
+		}
+		if cok {
+			resultcurrent = append(resultcurrent, cv)
+		}
+		if ook {
+			resultother = append(resultother, ov)
+		}
+	}
+	return resultcurrent, resultother, conflicts
+}
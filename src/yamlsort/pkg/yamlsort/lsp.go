@@ -0,0 +1,308 @@
+//
+// yamlsort - lsp subcommand
+//
+// language server on stdin/stdout. editors use yamlsort as yaml formatter.
+//   textDocument/formatting       sort whole text
+//   textDocument/rangeFormatting  sort documents (--- ... ---) in range
+// edits have no header comment , and no "---" and blank line which are not in text.
+//
+package yamlsort
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/spf13/cobra"
+)
+
+// json-rpc request or notification
+type lspMessage struct {
+	ID     *json.RawMessage `json:"id"`
+	Method string           `json:"method"`
+	Params json.RawMessage  `json:"params"`
+}
+
+type lspError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+type lspTextEdit struct {
+	Range   lspRange `json:"range"`
+	NewText string   `json:"newText"`
+}
+
+type lspTextDocument struct {
+	URI     string `json:"uri"`
+	Text    string `json:"text"`
+	Version int    `json:"version"`
+}
+
+type lspParams struct {
+	TextDocument   lspTextDocument `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+	Range lspRange `json:"range"`
+}
+
+// json-rpc error codes
+const (
+	lspMethodNotFound = -32601
+	lspInternalError  = -32603
+)
+
+//---------------------------------------------------------------------
+//  lspServer class
+//
+type lspServer struct {
+	yamlsort  *yamlsortCmd
+	reader    *bufio.Reader
+	writer    io.Writer
+	documents map[string]string
+	shutdown  bool
+}
+
+func newLspCmd(yamlsort *yamlsortCmd) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lsp",
+		Short: "run language server (textDocument/formatting) on stdin/stdout",
+		RunE: func(c *cobra.Command, args []string) error {
+			// edits of editor have no "# powered by" comment
+			yamlsort.blnNoHeader = true
+			err := yamlsort.prepareOptions()
+			if err != nil {
+				yamlsort.closeComparator()
+				return err
+			}
+			// comparator plugin of --preset runs until server exits
+			defer yamlsort.closeComparator()
+			server := &lspServer{
+				yamlsort:  yamlsort,
+				reader:    bufio.NewReader(yamlsort.stdin),
+				writer:    yamlsort.stdout,
+				documents: map[string]string{},
+			}
+			return server.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringArrayVar(&yamlsort.priorkeys, "key", []string{}, "set prior key name in sort. default prior key is name")
+	f.StringArrayVar(&yamlsort.presets, "preset", []string{}, "use preset plugin yamlsort-preset-<name> on PATH")
+	f.StringVar(&yamlsort.lintprofile, "lint-profile", "", "output yaml which passes linter rules. (yamllint-default , prettier)")
+
+	return cmd
+}
+
+func (s *lspServer) run() error {
+	for {
+		msg, err := s.readMessage()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return fmt.Errorf("lsp: exit without shutdown")
+			}
+			return nil
+		}
+		result, rpcerr := s.handle(msg)
+		if msg.ID == nil {
+			// notification has no response
+			continue
+		}
+		err = s.writeResponse(msg.ID, result, rpcerr)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *lspServer) handle(msg *lspMessage) (interface{}, *lspError) {
+	var params lspParams
+	if len(msg.Params) > 0 {
+		json.Unmarshal(msg.Params, &params)
+	}
+	switch msg.Method {
+	case "initialize":
+		return map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync":                1, // full text
+				"documentFormattingProvider":      true,
+				"documentRangeFormattingProvider": true,
+			},
+			"serverInfo": map[string]string{"name": "yamlsort", "version": s.yamlsort.version},
+		}, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		s.documents[params.TextDocument.URI] = params.TextDocument.Text
+	case "textDocument/didChange":
+		if n := len(params.ContentChanges); n > 0 {
+			s.documents[params.TextDocument.URI] = params.ContentChanges[n-1].Text
+		}
+	case "textDocument/didClose":
+		delete(s.documents, params.TextDocument.URI)
+	case "textDocument/formatting":
+		text := s.documents[params.TextDocument.URI]
+		lines := strings.SplitAfter(text, "\n")
+		return s.formatLines(lines, 0, len(lines))
+	case "textDocument/rangeFormatting":
+		text := s.documents[params.TextDocument.URI]
+		lines := strings.SplitAfter(text, "\n")
+		start, end := documentRange(lines, params.Range.Start.Line, params.Range.End.Line)
+		return s.formatLines(lines, start, end)
+	default:
+		if msg.ID != nil && !strings.HasPrefix(msg.Method, "$/") {
+			return nil, &lspError{Code: lspMethodNotFound, Message: "method not found: " + msg.Method}
+		}
+	}
+	return nil, nil
+}
+
+// sort lines[start:end] , and return text edits
+func (s *lspServer) formatLines(lines []string, start int, end int) (interface{}, *lspError) {
+	text := strings.Join(lines[start:end], "")
+	output, err := s.yamlsort.sortBytes([]byte(text), nil)
+	if err != nil {
+		return nil, &lspError{Code: lspInternalError, Message: err.Error()}
+	}
+	newtext := lspEditText(text, output.String())
+	if newtext == text {
+		return []lspTextEdit{}, nil
+	}
+	return []lspTextEdit{{
+		Range: lspRange{
+			Start: lspPosition{Line: start},
+			End:   endPosition(lines, end),
+		},
+		NewText: newtext,
+	}}, nil
+}
+
+// sorted output as replacement of text. "---" at top is removed when text has no "---" at top ,
+// and text ends with same newline as before (blank line after last document is removed).
+func lspEditText(text string, output string) string {
+	firstline := strings.TrimRight(strings.SplitN(text, "\n", 2)[0], "\r")
+	if marker, _ := documentMarker([]byte(firstline)); marker != startMarker && !strings.HasPrefix(firstline, "%") {
+		output = strings.TrimPrefix(output, "---\n")
+	}
+	output = strings.TrimRight(output, "\n")
+	if strings.HasSuffix(text, "\n") {
+		output = output + "\n"
+	}
+	return output
+}
+
+// lines of documents (--- ... ---) which contain from line to line
+func documentRange(lines []string, from int, to int) (int, int) {
+	starts := []int{0}
+	for i, line := range lines {
+		if marker, _ := documentMarker([]byte(strings.TrimRight(line, "\r\n"))); marker == startMarker && i > 0 {
+			// directives belong to next document
+			start := i
+			for start > 0 && strings.HasPrefix(lines[start-1], "%") {
+				start--
+			}
+			starts = append(starts, start)
+		}
+	}
+	start := 0
+	end := len(lines)
+	for _, pos := range starts {
+		if pos <= from {
+			start = pos
+		}
+		if pos > to && end == len(lines) {
+			end = pos
+		}
+	}
+	return start, end
+}
+
+// position after lines[:end]. character is counted in utf-16.
+func endPosition(lines []string, end int) lspPosition {
+	if end == 0 {
+		return lspPosition{}
+	}
+	last := lines[end-1]
+	if strings.HasSuffix(last, "\n") {
+		return lspPosition{Line: end}
+	}
+	return lspPosition{Line: end - 1, Character: len(utf16.Encode([]rune(last)))}
+}
+
+// read one message with Content-Length header
+func (s *lspServer) readMessage() (*lspMessage, error) {
+	length := -1
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if len(line) == 0 {
+			break
+		}
+		if strings.HasPrefix(strings.ToLower(line), "content-length:") {
+			length, err = strconv.Atoi(strings.TrimSpace(line[len("content-length:"):]))
+			if err != nil {
+				return nil, fmt.Errorf("lsp: invalid header %q", line)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("lsp: Content-Length header is not found")
+	}
+	body := make([]byte, length)
+	_, err := io.ReadFull(s.reader, body)
+	if err != nil {
+		return nil, err
+	}
+	msg := &lspMessage{}
+	err = json.Unmarshal(body, msg)
+	if err != nil {
+		return nil, fmt.Errorf("lsp: %v", err)
+	}
+	return msg, nil
+}
+
+// write response with result or error
+func (s *lspServer) writeResponse(id *json.RawMessage, result interface{}, rpcerr *lspError) error {
+	msg := map[string]interface{}{"jsonrpc": "2.0", "id": id}
+	if rpcerr != nil {
+		msg["error"] = rpcerr
+	} else {
+		msg["result"] = result
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	buffer := new(bytes.Buffer)
+	fmt.Fprintf(buffer, "Content-Length: %d\r\n\r\n", len(body))
+	buffer.Write(body)
+	_, err = s.writer.Write(buffer.Bytes())
+	return err
+}
@@ -0,0 +1,372 @@
+//
+// yamlsort - git-merge subcommand
+//
+// git merge driver , which merges yaml structurally.
+//   .gitattributes   values*.yaml merge=yamlsort
+//   git config merge.yamlsort.driver "yamlsort git-merge %O %A %B"
+//
+// map keys are merged one by one. when both sides change same value ,
+// it is a conflict and conflict markers are written around differing lines.
+// when documents can not be merged structurally , or they have comments (which structural
+// merge can not keep) , git merge-file is used. header comment is not written.
+//   --prefer ours|theirs   conflict is resolved with value of current (ours) or other (theirs)
+//   --interactive          ask ours / theirs / edit / skip for each conflict on terminal.
+//                          empty answer is --prefer side.
+//   --list-strategy path=strategy   merge lists at path (wildcard can be used) with strategy.
+//                          see liststrategy.go. other lists are one value.
+//
+package yamlsort
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+)
+
+// resolve conflict at path. value and existence of key in current and other are given.
+// return merged value , existence of key , and whether conflict is resolved.
+type mergeResolver func(segs []pathSegment, base interface{}, current interface{}, cok bool, other interface{}, ook bool) (interface{}, bool, bool)
+
+//---------------------------------------------------------------------
+//  git-merge subcommand
+//
+func newGitMergeCmd(yamlsort *yamlsortCmd) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "git-merge base current other",
+		Short: "git merge driver (%O %A %B). merge yaml structurally and write into current",
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) != 3 {
+				return fmt.Errorf("git-merge requires base , current and other file names")
+			}
+			return yamlsort.gitMerge(args[0], args[1], args[2])
+		},
+	}
+	// conflict is not usage error
+	cmd.SilenceUsage = true
+
+	f := cmd.Flags()
+	f.StringVar(&yamlsort.mergeprefer, "prefer", "", "resolve conflicts with value of ours (current) or theirs (other)")
+	f.BoolVar(&yamlsort.blnMergeInteractive, "interactive", false, "ask how to resolve each conflict on terminal")
+	f.StringArrayVar(&yamlsort.liststrategyargs, "list-strategy", []string{}, "merge strategy of lists at path , like spec.containers=merge-by-key=name (can specify multiple times)")
+	f.StringVar(&yamlsort.liststrategyfile, "list-strategy-file", "", "yaml file of list strategies (path: strategy)")
+
+	return cmd
+}
+
+func (c *yamlsortCmd) gitMerge(basefilename string, currentfilename string, otherfilename string) error {
+	c.blnNoHeader = true
+	c.maxlinesize = defaultMaxLineSize
+	err := c.prepareOptions()
+	if err != nil {
+		return err
+	}
+	resolve, closer, err := c.mergeResolver()
+	if err != nil {
+		return err
+	}
+	defer closer()
+	strategies, err := c.listStrategies()
+	if err != nil {
+		return err
+	}
+	m := &merger{resolve: resolve, strategies: strategies}
+	bases, err1 := c.readDocuments(basefilename)
+	currents, err2 := c.readDocuments(currentfilename)
+	others, err3 := c.readDocuments(otherfilename)
+	if err1 != nil || err2 != nil || err3 != nil || len(currents) != len(others) || (len(bases) != len(currents) && len(bases) > 0) {
+		// not mergeable structurally
+		return gitMergeFile(basefilename, currentfilename, otherfilename)
+	}
+	for _, filename := range []string{basefilename, currentfilename, otherfilename} {
+		if fileHasComments(filename) {
+			return gitMergeFile(basefilename, currentfilename, otherfilename)
+		}
+	}
+
+	ours := new(bytes.Buffer)
+	theirs := new(bytes.Buffer)
+	conflicts := 0
+	for i := range currents {
+		var base interface{}
+		if len(bases) > 0 {
+			base = bases[i].Data
+		}
+		a, b, n := m.merge3(base, currents[i].Data, others[i].Data, nil)
+		conflicts += n
+		doc := currents[i]
+		doc.Data = a
+		err := c.writeDocument(ours, doc)
+		if err != nil {
+			return err
+		}
+		doc.Data = b
+		err = c.writeDocument(theirs, doc)
+		if err != nil {
+			return err
+		}
+	}
+
+	output := ours.Bytes()
+	if conflicts > 0 {
+		output = conflictMarkers(ours.String(), theirs.String())
+	}
+	err = atomicWriteFile(currentfilename, output, 0644)
+	if err != nil {
+		return err
+	}
+	if conflicts > 0 {
+		return fmt.Errorf("merge conflict in %s (%d values)", currentfilename, conflicts)
+	}
+	return nil
+}
+
+// read all documents of file
+func (c *yamlsortCmd) readDocuments(filename string) ([]*Document, error) {
+	input, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	result := []*Document{}
+	err = c.processDocuments(bytes.NewReader(input), func(doc *Document) error {
+		if doc.Data == nil && doc.raw.isEmpty() {
+			return nil
+		}
+		// raw data is reused by next document
+		doc.raw.directives = append([]string{}, doc.raw.directives...)
+		doc.raw.data = append([]byte{}, doc.raw.data...)
+		result = append(result, doc)
+		return nil
+	})
+	return result, err
+}
+
+// file has comment lines or inline comments. header comment of yamlsort
+// ("# powered by ...") is not counted.
+func fileHasComments(filename string) bool {
+	input, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(input), "\n") {
+		if _, comment := splitQuotedText(line); comment && !strings.Contains(line, "# powered by ") {
+			return true
+		}
+	}
+	return false
+}
+
+//---------------------------------------------------------------------
+//  merger class
+// 3-way merge with conflict resolver and list strategies
+//
+type merger struct {
+	resolve    mergeResolver  // nil when conflicts are kept
+	strategies []listStrategy // --list-strategy
+}
+
+// 3-way merge. return merged data of current side and other side , and number of conflicts.
+// they are same when there is no conflict. resolver is called for each conflict.
+func (m *merger) merge3(base interface{}, current interface{}, other interface{}, segs []pathSegment) (interface{}, interface{}, int) {
+	if reflect.DeepEqual(current, other) || reflect.DeepEqual(base, other) {
+		return current, current, 0
+	}
+	if reflect.DeepEqual(base, current) {
+		return other, other, 0
+	}
+	cm, ok1 := current.(map[string]interface{})
+	om, ok2 := other.(map[string]interface{})
+	if !ok1 || !ok2 {
+		cl, ok1 := current.([]interface{})
+		ol, ok2 := other.([]interface{})
+		if strategy := m.listStrategy(segs); ok1 && ok2 && strategy != nil {
+			return m.mergeList(strategy, base, cl, ol, segs)
+		}
+		if v, _, ok := m.resolveConflict(segs, base, current, true, other, true); ok {
+			return v, v, 0
+		}
+		return current, other, 1
+	}
+	bm, ok := base.(map[string]interface{})
+	if !ok {
+		bm = map[string]interface{}{}
+	}
+	keys := map[string]bool{}
+	for _, m := range []map[string]interface{}{bm, cm, om} {
+		for k := range m {
+			keys[k] = true
+		}
+	}
+	// conflicts are resolved in sorted key order
+	keylist := make([]string, 0, len(keys))
+	for k := range keys {
+		keylist = append(keylist, k)
+	}
+	sortKeys(keylist)
+	resultcurrent := map[string]interface{}{}
+	resultother := map[string]interface{}{}
+	conflicts := 0
+	for _, k := range keylist {
+		childsegs := append(append([]pathSegment{}, segs...), pathSegment{kind: segKey, key: k})
+		bv, bok := bm[k]
+		cv, cok := cm[k]
+		ov, ook := om[k]
+		if cok == ook && reflect.DeepEqual(cv, ov) {
+			// same change
+		} else if bok == ook && reflect.DeepEqual(bv, ov) {
+			// only current changed
+			ov, ook = cv, cok
+		} else if bok == cok && reflect.DeepEqual(bv, cv) {
+			// only other changed
+			cv, cok = ov, ook
+		} else if cok && ook {
+			var n int
+			cv, ov, n = m.merge3(bv, cv, ov, childsegs)
+			conflicts += n
+		} else if v, ok, resolved := m.resolveConflict(childsegs, bv, cv, cok, ov, ook); resolved {
+			// deleted in one side , and changed in other side
+			cv, cok, ov, ook = v, ok, v, ok
+		} else {
+			conflicts++
+		}
+		if cok {
+			resultcurrent[k] = cv
+		}
+		if ook {
+			resultother[k] = ov
+		}
+	}
+	return resultcurrent, resultother, conflicts
+}
+
+// call resolver , if it is given
+func (m *merger) resolveConflict(segs []pathSegment, base interface{}, current interface{}, cok bool, other interface{}, ook bool) (interface{}, bool, bool) {
+	if m.resolve == nil {
+		return nil, false, false
+	}
+	return m.resolve(segs, base, current, cok, other, ook)
+}
+
+// resolver of --prefer and --interactive. closer closes terminal.
+func (c *yamlsortCmd) mergeResolver() (mergeResolver, func(), error) {
+	closer := func() {}
+	switch c.mergeprefer {
+	case "", "ours", "theirs":
+	default:
+		return nil, closer, fmt.Errorf("unknown --prefer %q , ours or theirs", c.mergeprefer)
+	}
+	prefer := func(segs []pathSegment, base interface{}, current interface{}, cok bool, other interface{}, ook bool) (interface{}, bool, bool) {
+		if c.mergeprefer == "ours" {
+			return current, cok, true
+		}
+		return other, ook, true
+	}
+	if !c.blnMergeInteractive {
+		if len(c.mergeprefer) == 0 {
+			return nil, closer, nil
+		}
+		return prefer, closer, nil
+	}
+	// git merge driver has no stdin , so terminal is opened
+	tty, err := os.Open(terminalInputPath)
+	if err != nil {
+		return nil, closer, fmt.Errorf("--interactive requires terminal: %v", err)
+	}
+	reader := bufio.NewReader(tty)
+	resolve := func(segs []pathSegment, base interface{}, current interface{}, cok bool, other interface{}, ook bool) (interface{}, bool, bool) {
+		fmt.Fprintf(c.stderr, "conflict at %s\n", pathString(segs))
+		fmt.Fprintf(c.stderr, "  ours   : %s\n", conflictValueString(current, cok))
+		fmt.Fprintf(c.stderr, "  theirs : %s\n", conflictValueString(other, ook))
+		for {
+			fmt.Fprintf(c.stderr, "[o]urs , [t]heirs , [e]dit , [s]kip ? ")
+			line, err := reader.ReadString('\n')
+			if err != nil && len(line) == 0 {
+				// end of input. conflict is kept.
+				fmt.Fprintln(c.stderr)
+				return nil, false, false
+			}
+			answer := strings.TrimSpace(line)
+			if len(answer) == 0 && len(c.mergeprefer) > 0 {
+				return prefer(segs, base, current, cok, other, ook)
+			}
+			switch answer {
+			case "o", "ours":
+				return current, cok, true
+			case "t", "theirs":
+				return other, ook, true
+			case "s", "skip":
+				return nil, false, false
+			case "e", "edit":
+				fmt.Fprintf(c.stderr, "value (yaml flow style) : ")
+				line, _ = reader.ReadString('\n')
+				var v interface{}
+				if err := yaml.Unmarshal([]byte(line), &v); err != nil {
+					fmt.Fprintf(c.stderr, "invalid value: %v\n", err)
+					continue
+				}
+				return v, true, true
+			}
+		}
+	}
+	return resolve, func() { tty.Close() }, nil
+}
+
+// one line text of conflicting value
+func conflictValueString(value interface{}, exists bool) string {
+	if !exists {
+		return "(deleted)"
+	}
+	text, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(text)
+}
+
+// text with git style conflict markers around differing lines
+func conflictMarkers(current string, other string) []byte {
+	result := new(bytes.Buffer)
+	lines := diffLines(strings.SplitAfter(current, "\n"), strings.SplitAfter(other, "\n"))
+	for i := 0; i < len(lines); {
+		if lines[i].kind == ' ' {
+			result.WriteString(lines[i].text)
+			i++
+			continue
+		}
+		ours := new(bytes.Buffer)
+		theirs := new(bytes.Buffer)
+		for ; i < len(lines) && lines[i].kind != ' '; i++ {
+			if lines[i].kind == '-' {
+				ours.WriteString(lines[i].text)
+			} else {
+				theirs.WriteString(lines[i].text)
+			}
+		}
+		result.WriteString("<<<<<<< current\n")
+		result.Write(ours.Bytes())
+		result.WriteString("=======\n")
+		result.Write(theirs.Bytes())
+		result.WriteString(">>>>>>> other\n")
+	}
+	return result.Bytes()
+}
+
+// line based merge with git merge-file
+func gitMergeFile(basefilename string, currentfilename string, otherfilename string) error {
+	cmd := exec.Command("git", "merge-file", currentfilename, basefilename, otherfilename)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+	if err != nil {
+		return fmt.Errorf("merge conflict in %s (git merge-file: %v)", currentfilename, err)
+	}
+	return nil
+}
@@ -0,0 +1,299 @@
+//
+// yamlsort - --minimal option
+//
+// only reorder map keys , and keep text of every line as is.
+// quoting , scalar styles , comments and blank lines are preserved , so diff after
+// adopting yamlsort contains nothing but the reordering.
+//   comment and blank lines before key move with the key (comment lines at top of document stay).
+//   block scalar (| >) , flow ({ } [ ]) and multi-line values are not changed.
+//   list elements are not reordered , but keys in them are.
+// other output options (header , quoting , --jsonoutput ...) are not applied ,
+// except --comment-space , --comment-column and --drop-comment of comment lines and inline comments.
+//
+package yamlsort
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// one key of block map , with comment lines before it
+type minimalEntry struct {
+	key   string
+	lines []string // comment lines , key line , and value lines
+	head  int      // index of key line in lines
+	child bool     // value lines are block map or list
+}
+
+// check --minimal and other options
+func (c *yamlsortCmd) checkMinimal() error {
+	if !c.blnMinimal {
+		return nil
+	}
+	if c.blnNormalMarshal || c.blnJSONMarshal {
+		return fmt.Errorf("--minimal can not be used with --normal or --jsonoutput")
+	}
+	return nil
+}
+
+// reorder keys of all documents , keeping text of lines
+func (c *yamlsortCmd) sortMinimal(input []byte) (*bytes.Buffer, error) {
+	text := string(input)
+	nonewline := len(text) > 0 && !strings.HasSuffix(text, "\n")
+	if nonewline {
+		text += "\n"
+	}
+	lines := strings.SplitAfter(text, "\n")
+	lines = lines[:len(lines)-1]
+
+	result := new(bytes.Buffer)
+	start := 0
+	for i := 0; i <= len(lines); i++ {
+		if i < len(lines) {
+			marker, _ := documentMarker([]byte(strings.TrimRight(lines[i], "\r\n")))
+			if marker == noMarker && !strings.HasPrefix(lines[i], "%") {
+				continue
+			}
+		}
+		// document between markers and directives. comment lines at top of document stay.
+		body := start
+		for body < i && minimalIsComment(lines[body]) {
+			if line, keep := c.normalizeMinimalComment(lines[body]); keep {
+				result.WriteString(line)
+			}
+			body++
+		}
+		for _, line := range reorderNode(c.normalizeMinimalComments(lines[body:i])) {
+			result.WriteString(line)
+		}
+		if i < len(lines) {
+			result.WriteString(lines[i])
+		}
+		start = i + 1
+	}
+	if nonewline {
+		result.Truncate(result.Len() - 1)
+	}
+	return result, nil
+}
+
+// apply --comment-space , --comment-column and --drop-comment to lines of document.
+// lines of block scalar are not changed.
+func (c *yamlsortCmd) normalizeMinimalComments(lines []string) []string {
+	if !c.blnCommentSpace && c.commentcolumn <= 0 && c.dropcommentregexp == nil {
+		return lines
+	}
+	result := make([]string, 0, len(lines))
+	blockindent := -1
+	for _, line := range lines {
+		indent := minimalIndent(line)
+		if blockindent >= 0 {
+			if len(strings.TrimSpace(line)) == 0 || indent > blockindent {
+				result = append(result, line)
+				continue
+			}
+			blockindent = -1
+		}
+		line, keep := c.normalizeMinimalComment(line)
+		if !keep {
+			continue
+		}
+		result = append(result, line)
+		// value of key line or list item , like "key: |" or "- >-"
+		code, _ := scanQuotedText(strings.TrimRight(line, "\r\n"))
+		value := strings.TrimSpace(code)
+		for value == "-" || strings.HasPrefix(value, "- ") {
+			value = strings.TrimSpace(value[1:])
+		}
+		if _, _, v, ok := parseKeyLine(value); ok {
+			value = v
+		}
+		if isBlockScalarValue(value) {
+			blockindent = indent
+		}
+	}
+	return result
+}
+
+// normalize comment line , or inline comment of line. return false when comment line is dropped.
+func (c *yamlsortCmd) normalizeMinimalComment(line string) (string, bool) {
+	text := strings.TrimRight(line, "\r\n")
+	eol := line[len(text):]
+	if minimalIsComment(text) {
+		if len(strings.TrimSpace(text)) == 0 {
+			return line, true
+		}
+		comment, keep := c.normalizeComment(text)
+		return comment + eol, keep
+	}
+	_, idx := scanQuotedText(text)
+	if idx < 0 {
+		return line, true
+	}
+	code := strings.TrimRight(text[:idx], " \t")
+	comment, keep := c.normalizeComment(text[idx:])
+	if !keep {
+		// inline comment is dropped , and line is kept
+		return code + eol, true
+	}
+	// align inline comment to column. longer line keeps its spacing.
+	padding := text[len(code):idx]
+	if c.commentcolumn > len(code)+1 {
+		padding = strings.Repeat(" ", c.commentcolumn-len(code))
+	}
+	return code + padding + comment + eol, true
+}
+
+// line has no content (blank or comment)
+func minimalIsComment(line string) bool {
+	body := strings.TrimSpace(line)
+	return len(body) == 0 || body[0] == '#'
+}
+
+// indent of line
+func minimalIndent(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// line is element of block list , like "- a" or "-"
+func minimalIsListItem(line string) bool {
+	body := strings.TrimSpace(line)
+	return body == "-" || strings.HasPrefix(body, "- ")
+}
+
+// reorder block node (map or list). other nodes are returned as is.
+func reorderNode(lines []string) []string {
+	for _, line := range lines {
+		if minimalIsComment(line) {
+			continue
+		}
+		if minimalIsListItem(line) {
+			return reorderList(lines, minimalIndent(line))
+		}
+		if _, _, _, ok := parseKeyLine(strings.TrimRight(line, "\r\n")); ok {
+			return reorderMap(lines, minimalIndent(line))
+		}
+		break
+	}
+	return lines
+}
+
+// value of key line starts block map or list in next lines (empty , anchor or tag only)
+func minimalHasChild(value string) bool {
+	if len(value) == 0 || value[0] == '#' {
+		return true
+	}
+	fields := strings.Fields(value)
+	if (value[0] == '&' || value[0] == '!') && (len(fields) == 1 || fields[1][0] == '#') {
+		return true
+	}
+	return false
+}
+
+// reorder keys of block map at indent
+func reorderMap(lines []string, indent int) []string {
+	entries := []*minimalEntry{}
+	pending := []string{}
+	var current *minimalEntry
+	for _, line := range lines {
+		if minimalIsComment(line) {
+			pending = append(pending, line)
+			continue
+		}
+		lineindent := minimalIndent(line)
+		if lineindent > indent || (lineindent == indent && minimalIsListItem(line) && current != nil) {
+			// value of current key. list of key may be at same indent.
+			if current == nil {
+				return lines
+			}
+			current.lines = append(current.lines, pending...)
+			current.lines = append(current.lines, line)
+			pending = []string{}
+			continue
+		}
+		_, key, value, ok := parseKeyLine(strings.TrimRight(line, "\r\n"))
+		if lineindent < indent || !ok {
+			// unknown structure , like complex key
+			return lines
+		}
+		current = &minimalEntry{key: key, head: len(pending), child: minimalHasChild(value)}
+		current.lines = append(pending, line)
+		pending = []string{}
+		entries = append(entries, current)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return compairString(entries[i].key, entries[j].key)
+	})
+	result := make([]string, 0, len(lines))
+	for _, entry := range entries {
+		result = append(result, entry.lines[:entry.head+1]...)
+		value := entry.lines[entry.head+1:]
+		if entry.child {
+			value = reorderNode(value)
+		}
+		result = append(result, value...)
+	}
+	// comment lines after last key do not move
+	return append(result, pending...)
+}
+
+// reorder keys in elements of block list at indent. elements are not reordered.
+func reorderList(lines []string, indent int) []string {
+	result := make([]string, 0, len(lines))
+	item := []string{}
+	flush := func() {
+		if len(item) > 0 {
+			result = append(result, reorderListItem(item, indent)...)
+		}
+		item = []string{}
+	}
+	for _, line := range lines {
+		if !minimalIsComment(line) {
+			lineindent := minimalIndent(line)
+			if lineindent < indent || (lineindent == indent && !minimalIsListItem(line)) {
+				return lines
+			}
+			if lineindent == indent {
+				flush()
+			}
+		}
+		if len(item) == 0 && minimalIsComment(line) {
+			// comment between elements
+			result = append(result, line)
+			continue
+		}
+		item = append(item, line)
+	}
+	flush()
+	return result
+}
+
+// reorder keys of one list element. "- key: value" is map at indent + 2.
+func reorderListItem(item []string, indent int) []string {
+	first := item[0]
+	rest := strings.TrimLeft(first[indent+1:], " ")
+	if len(strings.TrimSpace(rest)) == 0 || rest[0] == '#' || rest[0] == '&' || rest[0] == '!' {
+		// "- " and value in next lines
+		if !minimalHasChild(strings.TrimSpace(rest)) {
+			return item
+		}
+		return append([]string{first}, reorderNode(item[1:])...)
+	}
+	if _, _, _, ok := parseKeyLine(strings.TrimRight(rest, "\r\n")); !ok {
+		return item
+	}
+	// replace "-" with space , reorder as map , and put "-" on first key line
+	dash := first[:minimalIndent(first[indent+1:])+indent+1]
+	lines := append([]string{strings.Repeat(" ", len(dash)) + rest}, item[1:]...)
+	lines = reorderMap(lines, len(dash))
+	for i, line := range lines {
+		if !minimalIsComment(line) {
+			lines[i] = dash + line[len(dash):]
+			break
+		}
+	}
+	return lines
+}
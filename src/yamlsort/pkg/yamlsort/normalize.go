@@ -0,0 +1,103 @@
+//
+// yamlsort - string value normalization
+//   --trim-space , --collapse-spaces , --expand-tabs
+//
+package yamlsort
+
+import (
+	"fmt"
+	"strings"
+)
+
+// normalize string values are enabled
+func (c *yamlsortCmd) hasStringNormalization() bool {
+	return c.blnTrimSpace || c.blnCollapseSpaces || c.expandtabs > 0
+}
+
+// check tab width of --expand-tabs
+func checkExpandTabs(width int) error {
+	if width < 0 {
+		return fmt.Errorf("--expand-tabs must be 0 or more , but %d", width)
+	}
+	return nil
+}
+
+// normalize all string values in data
+func (c *yamlsortCmd) normalizeStringsRecursive(data interface{}) interface{} {
+	if m, ok := data.(map[string]interface{}); ok {
+		for k, v := range m {
+			m[k] = c.normalizeStringsRecursive(v)
+		}
+		return m
+	} else if a, ok := data.([]interface{}); ok {
+		for i, v := range a {
+			a[i] = c.normalizeStringsRecursive(v)
+		}
+		return a
+	} else if s, ok := data.(string); ok {
+		return c.normalizeString(s)
+	}
+	return data
+}
+
+// normalize each line of string value
+func (c *yamlsortCmd) normalizeString(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if c.expandtabs > 0 {
+			line = expandTabs(line, c.expandtabs)
+		}
+		if c.blnCollapseSpaces {
+			line = collapseSpaces(line)
+		}
+		if c.blnTrimSpace {
+			line = strings.TrimRight(line, " \t\r")
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// replace tab with spaces to next tab stop
+func expandTabs(line string, width int) string {
+	if !strings.Contains(line, "\t") {
+		return line
+	}
+	result := new(strings.Builder)
+	column := 0
+	for _, r := range line {
+		if r == '\t' {
+			n := width - column%width
+			result.WriteString(strings.Repeat(" ", n))
+			column += n
+			continue
+		}
+		result.WriteRune(r)
+		column++
+	}
+	return result.String()
+}
+
+// collapse runs of spaces and tabs into one space. leading indent is kept.
+func collapseSpaces(line string) string {
+	body := strings.TrimLeft(line, " \t")
+	indent := line[:len(line)-len(body)]
+	result := new(strings.Builder)
+	result.WriteString(indent)
+	blnSpace := false
+	for _, r := range body {
+		if r == ' ' || r == '\t' {
+			blnSpace = true
+			continue
+		}
+		if blnSpace {
+			result.WriteByte(' ')
+			blnSpace = false
+		}
+		result.WriteRune(r)
+	}
+	if blnSpace {
+		result.WriteByte(' ')
+	}
+	return result.String()
+}
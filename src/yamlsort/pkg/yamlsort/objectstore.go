@@ -0,0 +1,76 @@
+//
+// yamlsort - cloud object storage input/output
+//
+// s3:// and gs:// URIs are accepted for --input-file , --output-file and --input-output-file.
+// objects are streamed through cloud CLI on PATH , so credentials are read from
+// standard environment variables and config files of the CLI. no temporary file is used.
+//   s3://bucket/key   aws s3 cp
+//   gs://bucket/key   gcloud storage cp (or gsutil cp)
+//
+package yamlsort
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// URI of cloud object storage
+func isObjectURI(name string) bool {
+	return strings.HasPrefix(name, "s3://") || strings.HasPrefix(name, "gs://")
+}
+
+// command line which copies src to dst. "-" is stdin or stdout.
+func objectCopyCommand(src string, dst string) (*exec.Cmd, error) {
+	uri := src
+	if uri == "-" {
+		uri = dst
+	}
+	if strings.HasPrefix(uri, "s3://") {
+		if _, err := exec.LookPath("aws"); err != nil {
+			return nil, fmt.Errorf("%s: aws command is required for s3:// URI", uri)
+		}
+		return exec.Command("aws", "s3", "cp", "--only-show-errors", src, dst), nil
+	}
+	if _, err := exec.LookPath("gcloud"); err == nil {
+		return exec.Command("gcloud", "storage", "cp", src, dst), nil
+	}
+	if _, err := exec.LookPath("gsutil"); err == nil {
+		return exec.Command("gsutil", "-q", "cp", src, dst), nil
+	}
+	return nil, fmt.Errorf("%s: gcloud or gsutil command is required for gs:// URI", uri)
+}
+
+// read object of URI
+func readObject(uri string) ([]byte, error) {
+	cmd, err := objectCopyCommand(uri, "-")
+	if err != nil {
+		return nil, err
+	}
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	err = cmd.Run()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v %s", uri, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// write data into object of URI
+func writeObject(uri string, data []byte) error {
+	cmd, err := objectCopyCommand("-", uri)
+	if err != nil {
+		return err
+	}
+	stderr := new(bytes.Buffer)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stderr = stderr
+	err = cmd.Run()
+	if err != nil {
+		return fmt.Errorf("%s: %v %s", uri, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+//
+// yamlsort - buffered output
+//
+// bufferedOutput is bufio.Writer with Close , which flushes buffer and closes underlying file.
+// writers are closed with Close (or closeOutput in defer) , so errors of last flush and close (like disk
+// full) are returned , and output is not truncated silently.
+//   out := newBufferedOutput(fp)
+//   defer closeOutput(out, &err)
+// output files (-o , --output-template) are replaced by default.
+//   --append        append documents to existing output file
+//   --no-overwrite  refuse to write output file , which exists already
+//   --tee           write output to stdout too (for next stage of pipe)
+// --clipboard-in and --clipboard-out use system clipboard (clipboard.go) instead of stdin and stdout.
+// --output-format converts sorted yaml into html (htmloutput.go) , hcl (hcloutput.go) or xml (xmloutput.go).
+//
+package yamlsort
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// values of --output-format
+const (
+	outputFormatYAML = "yaml"
+	outputFormatHTML = "html"
+	outputFormatHCL  = "hcl"
+	outputFormatXML  = "xml"
+)
+
+func checkOutputFormat(format string) error {
+	switch format {
+	case "", outputFormatYAML, outputFormatHTML, outputFormatHCL, outputFormatXML:
+		return nil
+	}
+	return fmt.Errorf("unknown --output-format %q. (yaml , html , hcl , xml)", format)
+}
+
+// output in --output-format. title is input file name.
+func (c *yamlsortCmd) formatOutput(title string, output *bytes.Buffer) (*bytes.Buffer, error) {
+	switch c.outputformat {
+	case outputFormatHTML:
+		if len(title) == 0 {
+			title = "stdin"
+		}
+		return bytes.NewBuffer(renderHTML(title, output.Bytes())), nil
+	case outputFormatHCL:
+		result, err := c.renderHCL(output.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		return bytes.NewBuffer(result), nil
+	case outputFormatXML:
+		result, err := c.renderXML(output.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		return bytes.NewBuffer(result), nil
+	}
+	return output, nil
+}
+
+//---------------------------------------------------------------------
+//  bufferedOutput class
+//
+type bufferedOutput struct {
+	*bufio.Writer
+	closer io.Closer // nil for writers which are not closed (stdout)
+	closed bool
+}
+
+// buffered writer of w. w is closed by Close , when it is io.Closer.
+func newBufferedOutput(w io.Writer) *bufferedOutput {
+	closer, _ := w.(io.Closer)
+	return &bufferedOutput{Writer: bufio.NewWriter(w), closer: closer}
+}
+
+// buffered writer of w , which does not close w (like stdout)
+func newBufferedStdout(w io.Writer) *bufferedOutput {
+	return &bufferedOutput{Writer: bufio.NewWriter(w)}
+}
+
+// flush buffer and close underlying writer. first error is returned. second Close does nothing.
+func (o *bufferedOutput) Close() error {
+	if o.closed {
+		return nil
+	}
+	o.closed = true
+	err := o.Flush()
+	if o.closer != nil {
+		if err2 := o.closer.Close(); err == nil {
+			err = err2
+		}
+	}
+	return err
+}
+
+// close output in defer , and set error of close into *err when *err is nil
+func closeOutput(o io.Closer, err *error) {
+	if err2 := o.Close(); *err == nil {
+		*err = err2
+	}
+}
+
+// buffer stdout while subcommand writes report. returned function restores stdout , and
+// sets error of writes and flush (like disk full) into *err when *err is nil.
+//   defer c.bufferStdout()(&err)
+func (c *yamlsortCmd) bufferStdout() func(*error) {
+	stdout := c.stdout
+	out := newBufferedStdout(stdout)
+	c.stdout = out
+	return func(err *error) {
+		c.stdout = stdout
+		closeOutput(out, err)
+	}
+}
+
+// check --append , --no-overwrite , --tee , clipboard and --output-format options with other options
+func (c *yamlsortCmd) checkOutputMode() error {
+	if c.blnClipboardIn && (len(c.inputfilename) > 0 || len(c.inputoutputfilename) > 0) {
+		return fmt.Errorf("--clipboard-in can not be used with -i or -f")
+	}
+	if c.blnClipboardOut && (len(c.outputfilename) > 0 || len(c.inputoutputfilename) > 0 || c.blnWrite) {
+		return fmt.Errorf("--clipboard-out can not be used with -o , -f or -w")
+	}
+	if c.outputformat == outputFormatHTML && (c.blnWrite || len(c.inputoutputfilename) > 0 || c.blnCheck || c.blnJSONMarshal) {
+		return fmt.Errorf("--output-format html can not be used with -w , -f , --check or --jsonoutput")
+	}
+	if c.blnTee && len(c.outputfilename) == 0 && len(c.inputoutputfilename) == 0 && !c.blnClipboardOut {
+		return fmt.Errorf("--tee requires -o , -f or --clipboard-out")
+	}
+	if c.blnAppend && c.blnNoOverwrite {
+		return fmt.Errorf("--append and --no-overwrite can not be used together")
+	}
+	if !c.blnAppend && !c.blnNoOverwrite {
+		return nil
+	}
+	if c.blnWrite || len(c.inputoutputfilename) > 0 {
+		return fmt.Errorf("--append and --no-overwrite can not be used with -w or -f")
+	}
+	if isObjectURI(c.outputfilename) {
+		return fmt.Errorf("%s: --append and --no-overwrite are not supported for object storage", c.outputfilename)
+	}
+	return nil
+}
+
+// write output file of -o or --output-template , with --append and --no-overwrite
+func (c *yamlsortCmd) writeOutputFile(filename string, output []byte) error {
+	if c.blnNoOverwrite {
+		if _, err := os.Lstat(filename); err == nil {
+			return fmt.Errorf("%s exists already. (--no-overwrite)", filename)
+		}
+	}
+	if c.blnAppend {
+		current, err := ioutil.ReadFile(filename)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if len(current) > 0 {
+			buf := bytes.NewBuffer(current)
+			if current[len(current)-1] != '\n' {
+				buf.WriteByte('\n')
+			}
+			buf.Write(output)
+			output = buf.Bytes()
+		}
+	}
+	// output file is replaced atomically , not truncated on interrupt
+	return atomicWriteFile(filename, output, 0644)
+}
@@ -0,0 +1,84 @@
+//
+// yamlsort - output path template of file arguments
+//
+// with --output-template , result of each file is written into the path ,
+// instead of stdout. directories of the path are created.
+//   yamlsort --jsonoutput --output-template '{{.Dir}}/{{.Name}}.json' configs/
+//   yamlsort --output-template 'sorted/{{.Path}}' configs/
+//
+// fields of template
+//   .Path  input file path          configs/app/values.yaml
+//   .Dir   directory of input file  configs/app
+//   .Base  file name                values.yaml
+//   .Name  file name without .Ext   values
+//   .Ext   extension               .yaml
+//
+package yamlsort
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// fields of --output-template
+type outputPathData struct {
+	Path string
+	Dir  string
+	Base string
+	Name string
+	Ext  string
+}
+
+// parse --output-template. nil when it is not set.
+func parseOutputTemplate(text string) (*template.Template, error) {
+	if len(text) == 0 {
+		return nil, nil
+	}
+	tmpl, err := template.New("output-template").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("--output-template: %v", err)
+	}
+	return tmpl, nil
+}
+
+// output path of input file
+func outputPath(tmpl *template.Template, filename string) (string, error) {
+	base := filepath.Base(filename)
+	ext := filepath.Ext(base)
+	data := outputPathData{
+		Path: filepath.ToSlash(filename),
+		Dir:  filepath.ToSlash(filepath.Dir(filename)),
+		Base: base,
+		Name: strings.TrimSuffix(base, ext),
+		Ext:  ext,
+	}
+	buf := new(bytes.Buffer)
+	err := tmpl.Execute(buf, data)
+	if err != nil {
+		return "", fmt.Errorf("--output-template: %v", err)
+	}
+	if len(buf.String()) == 0 {
+		return "", fmt.Errorf("--output-template: output path of %s is empty", filename)
+	}
+	return filepath.FromSlash(buf.String()), nil
+}
+
+// write output of input file into path of --output-template
+func (c *yamlsortCmd) writeOutputTemplate(filename string, output []byte) error {
+	path, err := outputPath(c.outputtmpl, filename)
+	if err != nil {
+		return err
+	}
+	if filepath.Clean(path) == filepath.Clean(filename) {
+		return fmt.Errorf("--output-template path %s is same as input file (use -w to write in place)", path)
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+	return c.writeOutputFile(path, output)
+}
@@ -0,0 +1,226 @@
+//
+// yamlsort - overlay subcommand
+//
+// apply ordered stack of overlay directories to base directory , and output rendered
+// documents sorted. layering without full kustomize.
+//   yamlsort overlay base/ overlays/staging/ overlays/prod/
+// yaml files of overlay directory are merged into documents of same resource ,
+// with same rules as --override-file (maps are merged , lists of maps are merged by name).
+// resource is kind , metadata.namespace and metadata.name , or file name and document index
+// when document has no kind or name. document which matches no resource is added.
+// patches.yaml of overlay directory is list of patches , applied after merge.
+//   patches:
+//   - select: kind==Deployment       # same expression as --select (optional)
+//     set:
+//       spec.replicas: 3
+//       spec.template.spec.containers[name=app].image: app:1.2
+//     delete:
+//     - metadata.annotations.debug
+//
+package yamlsort
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+)
+
+// file name of patches in overlay directory
+var overlayPatchFiles = []string{"patches.yaml", "patches.yml"}
+
+// one patch of patches.yaml
+type overlayPatch struct {
+	Select string                 `json:"select"`
+	Set    map[string]interface{} `json:"set"`
+	Delete []string               `json:"delete"`
+}
+
+// patches.yaml
+type overlayPatchFile struct {
+	Patches []overlayPatch `json:"patches"`
+}
+
+// rendered document and its resource id
+type overlayResource struct {
+	id  string
+	doc *Document
+}
+
+// resource id of document
+func overlayResourceID(dir string, filename string, index int, data interface{}) string {
+	if m, ok := data.(map[string]interface{}); ok {
+		kind, _ := m["kind"].(string)
+		if metadata, ok := m["metadata"].(map[string]interface{}); ok && len(kind) > 0 {
+			name, _ := metadata["name"].(string)
+			namespace, _ := metadata["namespace"].(string)
+			if len(name) > 0 {
+				return kind + "/" + namespace + "/" + name
+			}
+		}
+	}
+	relpath, err := filepath.Rel(dir, filename)
+	if err != nil {
+		relpath = filename
+	}
+	return fmt.Sprintf("file:%s#%d", filepath.ToSlash(relpath), index)
+}
+
+//---------------------------------------------------------------------
+//  overlay subcommand
+//
+func newOverlayCmd(yamlsort *yamlsortCmd) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "overlay base-dir overlay-dir...",
+		Short: "apply ordered stack of overlay directories (merge and patches) to base directory , and output sorted result",
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("overlay requires base directory")
+			}
+			yamlsort.maxlinesize = defaultMaxLineSize
+			err := yamlsort.prepareOptions()
+			if err != nil {
+				return err
+			}
+			return yamlsort.overlay(args[0], args[1:])
+		},
+	}
+	return cmd
+}
+
+func (c *yamlsortCmd) overlay(basedir string, overlaydirs []string) error {
+	resources := []*overlayResource{}
+	for i, dir := range append([]string{basedir}, overlaydirs...) {
+		var err error
+		resources, err = c.applyOverlay(resources, dir, i > 0)
+		if err != nil {
+			return err
+		}
+	}
+	for _, r := range resources {
+		err := c.writeDocument(c.stdout, r.doc)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// merge documents of directory into resources , and apply patches of directory
+func (c *yamlsortCmd) applyOverlay(resources []*overlayResource, dir string, blnOverlay bool) ([]*overlayResource, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not directory", dir)
+	}
+	filenames, err := c.collectFiles([]string{dir})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(filenames)
+	patchfilename := ""
+	for _, filename := range filenames {
+		if blnOverlay && filepath.Dir(filename) == filepath.Clean(dir) && isOverlayPatchFile(filename) {
+			patchfilename = filename
+			continue
+		}
+		docs, err := c.readDocuments(filename)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", filename, err)
+		}
+		for i, doc := range docs {
+			if doc.Data == nil {
+				continue
+			}
+			id := overlayResourceID(dir, filename, i, doc.Data)
+			found := false
+			for _, r := range resources {
+				if r.id == id {
+					r.doc.Data, err = c.myOverride(r.doc.Data, doc.Data)
+					if err != nil {
+						return nil, fmt.Errorf("%s: %v", filename, err)
+					}
+					found = true
+					break
+				}
+			}
+			if !found {
+				resources = append(resources, &overlayResource{id: id, doc: doc})
+			}
+		}
+	}
+	if len(patchfilename) > 0 {
+		err = applyOverlayPatches(resources, patchfilename)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return resources, nil
+}
+
+// file is patches.yaml
+func isOverlayPatchFile(filename string) bool {
+	for _, name := range overlayPatchFiles {
+		if filepath.Base(filename) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// apply patches of patches.yaml to resources
+func applyOverlayPatches(resources []*overlayResource, filename string) error {
+	input, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	var patchfile overlayPatchFile
+	err = yaml.Unmarshal(input, &patchfile)
+	if err != nil {
+		return fmt.Errorf("%s: %v", filename, err)
+	}
+	for i, patch := range patchfile.Patches {
+		var conditions [][]selectCondition
+		if len(patch.Select) > 0 {
+			conditions, err = parseSelect(patch.Select)
+			if err != nil {
+				return fmt.Errorf("%s: patch %d: %v", filename, i, err)
+			}
+		}
+		// paths are applied in sorted order
+		setpaths := make([]string, 0, len(patch.Set))
+		for path := range patch.Set {
+			setpaths = append(setpaths, path)
+		}
+		sort.Strings(setpaths)
+		for _, r := range resources {
+			if len(conditions) > 0 && !matchConditions(conditions, r.doc.Data) {
+				continue
+			}
+			for _, path := range setpaths {
+				segs, err := parsePath(path)
+				if err != nil {
+					return fmt.Errorf("%s: patch %d: %v", filename, i, err)
+				}
+				r.doc.Data, err = setPath(r.doc.Data, segs, patch.Set[path])
+				if err != nil {
+					return fmt.Errorf("%s: patch %d: %s: %s: %v", filename, i, r.id, path, err)
+				}
+			}
+			for _, path := range patch.Delete {
+				segs, err := parsePath(path)
+				if err != nil {
+					return fmt.Errorf("%s: patch %d: %v", filename, i, err)
+				}
+				r.doc.Data, _ = deletePath(r.doc.Data, segs)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+//
+// yamlsort - pager for long output
+//
+// when stdout is terminal and output is longer than terminal height , output is piped through
+// pager , like git does. pager is $YAMLSORT_PAGER , $PAGER , or less. LESS=FRX is set when
+// LESS is not set. empty pager or "cat" writes output directly.
+//   yamlsort -i big.yaml             long output is shown in less
+//   yamlsort -i big.yaml --no-pager  write to terminal directly
+//
+package yamlsort
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// default pager , when $YAMLSORT_PAGER and $PAGER are not set
+const defaultPager = "less"
+
+// pager command. empty when output is written directly.
+func pagerCommand() string {
+	for _, name := range []string{"YAMLSORT_PAGER", "PAGER"} {
+		if pager, ok := os.LookupEnv(name); ok {
+			pager = strings.TrimSpace(pager)
+			if pager == "cat" {
+				return ""
+			}
+			return pager
+		}
+	}
+	return defaultPager
+}
+
+// writer is terminal
+func isTerminalWriter(w io.Writer) bool {
+	fp, ok := w.(*os.File)
+	return ok && isTerminalFile(fp)
+}
+
+// write output to stdout , through pager when output is longer than terminal
+func (c *yamlsortCmd) writeStdout(output []byte) error {
+	if c.blnNoPager || !isTerminalWriter(c.stdout) {
+		_, err := c.stdout.Write(output)
+		return err
+	}
+	_, height := terminalSize()
+	pager := pagerCommand()
+	if len(pager) == 0 || bytes.Count(output, []byte("\n")) < height {
+		_, err := c.stdout.Write(output)
+		return err
+	}
+	fields := strings.Fields(pager)
+	cmd := exec.Command(fields[0], fields[1:]...)
+	cmd.Stdin = bytes.NewReader(output)
+	cmd.Stdout = c.stdout
+	cmd.Stderr = c.stderr
+	cmd.Env = os.Environ()
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		cmd.Env = append(cmd.Env, "LESS=FRX")
+	}
+	err := cmd.Run()
+	if _, notfound := err.(*exec.Error); notfound {
+		// pager is not found
+		_, err = c.stdout.Write(output)
+	}
+	return err
+}
@@ -0,0 +1,318 @@
+//
+// yamlsort - key path expression
+//
+// path is same form as --skip-key.
+//   spec.template.spec.containers[name=app].env[0].value
+// and wildcards.
+//   data.*              all keys of map
+//   spec.ports[*].name  all elements of slice
+// and quoted key , which has '.' , '[' or ']'.
+//   metadata.labels["app.kubernetes.io/name"]
+//
+package yamlsort
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// kind of path segment
+const (
+	segKey           = iota // .key
+	segIndex                // [0]
+	segMatch                // [name=value]
+	segWildcardKey          // .*
+	segWildcardIndex        // [*]
+)
+
+type pathSegment struct {
+	kind       int
+	key        string
+	index      int
+	matchvalue string
+}
+
+func (s pathSegment) String() string {
+	switch s.kind {
+	case segKey:
+		if needsQuotedKey(s.key) {
+			return "[" + strconv.Quote(s.key) + "]"
+		}
+		return s.key
+	case segIndex:
+		return "[" + strconv.Itoa(s.index) + "]"
+	case segMatch:
+		return "[" + s.key + "=" + s.matchvalue + "]"
+	case segWildcardKey:
+		return "*"
+	}
+	return "[*]"
+}
+
+func (s pathSegment) isWildcard() bool {
+	return s.kind == segWildcardKey || s.kind == segWildcardIndex
+}
+
+// key can not be written as .key in path
+func needsQuotedKey(key string) bool {
+	return len(key) == 0 || key == "*" || strings.ContainsAny(key, ".[]\"")
+}
+
+// path string of segments
+func pathString(segs []pathSegment) string {
+	result := ""
+	for i, s := range segs {
+		if i > 0 && (s.kind == segKey && !needsQuotedKey(s.key) || s.kind == segWildcardKey) {
+			result = result + "."
+		}
+		result = result + s.String()
+	}
+	return result
+}
+
+// parse path string
+func parsePath(path string) ([]pathSegment, error) {
+	result := []pathSegment{}
+	rest := path
+	for len(rest) > 0 {
+		if strings.HasPrefix(rest, "[\"") {
+			key, n, err := parseQuotedKey(rest[1:])
+			if err != nil {
+				return nil, fmt.Errorf("path %q: %v", path, err)
+			}
+			rest = rest[1+n:]
+			if !strings.HasPrefix(rest, "]") {
+				return nil, fmt.Errorf("path %q: ']' is not found after quoted key", path)
+			}
+			rest = rest[1:]
+			result = append(result, pathSegment{kind: segKey, key: key})
+		} else if rest[0] == '[' {
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("path %q: ']' is not found", path)
+			}
+			inner := rest[1:end]
+			rest = rest[end+1:]
+			if inner == "*" {
+				result = append(result, pathSegment{kind: segWildcardIndex})
+			} else if idx := strings.Index(inner, "="); idx > 0 {
+				result = append(result, pathSegment{kind: segMatch, key: inner[:idx], matchvalue: inner[idx+1:]})
+			} else {
+				i, err := strconv.Atoi(inner)
+				if err != nil || i < 0 {
+					return nil, fmt.Errorf("path %q: invalid index [%s]", path, inner)
+				}
+				result = append(result, pathSegment{kind: segIndex, index: i})
+			}
+		} else {
+			if rest[0] == '.' {
+				if len(result) == 0 {
+					return nil, fmt.Errorf("path %q: starts with '.'", path)
+				}
+				rest = rest[1:]
+			}
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			key := rest[:end]
+			rest = rest[end:]
+			if len(key) == 0 {
+				return nil, fmt.Errorf("path %q: empty key", path)
+			}
+			if key == "*" {
+				result = append(result, pathSegment{kind: segWildcardKey})
+			} else {
+				result = append(result, pathSegment{kind: segKey, key: key})
+			}
+		}
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("path is empty")
+	}
+	return result, nil
+}
+
+// quoted key at start of text. returns key and length of quoted text.
+func parseQuotedKey(text string) (string, int, error) {
+	for i := 1; i < len(text); i++ {
+		if text[i] == '\\' {
+			i++
+		} else if text[i] == '"' {
+			key, err := strconv.Unquote(text[:i+1])
+			if err != nil {
+				return "", 0, fmt.Errorf("invalid quoted key %s", text[:i+1])
+			}
+			return key, i + 1, nil
+		}
+	}
+	return "", 0, fmt.Errorf("'\"' is not found after quoted key")
+}
+
+// slice element matches [key=value]
+func matchSliceElement(elem interface{}, seg pathSegment) bool {
+	m, ok := elem.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	v, ok := m[seg.key]
+	if !ok || v == nil {
+		return false
+	}
+	return scalarEqualsText(v, seg.matchvalue)
+}
+
+// call fn for each node at path. fn returns replacement of the node.
+// node which is not found is skipped.
+func mapPathNodes(data interface{}, segs []pathSegment, fn func(node interface{}) (interface{}, error)) (interface{}, error) {
+	return mapPathNodesAt(data, segs, nil, func(node interface{}, at []pathSegment) (interface{}, error) {
+		return fn(node)
+	})
+}
+
+// same as mapPathNodes , and fn gets the path of node (keys and indexes , without wildcard).
+func mapPathNodesAt(data interface{}, segs []pathSegment, at []pathSegment, fn func(node interface{}, at []pathSegment) (interface{}, error)) (interface{}, error) {
+	if len(segs) == 0 {
+		return fn(data, at)
+	}
+	seg := segs[0]
+	if m, ok := data.(map[string]interface{}); ok {
+		if seg.kind == segKey {
+			v, ok := m[seg.key]
+			if !ok {
+				return data, nil
+			}
+			result, err := mapPathNodesAt(v, segs[1:], appendSegment(at, seg), fn)
+			if err != nil {
+				return data, err
+			}
+			m[seg.key] = result
+		} else if seg.kind == segWildcardKey {
+			for k, v := range m {
+				result, err := mapPathNodesAt(v, segs[1:], appendSegment(at, pathSegment{kind: segKey, key: k}), fn)
+				if err != nil {
+					return data, err
+				}
+				m[k] = result
+			}
+		}
+		return data, nil
+	}
+	if a, ok := data.([]interface{}); ok {
+		for i, v := range a {
+			if seg.kind == segIndex && seg.index != i {
+				continue
+			}
+			if seg.kind == segMatch && !matchSliceElement(v, seg) {
+				continue
+			}
+			if seg.kind == segKey || seg.kind == segWildcardKey {
+				continue
+			}
+			result, err := mapPathNodesAt(v, segs[1:], appendSegment(at, pathSegment{kind: segIndex, index: i}), fn)
+			if err != nil {
+				return data, err
+			}
+			a[i] = result
+		}
+	}
+	return data, nil
+}
+
+// new path of segments and seg. segments are not shared.
+func appendSegment(segs []pathSegment, seg pathSegment) []pathSegment {
+	result := make([]pathSegment, len(segs), len(segs)+1)
+	copy(result, segs)
+	return append(result, seg)
+}
+
+// get value at path (no wildcard). return false when it is not found.
+func getPath(data interface{}, segs []pathSegment) (interface{}, bool) {
+	found := false
+	var value interface{}
+	mapPathNodes(data, segs, func(node interface{}) (interface{}, error) {
+		if !found {
+			value = node
+			found = true
+		}
+		return node, nil
+	})
+	return value, found
+}
+
+// delete value at path. return new data and deleted count.
+func deletePath(data interface{}, segs []pathSegment) (interface{}, int) {
+	count := 0
+	parent := segs[:len(segs)-1]
+	last := segs[len(segs)-1]
+	result, _ := mapPathNodes(data, parent, func(node interface{}) (interface{}, error) {
+		if m, ok := node.(map[string]interface{}); ok {
+			if last.kind == segKey {
+				if _, ok := m[last.key]; ok {
+					delete(m, last.key)
+					count++
+				}
+			} else if last.kind == segWildcardKey {
+				for k := range m {
+					delete(m, k)
+					count++
+				}
+			}
+			return m, nil
+		}
+		if a, ok := node.([]interface{}); ok {
+			result := make([]interface{}, 0, len(a))
+			for i, v := range a {
+				if (last.kind == segIndex && last.index == i) || (last.kind == segMatch && matchSliceElement(v, last)) || last.kind == segWildcardIndex {
+					count++
+					continue
+				}
+				result = append(result, v)
+			}
+			return result, nil
+		}
+		return node, nil
+	})
+	return result, count
+}
+
+// set value at path (no wildcard). missing maps are created.
+func setPath(data interface{}, segs []pathSegment, value interface{}) (interface{}, error) {
+	if len(segs) == 0 {
+		return value, nil
+	}
+	seg := segs[0]
+	switch seg.kind {
+	case segKey:
+		m, ok := data.(map[string]interface{})
+		if data == nil {
+			m = map[string]interface{}{}
+		} else if !ok {
+			return data, fmt.Errorf("can not set key %q of non-map value", seg.key)
+		}
+		result, err := setPath(m[seg.key], segs[1:], value)
+		if err != nil {
+			return data, err
+		}
+		m[seg.key] = result
+		return m, nil
+	case segIndex, segMatch:
+		a, ok := data.([]interface{})
+		if !ok {
+			return data, fmt.Errorf("can not set %s of non-slice value", seg.String())
+		}
+		for i, v := range a {
+			if (seg.kind == segIndex && seg.index == i) || (seg.kind == segMatch && matchSliceElement(v, seg)) {
+				result, err := setPath(v, segs[1:], value)
+				if err != nil {
+					return data, err
+				}
+				a[i] = result
+				return a, nil
+			}
+		}
+		return data, fmt.Errorf("%s is not found", seg.String())
+	}
+	return data, fmt.Errorf("can not set value to wildcard path")
+}
@@ -0,0 +1,124 @@
+//
+// yamlsort - paths subcommand
+//
+// flattened view of documents. one leaf value per line in sorted order.
+//   spec.template.spec.containers[0].image = nginx:1.25
+// documents are separated by "---" line. with multiple files , lines have "file: " prefix.
+//
+package yamlsort
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+//---------------------------------------------------------------------
+//  paths subcommand
+//
+func newPathsCmd(yamlsort *yamlsortCmd) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "paths file...",
+		Short: "print every leaf path and value in sorted order (path = value)",
+		RunE: func(c *cobra.Command, args []string) (err error) {
+			if len(args) == 0 {
+				return fmt.Errorf("paths requires input file names")
+			}
+			defer yamlsort.bufferStdout()(&err)
+			yamlsort.maxlinesize = defaultMaxLineSize
+			if len(globalpriorkeys) == 0 {
+				globalpriorkeys = []string{"name"}
+			}
+			filenames, err := yamlsort.collectFiles(args)
+			if err != nil {
+				return err
+			}
+			for _, filename := range filenames {
+				prefix := ""
+				if len(filenames) > 1 {
+					prefix = filename + ": "
+				}
+				err := yamlsort.printPaths(filename, prefix)
+				if err != nil {
+					return fmt.Errorf("%s: %v", filename, err)
+				}
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func (c *yamlsortCmd) printPaths(filename string, prefix string) error {
+	input, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	count := 0
+	return c.processDocuments(bytes.NewReader(input), func(doc *Document) error {
+		if doc.Data == nil && doc.raw.isEmpty() {
+			return nil
+		}
+		if count > 0 {
+			fmt.Fprintln(c.stdout, prefix+"---")
+		}
+		count++
+		c.writePaths(c.stdout, prefix, []pathSegment{}, doc.Data)
+		return nil
+	})
+}
+
+// write "path = value" lines of leaf values
+func (c *yamlsortCmd) writePaths(w io.Writer, prefix string, segs []pathSegment, data interface{}) {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		if len(v) > 0 {
+			keylist := make([]string, 0, len(v))
+			for k := range v {
+				keylist = append(keylist, k)
+			}
+			sortKeys(keylist)
+			for _, k := range keylist {
+				c.writePaths(w, prefix, append(segs, pathSegment{kind: segKey, key: k}), v[k])
+			}
+			return
+		}
+	case []interface{}:
+		if len(v) > 0 {
+			for i, item := range v {
+				c.writePaths(w, prefix, append(segs, pathSegment{kind: segIndex, index: i}), item)
+			}
+			return
+		}
+	}
+	path := "."
+	if len(segs) > 0 {
+		path = pathString(segs)
+	}
+	fmt.Fprintf(w, "%s%s = %s\n", prefix, path, c.scalarString(data))
+}
+
+// scalar value in yaml form
+func (c *yamlsortCmd) scalarString(data interface{}) string {
+	switch v := data.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "{}"
+	case []interface{}:
+		return "[]"
+	case string:
+		return c.escapeString(v)
+	case float64:
+		if text, ok := specialFloatText(v); ok {
+			return text
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return fmt.Sprint(data)
+}
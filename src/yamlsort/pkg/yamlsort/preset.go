@@ -0,0 +1,145 @@
+//
+// yamlsort - preset plugins
+//
+// preset plugin is executable file yamlsort-preset-<name> on PATH.
+// it is executed without arguments, and writes preset yaml to stdout.
+//
+//   key:                    # prior keys. same as --key
+//   - name
+//   skip-key:               # same as --skip-key
+//   - metadata.creationTimestamp
+//   transform:              # same as --transform
+//   - sed -e 's/foo/bar/'
+//   script: /path/to/transform.star   # same as --script
+//   quote-string: true      # same as --quote-string
+//   array-indent-plus-2: true         # same as --array-indent-plus-2
+//   comparator: k8s         # same as --comparator (see comparator.go)
+//
+package yamlsort
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+)
+
+// prefix of preset plugin executable name
+const presetCommandPrefix = "yamlsort-preset-"
+
+// preset yaml written by preset plugin
+type presetConfig struct {
+	Keys             []string `json:"key"`
+	SkipKeys         []string `json:"skip-key"`
+	Transforms       []string `json:"transform"`
+	Script           string   `json:"script"`
+	QuoteString      bool     `json:"quote-string"`
+	ArrayIndentPlus2 bool     `json:"array-indent-plus-2"`
+	Comparator       string   `json:"comparator"`
+}
+
+//-------------------------------------------------------------------------
+// load preset plugins of --preset option , and merge into options.
+// options of command line come first.
+//
+func (c *yamlsortCmd) applyPresets() error {
+	for _, name := range c.presets {
+		preset, err := c.loadPreset(name)
+		if err != nil {
+			return err
+		}
+		c.priorkeys = append(c.priorkeys, preset.Keys...)
+		c.skipkeys = append(c.skipkeys, preset.SkipKeys...)
+		c.transformcommands = append(c.transformcommands, preset.Transforms...)
+		if len(c.scriptfilename) == 0 {
+			c.scriptfilename = preset.Script
+		}
+		c.blnQuoteString = c.blnQuoteString || preset.QuoteString
+		c.blnArrayIndentPlus2 = c.blnArrayIndentPlus2 || preset.ArrayIndentPlus2
+		if len(c.comparatorname) == 0 {
+			c.comparatorname = preset.Comparator
+		}
+	}
+	return nil
+}
+
+func (c *yamlsortCmd) loadPreset(name string) (*presetConfig, error) {
+	path, err := exec.LookPath(presetCommandPrefix + name)
+	if err != nil {
+		return nil, fmt.Errorf("preset %q is not found. (%s%s is not in PATH)", name, presetCommandPrefix, name)
+	}
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd := exec.Command(path)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.Env = append(c.environment(), "YAMLSORT_VERSION="+c.version)
+	err = cmd.Run()
+	if err != nil {
+		return nil, fmt.Errorf("preset %q failed: %v: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	preset := &presetConfig{}
+	err = yaml.Unmarshal(stdout.Bytes(), preset)
+	if err != nil {
+		return nil, fmt.Errorf("preset %q output: %v", name, err)
+	}
+	return preset, nil
+}
+
+//-------------------------------------------------------------------------
+// presets subcommand. list preset plugins on PATH.
+//
+func newPresetsCmd(yamlsort *yamlsortCmd) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "presets",
+		Short: "list preset plugins (" + presetCommandPrefix + "<name>) on PATH",
+		RunE: func(c *cobra.Command, args []string) error {
+			for _, preset := range findPresets() {
+				fmt.Fprintf(yamlsort.stdout, "%-20s %s\n", preset[0], preset[1])
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+// find preset plugins. return list of [name, path]. first one in PATH is used.
+func findPresets() [][2]string {
+	result := [][2]string{}
+	found := map[string]bool{}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			name := file.Name()
+			if !strings.HasPrefix(name, presetCommandPrefix) || file.IsDir() {
+				continue
+			}
+			if runtime.GOOS == "windows" {
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			} else if file.Mode()&0111 == 0 {
+				continue
+			}
+			name = strings.TrimPrefix(name, presetCommandPrefix)
+			if found[name] {
+				continue
+			}
+			found[name] = true
+			result = append(result, [2]string{name, filepath.Join(dir, file.Name())})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i][0] < result[j][0]
+	})
+	return result
+}
@@ -0,0 +1,66 @@
+//
+// yamlsort - cpu/memory profiling (hidden flags for performance investigation)
+//
+package yamlsort
+
+import (
+	"os"
+	"runtime"
+	"runtime/pprof"
+)
+
+//---------------------------------------------------------------------
+//  profiler class
+// --cpuprofile , --memprofile write pprof files. see `go tool pprof`.
+//
+type profiler struct {
+	cpuprofile string
+	memprofile string
+	cpufile    *os.File
+}
+
+// profiler of this process. stopped in main() after command execution.
+var globalprofiler = &profiler{}
+
+func (p *profiler) start() error {
+	if len(p.cpuprofile) == 0 {
+		return nil
+	}
+	fp, err := os.Create(p.cpuprofile)
+	if err != nil {
+		return err
+	}
+	err = pprof.StartCPUProfile(fp)
+	if err != nil {
+		fp.Close()
+		return err
+	}
+	p.cpufile = fp
+	return nil
+}
+
+func (p *profiler) stop() error {
+	if p.cpufile != nil {
+		pprof.StopCPUProfile()
+		err := p.cpufile.Close()
+		p.cpufile = nil
+		if err != nil {
+			return err
+		}
+	}
+	if len(p.memprofile) > 0 {
+		fp, err := os.Create(p.memprofile)
+		if err != nil {
+			return err
+		}
+		out := newBufferedOutput(fp)
+		// get up-to-date statistics
+		runtime.GC()
+		err = pprof.WriteHeapProfile(out)
+		if err2 := out.Close(); err == nil {
+			err = err2
+		}
+		return err
+	}
+	return nil
+}
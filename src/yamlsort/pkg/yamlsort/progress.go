@@ -0,0 +1,108 @@
+//
+// yamlsort - progress reporting
+//
+package yamlsort
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+//---------------------------------------------------------------------
+//  progressReporter class
+// print periodic progress lines to stderr while processing large input.
+// nothing is printed when the whole run finishes before the first interval.
+//
+type progressReporter struct {
+	writer    io.Writer
+	enabled   bool
+	interval  time.Duration
+	totalSize int64
+	doneSize  int64
+	documents int
+	started   time.Time
+	last      time.Time
+	printed   bool
+}
+
+func newProgressReporter(writer io.Writer, enabled bool, totalSize int64) *progressReporter {
+	now := time.Now()
+	return &progressReporter{
+		writer:    writer,
+		enabled:   enabled,
+		interval:  time.Second,
+		totalSize: totalSize,
+		started:   now,
+		last:      now,
+	}
+}
+
+// progress of files. total size is sum of file sizes.
+func newFilesProgressReporter(writer io.Writer, enabled bool, filenames []string) *progressReporter {
+	totalSize := int64(0)
+	for _, filename := range filenames {
+		if info, err := os.Stat(filename); err == nil {
+			totalSize += info.Size()
+		}
+	}
+	return newProgressReporter(writer, enabled, totalSize)
+}
+
+// add processed one document (size bytes)
+func (p *progressReporter) addDocument(size int) {
+	if p == nil {
+		return
+	}
+	p.documents++
+	p.doneSize += int64(size)
+	if !p.enabled {
+		return
+	}
+	now := time.Now()
+	if now.Sub(p.last) < p.interval {
+		return
+	}
+	p.last = now
+	p.print()
+}
+
+// print last progress line , only when progress line is printed before.
+func (p *progressReporter) finish() {
+	if p == nil || !p.enabled || !p.printed {
+		return
+	}
+	// separator lines are not counted in addDocument
+	p.doneSize = p.totalSize
+	p.print()
+}
+
+func (p *progressReporter) print() {
+	p.printed = true
+	elapsed := time.Since(p.started).Seconds()
+	if p.totalSize > 0 {
+		percent := p.doneSize * 100 / p.totalSize
+		if percent > 100 {
+			percent = 100
+		}
+		fmt.Fprintf(p.writer, "yamlsort: %s %3d%% %d documents (%.1fs)\n", progressBar(percent), percent, p.documents, elapsed)
+	} else {
+		fmt.Fprintf(p.writer, "yamlsort: %d documents %d bytes (%.1fs)\n", p.documents, p.doneSize, elapsed)
+	}
+}
+
+// progress bar string like [#####.....]
+func progressBar(percent int64) string {
+	width := int64(20)
+	filled := percent * width / 100
+	bar := "["
+	for i := int64(0); i < width; i++ {
+		if i < filled {
+			bar = bar + "#"
+		} else {
+			bar = bar + "."
+		}
+	}
+	return bar + "]"
+}
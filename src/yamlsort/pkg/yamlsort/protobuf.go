@@ -0,0 +1,718 @@
+//
+// yamlsort - --descriptor and --message options
+//
+// validate yaml (and json) documents of protobuf messages in proto3 json mapping , and order keys by
+// field number instead of name.
+//   protoc --include_imports --descriptor_set_out=set.pb config.proto
+//   yamlsort --descriptor set.pb --message demo.config.Server -i server.yaml
+// descriptor is FileDescriptorSet (binary) , decoded without protobuf library. keys are field names
+// or json names (lowerCamelCase). unknown fields , types of values , enum names , oneof (only one
+// member) and proto2 required fields are checked. keys of map fields are sorted in normal order.
+// well-known types (google.protobuf.*) are not checked , because their json forms are special.
+//
+package yamlsort
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// field types and labels of FieldDescriptorProto
+const (
+	protoTypeDouble   = 1
+	protoTypeFloat    = 2
+	protoTypeInt64    = 3
+	protoTypeUint64   = 4
+	protoTypeInt32    = 5
+	protoTypeFixed64  = 6
+	protoTypeFixed32  = 7
+	protoTypeBool     = 8
+	protoTypeString   = 9
+	protoTypeGroup    = 10
+	protoTypeMessage  = 11
+	protoTypeBytes    = 12
+	protoTypeUint32   = 13
+	protoTypeEnum     = 14
+	protoTypeSfixed32 = 15
+	protoTypeSfixed64 = 16
+	protoTypeSint32   = 17
+	protoTypeSint64   = 18
+
+	protoLabelRequired = 2
+	protoLabelRepeated = 3
+)
+
+// field of message
+type protoField struct {
+	name       string
+	jsonName   string
+	number     int
+	label      int
+	typ        int
+	typeName   string // full name without leading dot , like demo.config.Mode
+	oneofIndex int    // -1 when field is not in oneof
+}
+
+// message type
+type protoMessage struct {
+	name     string
+	fields   []*protoField // in field number order
+	oneofs   []string
+	mapEntry bool
+}
+
+// enum type
+type protoEnum struct {
+	name   string
+	values map[string]bool
+}
+
+// messages and enums of FileDescriptorSet by full name
+type protoDescriptorSet struct {
+	messages map[string]*protoMessage
+	enums    map[string]*protoEnum
+}
+
+// load --descriptor , and find --message
+func (c *yamlsortCmd) prepareProtoMessage() error {
+	c.protomessage = nil
+	if len(c.descriptorfilename) == 0 && len(c.protomessagename) == 0 {
+		return nil
+	}
+	if len(c.descriptorfilename) == 0 || len(c.protomessagename) == 0 {
+		return fmt.Errorf("--descriptor and --message must be used together")
+	}
+	input, err := ioutil.ReadFile(c.descriptorfilename)
+	if err != nil {
+		return err
+	}
+	set, err := decodeProtoDescriptorSet(input)
+	if err != nil {
+		return fmt.Errorf("%s: %v", c.descriptorfilename, err)
+	}
+	message, ok := set.messages[strings.TrimPrefix(c.protomessagename, ".")]
+	if !ok {
+		return fmt.Errorf("message %s is not found in %s", c.protomessagename, c.descriptorfilename)
+	}
+	c.protoset = set
+	c.protomessage = message
+	return nil
+}
+
+// validate document with --message , and set key order of messages
+func (c *yamlsortCmd) applyProtoMessage(data interface{}) error {
+	if _, ok := data.(map[string]interface{}); !ok {
+		return fmt.Errorf("document must be map of message %s", c.protomessage.name)
+	}
+	if c.keyorders == nil {
+		c.keyorders = map[string][]string{}
+	}
+	return c.walkProtoMessage(c.protomessage, "", data)
+}
+
+// keys of message at path
+func (c *yamlsortCmd) walkProtoMessage(message *protoMessage, path string, data interface{}) error {
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s: message %s must be map", protoPath(path), message.name)
+	}
+	order := []string{}
+	fields := map[string]*protoField{}
+	for _, field := range message.fields {
+		order = append(order, field.name)
+		fields[field.name] = field
+		if field.jsonName != field.name {
+			order = append(order, field.jsonName)
+			fields[field.jsonName] = field
+		}
+	}
+	c.keyorders[path] = order
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	seen := map[int]string{}
+	oneofs := map[int]string{}
+	for _, k := range keys {
+		field, ok := fields[k]
+		if !ok {
+			return fmt.Errorf("%s: unknown field of message %s", protoPath(c.calcPathMap(path, k)), message.name)
+		}
+		if other, ok := seen[field.number]; ok {
+			return fmt.Errorf("%s: field %s is set twice (%s , %s)", protoPath(path), field.name, other, k)
+		}
+		seen[field.number] = k
+		if field.oneofIndex >= 0 && field.oneofIndex < len(message.oneofs) && m[k] != nil {
+			if other, ok := oneofs[field.oneofIndex]; ok {
+				return fmt.Errorf("%s: only one of oneof %s can be set (%s , %s)", protoPath(path), message.oneofs[field.oneofIndex], other, k)
+			}
+			oneofs[field.oneofIndex] = k
+		}
+		err := c.walkProtoField(field, c.calcPathMap(path, k), m[k])
+		if err != nil {
+			return err
+		}
+	}
+	for _, field := range message.fields {
+		if _, ok := seen[field.number]; !ok && field.label == protoLabelRequired {
+			return fmt.Errorf("%s: required field %s of message %s is not set", protoPath(path), field.name, message.name)
+		}
+	}
+	return nil
+}
+
+// value of field at path. null is default value.
+func (c *yamlsortCmd) walkProtoField(field *protoField, path string, data interface{}) error {
+	if data == nil {
+		return nil
+	}
+	if entry := c.protoMapEntry(field); entry != nil {
+		m, ok := data.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: map field %s must be map", protoPath(path), field.name)
+		}
+		key, value := entry.fields[0], entry.fields[1]
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			childpath := c.calcPathMap(path, k)
+			if err := checkProtoMapKey(key, k); err != nil {
+				return fmt.Errorf("%s: %v", protoPath(childpath), err)
+			}
+			if err := c.walkProtoValue(value, childpath, m[k]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if field.label == protoLabelRepeated {
+		list, ok := data.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: repeated field %s must be list", protoPath(path), field.name)
+		}
+		for i, child := range list {
+			if child == nil {
+				return fmt.Errorf("%s: element of repeated field %s can not be null", protoPath(c.calcPathSlice(path, i)), field.name)
+			}
+			if err := c.walkProtoValue(field, c.calcPathSliceElement(path, i, child), child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return c.walkProtoValue(field, path, data)
+}
+
+// entry type of map field , nil for other fields
+func (c *yamlsortCmd) protoMapEntry(field *protoField) *protoMessage {
+	if field.typ != protoTypeMessage || field.label != protoLabelRepeated {
+		return nil
+	}
+	message, ok := c.protoset.messages[field.typeName]
+	if !ok || !message.mapEntry || len(message.fields) != 2 {
+		return nil
+	}
+	return message
+}
+
+// singular value of field type
+func (c *yamlsortCmd) walkProtoValue(field *protoField, path string, data interface{}) error {
+	var err error
+	switch field.typ {
+	case protoTypeMessage, protoTypeGroup:
+		if strings.HasPrefix(field.typeName, "google.protobuf.") {
+			// well-known types
+			return nil
+		}
+		message, ok := c.protoset.messages[field.typeName]
+		if !ok {
+			return fmt.Errorf("%s: message %s is not found in descriptor (use --include_imports)", protoPath(path), field.typeName)
+		}
+		return c.walkProtoMessage(message, path, data)
+	case protoTypeEnum:
+		err = c.checkProtoEnum(field, data)
+	case protoTypeString:
+		if _, ok := data.(string); !ok {
+			err = fmt.Errorf("string is expected")
+		}
+	case protoTypeBytes:
+		err = checkProtoBytes(data)
+	case protoTypeBool:
+		if _, ok := data.(bool); !ok {
+			err = fmt.Errorf("bool is expected")
+		}
+	case protoTypeDouble, protoTypeFloat:
+		err = checkProtoFloat(data)
+	default:
+		err = checkProtoInteger(field.typ, data)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %v", protoPath(path), err)
+	}
+	return nil
+}
+
+// enum name , or number
+func (c *yamlsortCmd) checkProtoEnum(field *protoField, data interface{}) error {
+	enum, ok := c.protoset.enums[field.typeName]
+	if !ok {
+		if field.typeName == "google.protobuf.NullValue" {
+			return nil
+		}
+		return fmt.Errorf("enum %s is not found in descriptor (use --include_imports)", field.typeName)
+	}
+	switch v := data.(type) {
+	case string:
+		if !enum.values[v] {
+			return fmt.Errorf("%q is not value of enum %s", v, enum.name)
+		}
+		return nil
+	case float64:
+		return checkProtoInteger(protoTypeInt32, v)
+	}
+	return fmt.Errorf("name of enum %s is expected", enum.name)
+}
+
+// number , or numeric string (including "NaN" , "Infinity")
+func checkProtoFloat(data interface{}) error {
+	switch v := data.(type) {
+	case float64:
+		return nil
+	case string:
+		if _, err := strconv.ParseFloat(v, 64); err == nil || v == "NaN" || v == "Infinity" || v == "-Infinity" {
+			return nil
+		}
+	}
+	return fmt.Errorf("number is expected")
+}
+
+// integer in range of type , number or string
+func checkProtoInteger(typ int, data interface{}) error {
+	bits := 32
+	signed := true
+	switch typ {
+	case protoTypeInt64, protoTypeSint64, protoTypeSfixed64:
+		bits = 64
+	case protoTypeUint64, protoTypeFixed64:
+		bits = 64
+		signed = false
+	case protoTypeUint32, protoTypeFixed32:
+		signed = false
+	}
+	var text string
+	switch v := data.(type) {
+	case float64:
+		if v != math.Trunc(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("integer is expected , not %v", v)
+		}
+		text = strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		text = v
+	default:
+		return fmt.Errorf("integer is expected")
+	}
+	var err error
+	if signed {
+		_, err = strconv.ParseInt(text, 10, bits)
+	} else {
+		_, err = strconv.ParseUint(text, 10, bits)
+	}
+	if err != nil {
+		return fmt.Errorf("%s is not %s", text, protoIntegerName(signed, bits))
+	}
+	return nil
+}
+
+func protoIntegerName(signed bool, bits int) string {
+	if signed {
+		return fmt.Sprintf("int%d", bits)
+	}
+	return fmt.Sprintf("uint%d", bits)
+}
+
+// base64 text (standard or url safe , with or without padding)
+func checkProtoBytes(data interface{}) error {
+	s, ok := data.(string)
+	if !ok {
+		return fmt.Errorf("base64 string is expected")
+	}
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if _, err := encoding.DecodeString(s); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("base64 string is expected")
+}
+
+// key of map field is text of key type
+func checkProtoMapKey(field *protoField, key string) error {
+	switch field.typ {
+	case protoTypeString:
+		return nil
+	case protoTypeBool:
+		if key == "true" || key == "false" {
+			return nil
+		}
+		return fmt.Errorf("key of map must be true or false")
+	}
+	return checkProtoInteger(field.typ, key)
+}
+
+// path in error messages
+func protoPath(path string) string {
+	if len(path) == 0 {
+		return "top"
+	}
+	return path
+}
+
+//---------------------------------------------------------------------
+//  protoReader class
+// reader of protobuf wire format
+//
+type protoReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *protoReader) eof() bool {
+	return r.pos >= len(r.data)
+}
+
+func (r *protoReader) varint() (uint64, error) {
+	var result uint64
+	for shift := uint(0); shift < 64; shift += 7 {
+		if r.eof() {
+			return 0, fmt.Errorf("unexpected end of varint")
+		}
+		b := r.data[r.pos]
+		r.pos++
+		result |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			return result, nil
+		}
+	}
+	return 0, fmt.Errorf("varint is too long")
+}
+
+// field number and wire type of next field
+func (r *protoReader) tag() (int, int, error) {
+	tag, err := r.varint()
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(tag >> 3), int(tag & 7), nil
+}
+
+// length-delimited bytes
+func (r *protoReader) bytes() ([]byte, error) {
+	n, err := r.varint()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(r.data)-r.pos) {
+		return nil, fmt.Errorf("unexpected end of bytes")
+	}
+	b := r.data[r.pos : r.pos+int(n)]
+	r.pos += int(n)
+	return b, nil
+}
+
+// skip value of wire type
+func (r *protoReader) skip(wiretype int) error {
+	var size int
+	switch wiretype {
+	case 0:
+		_, err := r.varint()
+		return err
+	case 1:
+		size = 8
+	case 2:
+		_, err := r.bytes()
+		return err
+	case 5:
+		size = 4
+	default:
+		return fmt.Errorf("unsupported wire type %d", wiretype)
+	}
+	if size > len(r.data)-r.pos {
+		return fmt.Errorf("unexpected end of data")
+	}
+	r.pos += size
+	return nil
+}
+
+// read fields of message. fn reads value of known fields , and returns false for other fields.
+func readProtoFields(data []byte, fn func(r *protoReader, number int, wiretype int) (bool, error)) error {
+	r := &protoReader{data: data}
+	for !r.eof() {
+		number, wiretype, err := r.tag()
+		if err != nil {
+			return err
+		}
+		known, err := fn(r, number, wiretype)
+		if err != nil {
+			return err
+		}
+		if !known {
+			if err := r.skip(wiretype); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//---------------------------------------------------------------------
+//  FileDescriptorSet decoder
+//
+
+// decode FileDescriptorSet (google/protobuf/descriptor.proto)
+func decodeProtoDescriptorSet(data []byte) (*protoDescriptorSet, error) {
+	set := &protoDescriptorSet{messages: map[string]*protoMessage{}, enums: map[string]*protoEnum{}}
+	files := 0
+	err := readProtoFields(data, func(r *protoReader, number int, wiretype int) (bool, error) {
+		if number != 1 || wiretype != 2 {
+			return false, nil
+		}
+		file, err := r.bytes()
+		if err != nil {
+			return true, err
+		}
+		files++
+		return true, set.decodeFile(file)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptor set , %v", err)
+	}
+	if files == 0 {
+		return nil, fmt.Errorf("no file in descriptor set")
+	}
+	return set, nil
+}
+
+// FileDescriptorProto. package(2) , message_type(4) , enum_type(5)
+func (set *protoDescriptorSet) decodeFile(data []byte) error {
+	var pkg string
+	var messages, enums [][]byte
+	err := readProtoFields(data, func(r *protoReader, number int, wiretype int) (bool, error) {
+		if wiretype != 2 {
+			return false, nil
+		}
+		switch number {
+		case 2, 4, 5:
+			b, err := r.bytes()
+			if err != nil {
+				return true, err
+			}
+			switch number {
+			case 2:
+				pkg = string(b)
+			case 4:
+				messages = append(messages, b)
+			case 5:
+				enums = append(enums, b)
+			}
+			return true, nil
+		}
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, b := range messages {
+		if err := set.decodeMessage(pkg, b); err != nil {
+			return err
+		}
+	}
+	for _, b := range enums {
+		if err := set.decodeEnum(pkg, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DescriptorProto. name(1) , field(2) , nested_type(3) , enum_type(4) , options(7) , oneof_decl(8)
+func (set *protoDescriptorSet) decodeMessage(scope string, data []byte) error {
+	message := &protoMessage{}
+	var fields, nested, enums [][]byte
+	err := readProtoFields(data, func(r *protoReader, number int, wiretype int) (bool, error) {
+		if wiretype != 2 {
+			return false, nil
+		}
+		b, err := r.bytes()
+		if err != nil {
+			return true, err
+		}
+		switch number {
+		case 1:
+			message.name = string(b)
+		case 2:
+			fields = append(fields, b)
+		case 3:
+			nested = append(nested, b)
+		case 4:
+			enums = append(enums, b)
+		case 7:
+			// MessageOptions. map_entry(7)
+			return true, readProtoFields(b, func(r *protoReader, number int, wiretype int) (bool, error) {
+				if number != 7 || wiretype != 0 {
+					return false, nil
+				}
+				v, err := r.varint()
+				message.mapEntry = v != 0
+				return true, err
+			})
+		case 8:
+			// OneofDescriptorProto. name(1)
+			name := ""
+			err := readProtoFields(b, func(r *protoReader, number int, wiretype int) (bool, error) {
+				if number != 1 || wiretype != 2 {
+					return false, nil
+				}
+				s, err := r.bytes()
+				name = string(s)
+				return true, err
+			})
+			message.oneofs = append(message.oneofs, name)
+			return true, err
+		}
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+	message.name = protoFullName(scope, message.name)
+	for _, b := range fields {
+		field, err := decodeProtoField(b)
+		if err != nil {
+			return err
+		}
+		message.fields = append(message.fields, field)
+	}
+	sort.SliceStable(message.fields, func(i, j int) bool {
+		return message.fields[i].number < message.fields[j].number
+	})
+	set.messages[message.name] = message
+	for _, b := range nested {
+		if err := set.decodeMessage(message.name, b); err != nil {
+			return err
+		}
+	}
+	for _, b := range enums {
+		if err := set.decodeEnum(message.name, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FieldDescriptorProto. name(1) , number(3) , label(4) , type(5) , type_name(6) , oneof_index(9) , json_name(10)
+func decodeProtoField(data []byte) (*protoField, error) {
+	field := &protoField{oneofIndex: -1}
+	err := readProtoFields(data, func(r *protoReader, number int, wiretype int) (bool, error) {
+		switch {
+		case wiretype == 2 && (number == 1 || number == 6 || number == 10):
+			b, err := r.bytes()
+			switch number {
+			case 1:
+				field.name = string(b)
+			case 6:
+				field.typeName = strings.TrimPrefix(string(b), ".")
+			case 10:
+				field.jsonName = string(b)
+			}
+			return true, err
+		case wiretype == 0 && (number == 3 || number == 4 || number == 5 || number == 9):
+			v, err := r.varint()
+			switch number {
+			case 3:
+				field.number = int(v)
+			case 4:
+				field.label = int(v)
+			case 5:
+				field.typ = int(v)
+			case 9:
+				field.oneofIndex = int(v)
+			}
+			return true, err
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(field.jsonName) == 0 {
+		field.jsonName = protoJSONName(field.name)
+	}
+	return field, nil
+}
+
+// EnumDescriptorProto. name(1) , value(2) (EnumValueDescriptorProto. name(1))
+func (set *protoDescriptorSet) decodeEnum(scope string, data []byte) error {
+	enum := &protoEnum{values: map[string]bool{}}
+	err := readProtoFields(data, func(r *protoReader, number int, wiretype int) (bool, error) {
+		if wiretype != 2 || (number != 1 && number != 2) {
+			return false, nil
+		}
+		b, err := r.bytes()
+		if err != nil {
+			return true, err
+		}
+		if number == 1 {
+			enum.name = string(b)
+			return true, nil
+		}
+		return true, readProtoFields(b, func(r *protoReader, number int, wiretype int) (bool, error) {
+			if number != 1 || wiretype != 2 {
+				return false, nil
+			}
+			s, err := r.bytes()
+			enum.values[string(s)] = true
+			return true, err
+		})
+	})
+	if err != nil {
+		return err
+	}
+	enum.name = protoFullName(scope, enum.name)
+	set.enums[enum.name] = enum
+	return nil
+}
+
+// package.Message.Nested
+func protoFullName(scope string, name string) string {
+	if len(scope) == 0 {
+		return name
+	}
+	return scope + "." + name
+}
+
+// json name of field , like max_body_bytes -> maxBodyBytes (used when descriptor has no json_name)
+func protoJSONName(name string) string {
+	result := new(strings.Builder)
+	upper := false
+	for _, r := range name {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper && r >= 'a' && r <= 'z' {
+			r -= 'a' - 'A'
+		}
+		upper = false
+		result.WriteRune(r)
+	}
+	return result.String()
+}
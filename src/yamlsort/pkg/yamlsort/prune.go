@@ -0,0 +1,80 @@
+//
+// yamlsort - prune empty values (--prune-empty)
+//
+package yamlsort
+
+import (
+	"fmt"
+	"strings"
+)
+
+// categories of --prune-empty
+var pruneCategories = []string{"null", "string", "map", "list"}
+
+// parse --prune-empty value. "all" or comma separated categories.
+func parsePruneCategories(value string) (map[string]bool, error) {
+	result := map[string]bool{}
+	if len(value) == 0 {
+		return result, nil
+	}
+	for _, s := range strings.Split(value, ",") {
+		s = strings.TrimSpace(s)
+		if s == "all" {
+			for _, category := range pruneCategories {
+				result[category] = true
+			}
+			continue
+		}
+		found := false
+		for _, category := range pruneCategories {
+			if s == category {
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("--prune-empty must be all , or comma separated %s : %s", strings.Join(pruneCategories, ","), s)
+		}
+		result[s] = true
+	}
+	return result, nil
+}
+
+// value is empty in prune categories
+func (c *yamlsortCmd) isPrunable(data interface{}) bool {
+	if data == nil {
+		return c.prunecategories["null"]
+	}
+	if m, ok := data.(map[string]interface{}); ok {
+		return len(m) == 0 && c.prunecategories["map"]
+	} else if a, ok := data.([]interface{}); ok {
+		return len(a) == 0 && c.prunecategories["list"]
+	} else if s, ok := data.(string); ok {
+		return len(s) == 0 && c.prunecategories["string"]
+	}
+	return false
+}
+
+// remove keys of empty value. map or list which becomes empty is removed too.
+func (c *yamlsortCmd) pruneEmptyRecursive(data interface{}) interface{} {
+	if m, ok := data.(map[string]interface{}); ok {
+		for k, v := range m {
+			v = c.pruneEmptyRecursive(v)
+			if c.isPrunable(v) {
+				delete(m, k)
+			} else {
+				m[k] = v
+			}
+		}
+		return m
+	} else if a, ok := data.([]interface{}); ok {
+		result := make([]interface{}, 0, len(a))
+		for _, v := range a {
+			v = c.pruneEmptyRecursive(v)
+			if !c.isPrunable(v) {
+				result = append(result, v)
+			}
+		}
+		return result
+	}
+	return data
+}
@@ -0,0 +1,142 @@
+//
+// yamlsort - --policy option
+//
+// evaluate Rego policies for each document with opa command on PATH , and report
+// violations like --rules. normalization and policy gating run in one pass over files.
+// documents of input are evaluated in one opa command (opa is not linked , and its sdk is not used).
+//   yamlsort --policy policy/ --check k8s/
+//   yamlsort --policy policy/ --policy-query data.kubernetes.deny -w k8s/
+// document is input of policy. query (default data.main.deny , same as conftest) returns
+// messages of violations , as list (or set) of strings or objects with "msg".
+//
+package yamlsort
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// default of --policy-query
+const defaultPolicyQuery = "data.main.deny"
+
+// result of "opa eval --format json"
+type opaEvalResult struct {
+	Result []struct {
+		Expressions []struct {
+			Value interface{} `json:"value"`
+		} `json:"expressions"`
+	} `json:"result"`
+}
+
+// check --policy
+func (c *yamlsortCmd) preparePolicy() error {
+	if len(c.policydirs) == 0 {
+		return nil
+	}
+	if _, err := exec.LookPath("opa"); err != nil {
+		return fmt.Errorf("--policy requires opa command on PATH")
+	}
+	if len(c.policyquery) == 0 {
+		c.policyquery = defaultPolicyQuery
+	}
+	return nil
+}
+
+// document as input of policy
+type policyInput struct {
+	index int
+	data  json.RawMessage
+}
+
+func newPolicyInput(doc *Document) (policyInput, error) {
+	data, err := json.Marshal(doc.Data)
+	if err != nil {
+		return policyInput{}, err
+	}
+	return policyInput{index: doc.Index, data: data}, nil
+}
+
+// evaluate policies for documents with one opa command , and keep violations.
+// input of opa is list of documents , and query evaluates policy query for each of them.
+func (c *yamlsortCmd) evaluatePolicy(inputs []policyInput) error {
+	documents := make([]json.RawMessage, len(inputs))
+	for i, input := range inputs {
+		documents[i] = input.data
+	}
+	input, err := json.Marshal(documents)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf("[[yamlsort_index, yamlsort_value] | yamlsort_doc := input[yamlsort_index]; yamlsort_value := %s with input as yamlsort_doc]", c.policyquery)
+	args := []string{"eval", "--format", "json", "--stdin-input"}
+	for _, dir := range c.policydirs {
+		args = append(args, "--data", dir)
+	}
+	args = append(args, query)
+	cmd := exec.Command("opa", args...)
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	err = cmd.Run()
+	if err != nil {
+		return fmt.Errorf("opa eval: %v %s", err, strings.TrimSpace(stderr.String()))
+	}
+	var result opaEvalResult
+	err = json.Unmarshal(stdout.Bytes(), &result)
+	if err != nil {
+		return fmt.Errorf("opa eval: %v", err)
+	}
+	for _, r := range result.Result {
+		for _, expression := range r.Expressions {
+			// [index , value] of documents. undefined value is not in list.
+			pairs, _ := expression.Value.([]interface{})
+			for _, pair := range pairs {
+				p, _ := pair.([]interface{})
+				if len(p) != 2 {
+					return fmt.Errorf("opa eval: unexpected result %v", pair)
+				}
+				i, ok := p[0].(float64)
+				if !ok || i < 0 || int(i) >= len(inputs) {
+					return fmt.Errorf("opa eval: unexpected result %v", pair)
+				}
+				for _, message := range policyMessages(p[1]) {
+					c.addViolation(&Document{Index: inputs[int(i)].index}, c.policyquery, message)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// messages of query value. undefined or false is no violation.
+func policyMessages(value interface{}) []string {
+	result := []string{}
+	switch v := value.(type) {
+	case nil:
+	case bool:
+		if v {
+			result = append(result, "policy is violated")
+		}
+	case string:
+		result = append(result, v)
+	case []interface{}:
+		for _, item := range v {
+			result = append(result, policyMessages(item)...)
+		}
+	case map[string]interface{}:
+		if msg, ok := v["msg"]; ok {
+			result = append(result, fmt.Sprint(msg))
+		} else {
+			text, _ := json.Marshal(v)
+			result = append(result, string(text))
+		}
+	default:
+		result = append(result, fmt.Sprint(v))
+	}
+	return result
+}
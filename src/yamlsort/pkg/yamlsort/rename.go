@@ -0,0 +1,171 @@
+//
+// yamlsort - rename subcommand
+//
+package yamlsort
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// one rename rule. --from path --to path
+type renameRule struct {
+	from    []pathSegment
+	to      []pathSegment
+	matched bool
+}
+
+//---------------------------------------------------------------------
+//  rename subcommand
+// move keys within documents, and output sorted.
+//
+func newRenameCmd(yamlsort *yamlsortCmd) *cobra.Command {
+	var froms []string
+	var tos []string
+	var mappingfilename string
+
+	cmd := &cobra.Command{
+		Use:   "rename [file or directory...]",
+		Short: "move keys (--from old.path --to new.path) and output sorted",
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(froms) != len(tos) {
+				return fmt.Errorf("--from and --to must be specified in pairs")
+			}
+			for i := range froms {
+				err := yamlsort.addRenameRule(froms[i], tos[i])
+				if err != nil {
+					return err
+				}
+			}
+			if len(mappingfilename) > 0 {
+				err := yamlsort.loadRenameMapping(mappingfilename)
+				if err != nil {
+					return err
+				}
+			}
+			if len(yamlsort.renames) == 0 {
+				return fmt.Errorf("rename requires --from and --to , or --mapping-file")
+			}
+			err := yamlsort.run(args)
+			if err != nil {
+				return err
+			}
+			for _, rule := range yamlsort.renames {
+				if !rule.matched {
+					// output is written already
+					c.SilenceUsage = true
+					return fmt.Errorf("rename: %s is not found", pathString(rule.from))
+				}
+			}
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	addInputOutputFlags(f, yamlsort)
+	f.BoolVarP(&yamlsort.blnWrite, "write", "w", false, "write result to file arguments in place , instead of stdout")
+	f.BoolVar(&yamlsort.blnDryRun, "dry-run", false, "with -w or -f , show unified diff and write nothing")
+	f.StringArrayVar(&froms, "from", []string{}, "key path to move from. (can specify multiple values with --to)")
+	f.StringArrayVar(&tos, "to", []string{}, "key path to move to")
+	f.StringVar(&mappingfilename, "mapping-file", "", "path to mapping file. map of old.path: new.path , or list of {from: , to: }")
+
+	return cmd
+}
+
+func (c *yamlsortCmd) addRenameRule(from string, to string) error {
+	fromsegs, err := parsePath(from)
+	if err != nil {
+		return err
+	}
+	tosegs, err := parsePath(to)
+	if err != nil {
+		return err
+	}
+	// wildcard is allowed in common parent path of from and to
+	n := commonPathLength(fromsegs, tosegs)
+	for _, segs := range [][]pathSegment{fromsegs[n:], tosegs[n:]} {
+		for _, seg := range segs {
+			if seg.isWildcard() {
+				return fmt.Errorf("rename %s to %s: wildcard is allowed only in common parent path", from, to)
+			}
+		}
+	}
+	c.renames = append(c.renames, renameRule{from: fromsegs, to: tosegs})
+	return nil
+}
+
+// mapping file. map of from: to , or list of {from: , to: }
+func (c *yamlsortCmd) loadRenameMapping(filename string) error {
+	data, err := c.myLoadFromFile(filename)
+	if err != nil {
+		return err
+	}
+	if m, ok := data.(map[string]interface{}); ok {
+		keylist := make([]string, 0, len(m))
+		for k := range m {
+			keylist = append(keylist, k)
+		}
+		sortKeys(keylist)
+		for _, k := range keylist {
+			to, ok := m[k].(string)
+			if !ok {
+				return fmt.Errorf("%s: value of %s is not string", filename, k)
+			}
+			err := c.addRenameRule(k, to)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if a, ok := data.([]interface{}); ok {
+		for _, elem := range a {
+			m, _ := elem.(map[string]interface{})
+			from, ok1 := m["from"].(string)
+			to, ok2 := m["to"].(string)
+			if !ok1 || !ok2 {
+				return fmt.Errorf("%s: element must have from and to : %v", filename, elem)
+			}
+			err := c.addRenameRule(from, to)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("%s: mapping file must be map or list", filename)
+}
+
+// length of common parent path. last segment of from and to is not counted.
+func commonPathLength(from []pathSegment, to []pathSegment) int {
+	n := 0
+	for n < len(from)-1 && n < len(to)-1 && from[n] == to[n] {
+		n++
+	}
+	return n
+}
+
+//-------------------------------------------------------------------------
+// apply rename rules to data
+//
+func (c *yamlsortCmd) applyRenames(data interface{}) (interface{}, error) {
+	for i := range c.renames {
+		rule := &c.renames[i]
+		n := commonPathLength(rule.from, rule.to)
+		result, err := mapPathNodes(data, rule.from[:n], func(node interface{}) (interface{}, error) {
+			value, found := getPath(node, rule.from[n:])
+			if !found {
+				return node, nil
+			}
+			rule.matched = true
+			node, _ = deletePath(node, rule.from[n:])
+			return setPath(node, rule.to[n:], value)
+		})
+		if err != nil {
+			return data, fmt.Errorf("rename %s to %s: %v", pathString(rule.from), pathString(rule.to), err)
+		}
+		data = result
+	}
+	return data, nil
+}
@@ -0,0 +1,113 @@
+//
+// yamlsort - render go template in string values (--render)
+//
+package yamlsort
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+//---------------------------------------------------------------------
+//  templateRenderer class
+// string value which contains {{ ... }} is go template.
+// template data is {"Values": values file data}, like helm.
+//
+type templateRenderer struct {
+	c         *yamlsortCmd
+	values    interface{}
+	funcs     template.FuncMap
+	templates map[string]*template.Template
+}
+
+func (c *yamlsortCmd) newTemplateRenderer() (*templateRenderer, error) {
+	var values interface{} = map[string]interface{}{}
+	for _, filename := range c.valuesfilenames {
+		data, err := c.myLoadFromFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		// later values file overrides
+		values, err = c.myOverride(values, data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	r := &templateRenderer{
+		c:         c,
+		values:    values,
+		templates: map[string]*template.Template{},
+	}
+	r.funcs = template.FuncMap{
+		"default": func(def interface{}, value interface{}) interface{} {
+			if value == nil || value == "" || value == false {
+				return def
+			}
+			return value
+		},
+		"quote": func(value interface{}) string {
+			return fmt.Sprintf("%q", fmt.Sprint(value))
+		},
+		"upper":   strings.ToUpper,
+		"lower":   strings.ToLower,
+		"trim":    strings.TrimSpace,
+		"replace": func(old, new, s string) string { return strings.Replace(s, old, new, -1) },
+	}
+	return r, nil
+}
+
+// render all string values in data
+func (r *templateRenderer) renderRecursive(path string, data interface{}) (interface{}, error) {
+	if m, ok := data.(map[string]interface{}); ok {
+		for k, v := range m {
+			result, err := r.renderRecursive(r.c.calcPathMap(path, k), v)
+			if err != nil {
+				return data, err
+			}
+			m[k] = result
+		}
+	} else if a, ok := data.([]interface{}); ok {
+		for i, v := range a {
+			result, err := r.renderRecursive(r.c.calcPathSlice(path, i), v)
+			if err != nil {
+				return data, err
+			}
+			a[i] = result
+		}
+	} else if s, ok := data.(string); ok {
+		if !strings.Contains(s, "{{") {
+			return s, nil
+		}
+		rendered, err := r.render(s)
+		if err != nil {
+			return data, fmt.Errorf("render %s: %v", path, err)
+		}
+		// value which is only one template like "{{ .Values.replicas }}" is read as yaml scalar
+		if strings.HasPrefix(s, "{{") && strings.Index(s, "}}") == len(s)-2 {
+			return readScalar(rendered), nil
+		}
+		return rendered, nil
+	}
+	return data, nil
+}
+
+func (r *templateRenderer) render(text string) (string, error) {
+	tmpl, ok := r.templates[text]
+	if !ok {
+		var err error
+		tmpl, err = template.New("value").Funcs(r.funcs).Parse(text)
+		if err != nil {
+			return "", err
+		}
+		r.templates[text] = tmpl
+	}
+	buffer := new(bytes.Buffer)
+	err := tmpl.Execute(buffer, map[string]interface{}{"Values": r.values})
+	if err != nil {
+		return "", err
+	}
+	// missing value is empty string, like helm
+	return strings.Replace(buffer.String(), "<no value>", "", -1), nil
+}
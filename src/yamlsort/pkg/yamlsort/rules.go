@@ -0,0 +1,270 @@
+//
+// yamlsort - --rules option
+//
+// evaluate assertions of rule file for each document , like conftest.
+//   yamlsort --rules policy.yaml -w k8s/
+// rule file
+//   rules:
+//   - name: min-replicas
+//     when: kind==Deployment                     # same expression as --select (optional)
+//     path: spec.replicas
+//     op: ">="
+//     value: 2
+//     message: deployment needs 2 or more replicas  # optional
+//   - name: image-tag
+//     path: spec.template.spec.containers[*].image  # each container is checked
+//     op: matches
+//     value: ':[0-9]'
+// op is exists (default) , missing , == , != , > , >= , < , <= , matches (regexp) , in , not-in (value is list).
+// violations are written to stderr , and exit status is 1. with --check , they are findings.
+//
+package yamlsort
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// rule name of --check finding
+const checkRulePolicy = "policy"
+
+// one rule of rule file
+type policyRule struct {
+	Name    string      `json:"name"`
+	When    string      `json:"when"`
+	Path    string      `json:"path"`
+	Op      string      `json:"op"`
+	Value   interface{} `json:"value"`
+	Message string      `json:"message"`
+
+	conditions [][]selectCondition
+	segs       []pathSegment
+	regexp     *regexp.Regexp
+}
+
+// rule file
+type policyFile struct {
+	Rules []*policyRule `json:"rules"`
+}
+
+// violation of rule in document
+type policyViolation struct {
+	file    string
+	index   int
+	rule    string
+	message string
+}
+
+// read and check rule file
+func loadPolicyRules(filename string) ([]*policyRule, error) {
+	input, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var policy policyFile
+	err = yaml.Unmarshal(input, &policy)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	for i, rule := range policy.Rules {
+		if len(rule.Name) == 0 {
+			rule.Name = "rule" + strconv.Itoa(i)
+		}
+		if len(rule.Op) == 0 {
+			rule.Op = "exists"
+		}
+		if len(rule.When) > 0 {
+			rule.conditions, err = parseSelect(rule.When)
+			if err != nil {
+				return nil, fmt.Errorf("%s: rule %s: %v", filename, rule.Name, err)
+			}
+		}
+		rule.segs, err = parsePath(rule.Path)
+		if err != nil || len(rule.Path) == 0 {
+			return nil, fmt.Errorf("%s: rule %s: invalid path %q", filename, rule.Name, rule.Path)
+		}
+		switch rule.Op {
+		case "exists", "missing", "==", "!=", ">", ">=", "<", "<=":
+		case "matches":
+			rule.regexp, err = regexp.Compile(fmt.Sprint(rule.Value))
+			if err != nil {
+				return nil, fmt.Errorf("%s: rule %s: %v", filename, rule.Name, err)
+			}
+		case "in", "not-in":
+			if _, ok := rule.Value.([]interface{}); !ok {
+				return nil, fmt.Errorf("%s: rule %s: value of %s must be list", filename, rule.Name, rule.Op)
+			}
+		default:
+			return nil, fmt.Errorf("%s: rule %s: unknown op %q", filename, rule.Name, rule.Op)
+		}
+	}
+	return policy.Rules, nil
+}
+
+// evaluate rules for document , and keep violations
+func (c *yamlsortCmd) evaluateRules(doc *Document) {
+	for _, rule := range c.policyrules {
+		if len(rule.conditions) > 0 && !matchConditions(rule.conditions, doc.Data) {
+			continue
+		}
+		for _, message := range rule.evaluate(doc.Data) {
+			c.addViolation(doc, rule.Name, message)
+		}
+	}
+}
+
+// keep violation , and write it to stderr (without --check)
+func (c *yamlsortCmd) addViolation(doc *Document, rule string, message string) {
+	source := "-"
+	if len(c.inputfilename) > 0 {
+		source = c.inputfilename
+	}
+	c.violations = append(c.violations, policyViolation{file: source, index: doc.Index, rule: rule, message: message})
+	if !c.blnCheck {
+		fmt.Fprintf(c.stderr, "policy violation: %s (document %d) %s: %s\n", source, doc.Index, rule, message)
+	}
+}
+
+// messages of violations. node of last wildcard in path is checked one by one.
+func (rule *policyRule) evaluate(data interface{}) []string {
+	last := -1
+	for i, seg := range rule.segs {
+		if seg.isWildcard() {
+			last = i
+		}
+	}
+	result := []string{}
+	check := func(prefix string, node interface{}) {
+		value, found := getPath(node, rule.segs[last+1:])
+		if !rule.satisfied(value, found) {
+			result = append(result, rule.violationMessage(prefix, value, found))
+		}
+	}
+	if last < 0 {
+		check("", data)
+		return result
+	}
+	i := 0
+	mapPathNodes(data, rule.segs[:last+1], func(node interface{}) (interface{}, error) {
+		check(fmt.Sprintf("%s #%d: ", pathString(rule.segs[:last+1]), i), node)
+		i++
+		return node, nil
+	})
+	return result
+}
+
+// value at path satisfies rule
+func (rule *policyRule) satisfied(value interface{}, found bool) bool {
+	switch rule.Op {
+	case "exists":
+		return found
+	case "missing":
+		return !found
+	}
+	if !found {
+		return rule.Op == "!=" || rule.Op == "not-in"
+	}
+	switch rule.Op {
+	case "==":
+		return policyEqual(value, rule.Value)
+	case "!=":
+		return !policyEqual(value, rule.Value)
+	case "matches":
+		return rule.regexp.MatchString(scalarText(value))
+	case "in", "not-in":
+		in := false
+		for _, v := range rule.Value.([]interface{}) {
+			if policyEqual(value, v) {
+				in = true
+			}
+		}
+		return in == (rule.Op == "in")
+	}
+	// compare numbers
+	f1, ok1 := value.(float64)
+	f2, ok2 := rule.Value.(float64)
+	if !ok1 || !ok2 {
+		return false
+	}
+	switch rule.Op {
+	case ">":
+		return f1 > f2
+	case ">=":
+		return f1 >= f2
+	case "<":
+		return f1 < f2
+	}
+	return f1 <= f2
+}
+
+// values are same (number , string , bool , null)
+func policyEqual(a interface{}, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return scalarText(a) == scalarText(b)
+}
+
+// text of value in message. numbers are like 1000000 (not 1e+06)
+func policyValueText(value interface{}) string {
+	if a, ok := value.([]interface{}); ok {
+		texts := make([]string, len(a))
+		for i, v := range a {
+			texts[i] = policyValueText(v)
+		}
+		return "[" + strings.Join(texts, " ") + "]"
+	}
+	if value == nil {
+		return "null"
+	}
+	return scalarText(value)
+}
+
+// message of violation
+func (rule *policyRule) violationMessage(prefix string, value interface{}, found bool) string {
+	if len(rule.Message) > 0 {
+		return prefix + rule.Message
+	}
+	path := pathString(rule.segs)
+	switch {
+	case rule.Op == "exists":
+		return prefix + path + " is required"
+	case rule.Op == "missing":
+		return prefix + path + " must not exist"
+	case !found:
+		return fmt.Sprintf("%s%s is required (%s %s)", prefix, path, rule.Op, policyValueText(rule.Value))
+	}
+	return fmt.Sprintf("%s%s must be %s %s , but %s", prefix, path, rule.Op, policyValueText(rule.Value), policyValueText(value))
+}
+
+// prepare --rules
+func (c *yamlsortCmd) prepareRules() error {
+	if len(c.rulesfilename) == 0 {
+		return nil
+	}
+	rules, err := loadPolicyRules(c.rulesfilename)
+	if err != nil {
+		return err
+	}
+	c.policyrules = rules
+	return nil
+}
+
+// findings of --check from violations
+func violationFindings(name string, violations []policyViolation) []checkFinding {
+	result := []checkFinding{}
+	for _, v := range violations {
+		result = append(result, checkFinding{
+			file:    name,
+			line:    1,
+			rule:    checkRulePolicy,
+			message: fmt.Sprintf("document %d: %s: %s", v.index, v.rule, strings.TrimSpace(v.message)),
+		})
+	}
+	return result
+}
@@ -0,0 +1,271 @@
+//
+// yamlsort - scaffold subcommand
+//
+// write skeleton yaml of json schema , with all properties in sorted order ,
+// default values and descriptions as comments , for authoring new config files.
+//   yamlsort scaffold --schema schema.json > config.yaml
+// value of property is default , const , first of examples , first of enum ,
+// or empty value of type ("" , 0 , false , {} , []). array of objects has one skeleton element.
+// local $ref is followed , and first schema of allOf / anyOf / oneOf is used.
+// schema can be written in yaml.
+//
+package yamlsort
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+)
+
+// max depth of skeleton , for recursive $ref
+const maxScaffoldDepth = 32
+
+//---------------------------------------------------------------------
+//  scaffolder class
+// build skeleton data and comments of keys from json schema
+//
+type scaffolder struct {
+	c        *yamlsortCmd
+	schema   *helmSchema
+	comments map[string][]string // path -> comment lines
+}
+
+// comment lines of schema node (description , or title)
+func scaffoldComment(node *jsonNode) []string {
+	if node == nil {
+		return nil
+	}
+	for _, name := range []string{"description", "title"} {
+		if text, ok := node.fields[name]; ok {
+			if s, ok := text.value.(string); ok && len(strings.TrimSpace(s)) > 0 {
+				return strings.Split(strings.TrimSpace(s), "\n")
+			}
+		}
+	}
+	return nil
+}
+
+// schema node which has type or properties. first of allOf , anyOf , oneOf is used.
+func (s *scaffolder) resolve(node *jsonNode) *jsonNode {
+	for i := 0; i < maxScaffoldDepth; i++ {
+		node = s.schema.resolve(node)
+		if node == nil || node.fields == nil {
+			return nil
+		}
+		if _, ok := node.fields["type"]; ok {
+			return node
+		}
+		if _, ok := node.fields["properties"]; ok {
+			return node
+		}
+		var next *jsonNode
+		for _, name := range []string{"allOf", "anyOf", "oneOf"} {
+			if list, ok := node.fields[name]; ok && len(list.items) > 0 {
+				next = list.items[0]
+				break
+			}
+		}
+		if next == nil {
+			return node
+		}
+		node = next
+	}
+	return nil
+}
+
+// type name of schema node. first type which is not null , when type is list.
+func scaffoldType(node *jsonNode) string {
+	t, ok := node.fields["type"]
+	if !ok {
+		if _, ok := node.fields["properties"]; ok {
+			return "object"
+		}
+		return ""
+	}
+	if s, ok := t.value.(string); ok {
+		return s
+	}
+	for _, item := range t.items {
+		if s, ok := item.value.(string); ok && s != "null" {
+			return s
+		}
+	}
+	return "null"
+}
+
+// skeleton value of schema node at path
+func (s *scaffolder) value(node *jsonNode, path string, depth int) (interface{}, error) {
+	node = s.resolve(node)
+	if node == nil || depth > maxScaffoldDepth {
+		return nil, nil
+	}
+	for _, name := range []string{"default", "const"} {
+		if v, ok := node.fields[name]; ok {
+			return jsonNodeValue(v)
+		}
+	}
+	for _, name := range []string{"examples", "enum"} {
+		if v, ok := node.fields[name]; ok && len(v.items) > 0 {
+			return jsonNodeValue(v.items[0])
+		}
+	}
+	switch scaffoldType(node) {
+	case "object":
+		result := map[string]interface{}{}
+		properties := s.schema.resolve(node.fields["properties"])
+		if properties == nil {
+			return result, nil
+		}
+		for _, k := range properties.keys {
+			childpath := s.c.calcPathMap(path, k)
+			child, err := s.value(properties.fields[k], childpath, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			result[k] = child
+			// description of property , or description of referred schema
+			comment := scaffoldComment(properties.fields[k])
+			if property := s.resolve(properties.fields[k]); len(comment) == 0 && property != nil {
+				comment = scaffoldComment(property)
+			}
+			if len(comment) > 0 {
+				s.comments[childpath] = comment
+			}
+		}
+		return result, nil
+	case "array":
+		items := s.resolve(node.fields["items"])
+		if items == nil || scaffoldType(items) != "object" {
+			return []interface{}{}, nil
+		}
+		// one skeleton element. comments are moved to path of element.
+		comments := s.comments
+		s.comments = map[string][]string{}
+		child, err := s.value(items, "", depth+1)
+		if err != nil {
+			return nil, err
+		}
+		elementpath := s.c.calcPathSliceElement(path, 0, child)
+		for p, comment := range s.comments {
+			comments[elementpath+"."+p] = comment
+		}
+		s.comments = comments
+		return []interface{}{child}, nil
+	case "string":
+		return "", nil
+	case "integer", "number":
+		return float64(0), nil
+	case "boolean":
+		return false, nil
+	}
+	return nil, nil
+}
+
+// value of json node , like json.Unmarshal
+func jsonNodeValue(node *jsonNode) (interface{}, error) {
+	switch {
+	case node.fields != nil:
+		result := map[string]interface{}{}
+		for k, child := range node.fields {
+			v, err := jsonNodeValue(child)
+			if err != nil {
+				return nil, err
+			}
+			result[k] = v
+		}
+		return result, nil
+	case node.items != nil:
+		result := []interface{}{}
+		for _, child := range node.items {
+			v, err := jsonNodeValue(child)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, v)
+		}
+		return result, nil
+	}
+	if n, ok := node.value.(json.Number); ok {
+		return n.Float64()
+	}
+	return node.value, nil
+}
+
+// write comment lines of key in skeleton. first key of slice element follows "- ".
+func (c *yamlsortCmd) writeKeyComments(writer *bytes.Buffer, path string, level int, blnAfterDash bool) bool {
+	comment, ok := c.keycomments[path]
+	if !ok {
+		return false
+	}
+	for i, line := range comment {
+		if i > 0 || !blnAfterDash {
+			writer.WriteString(c.indentstr(level))
+		}
+		writer.WriteString(strings.TrimRight("# "+strings.TrimSpace(line), " ") + "\n")
+	}
+	return true
+}
+
+//---------------------------------------------------------------------
+//  scaffold subcommand
+//
+func newScaffoldCmd(yamlsort *yamlsortCmd) *cobra.Command {
+	var schemafilename string
+
+	cmd := &cobra.Command{
+		Use:   "scaffold --schema schema.json",
+		Short: "write skeleton yaml of json schema with default values and description comments",
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(schemafilename) == 0 {
+				return fmt.Errorf("scaffold requires --schema")
+			}
+			yamlsort.maxlinesize = defaultMaxLineSize
+			err := yamlsort.prepareOptions()
+			if err != nil {
+				return err
+			}
+			return yamlsort.scaffold(schemafilename)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&schemafilename, "schema", "", "json schema file (json or yaml)")
+
+	return cmd
+}
+
+func (c *yamlsortCmd) scaffold(schemafilename string) error {
+	input, err := ioutil.ReadFile(schemafilename)
+	if err != nil {
+		return err
+	}
+	schematext, err := yaml.YAMLToJSON(input)
+	if err != nil {
+		return fmt.Errorf("%s: %v", schemafilename, err)
+	}
+	root, err := parseOrderedJSON(schematext)
+	if err != nil {
+		return fmt.Errorf("%s: %v", schemafilename, err)
+	}
+	s := &scaffolder{c: c, schema: &helmSchema{root: root}, comments: map[string][]string{}}
+	data, err := s.value(root, "", 0)
+	if err != nil {
+		return fmt.Errorf("%s: %v", schemafilename, err)
+	}
+	doc := &Document{Data: data}
+	if resolved := s.resolve(root); resolved != nil {
+		if comment := scaffoldComment(resolved); len(comment) > 0 {
+			doc.Comment = "# " + strings.TrimSpace(comment[0]) + "  "
+		}
+	}
+	// empty list and map of skeleton are written as [] and {}
+	c.blnFlowEmptyList = true
+	c.blnFlowEmptyMap = true
+	c.keycomments = s.comments
+	return c.writeDocument(c.stdout, doc)
+}
@@ -0,0 +1,216 @@
+//
+// yamlsort - --normalize-scalar option
+//
+// normalize scalar values at path into one form , for teams standardizing how values are written.
+//   yamlsort --normalize-scalar 'spec.timeout=duration' --normalize-scalar 'spec.*.maxSize=size' -i app.yaml
+//   yamlsort --normalize-scalar-file normalizers.yaml -i app.yaml
+// normalizers
+//   duration   go duration , like 90s -> 1m30s   1.5h -> 1h30m   3600000ms -> 1h
+//   size       bytes , like 1048576 -> 1Mi   2048KiB -> 2Mi   1000000 -> 1M   1.5GB -> 1500M
+//   quantity   kubernetes quantity , like 1024Mi -> 1Gi   0.5 -> 500m (same as --normalize-quantities)
+// path can have wildcards , like spec.*.timeout or items[*].size. normalizer file is yaml map of
+// path and normalizer.
+//   spec.template.spec.terminationGracePeriod: duration
+//   storage[*].size: size
+// values which can not be parsed are not changed. number stays number , when normalized form has no unit.
+//
+package yamlsort
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+)
+
+// normalizer returns normalized text. false when text can not be normalized.
+type scalarNormalizer func(text string) (string, bool)
+
+// normalizers of --normalize-scalar. add new normalizer here.
+var scalarNormalizers = map[string]scalarNormalizer{
+	"duration": normalizeDuration,
+	"size":     normalizeSize,
+	"quantity": normalizeQuantity,
+}
+
+// size text , number and unit (B , KB , KiB , Mi ...)
+var sizeRegexp = regexp.MustCompile(`^([0-9]+(?:\.[0-9]*)?|\.[0-9]+) *([kKMGTPE]i?)?B?$`)
+
+// normalizer of values at path
+type scalarNormRule struct {
+	segs       []pathSegment
+	name       string
+	normalizer scalarNormalizer
+}
+
+// parse "normalizer" of path
+func parseScalarNormRule(path string, name string) (scalarNormRule, error) {
+	segs, err := parsePath(path)
+	if err != nil || len(path) == 0 {
+		return scalarNormRule{}, fmt.Errorf("invalid path of normalizer %q", path)
+	}
+	normalizer, ok := scalarNormalizers[name]
+	if !ok {
+		names := make([]string, 0, len(scalarNormalizers))
+		for k := range scalarNormalizers {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		return scalarNormRule{}, fmt.Errorf("unknown normalizer %q of %s , %s", name, path, strings.Join(names, " , "))
+	}
+	return scalarNormRule{segs: segs, name: name, normalizer: normalizer}, nil
+}
+
+// rules of --normalize-scalar-file and --normalize-scalar. later one is applied later.
+func (c *yamlsortCmd) prepareScalarNorms() error {
+	c.scalarnorms = nil
+	if len(c.scalarnormfile) > 0 {
+		input, err := ioutil.ReadFile(c.scalarnormfile)
+		if err != nil {
+			return err
+		}
+		normalizers := map[string]string{}
+		err = yaml.Unmarshal(input, &normalizers)
+		if err != nil {
+			return fmt.Errorf("%s: %v", c.scalarnormfile, err)
+		}
+		paths := make([]string, 0, len(normalizers))
+		for path := range normalizers {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			rule, err := parseScalarNormRule(path, normalizers[path])
+			if err != nil {
+				return fmt.Errorf("%s: %v", c.scalarnormfile, err)
+			}
+			c.scalarnorms = append(c.scalarnorms, rule)
+		}
+	}
+	for _, arg := range c.scalarnormargs {
+		idx := strings.LastIndex(arg, "=")
+		if idx < 0 {
+			return fmt.Errorf("--normalize-scalar requires path=normalizer , but %q", arg)
+		}
+		rule, err := parseScalarNormRule(arg[:idx], arg[idx+1:])
+		if err != nil {
+			return err
+		}
+		c.scalarnorms = append(c.scalarnorms, rule)
+	}
+	return nil
+}
+
+// normalize values at paths of rules
+func (c *yamlsortCmd) applyScalarNorms(data interface{}) interface{} {
+	for _, rule := range c.scalarnorms {
+		normalizer := rule.normalizer
+		data, _ = mapPathNodes(data, rule.segs, func(node interface{}) (interface{}, error) {
+			return normalizeScalar(node, normalizer), nil
+		})
+	}
+	return data
+}
+
+// normalized value of string or number. other values are not changed.
+func normalizeScalar(value interface{}, normalizer scalarNormalizer) interface{} {
+	switch v := value.(type) {
+	case string:
+		if text, ok := normalizer(v); ok {
+			return text
+		}
+	case float64:
+		text, ok := normalizer(strconv.FormatFloat(v, 'f', -1, 64))
+		if !ok {
+			return v
+		}
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return f
+		}
+		return text
+	}
+	return value
+}
+
+// go duration text without zero units. "90s" -> "1m30s" , "1h0m0s" -> "1h"
+func normalizeDuration(text string) (string, bool) {
+	if _, err := strconv.ParseFloat(text, 64); err == nil {
+		// number without unit
+		return "", false
+	}
+	d, err := time.ParseDuration(text)
+	if err != nil {
+		return "", false
+	}
+	if d == 0 {
+		return "0s", true
+	}
+	result := ""
+	if d < 0 {
+		result = "-"
+		d = -d
+	}
+	if d < time.Second {
+		for _, unit := range []struct {
+			suffix string
+			value  time.Duration
+		}{{"ms", time.Millisecond}, {"us", time.Microsecond}, {"ns", time.Nanosecond}} {
+			if d%unit.value == 0 {
+				return result + strconv.FormatInt(int64(d/unit.value), 10) + unit.suffix, true
+			}
+		}
+	}
+	if h := d / time.Hour; h > 0 {
+		result = result + strconv.FormatInt(int64(h), 10) + "h"
+		d -= h * time.Hour
+	}
+	if m := d / time.Minute; m > 0 {
+		result = result + strconv.FormatInt(int64(m), 10) + "m"
+		d -= m * time.Minute
+	}
+	if d > 0 {
+		result = result + strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+	}
+	return result, true
+}
+
+// bytes in largest exact binary unit (Ki , Mi ...) , or decimal unit (k , M ...). bytes must be integer.
+func normalizeSize(text string) (string, bool) {
+	m := sizeRegexp.FindStringSubmatch(text)
+	if m == nil || len(text) > 64 {
+		return "", false
+	}
+	value, ok := new(big.Rat).SetString(m[1])
+	if !ok {
+		return "", false
+	}
+	unit := m[2]
+	switch unit {
+	case "K":
+		unit = "k"
+	case "ki":
+		unit = "Ki"
+	}
+	value.Mul(value, quantityMultiplier(unit))
+	if !value.IsInt() {
+		return "", false
+	}
+	if value.Sign() == 0 {
+		return "0", true
+	}
+	for _, suffixes := range [][]string{k8sBinarySuffixes[:len(k8sBinarySuffixes)-1], k8sDecimalSuffixes} {
+		for _, suffix := range suffixes {
+			scaled := new(big.Rat).Quo(value, quantityMultiplier(suffix))
+			if scaled.IsInt() {
+				return scaled.Num().String() + suffix, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,184 @@
+//
+// yamlsort - embedded starlark script transform (--script)
+//
+package yamlsort
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"go.starlark.net/starlark"
+)
+
+//---------------------------------------------------------------------
+//  starlarkScript class
+// script file defines function transform(doc). it returns new document,
+// or None to drop the document.
+//
+//   def transform(doc):
+//       doc["metadata"]["labels"]["team"] = "payments"
+//       return doc
+//
+type starlarkScript struct {
+	filename  string
+	thread    *starlark.Thread
+	transform starlark.Value
+}
+
+func loadStarlarkScript(filename string) (*starlarkScript, error) {
+	thread := &starlark.Thread{Name: "yamlsort"}
+	globals, err := starlark.ExecFile(thread, filename, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	transform, ok := globals["transform"]
+	if !ok {
+		return nil, fmt.Errorf("%s: function transform(doc) is not defined", filename)
+	}
+	if _, ok := transform.(starlark.Callable); !ok {
+		return nil, fmt.Errorf("%s: transform is not a function", filename)
+	}
+	return &starlarkScript{filename: filename, thread: thread, transform: transform}, nil
+}
+
+// call transform(doc) of script
+func (s *starlarkScript) run(data interface{}) (interface{}, error) {
+	value, err := toStarlarkValue(data)
+	if err != nil {
+		return nil, err
+	}
+	result, err := starlark.Call(s.thread, s.transform, starlark.Tuple{value}, nil)
+	if err != nil {
+		if evalErr, ok := err.(*starlark.EvalError); ok {
+			return nil, fmt.Errorf("%s: %s", s.filename, evalErr.Backtrace())
+		}
+		return nil, fmt.Errorf("%s: %v", s.filename, err)
+	}
+	data, err = fromStarlarkValue(result, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", s.filename, err)
+	}
+	return data, nil
+}
+
+// convert unmarshaled data to starlark value
+func toStarlarkValue(data interface{}) (starlark.Value, error) {
+	if data == nil {
+		return starlark.None, nil
+	}
+	if m, ok := data.(map[string]interface{}); ok {
+		keylist := make([]string, 0, len(m))
+		for k := range m {
+			keylist = append(keylist, k)
+		}
+		sortKeys(keylist)
+		dict := starlark.NewDict(len(m))
+		for _, k := range keylist {
+			v, err := toStarlarkValue(m[k])
+			if err != nil {
+				return nil, err
+			}
+			dict.SetKey(starlark.String(k), v)
+		}
+		return dict, nil
+	} else if a, ok := data.([]interface{}); ok {
+		elems := make([]starlark.Value, 0, len(a))
+		for _, elem := range a {
+			v, err := toStarlarkValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, v)
+		}
+		return starlark.NewList(elems), nil
+	} else if s, ok := data.(string); ok {
+		return starlark.String(s), nil
+	} else if i, ok := data.(int); ok {
+		return starlark.MakeInt(i), nil
+	} else if f64, ok := data.(float64); ok {
+		// numbers are float64 after unmarshal. integer value is int in script.
+		if f64 == math.Trunc(f64) && math.Abs(f64) < 1e15 {
+			return starlark.MakeInt64(int64(f64)), nil
+		}
+		return starlark.Float(f64), nil
+	} else if b, ok := data.(bool); ok {
+		return starlark.Bool(b), nil
+	}
+	return nil, fmt.Errorf("unknown type:%T  data:%v", data, data)
+}
+
+// convert starlark value to data for marshal. ancestors are dicts and lists which contain value.
+func fromStarlarkValue(value starlark.Value, ancestors []starlark.Value) (interface{}, error) {
+	switch value.(type) {
+	case *starlark.Dict, *starlark.List:
+		for _, ancestor := range ancestors {
+			if ancestor == value {
+				return nil, fmt.Errorf("%s contains itself (cycle) , it can not be written as yaml", value.Type())
+			}
+		}
+		ancestors = append(ancestors, value)
+	}
+	switch v := value.(type) {
+	case starlark.NoneType:
+		return nil, nil
+	case *starlark.Dict:
+		result := map[string]interface{}{}
+		for _, item := range v.Items() {
+			k, ok := item[0].(starlark.String)
+			if !ok {
+				return nil, fmt.Errorf("map key must be string: %v", item[0])
+			}
+			elem, err := fromStarlarkValue(item[1], ancestors)
+			if err != nil {
+				return nil, err
+			}
+			result[string(k)] = elem
+		}
+		return result, nil
+	case *starlark.List:
+		result := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			elem, err := fromStarlarkValue(v.Index(i), ancestors)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, elem)
+		}
+		return result, nil
+	case starlark.Tuple:
+		return fromStarlarkValue(starlark.NewList(v), ancestors)
+	case starlark.String:
+		return string(v), nil
+	case starlark.Int:
+		i, ok := v.Int64()
+		if !ok {
+			return nil, fmt.Errorf("int value is too large: %v", v)
+		}
+		return float64(i), nil
+	case starlark.Float:
+		return float64(v), nil
+	case starlark.Bool:
+		return bool(v), nil
+	case *starlark.Set:
+		// set is sorted list
+		elems := []string{}
+		iter := v.Iterate()
+		defer iter.Done()
+		var x starlark.Value
+		for iter.Next(&x) {
+			s, ok := x.(starlark.String)
+			if !ok {
+				return nil, fmt.Errorf("set element must be string: %v", x)
+			}
+			elems = append(elems, string(s))
+		}
+		sort.Strings(elems)
+		result := make([]interface{}, 0, len(elems))
+		for _, s := range elems {
+			result = append(result, s)
+		}
+		return result, nil
+	}
+	return nil, fmt.Errorf("unsupported script value type:%s  value:%v", value.Type(), value)
+}
@@ -0,0 +1,152 @@
+//
+// yamlsort - document selection
+//
+// choose documents of multi-document stream.
+//   --doc 0,2                                         documents by index (0 origin)
+//   --select 'kind==Deployment && metadata.name=="api"' documents which match expression
+//   --unselected keep                                 pass other documents through unchanged (default skip)
+// expression is conditions joined by && and || (&& first).
+//   path==value , path!=value , path (exists) , !path (not exists)
+// path is same form as --skip-key , and value is bare or quoted ("..." or '...').
+//
+package yamlsort
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// values of --unselected
+const (
+	unselectedSkip = "skip"
+	unselectedKeep = "keep"
+)
+
+// one condition of --select
+type selectCondition struct {
+	segs    []pathSegment
+	op      string // "==" , "!=" , "exists" , "missing"
+	operand string
+}
+
+// parse --select expression. result is OR of AND conditions.
+func parseSelect(expr string) ([][]selectCondition, error) {
+	result := [][]selectCondition{}
+	for _, or := range strings.Split(expr, "||") {
+		and := []selectCondition{}
+		for _, term := range strings.Split(or, "&&") {
+			term = strings.TrimSpace(term)
+			cond := selectCondition{op: "exists"}
+			path := term
+			if strings.HasPrefix(term, "!") && !strings.Contains(term, "=") {
+				cond.op = "missing"
+				path = strings.TrimSpace(term[1:])
+			} else {
+				for _, op := range []string{"==", "!="} {
+					if idx := strings.Index(term, op); idx > 0 {
+						cond.op = op
+						path = strings.TrimSpace(term[:idx])
+						cond.operand = strings.TrimSpace(term[idx+2:])
+						if s, ok := jsonPathQuoted(cond.operand); ok {
+							cond.operand = s
+						}
+						break
+					}
+				}
+			}
+			segs, err := parsePath(path)
+			if err != nil {
+				return nil, fmt.Errorf("--select %q: %v", expr, err)
+			}
+			cond.segs = segs
+			and = append(and, cond)
+		}
+		result = append(result, and)
+	}
+	return result, nil
+}
+
+// data matches condition
+func (cond selectCondition) match(data interface{}) bool {
+	value, found := getPath(data, cond.segs)
+	equal := scalarEqualsText(value, cond.operand)
+	if value == nil {
+		equal = cond.operand == "null"
+	}
+	switch cond.op {
+	case "==":
+		return found && equal
+	case "!=":
+		return !found || !equal
+	case "missing":
+		return !found
+	}
+	return found
+}
+
+// check --doc , --select and --unselected
+func (c *yamlsortCmd) prepareSelect() error {
+	if c.unselected != unselectedSkip && c.unselected != unselectedKeep {
+		return fmt.Errorf("--unselected must be skip or keep: %s", c.unselected)
+	}
+	for _, i := range c.docindexes {
+		if i < 0 {
+			return fmt.Errorf("--doc must be 0 or more: %d", i)
+		}
+	}
+	if len(c.selectexpr) > 0 {
+		conditions, err := parseSelect(c.selectexpr)
+		if err != nil {
+			return err
+		}
+		c.selectconditions = conditions
+	}
+	return nil
+}
+
+// document is selected by --doc and --select
+func (c *yamlsortCmd) isSelected(doc *Document) bool {
+	if len(c.docindexes) > 0 {
+		found := false
+		for _, i := range c.docindexes {
+			if i == doc.Index {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(c.selectconditions) == 0 {
+		return true
+	}
+	return matchConditions(c.selectconditions, doc.Data)
+}
+
+// data matches OR of AND conditions
+func matchConditions(conditions [][]selectCondition, data interface{}) bool {
+	for _, and := range conditions {
+		matched := true
+		for _, cond := range and {
+			if !cond.match(data) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// write document as is (--unselected keep)
+func writeRawDocument(w io.Writer, doc *Document) error {
+	writeDirectives(w, doc.raw.directives)
+	_, err := fmt.Fprint(w, "---\n")
+	if err == nil {
+		_, err = w.Write(doc.raw.data)
+	}
+	return err
+}
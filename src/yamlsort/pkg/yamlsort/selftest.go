@@ -0,0 +1,163 @@
+//
+// yamlsort - selftest subcommand
+//
+// check invariants of sort for every yaml file of directories.
+//   structure    parse(sort(file)) is same value as parse(file)
+//   idempotence  sort(sort(file)) is same text as sort(file)
+//   yamlsort selftest testdata/
+//   testdata/a.yaml: structure changed: [doc 0] ~ spec.port: 80 -> "80"
+//   testdata/b.yaml: second sort differs at line 12: "    paths:" -> "    paths: null"
+//   2 of 40 files violate invariants
+// files which are not valid yaml are skipped. exit 1 when some files violate invariants.
+//
+package yamlsort
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+//---------------------------------------------------------------------
+//  selftest subcommand
+//
+func newSelftestCmd(yamlsort *yamlsortCmd) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "selftest file|dir...",
+		Short: "check that sort keeps structure of yaml files , and second sort changes nothing",
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("selftest requires input file or directory names")
+			}
+			yamlsort.maxlinesize = defaultMaxLineSize
+			err := yamlsort.prepareOptions()
+			if err != nil {
+				return err
+			}
+			err = yamlsort.selftest(args)
+			if err == errCheckFailed {
+				// violations are reported already
+				c.SilenceErrors = true
+				c.SilenceUsage = true
+			}
+			return err
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringArrayVar(&yamlsort.priorkeys, "key", []string{}, "set prior key name in sort. default prior key is name")
+	f.StringArrayVar(&yamlsort.presets, "preset", []string{}, "use preset plugin yamlsort-preset-<name> on PATH")
+	f.StringVar(&yamlsort.lintprofile, "lint-profile", "", "output yaml which passes linter rules. (yamllint-default , prettier)")
+
+	return cmd
+}
+
+func (c *yamlsortCmd) selftest(args []string) (err error) {
+	defer c.bufferStdout()(&err)
+	filenames, err := c.collectFiles(args)
+	if err != nil {
+		return err
+	}
+	stderr := c.stderr
+	violations := 0
+	tested := 0
+	for _, filename := range filenames {
+		input, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		// warnings of input are not result of selftest
+		c.stderr = ioutil.Discard
+		problem, ok := c.selftestInput(input)
+		c.stderr = stderr
+		if !ok {
+			continue
+		}
+		tested++
+		if len(problem) > 0 {
+			violations++
+			fmt.Fprintf(c.stdout, "%s: %s\n", filename, problem)
+		}
+	}
+	fmt.Fprintf(c.stdout, "%d of %d files violate invariants\n", violations, tested)
+	if violations > 0 {
+		return errCheckFailed
+	}
+	return nil
+}
+
+// check invariants of one input. ok is false , when input is not valid yaml.
+func (c *yamlsortCmd) selftestInput(input []byte) (string, bool) {
+	data1, err := c.parseAllData(input)
+	if err != nil {
+		return "", false
+	}
+	output1, err := c.sortBytes(input, nil)
+	if err != nil {
+		return fmt.Sprintf("sort failed: %v", err), true
+	}
+	data2, err := c.parseAllData(output1.Bytes())
+	if err != nil {
+		return fmt.Sprintf("output is not valid yaml: %v", err), true
+	}
+	if changes := diffDocumentData(data1, data2); len(changes) > 0 {
+		return "structure changed: " + changes[0], true
+	}
+	output2, err := c.sortBytes(output1.Bytes(), nil)
+	if err != nil {
+		return fmt.Sprintf("second sort failed: %v", err), true
+	}
+	if !bytes.Equal(output1.Bytes(), output2.Bytes()) {
+		return "second sort differs at " + firstLineDiff(output1.String(), output2.String()), true
+	}
+	return "", true
+}
+
+// data of documents in text , without empty documents
+func (c *yamlsortCmd) parseAllData(text []byte) ([]interface{}, error) {
+	result := []interface{}{}
+	err := c.processDocuments(bytes.NewReader(text), func(doc *Document) error {
+		if doc.Data == nil && doc.raw.isEmpty() {
+			return nil
+		}
+		result = append(result, doc.Data)
+		return nil
+	})
+	return result, err
+}
+
+// changed paths of documents , like "[doc 0] ~ spec.port: 80 -> "80""
+func diffDocumentData(docs1 []interface{}, docs2 []interface{}) []string {
+	if len(docs1) != len(docs2) {
+		return []string{fmt.Sprintf("%d documents -> %d documents", len(docs1), len(docs2))}
+	}
+	changes := []string{}
+	for i := range docs1 {
+		for _, change := range diffValues("", docs1[i], docs2[i]) {
+			changes = append(changes, fmt.Sprintf("[doc %d] %s", i, change))
+		}
+	}
+	return changes
+}
+
+// first differing line of two texts , like `line 12: "a:" -> "a: null"`
+func firstLineDiff(text1 string, text2 string) string {
+	lines1 := strings.Split(text1, "\n")
+	lines2 := strings.Split(text2, "\n")
+	for i := 0; i < len(lines1) || i < len(lines2); i++ {
+		line1, line2 := "", ""
+		if i < len(lines1) {
+			line1 = lines1[i]
+		}
+		if i < len(lines2) {
+			line2 = lines2[i]
+		}
+		if line1 != line2 || i >= len(lines1) || i >= len(lines2) {
+			return fmt.Sprintf("line %d: %q -> %q", i+1, line1, line2)
+		}
+	}
+	return "end of text"
+}
@@ -0,0 +1,114 @@
+//
+// yamlsort - signal handling and atomic file writes
+//
+// files are written into temporary file in same directory (.name.yamlsort-XXXX) , and
+// renamed over target file. so output file is old content or new content , never truncated.
+// on SIGINT or SIGTERM , current file write is finished , temporary files are removed ,
+// and yamlsort exits with 128 + signal number (130 , 143).
+// non regular files (like /dev/stdout) , and files in directories which are not writable
+// are written directly.
+//
+package yamlsort
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+)
+
+//---------------------------------------------------------------------
+//  writeGuard class
+// file writes , which signals wait for
+//
+type writeGuard struct {
+	mutex     sync.Mutex
+	once      sync.Once
+	tempfiles map[string]bool
+}
+
+var globalwriteguard = &writeGuard{tempfiles: map[string]bool{}}
+
+// watch signals , at first write
+func (g *writeGuard) watch() {
+	g.once.Do(func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			sig := <-signals
+			// wait for current write
+			g.mutex.Lock()
+			for tempfile := range g.tempfiles {
+				os.Remove(tempfile)
+			}
+			fmt.Fprintf(os.Stderr, "yamlsort: interrupted by %v\n", sig)
+			code := 130
+			if sig == syscall.SIGTERM {
+				code = 143
+			}
+			restoreConsole()
+			os.Exit(code)
+		}()
+	})
+}
+
+// write data into file atomically. mode , owner and extended attributes of existing file are
+// kept , and perm is mode of new file.
+func atomicWriteFile(filename string, data []byte, perm os.FileMode) error {
+	g := globalwriteguard
+	g.watch()
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	// rename replaces symbolic link itself , so write into file of link
+	if target, err := filepath.EvalSymlinks(filename); err == nil {
+		filename = target
+	}
+	info, err := os.Stat(filename)
+	if err == nil {
+		if !info.Mode().IsRegular() {
+			return writeFileRetry(filename, data, perm)
+		}
+		perm = info.Mode().Perm()
+	} else {
+		info = nil
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(filename), "."+filepath.Base(filename)+".yamlsort-")
+	if err != nil {
+		if os.IsPermission(err) && info != nil {
+			// directory is not writable
+			return writeFileKeepMode(filename, data, perm)
+		}
+		return err
+	}
+	tempfile := tmp.Name()
+	g.tempfiles[tempfile] = true
+	defer func() {
+		// removed when rename failed
+		os.Remove(tempfile)
+		delete(g.tempfiles, tempfile)
+	}()
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if err2 := tmp.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		return err
+	}
+	if info != nil {
+		copyFileAttributes(filename, tempfile, info)
+		err = os.Chmod(tempfile, info.Mode()&(os.ModePerm|os.ModeSetuid|os.ModeSetgid|os.ModeSticky))
+	} else {
+		err = os.Chmod(tempfile, perm)
+	}
+	if err != nil {
+		return err
+	}
+	return renameRetry(tempfile, filename)
+}
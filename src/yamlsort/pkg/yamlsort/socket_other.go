@@ -0,0 +1,30 @@
+// +build !windows
+
+//
+// yamlsort - unix socket of daemon on unix like systems
+//
+package yamlsort
+
+import (
+	"net"
+	"os"
+	"syscall"
+)
+
+// listen on unix socket , which only owner can connect.
+// socket file is created with umask 0177 , so it is not open to other users before chmod.
+func listenUnixSocket(path string) (net.Listener, error) {
+	oldmask := syscall.Umask(0177)
+	listener, err := net.Listen("unix", path)
+	syscall.Umask(oldmask)
+	if err != nil {
+		return nil, err
+	}
+	err = os.Chmod(path, 0600)
+	if err != nil {
+		listener.Close()
+		os.Remove(path)
+		return nil, err
+	}
+	return listener, nil
+}
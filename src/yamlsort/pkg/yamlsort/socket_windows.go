@@ -0,0 +1,15 @@
+// +build windows
+
+//
+// yamlsort - unix socket of daemon on windows
+//
+package yamlsort
+
+import (
+	"net"
+)
+
+// listen on unix socket
+func listenUnixSocket(path string) (net.Listener, error) {
+	return net.Listen("unix", path)
+}
@@ -0,0 +1,44 @@
+//
+// yamlsort - SortBytes entry point
+//
+// SortBytes sorts yaml text with options of command line , like "yamlsort [options] < input".
+// it is entry point for fuzzing and server modes (daemon , lsp) , and any input returns
+// output or error. panics in parsers are returned as error , instead of crash of server.
+//   output, err := SortBytes([]byte("b: 1\na: 2\n"), "--key", "name")
+// FuzzSortBytes (sortbytes_fuzz_test.go) is fuzz target of it , with sample files of test.sh.
+//
+package yamlsort
+
+import (
+	"bytes"
+	"fmt"
+
+	yamlv2 "gopkg.in/yaml.v2"
+)
+
+// SortBytes returns sorted text of input. args are options of command line.
+func SortBytes(input []byte, args ...string) ([]byte, error) {
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	err := sortWithArgs(args, input, stdout, stderr)
+	if err != nil {
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+// error of panic in parser
+func recoveredError(r interface{}) error {
+	return fmt.Errorf("internal error on input: %v", r)
+}
+
+// yamlv2.Unmarshal , and panic of yaml.v2 is returned as error.
+// (yaml.v2 panics on merge key (<<) of null alias into typed map)
+func unmarshalYAMLv2(text []byte, out interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recoveredError(r)
+		}
+	}()
+	return yamlv2.Unmarshal(text, out)
+}
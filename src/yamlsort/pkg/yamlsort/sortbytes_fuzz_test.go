@@ -0,0 +1,93 @@
+package yamlsort
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// options of fuzzing. index is selected by fuzzer.
+var fuzzOptions = [][]string{
+	{},
+	{"--key", "kind"},
+	{"--float-format=preserve"},
+	{"--lint-profile=prettier"},
+	{"--jsonoutput"},
+	{"--blank-lines=keep"},
+	{"--minimal"},
+}
+
+// sample files of test.sh are seed corpus
+func addSeedCorpus(f *testing.F) {
+	filenames, err := filepath.Glob(filepath.Join("..", "..", "..", "..", "test", "*.yaml"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	if len(filenames) == 0 {
+		f.Fatal("no seed files in test directory")
+	}
+	for _, filename := range filenames {
+		input, err := ioutil.ReadFile(filename)
+		if err != nil {
+			f.Fatal(err)
+		}
+		for i := range fuzzOptions {
+			f.Add(input, uint8(i))
+		}
+	}
+}
+
+// nested aliases (billion laughs , CVE-2019-11253)
+func billionLaughs() []byte {
+	text := "a: &a [\"lol\",\"lol\",\"lol\",\"lol\",\"lol\",\"lol\",\"lol\",\"lol\",\"lol\"]\n"
+	for ch := 'b'; ch <= 'i'; ch++ {
+		alias := fmt.Sprintf("*%c", ch-1)
+		text += fmt.Sprintf("%c: &%c [%s]\n", ch, ch, strings.TrimSuffix(strings.Repeat(alias+",", 9), ","))
+	}
+	return []byte(text)
+}
+
+// deeply nested flow lists (CVE-2019-11254)
+func deeplyNested() []byte {
+	return []byte("a: " + strings.Repeat("[", 100000) + "\n")
+}
+
+// inputs which exhausted memory or stack of parser
+func addHostileSeeds(f *testing.F) {
+	for _, input := range [][]byte{billionLaughs(), deeplyNested()} {
+		for i := range fuzzOptions {
+			f.Add(input, uint8(i))
+		}
+	}
+}
+
+// hostile inputs are errors
+func TestSortBytesHostileInput(t *testing.T) {
+	for _, input := range [][]byte{billionLaughs(), deeplyNested()} {
+		output, err := SortBytes(input)
+		if err == nil {
+			t.Errorf("no error for input %.40q , output %d bytes", input, len(output))
+		}
+	}
+}
+
+// any input returns output or error , and output can be sorted again.
+//   go test -run '^$' -fuzz FuzzSortBytes ./pkg/yamlsort
+func FuzzSortBytes(f *testing.F) {
+	addSeedCorpus(f)
+	addHostileSeeds(f)
+	f.Fuzz(func(t *testing.T, input []byte, option uint8) {
+		args := fuzzOptions[int(option)%len(fuzzOptions)]
+		output, err := SortBytes(input, args...)
+		if err != nil {
+			// error (and panic in parser) of broken input
+			return
+		}
+		_, err = SortBytes(output, args...)
+		if err != nil {
+			t.Fatalf("output of %v can not be sorted again: %v\ninput:\n%s\noutput:\n%s", args, err, input, output)
+		}
+	})
+}
@@ -0,0 +1,138 @@
+//
+// yamlsort - .inf , -.inf , .nan values
+//
+// ghodss/yaml converts yaml into json , and json has no infinity and NaN. so documents
+// which have these values are parsed with gopkg.in/yaml.v2 , and values are kept as float64.
+// they are written as .inf , -.inf and .nan (not +Inf , NaN of go).
+//   yamlsort --strict-floats    reject these values in input (strict mode)
+// json output (--jsonoutput , --normal) can not have these values , and reports path of value.
+//
+package yamlsort
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// yaml text of infinity and NaN
+func specialFloatText(f float64) (string, bool) {
+	switch {
+	case math.IsInf(f, 1):
+		return ".inf", true
+	case math.IsInf(f, -1):
+		return "-.inf", true
+	case math.IsNaN(f):
+		return ".nan", true
+	}
+	return "", false
+}
+
+// parse yaml data. documents with .inf , -.inf or .nan are parsed with yaml.v2.
+func (c *yamlsortCmd) unmarshalYAML(input []byte) (interface{}, error) {
+	var data interface{}
+	err := yaml.Unmarshal(input, &data)
+	if err == nil || !strings.Contains(err.Error(), "json: unsupported value") {
+		return data, err
+	}
+	var raw interface{}
+	if err2 := unmarshalYAMLv2(input, &raw); err2 != nil {
+		return nil, err
+	}
+	data = jsonCompatibleValue(raw)
+	if c.blnStrictFloats {
+		if path, text, found := findSpecialFloat("", data); found {
+			return data, fmt.Errorf("%s at %s is rejected by --strict-floats", text, path)
+		}
+	}
+	return data, nil
+}
+
+// value of yaml.v2 in same types as ghodss/yaml (map[string]interface{} , float64 numbers)
+func jsonCompatibleValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for k, child := range v {
+			result[jsonCompatibleKey(k)] = jsonCompatibleValue(child)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, child := range v {
+			result[i] = jsonCompatibleValue(child)
+		}
+		return result
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case nil, string, bool, float64:
+		return v
+	}
+	return fmt.Sprint(value)
+}
+
+// map key in text , like ghodss/yaml
+func jsonCompatibleKey(key interface{}) string {
+	switch k := key.(type) {
+	case string:
+		return k
+	case nil:
+		return "null"
+	case float64:
+		if text, ok := specialFloatText(k); ok {
+			return text
+		}
+		return strconv.FormatFloat(k, 'g', -1, 64)
+	}
+	return fmt.Sprint(key)
+}
+
+// path and text of first infinity or NaN in data , in sorted key order
+func findSpecialFloat(path string, data interface{}) (string, string, bool) {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		keylist := make([]string, 0, len(v))
+		for k := range v {
+			keylist = append(keylist, k)
+		}
+		sortKeys(keylist)
+		for _, k := range keylist {
+			childpath := k
+			if len(path) > 0 {
+				childpath = path + "." + k
+			}
+			if p, text, found := findSpecialFloat(childpath, v[k]); found {
+				return p, text, true
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			if p, text, found := findSpecialFloat(fmt.Sprintf("%s[%d]", path, i), child); found {
+				return p, text, true
+			}
+		}
+	case float64:
+		if text, ok := specialFloatText(v); ok {
+			if len(path) == 0 {
+				path = "."
+			}
+			return path, text, true
+		}
+	}
+	return "", "", false
+}
+
+// json has no infinity and NaN
+func checkJSONFloats(data interface{}) error {
+	if path, text, found := findSpecialFloat("", data); found {
+		return fmt.Errorf("%s at %s can not be written in json", text, path)
+	}
+	return nil
+}
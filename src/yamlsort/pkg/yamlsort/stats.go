@@ -0,0 +1,251 @@
+//
+// yamlsort - stats subcommand
+//
+// per document metrics of yaml files.
+//   keys , maximum depth , longest line , duplicate keys , histogram of value types
+// with --aliases , expansion of each anchor is reported too.
+//
+package yamlsort
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	yamlv2 "gopkg.in/yaml.v2"
+)
+
+// metrics of one document
+type documentStats struct {
+	keys          int
+	depth         int
+	longestline   int
+	duplicatekeys []string
+	types         map[string]int
+	aliases       *aliasReport
+}
+
+// yaml node which keeps duplicate keys (ghodss/yaml overwrites them)
+type statsNode struct {
+	value interface{}
+}
+
+func (n *statsNode) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var probe interface{}
+	if err := unmarshal(&probe); err != nil {
+		return err
+	}
+	switch probe.(type) {
+	case map[interface{}]interface{}:
+		var m yamlv2.MapSlice
+		err := unmarshal(&m)
+		n.value = restoreMergedKeys(m, probe)
+		return err
+	case []interface{}:
+		var a []statsNode
+		err := unmarshal(&a)
+		n.value = a
+		return err
+	}
+	n.value = probe
+	return nil
+}
+
+// yaml.v2 drops keys of merge key (<<: *alias) in nested MapSlice.
+// they are restored from plain decoding , which resolves merge keys.
+func restoreMergedKeys(value interface{}, plain interface{}) interface{} {
+	switch v := value.(type) {
+	case yamlv2.MapSlice:
+		m, ok := plain.(map[interface{}]interface{})
+		if !ok {
+			return v
+		}
+		seen := map[interface{}]bool{}
+		for i, item := range v {
+			seen[item.Key] = true
+			v[i].Value = restoreMergedKeys(item.Value, m[item.Key])
+		}
+		return append(v, mergedMapSlice(m, seen)...)
+	case []interface{}:
+		a, ok := plain.([]interface{})
+		if !ok || len(a) != len(v) {
+			return v
+		}
+		for i := range v {
+			v[i] = restoreMergedKeys(v[i], a[i])
+		}
+		return v
+	case map[interface{}]interface{}:
+		return mergedMapSlice(v, map[interface{}]bool{})
+	}
+	return value
+}
+
+// keys of plain map which are not seen , in order of key text
+func mergedMapSlice(m map[interface{}]interface{}, seen map[interface{}]bool) yamlv2.MapSlice {
+	result := yamlv2.MapSlice{}
+	for key, value := range m {
+		if !seen[key] {
+			result = append(result, yamlv2.MapItem{Key: key, Value: restoreMergedKeys(value, value)})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return fmt.Sprint(result[i].Key) < fmt.Sprint(result[j].Key)
+	})
+	return result
+}
+
+// names of type histogram , in output order
+var statsTypeNames = []string{"map", "list", "string", "int", "float", "bool", "null"}
+
+func (s *documentStats) walk(path string, depth int, data interface{}) {
+	switch v := data.(type) {
+	case statsNode:
+		s.walk(path, depth, v.value)
+		return
+	case yamlv2.MapSlice:
+		s.types["map"]++
+		if depth+1 > s.depth {
+			s.depth = depth + 1
+		}
+		seen := map[string]bool{}
+		for _, item := range v {
+			key := fmt.Sprint(item.Key)
+			childpath := key
+			if len(path) > 0 {
+				childpath = path + "." + key
+			}
+			if seen[key] {
+				s.duplicatekeys = append(s.duplicatekeys, childpath)
+			}
+			seen[key] = true
+			s.keys++
+			s.walk(childpath, depth+1, item.Value)
+		}
+		return
+	case []statsNode, []interface{}:
+		s.types["list"]++
+		if depth+1 > s.depth {
+			s.depth = depth + 1
+		}
+		items := []interface{}{}
+		if a, ok := v.([]interface{}); ok {
+			items = a
+		} else {
+			for _, item := range v.([]statsNode) {
+				items = append(items, item)
+			}
+		}
+		for i, item := range items {
+			s.walk(fmt.Sprintf("%s[%d]", path, i), depth+1, item)
+		}
+		return
+	case nil:
+		s.types["null"]++
+	case string:
+		s.types["string"]++
+	case bool:
+		s.types["bool"]++
+	case int, int64, uint64:
+		s.types["int"]++
+	case float64:
+		s.types["float"]++
+	default:
+		s.types["string"]++
+	}
+}
+
+//---------------------------------------------------------------------
+//  stats subcommand
+//
+func newStatsCmd(yamlsort *yamlsortCmd) *cobra.Command {
+	var blnAliases bool
+
+	cmd := &cobra.Command{
+		Use:   "stats file...",
+		Short: "report per document metrics (keys , depth , longest line , duplicate keys , types)",
+		RunE: func(c *cobra.Command, args []string) (err error) {
+			if len(args) == 0 {
+				return fmt.Errorf("stats requires input file names")
+			}
+			defer yamlsort.bufferStdout()(&err)
+			yamlsort.maxlinesize = defaultMaxLineSize
+			filenames, err := yamlsort.collectFiles(args)
+			if err != nil {
+				return err
+			}
+			for _, filename := range filenames {
+				err := yamlsort.printStats(filename, blnAliases)
+				if err != nil {
+					return fmt.Errorf("%s: %v", filename, err)
+				}
+			}
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&blnAliases, "aliases", false, "report anchors , aliases , and expanded size of each alias")
+
+	return cmd
+}
+
+func (c *yamlsortCmd) printStats(filename string, blnAliases bool) error {
+	input, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	all := []*documentStats{}
+	docscanner := newDocumentScanner(bytes.NewReader(input), c.maxlinesize)
+	for docscanner.Scan() {
+		rawdoc := docscanner.Document()
+		if rawdoc.isEmpty() {
+			continue
+		}
+		stats := &documentStats{types: map[string]int{}}
+		for _, line := range strings.Split(string(rawdoc.data), "\n") {
+			if len(line) > stats.longestline {
+				stats.longestline = len(line)
+			}
+		}
+		node := statsNode{}
+		err := unmarshalYAMLv2(rawdoc.parseData(), &node)
+		if err != nil {
+			return err
+		}
+		stats.walk("", 0, node)
+		if blnAliases {
+			stats.aliases, err = newAliasReport(rawdoc.parseData())
+			if err != nil {
+				return err
+			}
+		}
+		all = append(all, stats)
+	}
+	if err := docscanner.Err(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "%s: %d documents\n", filename, len(all))
+	for i, stats := range all {
+		types := []string{}
+		for _, name := range statsTypeNames {
+			if stats.types[name] > 0 {
+				types = append(types, fmt.Sprintf("%s=%d", name, stats.types[name]))
+			}
+		}
+		fmt.Fprintf(c.stdout, "  [%d] keys=%d depth=%d longest-line=%d duplicate-keys=%d types: %s\n",
+			i, stats.keys, stats.depth, stats.longestline, len(stats.duplicatekeys), strings.Join(types, " "))
+		sort.Strings(stats.duplicatekeys)
+		for _, path := range stats.duplicatekeys {
+			fmt.Fprintf(c.stdout, "      duplicate key: %s\n", path)
+		}
+		if stats.aliases != nil {
+			stats.aliases.print(c)
+		}
+	}
+	return nil
+}
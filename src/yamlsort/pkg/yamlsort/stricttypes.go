@@ -0,0 +1,85 @@
+//
+// yamlsort - --strict-types option
+//
+// error when same key has different types in list elements or documents of input ,
+// like port is number in one element , and string in other element.
+//   yamlsort --strict-types -i service.yaml
+//   Error: --strict-types: document 0 spec.ports[1].port is string , but number at document 0 spec.ports[0].port
+// list elements are compared as [*]. documents are compared with documents of same kind
+// (documents without kind are compared together). null values are not checked.
+//
+package yamlsort
+
+import (
+	"fmt"
+)
+
+// type of key path , and where it is seen first
+type typeSighting struct {
+	typename string
+	location string
+}
+
+//---------------------------------------------------------------------
+//  typeChecker class
+// types of key paths in documents of one input
+//
+type typeChecker struct {
+	seen map[string]typeSighting // kind + key path with [*] -> first type
+}
+
+func newTypeChecker() *typeChecker {
+	return &typeChecker{seen: map[string]typeSighting{}}
+}
+
+// check types of document , with documents checked before
+func (t *typeChecker) check(doc *Document) error {
+	scope := ""
+	if m, ok := doc.Data.(map[string]interface{}); ok {
+		if kind, ok := m["kind"].(string); ok {
+			scope = kind
+		}
+	}
+	return t.walk(scope+":", fmt.Sprintf("document %d ", doc.Index), "", doc.Data)
+}
+
+// pattern is key path with [*] , path is key path of value
+func (t *typeChecker) walk(pattern string, location string, path string, data interface{}) error {
+	if data == nil {
+		return nil
+	}
+	if len(path) > 0 {
+		typename := valueTypeName(data)
+		if first, ok := t.seen[pattern]; !ok {
+			t.seen[pattern] = typeSighting{typename: typename, location: location + path}
+		} else if first.typename != typename {
+			return fmt.Errorf("--strict-types: %s%s is %s , but %s at %s", location, path, typename, first.typename, first.location)
+		}
+	}
+	switch v := data.(type) {
+	case map[string]interface{}:
+		keylist := make([]string, 0, len(v))
+		for k := range v {
+			keylist = append(keylist, k)
+		}
+		sortKeys(keylist)
+		for _, k := range keylist {
+			childpath := k
+			if len(path) > 0 {
+				childpath = path + "." + k
+			}
+			err := t.walk(pattern+"."+k, location, childpath, v[k])
+			if err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			err := t.walk(pattern+"[*]", location, fmt.Sprintf("%s[%d]", path, i), child)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+// +build darwin dragonfly freebsd netbsd openbsd
+
+//
+// yamlsort - terminal check on bsd systems (and macOS)
+//
+package yamlsort
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// file is terminal. character devices like /dev/null are not terminal.
+func isTerminalFile(fp *os.File) bool {
+	var termios syscall.Termios
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fp.Fd(), syscall.TIOCGETA, uintptr(unsafe.Pointer(&termios)))
+	return errno == 0
+}
@@ -0,0 +1,17 @@
+//
+// yamlsort - terminal check on linux
+//
+package yamlsort
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// file is terminal. character devices like /dev/null are not terminal.
+func isTerminalFile(fp *os.File) bool {
+	var termios syscall.Termios
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fp.Fd(), syscall.TCGETS, uintptr(unsafe.Pointer(&termios)))
+	return errno == 0
+}
@@ -0,0 +1,16 @@
+// +build !linux,!windows,!darwin,!dragonfly,!freebsd,!netbsd,!openbsd
+
+//
+// yamlsort - terminal check on other systems
+//
+package yamlsort
+
+import (
+	"os"
+)
+
+// file is character device. terminal can not be told from other devices.
+func isTerminalFile(fp *os.File) bool {
+	info, err := fp.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,59 @@
+//
+// yamlsort - textconv subcommand
+//
+// git textconv filter. git diff compares sorted forms of yaml files.
+//   .gitattributes   *.yaml diff=yamlsort
+//   git config diff.yamlsort.textconv "yamlsort textconv"
+//
+package yamlsort
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/spf13/cobra"
+)
+
+//---------------------------------------------------------------------
+//  textconv subcommand
+// output sorted text without header comments. file which is not valid yaml
+// is written as it is , so git diff never fails.
+//
+func newTextconvCmd(yamlsort *yamlsortCmd) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "textconv file",
+		Short: "output sorted text for git diff textconv (no header comments)",
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("textconv requires one input file name")
+			}
+			return yamlsort.textconv(args[0])
+		},
+	}
+
+	return cmd
+}
+
+func (c *yamlsortCmd) textconv(filename string) error {
+	input, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	c.blnNoHeader = true
+	c.maxlinesize = defaultMaxLineSize
+	err = c.prepareOptions()
+	if err != nil {
+		return err
+	}
+	stderr := c.stderr
+	c.stderr = ioutil.Discard
+	output, err := c.sortBytes(input, nil)
+	c.stderr = stderr
+	if err != nil {
+		// not yaml. raw text is compared.
+		_, err = c.stdout.Write(input)
+		return err
+	}
+	_, err = c.stdout.Write(output.Bytes())
+	return err
+}
@@ -0,0 +1,500 @@
+//
+// yamlsort - toml decoder
+//
+// decode toml v1.0 text into map[string]interface{}.
+//   strings (basic , literal , multi-line) , integers (0x , 0o , 0b , _) , floats , booleans ,
+//   arrays , inline tables , [tables] , [[arrays of tables]] , dotted keys
+// numbers keep their text (json.Number). date and time values are strings. inf and nan are errors ,
+// because they can not be json text.
+//
+package yamlsort
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// date and time values , like 1979-05-27T07:32:00Z , 1979-05-27 , 07:32:00
+var (
+	tomlDateTimeRegexp = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}([Tt ]\d{2}:\d{2}:\d{2}(\.\d+)?([Zz]|[+-]\d{2}:\d{2})?)?$`)
+	tomlTimeRegexp     = regexp.MustCompile(`^\d{2}:\d{2}:\d{2}(\.\d+)?$`)
+	tomlDateRegexp     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	tomlFloatRegexp    = regexp.MustCompile(`^[+-]?(0|[1-9][0-9_]*)(\.[0-9_]+)?([eE][+-]?[0-9_]+)?$`)
+)
+
+//---------------------------------------------------------------------
+//  tomlParser class
+//
+type tomlParser struct {
+	text string
+	pos  int
+}
+
+// decode toml text
+func (c *yamlsortCmd) decodeTOML(input []byte) (interface{}, error) {
+	p := &tomlParser{text: strings.Replace(string(input), "\r\n", "\n", -1)}
+	p.text = strings.TrimPrefix(p.text, "\ufeff")
+	root := map[string]interface{}{}
+	current := root
+	for {
+		p.skipBlank()
+		if p.eof() {
+			break
+		}
+		var keys []string
+		var err error
+		if strings.HasPrefix(p.rest(), "[[") {
+			p.pos += 2
+			keys, err = p.parseKey()
+			if err != nil {
+				return nil, err
+			}
+			if !p.consume("]]") {
+				return nil, p.errorf("']]' is expected")
+			}
+			current, err = p.arrayTable(root, keys)
+		} else if p.peek() == '[' {
+			p.pos++
+			keys, err = p.parseKey()
+			if err != nil {
+				return nil, err
+			}
+			if !p.consume("]") {
+				return nil, p.errorf("']' is expected")
+			}
+			current, err = p.table(root, keys)
+		} else {
+			err = p.parseKeyValue(current)
+		}
+		if err != nil {
+			return nil, err
+		}
+		err = p.endOfLine()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return root, nil
+}
+
+func (p *tomlParser) eof() bool {
+	return p.pos >= len(p.text)
+}
+
+func (p *tomlParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.text[p.pos]
+}
+
+func (p *tomlParser) rest() string {
+	return p.text[p.pos:]
+}
+
+// skip s , and return true when rest starts with s
+func (p *tomlParser) consume(s string) bool {
+	p.skipSpaces()
+	if strings.HasPrefix(p.rest(), s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+// error with line number
+func (p *tomlParser) errorf(format string, args ...interface{}) error {
+	line := strings.Count(p.text[:p.pos], "\n") + 1
+	return fmt.Errorf("toml: line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+// skip spaces and tabs
+func (p *tomlParser) skipSpaces() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.pos++
+	}
+}
+
+// skip spaces , line breaks and comments
+func (p *tomlParser) skipBlank() {
+	for !p.eof() {
+		switch p.peek() {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		case '#':
+			p.skipComment()
+		default:
+			return
+		}
+	}
+}
+
+func (p *tomlParser) skipComment() {
+	if idx := strings.Index(p.rest(), "\n"); idx >= 0 {
+		p.pos += idx
+	} else {
+		p.pos = len(p.text)
+	}
+}
+
+// spaces and comment until line break
+func (p *tomlParser) endOfLine() error {
+	p.skipSpaces()
+	if p.peek() == '#' {
+		p.skipComment()
+	}
+	if p.eof() {
+		return nil
+	}
+	if p.peek() != '\n' {
+		return p.errorf("unexpected %q after value", p.peek())
+	}
+	p.pos++
+	return nil
+}
+
+// dotted key , like a.b."c.d"
+func (p *tomlParser) parseKey() ([]string, error) {
+	keys := []string{}
+	for {
+		p.skipSpaces()
+		var key string
+		var err error
+		switch p.peek() {
+		case '"':
+			key, err = p.parseBasicString()
+		case '\'':
+			key, err = p.parseLiteralString()
+		default:
+			start := p.pos
+			for !p.eof() && isTOMLBareKeyChar(p.peek()) {
+				p.pos++
+			}
+			if start == p.pos {
+				return nil, p.errorf("key is expected")
+			}
+			key = p.text[start:p.pos]
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+		p.skipSpaces()
+		if p.peek() != '.' {
+			return keys, nil
+		}
+		p.pos++
+	}
+}
+
+func isTOMLBareKeyChar(b byte) bool {
+	return b >= 'A' && b <= 'Z' || b >= 'a' && b <= 'z' || b >= '0' && b <= '9' || b == '_' || b == '-'
+}
+
+// key = value into table
+func (p *tomlParser) parseKeyValue(table map[string]interface{}) error {
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	if !p.consume("=") {
+		return p.errorf("'=' is expected after key %s", strings.Join(keys, "."))
+	}
+	p.skipSpaces()
+	value, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+	for _, key := range keys[:len(keys)-1] {
+		child, ok := table[key]
+		if !ok {
+			child = map[string]interface{}{}
+			table[key] = child
+		}
+		table, ok = child.(map[string]interface{})
+		if !ok {
+			return p.errorf("key %s is not table", key)
+		}
+	}
+	last := keys[len(keys)-1]
+	if _, ok := table[last]; ok {
+		return p.errorf("duplicate key %s", strings.Join(keys, "."))
+	}
+	table[last] = value
+	return nil
+}
+
+// table of [a.b.c] header. last element of array of tables is used in path.
+func (p *tomlParser) table(root map[string]interface{}, keys []string) (map[string]interface{}, error) {
+	table := root
+	for _, key := range keys {
+		child, ok := table[key]
+		if !ok {
+			child = map[string]interface{}{}
+			table[key] = child
+		}
+		if a, ok := child.([]interface{}); ok && len(a) > 0 {
+			child = a[len(a)-1]
+		}
+		table, ok = child.(map[string]interface{})
+		if !ok {
+			return nil, p.errorf("key %s is not table", key)
+		}
+	}
+	return table, nil
+}
+
+// new element of [[a.b.c]] header
+func (p *tomlParser) arrayTable(root map[string]interface{}, keys []string) (map[string]interface{}, error) {
+	parent, err := p.table(root, keys[:len(keys)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := keys[len(keys)-1]
+	array, ok := parent[last].([]interface{})
+	if _, exists := parent[last]; exists && !ok {
+		return nil, p.errorf("key %s is not array of tables", last)
+	}
+	table := map[string]interface{}{}
+	parent[last] = append(array, table)
+	return table, nil
+}
+
+// value of key , or element of array
+func (p *tomlParser) parseValue() (interface{}, error) {
+	switch {
+	case strings.HasPrefix(p.rest(), `"""`):
+		return p.parseMultiLineString(`"""`)
+	case strings.HasPrefix(p.rest(), `'''`):
+		return p.parseMultiLineString(`'''`)
+	case p.peek() == '"':
+		return p.parseBasicString()
+	case p.peek() == '\'':
+		return p.parseLiteralString()
+	case p.peek() == '[':
+		return p.parseArray()
+	case p.peek() == '{':
+		return p.parseInlineTable()
+	}
+	return p.parseScalar()
+}
+
+// [ value , ... ]
+func (p *tomlParser) parseArray() (interface{}, error) {
+	p.pos++
+	result := []interface{}{}
+	for {
+		p.skipBlank()
+		if p.peek() == ']' {
+			p.pos++
+			return result, nil
+		}
+		if p.eof() {
+			return nil, p.errorf("']' is expected")
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, value)
+		p.skipBlank()
+		if p.peek() == ',' {
+			p.pos++
+		} else if p.peek() != ']' {
+			return nil, p.errorf("',' or ']' is expected in array")
+		}
+	}
+}
+
+// { key = value , ... }
+func (p *tomlParser) parseInlineTable() (interface{}, error) {
+	p.pos++
+	result := map[string]interface{}{}
+	if p.consume("}") {
+		return result, nil
+	}
+	for {
+		err := p.parseKeyValue(result)
+		if err != nil {
+			return nil, err
+		}
+		if p.consume("}") {
+			return result, nil
+		}
+		if !p.consume(",") {
+			return nil, p.errorf("',' or '}' is expected in inline table")
+		}
+	}
+}
+
+// "..." with escapes
+func (p *tomlParser) parseBasicString() (string, error) {
+	p.pos++
+	buf := new(strings.Builder)
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", p.errorf("string is not closed")
+		}
+		b := p.peek()
+		if b == '"' {
+			p.pos++
+			return buf.String(), nil
+		}
+		if b == '\\' {
+			err := p.parseEscape(buf)
+			if err != nil {
+				return "", err
+			}
+			continue
+		}
+		buf.WriteByte(b)
+		p.pos++
+	}
+}
+
+// '...' without escapes
+func (p *tomlParser) parseLiteralString() (string, error) {
+	p.pos++
+	end := strings.IndexAny(p.rest(), "'\n")
+	if end < 0 || p.text[p.pos+end] != '\'' {
+		return "", p.errorf("string is not closed")
+	}
+	s := p.text[p.pos : p.pos+end]
+	p.pos += end + 1
+	return s, nil
+}
+
+// """...""" or '''...'''. line break after opening quotes is trimmed.
+func (p *tomlParser) parseMultiLineString(quotes string) (string, error) {
+	p.pos += 3
+	if p.peek() == '\n' {
+		p.pos++
+	}
+	buf := new(strings.Builder)
+	for {
+		if p.eof() {
+			return "", p.errorf("%s string is not closed", quotes)
+		}
+		if strings.HasPrefix(p.rest(), quotes) {
+			p.pos += 3
+			// up to 2 quotes before closing quotes are content
+			for i := 0; i < 2 && strings.HasPrefix(p.rest(), quotes[:1]); i++ {
+				buf.WriteByte(quotes[0])
+				p.pos++
+			}
+			return buf.String(), nil
+		}
+		b := p.peek()
+		if b == '\\' && quotes == `"""` {
+			// line ending backslash trims spaces and line breaks
+			rest := strings.TrimLeft(p.rest()[1:], " \t")
+			if strings.HasPrefix(rest, "\n") {
+				p.pos = len(p.text) - len(strings.TrimLeft(rest, " \t\n"))
+				continue
+			}
+			err := p.parseEscape(buf)
+			if err != nil {
+				return "", err
+			}
+			continue
+		}
+		buf.WriteByte(b)
+		p.pos++
+	}
+}
+
+// \n , \" , \uXXXX ...
+func (p *tomlParser) parseEscape(buf *strings.Builder) error {
+	p.pos++
+	if p.eof() {
+		return p.errorf("invalid escape")
+	}
+	b := p.peek()
+	p.pos++
+	switch b {
+	case 'b':
+		buf.WriteByte('\b')
+	case 't':
+		buf.WriteByte('\t')
+	case 'n':
+		buf.WriteByte('\n')
+	case 'f':
+		buf.WriteByte('\f')
+	case 'r':
+		buf.WriteByte('\r')
+	case 'e':
+		buf.WriteByte(0x1b)
+	case '"', '\\':
+		buf.WriteByte(b)
+	case 'u', 'U':
+		n := 4
+		if b == 'U' {
+			n = 8
+		}
+		if p.pos+n > len(p.text) {
+			return p.errorf("invalid escape \\%c", b)
+		}
+		code, err := strconv.ParseUint(p.text[p.pos:p.pos+n], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return p.errorf("invalid escape \\%c%s", b, p.text[p.pos:p.pos+n])
+		}
+		buf.WriteRune(rune(code))
+		p.pos += n
+	default:
+		return p.errorf("invalid escape \\%c", b)
+	}
+	return nil
+}
+
+// number , boolean , date and time
+func (p *tomlParser) parseScalar() (interface{}, error) {
+	start := p.pos
+	for !p.eof() && !strings.ContainsRune(" \t\n,]}#", rune(p.peek())) {
+		p.pos++
+	}
+	token := p.text[start:p.pos]
+	// date and time separated by space
+	if tomlDateRegexp.MatchString(token) && strings.HasPrefix(p.rest(), " ") {
+		end := p.pos + 1
+		for end < len(p.text) && !strings.ContainsRune(" \t\n,]}#", rune(p.text[end])) {
+			end++
+		}
+		if tomlDateTimeRegexp.MatchString(token + p.text[p.pos:end]) {
+			token = token + p.text[p.pos:end]
+			p.pos = end
+		}
+	}
+	switch {
+	case len(token) == 0:
+		return nil, p.errorf("value is expected")
+	case token == "true":
+		return true, nil
+	case token == "false":
+		return false, nil
+	case tomlDateTimeRegexp.MatchString(token) || tomlTimeRegexp.MatchString(token):
+		return token, nil
+	case strings.HasSuffix(token, "inf") || strings.HasSuffix(token, "nan"):
+		return nil, p.errorf("%s is not supported", token)
+	}
+	text := strings.TrimPrefix(token, "+")
+	if i, err := strconv.ParseInt(text, 0, 64); err == nil && !isTOMLLeadingZero(text) {
+		return json.Number(strconv.FormatInt(i, 10)), nil
+	}
+	if tomlFloatRegexp.MatchString(token) {
+		text = strings.Replace(text, "_", "", -1)
+		if _, err := strconv.ParseFloat(text, 64); err == nil {
+			return json.Number(text), nil
+		}
+	}
+	return nil, p.errorf("invalid value %s", token)
+}
+
+// decimal integer with leading zero , like 007 (octal in go)
+func isTOMLLeadingZero(text string) bool {
+	text = strings.TrimPrefix(text, "-")
+	return len(text) > 1 && text[0] == '0' && text[1] >= '0' && text[1] <= '9'
+}
@@ -0,0 +1,196 @@
+//
+// yamlsort - transform documents before sorted output
+//
+package yamlsort
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+//-------------------------------------------------------------------------
+// apply transforms to one document.
+// return false when the document is dropped by transform.
+//
+func (c *yamlsortCmd) applyTransforms(doc *Document) (bool, error) {
+	if doc.Data == nil {
+		return true, nil
+	}
+	if len(c.filter) > 0 {
+		data, found, err := c.applyFilter(doc.Data)
+		if err != nil || !found {
+			return false, err
+		}
+		doc.Data = data
+	}
+	if len(c.renames) > 0 {
+		data, err := c.applyRenames(doc.Data)
+		if err != nil {
+			return false, err
+		}
+		doc.Data = data
+	}
+	if c.blnEnvsubst {
+		if c.envmap == nil {
+			env, err := c.loadEnv()
+			if err != nil {
+				return false, err
+			}
+			c.envmap = env
+		}
+		doc.Data = expandEnvRecursive(doc.Data, c.envmap)
+	}
+	if c.blnRender {
+		if c.renderer == nil {
+			renderer, err := c.newTemplateRenderer()
+			if err != nil {
+				return false, err
+			}
+			c.renderer = renderer
+		}
+		data, err := c.renderer.renderRecursive("", doc.Data)
+		if err != nil {
+			return false, err
+		}
+		doc.Data = data
+	}
+	// edits , normalizers and embedded sorters see values after --envsubst and --render
+	if len(c.metadataedits) > 0 {
+		c.applyMetadataEdits(doc.Data)
+	}
+	if len(c.namespace) > 0 {
+		c.applyNamespace(doc.Data)
+	}
+	if len(c.imageedits) > 0 {
+		c.applyImageEdits(doc.Data)
+	}
+	if c.blnQuantities {
+		c.applyQuantities(doc.Data)
+	}
+	if len(c.scalarnorms) > 0 {
+		doc.Data = c.applyScalarNorms(doc.Data)
+	}
+	if len(c.embeddedjson) > 0 {
+		doc.Data = c.sortEmbeddedJSONRecursive(doc.Data)
+	}
+	if c.blnEmbeddedYAML {
+		doc.Data = c.sortEmbeddedYAMLRecursive(doc.Data)
+	}
+	if len(c.configmapdata) > 0 {
+		c.applyConfigMapData(doc.Data)
+	}
+	if len(c.embeddedglobs) > 0 {
+		c.sortEmbeddedRecursive(doc.Data)
+	}
+	if len(c.scriptfilename) > 0 {
+		if c.script == nil {
+			script, err := loadStarlarkScript(c.scriptfilename)
+			if err != nil {
+				return false, err
+			}
+			c.script = script
+		}
+		data, err := c.script.run(doc.Data)
+		if err != nil {
+			return false, err
+		}
+		if data == nil {
+			// transform(doc) returns None. drop this document.
+			return false, nil
+		}
+		doc.Data = data
+	}
+	if len(c.keycase) > 0 && c.keycase != "preserve" {
+		data, err := c.convertKeyCaseRecursive("", doc.Data)
+		if err != nil {
+			return false, err
+		}
+		doc.Data = data
+	}
+	if c.hasStringNormalization() {
+		doc.Data = c.normalizeStringsRecursive(doc.Data)
+	}
+	if c.blnUnique || len(c.uniquebyfields) > 0 {
+		doc.Data = c.uniqueRecursive(doc.Data)
+	}
+	if len(c.prunecategories) > 0 {
+		doc.Data = c.pruneEmptyRecursive(doc.Data)
+	}
+	for _, command := range c.transformcommands {
+		data, err := c.transformByCommand(command, doc.Data)
+		if err != nil {
+			return false, err
+		}
+		if data == nil {
+			// command output is empty. drop this document.
+			return false, nil
+		}
+		doc.Data = data
+	}
+	if len(c.checksumsegs) > 0 {
+		data, err := c.applyChecksum(doc.Data)
+		if err != nil {
+			return false, err
+		}
+		doc.Data = data
+	}
+	if c.protomessage != nil {
+		err := c.applyProtoMessage(doc.Data)
+		if err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+//-------------------------------------------------------------------------
+// --transform 'cmd'
+// pipe yaml text of data to external command , and read yaml text from its stdout.
+//
+func (c *yamlsortCmd) transformByCommand(command string, data interface{}) (interface{}, error) {
+	inputbytes, err := c.myMarshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd.Stdin = bytes.NewReader(inputbytes)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	err = cmd.Run()
+	if err != nil {
+		return nil, fmt.Errorf("transform command %q failed: %v: %s", command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var result interface{}
+	err = yaml.Unmarshal(stdout.Bytes(), &result)
+	if err != nil {
+		return nil, fmt.Errorf("transform command %q output: %v", command, err)
+	}
+	return result, nil
+}
+
+// read string as yaml scalar. number and bool are converted, others are string.
+func readScalar(s string) interface{} {
+	var scalar interface{}
+	err := yaml.Unmarshal([]byte(s), &scalar)
+	if err == nil {
+		switch scalar.(type) {
+		case float64, bool:
+			return scalar
+		}
+	}
+	return s
+}
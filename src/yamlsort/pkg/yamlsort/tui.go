@@ -0,0 +1,478 @@
+//
+// yamlsort - tui subcommand
+//
+// terminal tree view of sorted documents.
+//   up/down j/k      move cursor
+//   right/l enter    expand
+//   left/h           collapse , or move to parent
+//   space            toggle expand/collapse
+//   /                incremental search of key path and value. n , N for next , previous
+//   y                copy key path of cursor to clipboard
+//   q                quit
+//
+package yamlsort
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// one node of tree view
+type tuiNode struct {
+	key      string
+	segs     []pathSegment
+	value    interface{}
+	children []*tuiNode
+	parent   *tuiNode
+	depth    int
+	expanded bool
+}
+
+func newTuiNode(key string, segs []pathSegment, value interface{}, parent *tuiNode) *tuiNode {
+	node := &tuiNode{key: key, segs: segs, value: value, parent: parent}
+	if parent != nil {
+		node.depth = parent.depth + 1
+	}
+	if m, ok := value.(map[string]interface{}); ok {
+		keylist := make([]string, 0, len(m))
+		for k := range m {
+			keylist = append(keylist, k)
+		}
+		sortKeys(keylist)
+		for _, k := range keylist {
+			childsegs := append(append([]pathSegment{}, segs...), pathSegment{kind: segKey, key: k})
+			node.children = append(node.children, newTuiNode(k, childsegs, m[k], node))
+		}
+	} else if a, ok := value.([]interface{}); ok {
+		for i, v := range a {
+			childsegs := append(append([]pathSegment{}, segs...), pathSegment{kind: segIndex, index: i})
+			node.children = append(node.children, newTuiNode("- ["+strconv.Itoa(i)+"]", childsegs, v, node))
+		}
+	}
+	return node
+}
+
+// key path of node , same form as --skip-key
+func (n *tuiNode) path() string {
+	if len(n.segs) == 0 {
+		return "."
+	}
+	return pathString(n.segs)
+}
+
+// one line text of node
+func (n *tuiNode) label(c *yamlsortCmd) string {
+	marker := "  "
+	if len(n.children) > 0 {
+		if n.expanded {
+			marker = "- "
+		} else {
+			marker = "+ "
+		}
+	}
+	summary := ""
+	switch v := n.value.(type) {
+	case map[string]interface{}:
+		if !n.expanded || len(v) == 0 {
+			summary = "{" + strconv.Itoa(len(v)) + " keys}"
+		}
+	case []interface{}:
+		if !n.expanded || len(v) == 0 {
+			summary = "[" + strconv.Itoa(len(v)) + " items]"
+		}
+	case string:
+		summary = c.escapeString(v)
+	case nil:
+		summary = "null"
+	default:
+		summary = fmt.Sprint(v)
+	}
+	if len(summary) > 0 {
+		summary = " " + summary
+	}
+	return strings.Repeat("  ", n.depth) + marker + n.key + ":" + summary
+}
+
+// pre-order walk of all nodes
+func walkTuiNodes(nodes []*tuiNode, fn func(node *tuiNode)) {
+	for _, n := range nodes {
+		fn(n)
+		walkTuiNodes(n.children, fn)
+	}
+}
+
+//---------------------------------------------------------------------
+//  tuiCmd class
+//
+type tuiCmd struct {
+	yamlsort  *yamlsortCmd
+	roots     []*tuiNode
+	visible   []*tuiNode
+	cursor    int
+	top       int
+	width     int
+	height    int
+	searching bool
+	search    string
+	origin    *tuiNode
+	status    string
+	out       *bufio.Writer
+}
+
+func newTuiCmd(yamlsort *yamlsortCmd) *cobra.Command {
+	tui := &tuiCmd{
+		yamlsort: yamlsort,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "tui file.yaml",
+		Short: "browse sorted documents in terminal tree view",
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("tui requires one input file name")
+			}
+			err := tui.load(args[0])
+			if err != nil {
+				return err
+			}
+			return tui.run()
+		},
+	}
+
+	return cmd
+}
+
+// read documents of file into tree
+func (t *tuiCmd) load(filename string) error {
+	input, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	if len(globalpriorkeys) == 0 {
+		globalpriorkeys = []string{"name"}
+	}
+	c := t.yamlsort
+	c.inputfilename = filename
+	if c.maxlinesize == 0 {
+		c.maxlinesize = defaultMaxLineSize
+	}
+	err = c.processDocuments(bytes.NewReader(input), func(doc *Document) error {
+		key := "--- document " + strconv.Itoa(doc.Index)
+		root := newTuiNode(key, []pathSegment{}, doc.Data, nil)
+		root.expanded = true
+		t.roots = append(t.roots, root)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(t.roots) == 0 {
+		return fmt.Errorf("%s has no documents", filename)
+	}
+	t.refresh()
+	return nil
+}
+
+// list of visible nodes
+func (t *tuiCmd) refresh() {
+	t.visible = t.visible[:0]
+	var add func(nodes []*tuiNode)
+	add = func(nodes []*tuiNode) {
+		for _, n := range nodes {
+			t.visible = append(t.visible, n)
+			if n.expanded {
+				add(n.children)
+			}
+		}
+	}
+	add(t.roots)
+	if t.cursor >= len(t.visible) {
+		t.cursor = len(t.visible) - 1
+	}
+}
+
+// move cursor to node. parents are expanded.
+func (t *tuiCmd) moveTo(node *tuiNode) {
+	for p := node.parent; p != nil; p = p.parent {
+		p.expanded = true
+	}
+	t.refresh()
+	for i, n := range t.visible {
+		if n == node {
+			t.cursor = i
+		}
+	}
+}
+
+// find node , whose path or value contains search text. start is index in all nodes.
+func (t *tuiCmd) find(start int, forward bool) *tuiNode {
+	all := []*tuiNode{}
+	walkTuiNodes(t.roots, func(node *tuiNode) {
+		all = append(all, node)
+	})
+	query := strings.ToLower(t.search)
+	for i := 0; i < len(all); i++ {
+		idx := (start + i) % len(all)
+		if !forward {
+			idx = (start - i + 2*len(all)) % len(all)
+		}
+		n := all[idx]
+		text := n.path()
+		if len(n.children) == 0 {
+			text = text + " " + fmt.Sprint(n.value)
+		}
+		if strings.Contains(strings.ToLower(text), query) {
+			return n
+		}
+	}
+	return nil
+}
+
+// index of node in all nodes
+func (t *tuiCmd) indexOf(node *tuiNode) int {
+	result := 0
+	i := 0
+	walkTuiNodes(t.roots, func(n *tuiNode) {
+		if n == node {
+			result = i
+		}
+		i++
+	})
+	return result
+}
+
+// search from cursor , and move to found node
+func (t *tuiCmd) searchNext(start int, forward bool) {
+	if len(t.search) == 0 {
+		return
+	}
+	node := t.find(start, forward)
+	if node == nil {
+		t.status = "not found: " + t.search
+		return
+	}
+	t.status = ""
+	t.moveTo(node)
+}
+
+// handle one key. return true to quit.
+func (t *tuiCmd) handleKey(key string) bool {
+	node := t.visible[t.cursor]
+	if t.searching {
+		switch key {
+		case "enter":
+			t.searching = false
+		case "esc":
+			t.searching = false
+			t.search = ""
+			t.moveTo(t.origin)
+		case "backspace":
+			if len(t.search) > 0 {
+				t.search = t.search[:len(t.search)-1]
+			}
+			t.searchNext(t.indexOf(t.origin), true)
+		default:
+			if len(key) == 1 {
+				t.search = t.search + key
+				t.searchNext(t.indexOf(t.origin), true)
+			}
+		}
+		return false
+	}
+	t.status = ""
+	switch key {
+	case "q", "ctrl-c":
+		return true
+	case "up", "k":
+		if t.cursor > 0 {
+			t.cursor--
+		}
+	case "down", "j":
+		if t.cursor < len(t.visible)-1 {
+			t.cursor++
+		}
+	case "right", "l", "enter":
+		if len(node.children) > 0 {
+			node.expanded = true
+		}
+	case "left", "h":
+		if node.expanded && len(node.children) > 0 {
+			node.expanded = false
+		} else if node.parent != nil {
+			t.moveTo(node.parent)
+		}
+	case " ":
+		if len(node.children) > 0 {
+			node.expanded = !node.expanded
+		}
+	case "/":
+		t.searching = true
+		t.search = ""
+		t.origin = node
+	case "n":
+		t.searchNext(t.indexOf(node)+1, true)
+	case "N":
+		t.searchNext(t.indexOf(node)-1, false)
+	case "y":
+		err := copyToClipboard(node.path())
+		if err != nil {
+			t.status = "copy error: " + err.Error()
+		} else {
+			t.status = "copied: " + node.path()
+		}
+	}
+	t.refresh()
+	return false
+}
+
+// draw screen
+func (t *tuiCmd) draw() {
+	rows := t.height - 1
+	if t.cursor < t.top {
+		t.top = t.cursor
+	}
+	if t.cursor >= t.top+rows {
+		t.top = t.cursor - rows + 1
+	}
+	t.out.WriteString("\x1b[H\x1b[2J")
+	for i := t.top; i < len(t.visible) && i < t.top+rows; i++ {
+		line := t.visible[i].label(t.yamlsort)
+		if len(line) > t.width {
+			line = line[:t.width]
+		}
+		if i == t.cursor {
+			line = "\x1b[7m" + line + "\x1b[0m"
+		}
+		t.out.WriteString(line + "\r\n")
+	}
+	status := t.visible[t.cursor].path()
+	if t.searching {
+		status = "/" + t.search
+	} else if len(t.status) > 0 {
+		status = t.status
+	}
+	if len(status) > t.width {
+		status = status[:t.width]
+	}
+	fmt.Fprintf(t.out, "\x1b[%d;1H\x1b[1m%s\x1b[0m", t.height, status)
+	t.out.Flush()
+}
+
+func (t *tuiCmd) run() error {
+	restore, err := terminalRawMode()
+	if err != nil {
+		return err
+	}
+	defer restore()
+	t.width, t.height = terminalSize()
+	t.out = bufio.NewWriter(os.Stdout)
+	defer func() {
+		t.out.WriteString("\x1b[H\x1b[2J")
+		t.out.Flush()
+	}()
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		t.draw()
+		key, err := readTerminalKey(reader)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if t.handleKey(key) {
+			return nil
+		}
+	}
+}
+
+// read one key from terminal in raw mode
+func readTerminalKey(reader *bufio.Reader) (string, error) {
+	b, err := reader.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	switch b {
+	case 3:
+		return "ctrl-c", nil
+	case '\r', '\n':
+		return "enter", nil
+	case 127, 8:
+		return "backspace", nil
+	case 27:
+		if reader.Buffered() == 0 {
+			return "esc", nil
+		}
+		b2, _ := reader.ReadByte()
+		if b2 != '[' && b2 != 'O' {
+			return "esc", nil
+		}
+		b3, _ := reader.ReadByte()
+		switch b3 {
+		case 'A':
+			return "up", nil
+		case 'B':
+			return "down", nil
+		case 'C':
+			return "right", nil
+		case 'D':
+			return "left", nil
+		}
+		return "esc", nil
+	}
+	return string([]byte{b}), nil
+}
+
+// set terminal raw mode with stty (console mode on windows). returns function to restore.
+func terminalRawMode() (func(), error) {
+	if runtime.GOOS == "windows" {
+		return consoleRawMode()
+	}
+	saved, err := stty("-g")
+	if err != nil {
+		return nil, fmt.Errorf("stdin is not a terminal: %v", err)
+	}
+	_, err = stty("raw", "-echo")
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		stty(strings.TrimSpace(saved))
+	}, nil
+}
+
+// terminal size (width , height). 80x24 when unknown.
+func terminalSize() (int, int) {
+	if width, height, ok := consoleSize(); ok {
+		return width, height
+	}
+	out, err := stty("size")
+	if err == nil {
+		fields := strings.Fields(out)
+		if len(fields) == 2 {
+			height, err1 := strconv.Atoi(fields[0])
+			width, err2 := strconv.Atoi(fields[1])
+			if err1 == nil && err2 == nil && width > 0 && height > 1 {
+				return width, height
+			}
+		}
+	}
+	return 80, 24
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return string(out), err
+}
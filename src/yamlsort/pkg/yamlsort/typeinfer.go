@@ -0,0 +1,192 @@
+//
+// yamlsort - type inference from documents
+//
+// infer structure type from values of documents , for code generation (gen-go , gen-types).
+// types of all documents and all list elements are merged.
+//   int and float become float , null and other type become nullable type ,
+//   other different types become any.
+// map key which is not in every sample is optional.
+//
+package yamlsort
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// kind of inferred type
+const (
+	typeNull = iota
+	typeBool
+	typeInt
+	typeFloat
+	typeString
+	typeObject
+	typeArray
+	typeAny
+)
+
+type inferredType struct {
+	kind     int
+	nullable bool
+	fields   map[string]*inferredField // typeObject
+	samples  int                       // typeObject. number of merged maps
+	elem     *inferredType             // typeArray. nil when all lists are empty
+}
+
+type inferredField struct {
+	typ   *inferredType
+	count int // number of maps which have the key
+}
+
+// type of value
+func inferType(data interface{}) *inferredType {
+	switch v := data.(type) {
+	case nil:
+		return &inferredType{kind: typeNull}
+	case bool:
+		return &inferredType{kind: typeBool}
+	case int, int64:
+		return &inferredType{kind: typeInt}
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return &inferredType{kind: typeInt}
+		}
+		return &inferredType{kind: typeFloat}
+	case string:
+		return &inferredType{kind: typeString}
+	case map[string]interface{}:
+		t := &inferredType{kind: typeObject, fields: map[string]*inferredField{}, samples: 1}
+		for k, child := range v {
+			t.fields[k] = &inferredField{typ: inferType(child), count: 1}
+		}
+		return t
+	case []interface{}:
+		t := &inferredType{kind: typeArray}
+		for _, child := range v {
+			t.elem = mergeTypes(t.elem, inferType(child))
+		}
+		return t
+	}
+	return &inferredType{kind: typeAny}
+}
+
+// merge two types of same place
+func mergeTypes(a *inferredType, b *inferredType) *inferredType {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if a.kind == typeNull {
+		result := *b
+		result.nullable = true
+		return &result
+	}
+	if b.kind == typeNull {
+		result := *a
+		result.nullable = true
+		return &result
+	}
+	nullable := a.nullable || b.nullable
+	switch {
+	case a.kind == b.kind && a.kind == typeObject:
+		result := &inferredType{kind: typeObject, nullable: nullable, fields: map[string]*inferredField{}, samples: a.samples + b.samples}
+		for k, f := range a.fields {
+			result.fields[k] = &inferredField{typ: f.typ, count: f.count}
+		}
+		for k, f := range b.fields {
+			if rf, ok := result.fields[k]; ok {
+				rf.typ = mergeTypes(rf.typ, f.typ)
+				rf.count += f.count
+			} else {
+				result.fields[k] = &inferredField{typ: f.typ, count: f.count}
+			}
+		}
+		return result
+	case a.kind == b.kind && a.kind == typeArray:
+		return &inferredType{kind: typeArray, nullable: nullable, elem: mergeTypes(a.elem, b.elem)}
+	case a.kind == b.kind:
+		return &inferredType{kind: a.kind, nullable: nullable}
+	case (a.kind == typeInt && b.kind == typeFloat) || (a.kind == typeFloat && b.kind == typeInt):
+		return &inferredType{kind: typeFloat, nullable: nullable}
+	}
+	return &inferredType{kind: typeAny}
+}
+
+// field is not in every map
+func (t *inferredType) optional(key string) bool {
+	return t.fields[key].count < t.samples
+}
+
+// keys of object in sorted order
+func (t *inferredType) sortedKeys() []string {
+	keylist := make([]string, 0, len(t.fields))
+	for k := range t.fields {
+		keylist = append(keylist, k)
+	}
+	sortKeys(keylist)
+	return keylist
+}
+
+// common initialisms , which are upper case in type names
+var typeNameInitialisms = map[string]bool{
+	"api": true, "cpu": true, "dns": true, "http": true, "https": true, "id": true, "ip": true,
+	"json": true, "tcp": true, "tls": true, "ttl": true, "udp": true, "uid": true, "uri": true,
+	"url": true, "uuid": true, "xml": true, "yaml": true,
+}
+
+// upper camel case name of key. "apiVersion" -> "APIVersion"
+func typeName(key string) string {
+	result := ""
+	for _, word := range splitKeyWords(key) {
+		if typeNameInitialisms[word] {
+			result += strings.ToUpper(word)
+			continue
+		}
+		runes := []rune(word)
+		result += string(unicode.ToUpper(runes[0])) + string(runes[1:])
+	}
+	// remove characters which can not be used in names
+	result = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, result)
+	if len(result) == 0 || unicode.IsDigit([]rune(result)[0]) {
+		result = "X" + result
+	}
+	return result
+}
+
+// used type names of generated source
+type typeNameSet map[string]bool
+
+// unique type name. parent name is added when name is used.
+func (names typeNameSet) unique(name string, parent string) string {
+	if !names[name] {
+		names[name] = true
+		return name
+	}
+	candidate := parent + name
+	for i := 2; names[candidate]; i++ {
+		candidate = parent + name + strconv.Itoa(i)
+	}
+	names[candidate] = true
+	return candidate
+}
+
+// singular name of list element. "Containers" -> "Container"
+func singularName(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies") && len(name) > 4:
+		return name[:len(name)-3] + "y"
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss") && len(name) > 3:
+		return name[:len(name)-1]
+	}
+	return name + "Item"
+}
@@ -0,0 +1,61 @@
+//
+// yamlsort - deduplicate sequence elements (--unique , --unique-by)
+//
+package yamlsort
+
+import (
+	"fmt"
+)
+
+// remove duplicate elements of slices. first one is kept.
+// scalar elements with --unique , map elements with same --unique-by field value.
+func (c *yamlsortCmd) uniqueRecursive(data interface{}) interface{} {
+	if m, ok := data.(map[string]interface{}); ok {
+		for k, v := range m {
+			m[k] = c.uniqueRecursive(v)
+		}
+		return m
+	} else if a, ok := data.([]interface{}); ok {
+		result := make([]interface{}, 0, len(a))
+		seen := map[string]bool{}
+		for _, v := range a {
+			v = c.uniqueRecursive(v)
+			key, ok := c.uniqueKey(v)
+			if ok {
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+			}
+			result = append(result, v)
+		}
+		return result
+	}
+	return data
+}
+
+// key to compare element. return false when element is not compared.
+func (c *yamlsortCmd) uniqueKey(elem interface{}) (string, bool) {
+	if m, ok := elem.(map[string]interface{}); ok {
+		for _, field := range c.uniquebyfields {
+			if v, ok := m[field]; ok && v != nil {
+				if _, ok := v.(map[string]interface{}); ok {
+					continue
+				}
+				if _, ok := v.([]interface{}); ok {
+					continue
+				}
+				return fmt.Sprintf("map:%s=%T:%v", field, v, v), true
+			}
+		}
+		return "", false
+	}
+	if _, ok := elem.([]interface{}); ok {
+		return "", false
+	}
+	if !c.blnUnique {
+		return "", false
+	}
+	// type is part of key. 1 and "1" are different.
+	return fmt.Sprintf("%T:%v", elem, elem), true
+}
@@ -0,0 +1,39 @@
+//
+// yamlsort - windows compatibility
+//
+// in-place writes (rename of temporary file) are retried while other process (editor , virus scanner , indexer) locks
+// the file on windows. console specific parts are in console_windows.go and console_other.go.
+//
+package yamlsort
+
+import (
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+// retries of in-place write while file is locked
+const (
+	lockRetryCount    = 20
+	lockRetryInterval = 100 * time.Millisecond
+)
+
+// ioutil.WriteFile , and retry while file is locked by other process
+func writeFileRetry(filename string, data []byte, perm os.FileMode) error {
+	err := ioutil.WriteFile(filename, data, perm)
+	for i := 0; i < lockRetryCount && err != nil && isFileLockError(err); i++ {
+		time.Sleep(lockRetryInterval)
+		err = ioutil.WriteFile(filename, data, perm)
+	}
+	return err
+}
+
+// os.Rename , and retry while file is locked by other process
+func renameRetry(oldpath string, newpath string) error {
+	err := os.Rename(oldpath, newpath)
+	for i := 0; i < lockRetryCount && err != nil && isFileLockError(err); i++ {
+		time.Sleep(lockRetryInterval)
+		err = os.Rename(oldpath, newpath)
+	}
+	return err
+}
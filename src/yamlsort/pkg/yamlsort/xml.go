@@ -0,0 +1,191 @@
+//
+// yamlsort - xml decoder
+//
+// decode xml text into map[string]interface{} , with root element as only key.
+//   <server port="8080"><name>web</name><alias>a</alias><alias>b</alias></server>
+//     server:
+//       '@port': "8080"
+//       alias:
+//       - a
+//       - b
+//       name: web
+// --xml-attributes
+//   prefix   attributes are keys with @ (default)
+//   merge    attributes are keys same as child elements
+//   ignore   attributes are removed
+// repeated elements are list. text of element with attributes or children is #text. empty element
+// is null. all values are strings (xml has no types). comments , processing instructions and
+// doctype are not kept. prefix of namespace is kept in name (like xsi:type).
+//
+package yamlsort
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// values of --xml-attributes
+const (
+	xmlAttributesPrefix = "prefix"
+	xmlAttributesMerge  = "merge"
+	xmlAttributesIgnore = "ignore"
+)
+
+// keys of attributes and text in prefix mode
+const (
+	xmlAttributeKeyPrefix = "@"
+	xmlTextKey            = "#text"
+)
+
+// first line of xml , like <?xml ... ?> , <!-- --> , <root>
+var xmlFirstLineRegexp = regexp.MustCompile(`^<(\?xml|!--|![A-Z]|[A-Za-z_])`)
+
+func checkXMLAttributes(mode string) error {
+	switch mode {
+	case "", xmlAttributesPrefix, xmlAttributesMerge, xmlAttributesIgnore:
+		return nil
+	}
+	return fmt.Errorf("unknown --xml-attributes %q. (prefix , merge , ignore)", mode)
+}
+
+// first line (without blank lines) looks like xml
+func sniffXML(input []byte) bool {
+	text := strings.TrimPrefix(strings.TrimSpace(string(input)), "\ufeff")
+	return xmlFirstLineRegexp.MatchString(text)
+}
+
+// name of element or attribute with namespace prefix
+func xmlName(name xml.Name) string {
+	if len(name.Space) == 0 {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// decode xml text
+func (c *yamlsortCmd) decodeXML(input []byte) (interface{}, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(input))
+	// encoding declaration is ignored , input is utf-8
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			return nil, fmt.Errorf("xml: root element is not found")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("xml: %v", err)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			value, err := c.decodeXMLElement(decoder, input, t)
+			if err != nil {
+				return nil, err
+			}
+			root := map[string]interface{}{xmlName(t.Name): value}
+			// only comments and spaces after root element
+			for {
+				token, err := decoder.RawToken()
+				if err == io.EOF {
+					return root, nil
+				}
+				if err != nil {
+					return nil, fmt.Errorf("xml: %v", err)
+				}
+				switch t := token.(type) {
+				case xml.StartElement:
+					return nil, fmt.Errorf("xml: line %d: element <%s> after root element", xmlLine(input, decoder), xmlName(t.Name))
+				case xml.CharData:
+					if len(bytes.TrimSpace(t)) > 0 {
+						return nil, fmt.Errorf("xml: text after root element")
+					}
+				}
+			}
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) > 0 {
+				return nil, fmt.Errorf("xml: text before root element")
+			}
+		}
+	}
+}
+
+// value of element after start tag until end tag
+func (c *yamlsortCmd) decodeXMLElement(decoder *xml.Decoder, input []byte, start xml.StartElement) (interface{}, error) {
+	m := map[string]interface{}{}
+	attributes := map[string]bool{}
+	if c.xmlattributes != xmlAttributesIgnore {
+		for _, attr := range start.Attr {
+			key := xmlName(attr.Name)
+			if c.xmlattributes != xmlAttributesMerge {
+				key = xmlAttributeKeyPrefix + key
+			}
+			m[key] = attr.Value
+			attributes[key] = true
+		}
+	}
+	text := new(strings.Builder)
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			return nil, fmt.Errorf("xml: element <%s> is not closed", xmlName(start.Name))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("xml: %v", err)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			value, err := c.decodeXMLElement(decoder, input, t)
+			if err != nil {
+				return nil, err
+			}
+			key := xmlName(t.Name)
+			if attributes[key] {
+				return nil, fmt.Errorf("xml: line %d: element <%s> conflicts with attribute", xmlLine(input, decoder), key)
+			}
+			// repeated elements are list. values of elements are not list.
+			switch existing := m[key].(type) {
+			case nil:
+				if _, ok := m[key]; ok {
+					m[key] = []interface{}{nil, value}
+				} else {
+					m[key] = value
+				}
+			case []interface{}:
+				m[key] = append(existing, value)
+			default:
+				m[key] = []interface{}{existing, value}
+			}
+		case xml.EndElement:
+			if xmlName(t.Name) != xmlName(start.Name) {
+				return nil, fmt.Errorf("xml: line %d: </%s> is expected , not </%s>", xmlLine(input, decoder), xmlName(start.Name), xmlName(t.Name))
+			}
+			s := strings.TrimSpace(text.String())
+			if len(m) == 0 {
+				if len(s) == 0 {
+					return nil, nil
+				}
+				return s, nil
+			}
+			if len(s) > 0 {
+				m[xmlTextKey] = s
+			}
+			return m, nil
+		case xml.CharData:
+			text.Write(t)
+		}
+	}
+}
+
+// line number of decoder position
+func xmlLine(input []byte, decoder *xml.Decoder) int {
+	offset := int(decoder.InputOffset())
+	if offset > len(input) {
+		offset = len(input)
+	}
+	return bytes.Count(input[:offset], []byte("\n")) + 1
+}
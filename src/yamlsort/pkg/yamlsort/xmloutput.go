@@ -0,0 +1,196 @@
+//
+// yamlsort - --output-format xml
+//
+// write sorted yaml as xml. document must be map with one key , which is root element.
+//   yamlsort --output-format xml -i server.yaml -o server.xml
+//     <?xml version="1.0" encoding="UTF-8"?>
+//     <server port="8080">
+//       <alias>a</alias>
+//       <alias>b</alias>
+//       <name>web</name>
+//     </server>
+// lists are repeated elements , and null is empty element. attributes follow --xml-attributes
+//   prefix   keys with @ are attributes (default)
+//   merge    keys with @ and scalar values are attributes
+//   ignore   keys with @ are not written
+// #text is text of element. attributes and elements are sorted same as yaml. only one document
+// can be written , because xml has one root element.
+//
+package yamlsort
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// name of element and attribute
+var xmlNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.\-]*(:[A-Za-z_][A-Za-z0-9_.\-]*)?$`)
+
+// xml text of sorted yaml text
+func (c *yamlsortCmd) renderXML(input []byte) ([]byte, error) {
+	docs, err := splitDocuments(input, defaultMaxLineSize)
+	if err != nil {
+		return nil, err
+	}
+	var root map[string]interface{}
+	for _, doc := range docs {
+		if doc.isEmpty() {
+			continue
+		}
+		if root != nil {
+			return nil, fmt.Errorf("xml: only one document can be written")
+		}
+		data, err := c.unmarshalYAML(doc.parseData())
+		if err != nil {
+			return nil, err
+		}
+		m, ok := data.(map[string]interface{})
+		if !ok || len(m) != 1 {
+			return nil, fmt.Errorf("xml: document must be map with one key (root element)")
+		}
+		root = m
+	}
+	if root == nil {
+		return nil, fmt.Errorf("xml: root element is not found")
+	}
+	buf := new(bytes.Buffer)
+	buf.WriteString(xml.Header)
+	for name, value := range root {
+		err = c.writeXMLElement(buf, name, value, 0)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// key is attribute of element in --xml-attributes mode
+func (c *yamlsortCmd) isXMLAttribute(key string, value interface{}) bool {
+	if strings.HasPrefix(key, xmlAttributeKeyPrefix) {
+		return true
+	}
+	if c.xmlattributes != xmlAttributesMerge || key == xmlTextKey {
+		return false
+	}
+	switch value.(type) {
+	case nil, []interface{}, map[string]interface{}:
+		return false
+	}
+	return true
+}
+
+// <name>value</name>. list is repeated elements.
+func (c *yamlsortCmd) writeXMLElement(buf *bytes.Buffer, name string, value interface{}, indent int) error {
+	if !xmlNameRegexp.MatchString(name) {
+		return fmt.Errorf("xml: key %q is not name of element", name)
+	}
+	prefix := strings.Repeat(" ", indent)
+	switch v := value.(type) {
+	case nil:
+		fmt.Fprintf(buf, "%s<%s/>\n", prefix, name)
+	case []interface{}:
+		for _, child := range v {
+			if _, ok := child.([]interface{}); ok {
+				return fmt.Errorf("xml: list in list at %s can not be xml", name)
+			}
+			err := c.writeXMLElement(buf, name, child, indent)
+			if err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		attributes := []string{}
+		children := []string{}
+		for k, child := range v {
+			if k == xmlTextKey {
+				continue
+			}
+			if c.isXMLAttribute(k, child) {
+				if c.xmlattributes != xmlAttributesIgnore {
+					attributes = append(attributes, k)
+				}
+			} else {
+				children = append(children, k)
+			}
+		}
+		sortKeys(attributes)
+		sortKeys(children)
+		fmt.Fprintf(buf, "%s<%s", prefix, name)
+		for _, k := range attributes {
+			attr := strings.TrimPrefix(k, xmlAttributeKeyPrefix)
+			if !xmlNameRegexp.MatchString(attr) {
+				return fmt.Errorf("xml: key %q is not name of attribute", k)
+			}
+			text, err := c.xmlText(v[k])
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(buf, " %s=\"%s\"", attr, text)
+		}
+		text := ""
+		if t, ok := v[xmlTextKey]; ok {
+			var err error
+			text, err = c.xmlText(t)
+			if err != nil {
+				return err
+			}
+		}
+		switch {
+		case len(children) == 0 && len(text) == 0:
+			buf.WriteString("/>\n")
+		case len(children) == 0:
+			fmt.Fprintf(buf, ">%s</%s>\n", text, name)
+		default:
+			buf.WriteString(">\n")
+			if len(text) > 0 {
+				fmt.Fprintf(buf, "%s  %s\n", prefix, text)
+			}
+			for _, k := range children {
+				err := c.writeXMLElement(buf, k, v[k], indent+2)
+				if err != nil {
+					return err
+				}
+			}
+			fmt.Fprintf(buf, "%s</%s>\n", prefix, name)
+		}
+	default:
+		text, err := c.xmlText(v)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(buf, "%s<%s>%s</%s>\n", prefix, name, text, name)
+	}
+	return nil
+}
+
+// escaped text of scalar value
+func (c *yamlsortCmd) xmlText(value interface{}) (string, error) {
+	var s string
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		s = v
+	case bool:
+		s = strconv.FormatBool(v)
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1e21 {
+			s = strconv.FormatFloat(v, 'f', -1, 64)
+		} else {
+			s = c.formatFloat("", v)
+		}
+	default:
+		return "", fmt.Errorf("xml: map or list can not be text or attribute")
+	}
+	buf := new(bytes.Buffer)
+	err := xml.EscapeText(buf, []byte(s))
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
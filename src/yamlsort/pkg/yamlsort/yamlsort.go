@@ -0,0 +1,1641 @@
+//
+// yamlsort - sort by map's key
+//
+//
+//
+package yamlsort
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// version string of command , set by Main
+var version string
+
+var yamlsortUsage = `
+yaml sorter. read yaml text from stdin or file, output map key sorted text to stdout or file.
+`
+
+//---------------------------------------------------------------------
+//  stringMacro class
+// helm chart macro value
+//
+type stringMacro struct {
+	value string
+}
+
+func (c *stringMacro) setString(arg string) {
+}
+func (c *stringMacro) getString() string {
+	return c.value
+}
+
+//---------------------------------------------------------------------
+//  yamlsortCmd class
+//
+type yamlsortCmd struct {
+	stdin               io.Reader
+	stdout              io.Writer
+	stderr              io.Writer
+	inputfilename       string
+	outputfilename      string
+	inputoutputfilename string
+	overridefilename    string
+	skipkeys            []string
+	blnInputJSON        bool
+	inputformat         string
+	xmlattributes       string
+	blnNormalMarshal    bool
+	blnJSONMarshal      bool
+	blnQuoteString      bool
+	blnArrayIndentPlus2 bool
+	priorkeys           []string
+	blnVersion          bool
+	blnNoProgress       bool
+	maxlinesize         int
+	maxdepth            int
+	maxaliasexpansion   int
+	transformcommands   []string
+	scriptfilename      string
+	script              *starlarkScript
+	presets             []string
+	comparatorname      string
+	comparator          *keyComparator
+	blnEnvsubst         bool
+	envfilenames        []string
+	envmap              map[string]string
+	blnRender           bool
+	valuesfilenames     []string
+	renderer            *templateRenderer
+	renames             []renameRule
+	metadataedits       []k8sMetadataEdit
+	blnPodTemplate      bool
+	imageedits          []*imageEdit
+	blnQuantities       bool
+	scalarnormargs      []string // --normalize-scalar
+	scalarnormfile      string
+	scalarnorms         []scalarNormRule
+	embeddedjson        string // --sort-embedded-json
+	blnEmbeddedYAML     bool
+	blockstrings        map[string]bool // string values written as block scalar
+	configmapdata       string          // --configmap-data
+	embeddedglobs       []string        // --sort-embedded
+	descriptorfilename  string          // --descriptor
+	protomessagename    string          // --message
+	protoset            *protoDescriptorSet
+	protomessage        *protoMessage
+	namespace           string
+	clusterkinds        map[string]bool // kinds of cluster-scoped custom resources
+	keycase             string
+	pruneempty          string
+	prunecategories     map[string]bool
+	blnUnique           bool
+	uniquebyfields      []string
+	blnTrimSpace        bool
+	blnCollapseSpaces   bool
+	expandtabs          int
+	blnCommentSpace     bool
+	commentcolumn       int
+	dropcomment         string
+	dropcommentregexp   *regexp.Regexp
+	lintprofile         string
+	blnQuoteTruthy      bool
+	blnFlowEmptyList    bool
+	blnNoTrailingBlank  bool
+	blnDoubleQuote      bool
+	blnFlowEmptyMap     bool
+	blnNoDocumentBlank  bool
+	linewidth           int
+	blanklines          string
+	blanklinepaths      map[string]bool
+	blnNoHeader         bool
+	blnPartialInput     bool
+	environ             []string
+	blnRemote           bool
+	blnWrite            bool
+	blnDryRun           bool
+	backupsuffix        string
+	blnNoClobber        bool
+	chmod               string
+	blnAppend           bool
+	blnNoOverwrite      bool
+	blnTee              bool
+	blnClipboardIn      bool
+	blnClipboardOut     bool
+	blnNoPager          bool
+	outputformat        string
+	filemode            os.FileMode
+	blnNoIgnore         bool
+	blnFollowSymlinks   bool
+	blnNoFollowSymlinks bool
+	extensions          string
+	blnSniff            bool
+	startline           int
+	endline             int
+	blnFramed           bool
+	outputtemplate      string
+	outputtmpl          *template.Template
+	keyorders           map[string][]string // path -> key order (helm-values)
+	keycomments         map[string][]string // path -> comment lines of key (scaffold)
+	mergeprefer         string              // ours , theirs (git-merge)
+	blnMergeInteractive bool
+	liststrategyargs    []string // --list-strategy (git-merge)
+	liststrategyfile    string
+	checksumpaths       []string
+	checksumsegs        [][]pathSegment
+	checksumannotation  string
+	canonicalversion    int
+	rules               *emissionRules // rules of --canonical-version
+	floatformat         string
+	numbertexts         map[string]numberText // numbers of document written now
+	blnStrictFloats     bool
+	blnStrictTypes      bool
+	typechecker         *typeChecker // --strict-types state of input
+	blnCheckRefs        bool
+	refchecker          *refChecker       // --check-refs state of input
+	ancestors           []marshalAncestor // maps and lists which contain node written now
+	filter              string
+	filtersteps         []jsonPathStep
+	docindexes          []int
+	selectexpr          string
+	selectconditions    [][]selectCondition
+	unselected          string
+	blnAnnotateSource   bool
+	dedupeanchors       int
+	dedupe              *dedupeState
+	blnMinimal          bool
+	blnGitChanged       bool
+	blnCheck            bool
+	checkformat         string
+	rulesfilename       string
+	policyrules         []*policyRule
+	violations          []policyViolation
+	policydirs          []string
+	policyquery         string
+	version             string
+}
+
+// root command of process , and its options
+func newRootCmd() (*cobra.Command, *yamlsortCmd) {
+	yamlsort := &yamlsortCmd{
+		version: version,
+		stdin:   os.Stdin,
+		stdout:  os.Stdout,
+		stderr:  os.Stderr,
+	}
+	return newRootCommand(yamlsort), yamlsort
+}
+
+// root command of options. library API (NewOptions) parses options with it.
+func newRootCommand(yamlsort *yamlsortCmd) *cobra.Command {
+
+	cmd := &cobra.Command{
+		Use:   "yamlsort",
+		Short: "yaml sorter",
+		Long:  yamlsortUsage,
+		// file and directory arguments. "yamlsort version" displays version
+		Args: cobra.ArbitraryArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			err := yamlsort.run(args)
+			if err == nil && !yamlsort.blnCheck && len(yamlsort.violations) > 0 {
+				// violations are reported already
+				c.SilenceUsage = true
+				err = fmt.Errorf("%d policy violations", len(yamlsort.violations))
+			}
+			if err == errCheckFailed {
+				// findings are reported already
+				c.SilenceErrors = true
+				c.SilenceUsage = true
+			}
+			return err
+		},
+		PersistentPreRunE: func(c *cobra.Command, args []string) error {
+			return globalprofiler.start()
+		},
+	}
+
+	f := cmd.Flags()
+	addInputOutputFlags(f, yamlsort)
+	f.BoolVarP(&yamlsort.blnWrite, "write", "w", false, "write result to file arguments in place , instead of stdout")
+	f.BoolVar(&yamlsort.blnDryRun, "dry-run", false, "with -w or -f , show unified diff and write nothing")
+	f.BoolVar(&yamlsort.blnCheck, "check", false, "check that file arguments are sorted , and write nothing. exit 1 when some files are not sorted")
+	f.StringVar(&yamlsort.rulesfilename, "rules", "", "path to rule file of policy checks for each document (violations are reported , and exit 1)")
+	f.StringArrayVar(&yamlsort.policydirs, "policy", []string{}, "path to directory (or file) of Rego policies , evaluated for each document with opa command. (can specify multiple paths)")
+	f.StringVar(&yamlsort.policyquery, "policy-query", defaultPolicyQuery, "query of --policy , which returns messages of violations")
+	f.StringVar(&yamlsort.checkformat, "format", checkFormatText, "report format of --check. text , github , gitlab , junit , sarif")
+	f.StringVar(&yamlsort.backupsuffix, "backup", "", "with -w or -f , save original file with this suffix (default .orig)")
+	f.Lookup("backup").NoOptDefVal = defaultBackupSuffix
+	f.BoolVar(&yamlsort.blnNoClobber, "no-clobber", false, "with -w or -f , refuse to overwrite file which is modified since read")
+	f.StringVar(&yamlsort.chmod, "chmod", "", "with -w or -f , set mode of written files (octal , like 0644). default keeps mode of file")
+	f.BoolVar(&yamlsort.blnNoIgnore, "no-ignore", false, "do not skip files matched by .gitignore and .yamlsortignore in directories")
+	f.BoolVar(&yamlsort.blnFollowSymlinks, "follow-symlinks", false, "follow symbolic links in directories (link cycles are detected)")
+	f.BoolVar(&yamlsort.blnNoFollowSymlinks, "no-follow-symlinks", false, "skip symbolic links in directories (default)")
+	f.StringVar(&yamlsort.extensions, "ext", defaultExtensions, "comma separated extensions of yaml files in directories (like yaml,yml,yaml.tpl)")
+	f.BoolVar(&yamlsort.blnSniff, "sniff", false, "sort files without extension in directories , when the content looks like yaml")
+	f.StringVarP(&yamlsort.overridefilename, "override-file", "", "", "path to override input file name")
+	f.BoolVar(&yamlsort.blnInputJSON, "jsoninput", false, "read JSON data")
+	f.StringVar(&yamlsort.inputformat, "input-format", "", "format of input. yaml , json , toml , hcl (experimental) , xml. default is extension of input file , or content")
+	f.StringVar(&yamlsort.xmlattributes, "xml-attributes", xmlAttributesPrefix, "attributes of xml input and output. prefix (@name keys) , merge (same as child elements , scalar values are attributes in output) , ignore")
+	f.BoolVar(&yamlsort.blnQuoteString, "quote-string", false, "string value is always quoted in output")
+	f.BoolVar(&yamlsort.blnNormalMarshal, "normal", false, "use marshal (github.com/ghodss/yaml)")
+	f.BoolVar(&yamlsort.blnJSONMarshal, "jsonoutput", false, "use json marshal (encoding/json)")
+	f.BoolVar(&yamlsort.blnArrayIndentPlus2, "array-indent-plus-2", false, "output array indent + 2 in yaml format")
+	f.BoolVar(&yamlsort.blnVersion, "version", false, "displays version")
+	f.IntVar(&yamlsort.maxlinesize, "max-line-size", defaultMaxLineSize, "maximum input line size in bytes")
+	f.IntVar(&yamlsort.maxdepth, "max-depth", defaultMaxDepth, "maximum nesting depth of maps and lists in output")
+	f.IntVar(&yamlsort.maxaliasexpansion, "max-alias-expansion", defaultMaxAliasExpansion, "maximum nodes expanded from aliases in one document")
+	f.BoolVar(&yamlsort.blnNoProgress, "no-progress", false, "do not print progress lines to stderr on long runs")
+	f.StringArrayVar(&yamlsort.priorkeys, "key", []string{}, "set prior key name in sort. default prior key is name. (can specify multiple values with --key name --key title)")
+	f.StringVar(&yamlsort.scriptfilename, "script", "", "path to starlark script file , which defines transform(doc) function")
+	f.StringArrayVar(&yamlsort.transformcommands, "transform", []string{}, "pipe each document through external command (yaml text on stdin and stdout). (can specify multiple commands)")
+	f.BoolVar(&yamlsort.blnEnvsubst, "envsubst", false, "expand ${VAR} and ${VAR:-default} in string values with environment variables")
+	f.StringArrayVar(&yamlsort.envfilenames, "env-file", []string{}, "path to env file (KEY=VALUE lines) for --envsubst. (can specify multiple files)")
+	f.BoolVar(&yamlsort.blnRender, "render", false, "render go template {{ ... }} in string values with --values data")
+	f.StringArrayVar(&yamlsort.valuesfilenames, "values", []string{}, "path to values file for --render. (can specify multiple files, later one overrides)")
+	f.StringVar(&yamlsort.keycase, "key-case", "preserve", "convert all map keys to camel , snake , kebab case , or preserve")
+	f.StringVar(&yamlsort.pruneempty, "prune-empty", "", "remove keys of empty values. all , or comma separated null,string,map,list")
+	f.Lookup("prune-empty").NoOptDefVal = "all"
+	f.StringArrayVar(&yamlsort.checksumpaths, "checksum-files", []string{}, "path of values which refer files (like 'configMapGenerator[*].files[*]'). sha256 of the files is written as annotation. (can specify multiple paths)")
+	f.StringVar(&yamlsort.checksumannotation, "checksum-annotation", defaultChecksumAnnotation, "annotation name of --checksum-files")
+	f.BoolVar(&yamlsort.blnUnique, "unique", false, "remove duplicate scalar elements from lists")
+	f.StringArrayVar(&yamlsort.uniquebyfields, "unique-by", []string{}, "remove duplicate map elements from lists , which have same value of the key. (can specify multiple values with --unique-by name --unique-by id)")
+	f.BoolVar(&yamlsort.blnTrimSpace, "trim-space", false, "remove trailing white spaces of each line in string values")
+	f.BoolVar(&yamlsort.blnCollapseSpaces, "collapse-spaces", false, "collapse runs of spaces in string values into one space (leading indent is kept)")
+	f.IntVar(&yamlsort.expandtabs, "expand-tabs", 0, "replace tabs in string values with spaces of this tab width")
+	f.BoolVar(&yamlsort.blnCommentSpace, "comment-space", false, "ensure a space after '#' in comments")
+	f.IntVar(&yamlsort.commentcolumn, "comment-column", 0, "align inline comment (# powered by ...) to this column")
+	f.StringVar(&yamlsort.dropcomment, "drop-comment", "", "drop comment lines which match this regexp (like commented-out code)")
+	f.StringVar(&yamlsort.blanklines, "blank-lines", "none", "blank lines between map keys. keep (blank lines of input) , none , between-top-level")
+	f.BoolVar(&yamlsort.blnAnnotateSource, "annotate-source", false, "write source file and document index as comment before each output document")
+	f.BoolVar(&yamlsort.blnGitChanged, "git-changed", false, "sort only documents which contain lines changed in working tree (git diff HEAD) , and write others as is")
+	f.BoolVar(&yamlsort.blnMinimal, "minimal", false, "only reorder map keys , and keep quoting , scalar styles and comments of lines as is")
+	f.IntVar(&yamlsort.dedupeanchors, "dedupe-anchors", 0, "write identical maps and lists of this json size or more once with anchor , and aliases at other places (like --dedupe-anchors=128)")
+	f.Lookup("dedupe-anchors").NoOptDefVal = strconv.Itoa(defaultDedupeSize)
+	f.IntSliceVar(&yamlsort.docindexes, "doc", []int{}, "output only documents of these indexes (0 origin , like --doc 0,2)")
+	f.StringVar(&yamlsort.selectexpr, "select", "", "output only documents which match expression (like 'kind==Deployment && metadata.name==\"api\"')")
+	f.StringVar(&yamlsort.unselected, "unselected", unselectedSkip, "documents not selected by --doc or --select. skip , or keep (pass through unchanged)")
+	f.StringVar(&yamlsort.filter, "filter", "", "output only nodes selected by JSONPath (like '$.spec.template' or '$..image')")
+	f.StringVar(&yamlsort.outputtemplate, "output-template", "", "with file arguments , write result of each file into this path. (like '{{.Dir}}/{{.Name}}.json')")
+	f.BoolVar(&yamlsort.blnFramed, "framed", false, "read many files from stdin in frames (frame <length> <filename> header) , and write sorted frames")
+	f.IntVar(&yamlsort.startline, "start-line", 0, "sort only documents which contain lines from this line (1 origin)")
+	f.IntVar(&yamlsort.endline, "end-line", 0, "sort only documents which contain lines until this line (1 origin)")
+	f.StringVar(&yamlsort.floatformat, "float-format", floatFormatG, "format of numbers. g (like %v) , f (no exponent) , preserve (text of input) , or printf format like %.2f")
+	f.BoolVar(&yamlsort.blnStrictFloats, "strict-floats", false, "reject .inf , -.inf and .nan values in input (strict mode)")
+	f.BoolVar(&yamlsort.blnStrictTypes, "strict-types", false, "error when same key has different types in list elements or documents (like port is number and string)")
+	f.BoolVar(&yamlsort.blnCheckRefs, "check-refs", false, "report dangling references between kubernetes documents (selectors , configmaps , secrets) as policy violations")
+	f.StringVar(&yamlsort.namespace, "set-namespace", "", "set metadata.namespace of all namespaced kubernetes documents (cluster-scoped kinds are skipped)")
+	f.BoolVar(&yamlsort.blnQuantities, "normalize-quantities", false, "normalize kubernetes resource quantities into canonical form (like 1024Mi -> 1Gi , 0.5 -> 500m)")
+	f.StringArrayVar(&yamlsort.scalarnormargs, "normalize-scalar", []string{}, "normalize values at path , like spec.timeout=duration (duration , size , quantity) (can specify multiple times)")
+	f.StringVar(&yamlsort.scalarnormfile, "normalize-scalar-file", "", "yaml file of normalizers of values (path: normalizer)")
+	f.StringVar(&yamlsort.embeddedjson, "sort-embedded-json", "", "sort keys of json in string values. keep (pretty when value has line breaks) , compact , pretty")
+	f.Lookup("sort-embedded-json").NoOptDefVal = embeddedJSONKeep
+	f.BoolVar(&yamlsort.blnEmbeddedYAML, "sort-embedded-yaml", false, "sort yaml in string values (like data of ConfigMap) , and write them as block scalar")
+	f.StringVar(&yamlsort.configmapdata, "configmap-data", "", "normalize data of ConfigMap. format (multi-line values in block scalar) , sort (format , and sort .properties and .env values by key)")
+	f.Lookup("configmap-data").NoOptDefVal = configMapDataFormat
+	f.StringArrayVar(&yamlsort.embeddedglobs, "sort-embedded", []string{}, "sort lines of ini , properties and conf text by key within sections , in values of keys which match glob (like '*.properties'). (can specify multiple globs)")
+	f.StringVar(&yamlsort.descriptorfilename, "descriptor", "", "path to protobuf descriptor set (protoc --descriptor_set_out) for --message")
+	f.StringVar(&yamlsort.protomessagename, "message", "", "protobuf message of documents (like pkg.Msg). documents are validated , and keys are ordered by field number")
+	f.IntVar(&yamlsort.canonicalversion, "canonical-version", 0, "pin emission rules of output to this version (like --canonical-version=1). default is latest")
+	f.StringVar(&yamlsort.lintprofile, "lint-profile", "", "output yaml which passes linter rules. (yamllint-default , prettier)")
+	f.StringArrayVar(&yamlsort.presets, "preset", []string{}, "use preset plugin yamlsort-preset-<name> on PATH. (can specify multiple presets)")
+	f.StringVar(&yamlsort.comparatorname, "comparator", "", "order keys of maps by comparator plugin yamlsort-comparator-<name> on PATH")
+	f.StringArrayVar(&yamlsort.skipkeys, "skip-key", []string{}, "skip key name in marshal output. (can specify multiple values with --skip-key name --skip-key title)")
+
+	pf := cmd.PersistentFlags()
+	pf.StringVar(&globalprofiler.cpuprofile, "cpuprofile", "", "write cpu profile to file")
+	pf.StringVar(&globalprofiler.memprofile, "memprofile", "", "write memory profile to file")
+	pf.MarkHidden("cpuprofile")
+	pf.MarkHidden("memprofile")
+
+	cmd.AddCommand(newBenchCmd(yamlsort))
+	cmd.AddCommand(newPresetsCmd(yamlsort))
+	cmd.AddCommand(newRenameCmd(yamlsort))
+	cmd.AddCommand(newTuiCmd(yamlsort))
+	cmd.AddCommand(newLspCmd(yamlsort))
+	cmd.AddCommand(newDaemonCmd(yamlsort))
+	cmd.AddCommand(newClientCmd(yamlsort))
+	cmd.AddCommand(newTextconvCmd(yamlsort))
+	cmd.AddCommand(newGitMergeCmd(yamlsort))
+	cmd.AddCommand(newStatsCmd(yamlsort))
+	cmd.AddCommand(newPathsCmd(yamlsort))
+	cmd.AddCommand(newAuditCmd(yamlsort))
+	cmd.AddCommand(newDiffDirCmd(yamlsort))
+	cmd.AddCommand(newEqualCmd(yamlsort))
+	cmd.AddCommand(newIsSortedCmd(yamlsort))
+	cmd.AddCommand(newArchiveCmd(yamlsort))
+	cmd.AddCommand(newKubeCmd(yamlsort))
+	cmd.AddCommand(newHelmValuesCmd(yamlsort))
+	cmd.AddCommand(newComposeConfigCmd(yamlsort))
+	cmd.AddCommand(newGenGoCmd(yamlsort))
+	cmd.AddCommand(newGenTypesCmd(yamlsort))
+	cmd.AddCommand(newScaffoldCmd(yamlsort))
+	cmd.AddCommand(newOverlayCmd(yamlsort))
+	cmd.AddCommand(newEncryptCmd(yamlsort))
+	cmd.AddCommand(newDecryptCmd(yamlsort))
+	cmd.AddCommand(newSelftestCmd(yamlsort))
+	cmd.AddCommand(newGraphCmd(yamlsort))
+	cmd.AddCommand(newK8sLabelCmd(yamlsort))
+	cmd.AddCommand(newSetImageCmd(yamlsort))
+
+	return cmd
+}
+
+// -f , -i , -o options. root command and subcommands use them.
+func addInputOutputFlags(f *pflag.FlagSet, yamlsort *yamlsortCmd) {
+	f.StringVarP(&yamlsort.inputoutputfilename, "input-output-file", "f", "", "path to input/output file name")
+	f.StringVarP(&yamlsort.inputfilename, "input-file", "i", "", "path to input file name")
+	f.StringVarP(&yamlsort.outputfilename, "output-file", "o", "", "path to output file name")
+	f.BoolVar(&yamlsort.blnAppend, "append", false, "append documents to existing output file (-o , --output-template)")
+	f.BoolVar(&yamlsort.blnNoOverwrite, "no-overwrite", false, "refuse to write output file (-o , --output-template) , which exists already")
+	f.BoolVar(&yamlsort.blnTee, "tee", false, "write output to stdout too , with -o , -f or --clipboard-out")
+	f.BoolVar(&yamlsort.blnClipboardIn, "clipboard-in", false, "read input from system clipboard , instead of stdin")
+	f.BoolVar(&yamlsort.blnClipboardOut, "clipboard-out", false, "write output to system clipboard , instead of stdout")
+	f.BoolVar(&yamlsort.blnNoPager, "no-pager", false, "do not pipe long output to terminal through pager ($YAMLSORT_PAGER , $PAGER , less)")
+	f.StringVar(&yamlsort.outputformat, "output-format", outputFormatYAML, "format of output. yaml , html (standalone page with syntax highlight and collapsible nodes) , hcl (experimental) , or xml")
+}
+
+// Main runs yamlsort command with arguments of process , and exits with status 1 on error
+// (2 for errors of equal and diff-dir , which exit 1 when files differ).
+// versionstr is version of command (git describe , set by ldflags of main package).
+func Main(versionstr string) {
+	version = versionstr
+	prepareConsole()
+	cmd, yamlsort := newRootCmd()
+	err := cmd.Execute()
+	if err2 := yamlsort.closeComparator(); err2 != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err2)
+		err = err2
+	}
+	if err2 := globalprofiler.stop(); err2 != nil {
+		fmt.Fprintln(os.Stderr, "profile error:", err2)
+	}
+	restoreConsole()
+	if err != nil {
+		os.Exit(exitStatus(err))
+	}
+}
+
+// in my marshal, sort prior key
+var globalpriorkeys []string
+
+//------------------------------------------------------------------------
+// run main
+//
+func (c *yamlsortCmd) run(args []string) error {
+
+	if len(args) == 1 && args[0] == "version" {
+		if _, err := os.Stat(args[0]); err != nil {
+			fmt.Fprintln(c.stdout, "yamlsort version "+c.version)
+			return nil
+		}
+	}
+	if c.blnVersion {
+		fmt.Fprintln(c.stdout, "yamlsort version "+c.version)
+		return nil
+	}
+	if c.blnRemote {
+		err := c.checkRemoteOptions()
+		if err != nil {
+			return err
+		}
+	}
+	if c.blnFramed {
+		if len(args) > 0 {
+			return fmt.Errorf("--framed reads files from stdin , file arguments can not be used")
+		}
+		return c.runFramed()
+	}
+	if len(args) > 0 {
+		return c.runFiles(args)
+	}
+	if c.blnWrite {
+		return fmt.Errorf("-w requires file or directory arguments")
+	}
+	if c.blnCheck {
+		return fmt.Errorf("--check requires file or directory arguments")
+	}
+	if len(c.outputtemplate) > 0 {
+		return fmt.Errorf("--output-template requires file or directory arguments")
+	}
+	if c.blnDryRun && len(c.inputoutputfilename) == 0 {
+		return fmt.Errorf("--dry-run requires -w or -f")
+	}
+	err := c.checkOutputMode()
+	if err != nil {
+		return err
+	}
+
+	// override inputoutputfilename
+	if len(c.inputoutputfilename) > 0 {
+		if len(c.inputfilename) == 0 {
+			c.inputfilename = c.inputoutputfilename
+		}
+		if len(c.outputfilename) == 0 {
+			c.outputfilename = c.inputoutputfilename
+		}
+	}
+
+	myReadBytes := []byte{}
+
+	err = c.prepareOptions()
+	if err != nil {
+		return err
+	}
+
+	// check input-file option
+	var snapshot fileSnapshot
+	if len(c.inputfilename) > 0 {
+		// read from file
+		snapshot = snapshotFile(c.inputfilename)
+		if isObjectURI(c.inputfilename) {
+			myReadBytes, err = readObject(c.inputfilename)
+		} else {
+			myReadBytes, err = ioutil.ReadFile(c.inputfilename)
+		}
+		if err != nil {
+			return err
+		}
+	} else if c.blnClipboardIn {
+		myReadBytes, err = readClipboard()
+		if err != nil {
+			return err
+		}
+	} else {
+		// read from stdin
+		myReadBuffer := new(bytes.Buffer)
+		_, err := io.Copy(myReadBuffer, c.stdin)
+		if err != nil {
+			return err
+		}
+		myReadBytes = myReadBuffer.Bytes()
+	}
+
+	// input of other formats (like toml) is decoded
+	sortInput, decoded, err := c.decodeInput(c.inputfilename, myReadBytes)
+	if err != nil {
+		return err
+	}
+	if len(decoded) > 0 && len(c.outputfilename) > 0 && c.outputfilename == c.inputfilename {
+		return fmt.Errorf("-f can not write yaml into %s input %s , use -i and -o", decoded, c.inputfilename)
+	}
+
+	// progress lines for large input
+	progress := newProgressReporter(c.stderr, !c.blnNoProgress, int64(len(sortInput)))
+
+	outputBuffer, err := c.sortBytes(sortInput, progress)
+	progress.finish()
+	if err != nil {
+		return err
+	}
+	outputBuffer, err = c.formatOutput(c.inputfilename, outputBuffer)
+	if err != nil {
+		return err
+	}
+
+	// -f --dry-run shows diff , and writes nothing
+	if c.blnDryRun {
+		_, err = fmt.Fprint(c.stdout, unifiedDiff(filepath.ToSlash(c.inputfilename), string(myReadBytes), outputBuffer.String()))
+		return err
+	}
+
+	// at last, write outputBuffer into file , clipboard or stdout.
+	if len(c.outputfilename) > 0 || c.blnClipboardOut {
+		if c.blnClipboardOut {
+			err = copyToClipboard(outputBuffer.String())
+		} else if c.outputfilename == c.inputfilename {
+			// -f writes in place
+			err = c.writeInPlace(c.outputfilename, myReadBytes, outputBuffer.Bytes(), snapshot)
+		} else if isObjectURI(c.outputfilename) {
+			err = writeObject(c.outputfilename, outputBuffer.Bytes())
+		} else {
+			err = c.writeOutputFile(c.outputfilename, outputBuffer.Bytes())
+		}
+		// --tee writes output to stdout too
+		if err != nil || !c.blnTee {
+			return err
+		}
+	}
+	// do output. error of stdout (like closed pipe , disk full) is returned
+	if c.blnTee {
+		_, err = c.stdout.Write(outputBuffer.Bytes())
+		return err
+	}
+	return c.writeStdout(outputBuffer.Bytes())
+}
+
+// merge presets and profiles into options , and check them.
+func (c *yamlsortCmd) prepareOptions() error {
+	// merge preset plugin options
+	err := c.applyPresets()
+	if err != nil {
+		return err
+	}
+
+	// options of lint profile
+	err = c.applyLintProfile()
+	if err != nil {
+		return err
+	}
+
+	// start comparator plugin
+	err = c.prepareComparator()
+	if err != nil {
+		return err
+	}
+
+	// check options
+	err = c.prepareCanonicalVersion()
+	if err != nil {
+		return err
+	}
+	err = checkFloatFormat(c.floatformat)
+	if err != nil {
+		return err
+	}
+	c.filemode, err = parseFileMode(c.chmod)
+	if err != nil {
+		return err
+	}
+	err = checkOutputFormat(c.outputformat)
+	if err != nil {
+		return err
+	}
+	err = checkInputFormat(c.inputformat)
+	if err != nil {
+		return err
+	}
+	if c.inputformat == "json" {
+		c.blnInputJSON = true
+	}
+	err = checkXMLAttributes(c.xmlattributes)
+	if err != nil {
+		return err
+	}
+	err = checkNamespace(c.namespace)
+	if err != nil {
+		return err
+	}
+	err = c.prepareScalarNorms()
+	if err != nil {
+		return err
+	}
+	err = c.prepareProtoMessage()
+	if err != nil {
+		return err
+	}
+	err = checkEmbeddedJSON(c.embeddedjson)
+	if err != nil {
+		return err
+	}
+	err = checkConfigMapData(c.configmapdata)
+	if err != nil {
+		return err
+	}
+	err = checkEmbeddedGlobs(c.embeddedglobs)
+	if err != nil {
+		return err
+	}
+	err = checkKeyCase(c.keycase)
+	if err != nil {
+		return err
+	}
+	err = checkExpandTabs(c.expandtabs)
+	if err != nil {
+		return err
+	}
+	c.prunecategories, err = parsePruneCategories(c.pruneempty)
+	if err != nil {
+		return err
+	}
+	c.dropcommentregexp, err = compileDropComment(c.dropcomment)
+	if err != nil {
+		return err
+	}
+	err = checkBlankLines(c.blanklines)
+	if err != nil {
+		return err
+	}
+	err = checkLineRange(c.startline, c.endline)
+	if err != nil {
+		return err
+	}
+	err = c.checkMinimal()
+	if err != nil {
+		return err
+	}
+	err = c.prepareChecksum()
+	if err != nil {
+		return err
+	}
+	err = c.prepareRules()
+	if err != nil {
+		return err
+	}
+	err = c.preparePolicy()
+	if err != nil {
+		return err
+	}
+	if len(c.unselected) == 0 {
+		c.unselected = unselectedSkip
+	}
+	err = c.prepareSelect()
+	if err != nil {
+		return err
+	}
+	if len(c.filter) > 0 {
+		c.filtersteps, err = parseJSONPath(c.filter)
+		if err != nil {
+			return err
+		}
+	}
+
+	// check prior keys
+	if len(c.priorkeys) == 0 {
+		c.priorkeys = []string{"name"}
+	}
+
+	// set global variable priorkeys
+	globalpriorkeys = c.priorkeys
+
+	return nil
+}
+
+// sort all documents of yaml text. progress can be nil , and caller finishes it.
+func (c *yamlsortCmd) sortBytes(input []byte, progress *progressReporter) (output *bytes.Buffer, err error) {
+	// panic in parser is error of this input (lsp , framed and file arguments continue)
+	defer func() {
+		if r := recover(); r != nil {
+			c.ancestors = nil
+			output, err = nil, recoveredError(r)
+		}
+	}()
+	// --git-changed sorts only documents changed in working tree
+	if c.blnGitChanged {
+		return c.sortGitChanged(input, progress)
+	}
+	// --minimal reorders lines only
+	if c.blnMinimal {
+		return c.sortMinimal(input)
+	}
+	// --start-line , --end-line sort only documents in range
+	if c.startline > 0 || c.endline > 0 {
+		return c.sortLineRange(input, progress)
+	}
+	return c.sortDocuments(input, progress)
+}
+
+// sort all documents of input
+func (c *yamlsortCmd) sortDocuments(input []byte, progress *progressReporter) (*bytes.Buffer, error) {
+	// create output buffer
+	outputBuffer := new(bytes.Buffer)
+	if c.blnStrictTypes {
+		c.typechecker = newTypeChecker()
+	}
+	c.refchecker = nil
+	if c.blnCheckRefs {
+		c.refchecker = newRefChecker()
+	}
+	// documents are evaluated by opa at once
+	policyinputs := []policyInput{}
+
+	// split documents, and marshal one by one
+	err := c.processDocuments(bytes.NewReader(input), func(doc *Document) error {
+		progress.addDocument(len(doc.raw.data))
+		if !c.isSelected(doc) {
+			if c.unselected == unselectedKeep {
+				return writeRawDocument(outputBuffer, doc)
+			}
+			return nil
+		}
+		keep, err := c.applyTransforms(doc)
+		if err != nil || !keep {
+			return err
+		}
+		if c.typechecker != nil {
+			err = c.typechecker.check(doc)
+			if err != nil {
+				return err
+			}
+		}
+		if c.refchecker != nil {
+			c.refchecker.add(doc)
+		}
+		if len(c.policyrules) > 0 {
+			c.evaluateRules(doc)
+		}
+		if len(c.policydirs) > 0 {
+			input, err := newPolicyInput(doc)
+			if err != nil {
+				return err
+			}
+			policyinputs = append(policyinputs, input)
+		}
+		return c.writeDocument(outputBuffer, doc)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if c.refchecker != nil {
+		c.reportRefs(c.refchecker)
+	}
+	if len(policyinputs) > 0 {
+		err = c.evaluatePolicy(policyinputs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// no blank line at end of file
+	if c.blnNoTrailingBlank {
+		for bytes.HasSuffix(outputBuffer.Bytes(), []byte("\n\n")) {
+			outputBuffer.Truncate(outputBuffer.Len() - 1)
+		}
+	}
+	return outputBuffer, nil
+}
+
+//-------------------------------------------------------------------------------------
+//  streaming document API
+//
+
+// Document is one parsed document of multi-document input.
+type Document struct {
+	Index   int         // index of document in input , 0 origin
+	Comment string      // first line comment , like "# sample.yaml  "
+	Data    interface{} // parsed data. fn of ProcessDocuments can replace it.
+	raw     yamlDocument
+	numbers map[string]numberText // text of numbers in input (--float-format=preserve)
+}
+
+// read documents from r , and call fn for each document (see Options.ProcessDocuments)
+func (c *yamlsortCmd) processDocuments(r io.Reader, fn func(doc *Document) error) error {
+	docscanner := newDocumentScanner(r, c.maxlinesize)
+	for i := 0; docscanner.Scan(); i++ {
+		rawdoc := docscanner.Document()
+		firstlinestr := rawdoc.firstline
+		// part of file (--start-line , --git-changed) has no filename comment
+		if i == 0 && len(firstlinestr) == 0 && len(c.inputfilename) > 0 && !c.blnPartialInput {
+			firstlinestr = "# " + c.inputfilename + "  "
+		}
+		data, err := c.parseDocument(rawdoc)
+		if err != nil {
+			return err
+		}
+		doc := &Document{Index: i, Comment: firstlinestr, Data: data, raw: rawdoc}
+		if c.floatformat == floatFormatPreserve && data != nil {
+			doc.numbers = c.numberTexts(rawdoc.parseData(), data)
+		}
+		err = fn(doc)
+		if err != nil {
+			return err
+		}
+	}
+	if err := docscanner.Err(); err != nil {
+		fmt.Fprintln(c.stderr, "Read input error:", err)
+		return err
+	}
+	return nil
+}
+
+//-------------------------------------------------------------------------------------
+//  unmarshal one document , and override.
+//
+func (c *yamlsortCmd) parseDocument(doc yamlDocument) (interface{}, error) {
+	var data interface{}
+
+	// document has only comments or white spaces.
+	if doc.isEmpty() && len(c.overridefilename) == 0 {
+		return nil, nil
+	}
+
+	for _, s := range doc.directivesByName("%TAG") {
+		fmt.Fprintln(c.stderr, "Warning: directive is not kept in output:", s)
+	}
+	for _, s := range doc.directivesByName("") {
+		fmt.Fprintln(c.stderr, "Warning: unknown directive is ignored:", s)
+	}
+
+	inputbytes := doc.parseData()
+	if c.blnInputJSON {
+		// parse json data
+		err := json.Unmarshal(inputbytes, &data)
+		if err != nil {
+			fmt.Fprintln(c.stderr, "Unmarshal JSON error:", err)
+			return data, err
+		}
+	} else {
+		// check anchors and aliases
+		refs := scanAnchorRefs(doc.data, doc.startline)
+		warnings, aliaserr := checkAnchorRefs(refs)
+		for _, s := range warnings {
+			fmt.Fprintln(c.stderr, "Warning:", s)
+		}
+		// nested aliases expand exponentially (billion laughs)
+		if limit := c.maxAliasExpansion(); aliasExpansion(refs, limit) > limit {
+			err := fmt.Errorf("aliases of document at line %d expand to more than %d nodes (--max-alias-expansion)", doc.startline, limit)
+			fmt.Fprintln(c.stderr, "Unmarshal YAML error:", err)
+			return data, err
+		}
+		// parse yaml data
+		var err error
+		data, err = c.unmarshalYAML(inputbytes)
+		if err != nil && aliaserr != nil {
+			err = aliaserr
+		}
+		if err != nil {
+			fmt.Fprintln(c.stderr, "Unmarshal YAML error:", err)
+			return data, err
+		}
+	}
+
+	// override
+	if len(c.overridefilename) > 0 {
+		dataOverride, err := c.myLoadFromFile(c.overridefilename)
+		if err != nil {
+			return data, err
+		}
+		result, err2 := c.myOverride(data, dataOverride)
+		if err2 != nil {
+			return data, err2
+		}
+		data = result
+	}
+	return data, nil
+}
+
+//-------------------------------------------------------------------------------------
+//  writeDocument sorts and marshals one document into outputWriter.
+//
+func (c *yamlsortCmd) writeDocument(outputWriter io.Writer, doc *Document) error {
+	data := doc.Data
+	firstlinestr := doc.Comment
+
+	// document has only comments or white spaces. pass through comments.
+	if data == nil && doc.raw.isEmpty() && len(c.overridefilename) == 0 {
+		comments := []string{}
+		for _, s := range doc.raw.commentLines() {
+			if s, keep := c.normalizeComment(s); keep {
+				comments = append(comments, s)
+			}
+		}
+		if len(comments) > 0 {
+			fmt.Fprintln(outputWriter, "---")
+			for _, s := range comments {
+				fmt.Fprintln(outputWriter, s)
+			}
+			if !c.blnNoDocumentBlank {
+				fmt.Fprintln(outputWriter)
+			}
+		}
+		return nil
+	}
+
+	// %YAML directive is kept in yaml output. other directives are not.
+	yamldirectives := doc.raw.directivesByName("%YAML")
+
+	// if firstline contains '# powered by ' , remove it.
+	idx := strings.Index(firstlinestr, "# powered by ")
+	if idx >= 0 {
+		firstlinestr = string([]rune(firstlinestr)[:idx])
+	}
+	firstlinestr = c.normalizeHeaderComment(firstlinestr)
+	if c.blnNormalMarshal || c.blnJSONMarshal {
+		// json has no .inf and .nan
+		err := checkJSONFloats(data)
+		if err != nil {
+			fmt.Fprintln(c.stderr, "Marshal error:", err)
+			return err
+		}
+	}
+	if c.blnNormalMarshal {
+		// write yaml data with normal marshal (github.com/ghodss/yaml)
+		outputBytes, err := yaml.Marshal(data)
+		if err != nil {
+			fmt.Fprintln(c.stderr, "Marshal error:", err)
+			return err
+		}
+		writeDirectives(outputWriter, yamldirectives)
+		fmt.Fprintln(outputWriter, "---")
+		c.writeHeaderLine(outputWriter, firstlinestr, "# powered by github.com/ghodss/yaml/Marshal")
+		c.writeSourceComment(outputWriter, doc)
+		fmt.Fprintln(outputWriter, string(outputBytes))
+	} else if c.blnJSONMarshal {
+		// write json data with normal marshal
+		outputBytes, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			fmt.Fprintln(c.stderr, "Marshal error:", err)
+			return err
+		}
+		fmt.Fprintln(outputWriter, "---")
+		c.writeHeaderLine(outputWriter, firstlinestr, "# powered by json.MarshalIndent output")
+		c.writeSourceComment(outputWriter, doc)
+		fmt.Fprintln(outputWriter, string(outputBytes))
+
+	} else {
+		// write yamlsort my marshal
+		if c.blanklines == blankLinesKeep {
+			c.blanklinepaths = blankLinePaths(doc.raw.data)
+		}
+		outputBuffer2 := getBuffer()
+		defer putBuffer(outputBuffer2)
+		c.numbertexts = doc.numbers
+		var err error
+		if c.dedupeanchors > 0 {
+			err = c.myMarshalDedupe(outputBuffer2, data)
+		} else {
+			err = c.myMershalRecursive(outputBuffer2, 0, "", false, data)
+		}
+		if err != nil {
+			fmt.Fprintln(c.stderr, "myMarshal error:", err)
+			return err
+		}
+		writeDirectives(outputWriter, yamldirectives)
+		fmt.Fprintln(outputWriter, "---")
+		c.writeHeaderLine(outputWriter, firstlinestr, "# powered by myMarshal output")
+		c.writeSourceComment(outputWriter, doc)
+		outputWriter.Write(outputBuffer2.Bytes())
+		if !c.blnNoDocumentBlank {
+			fmt.Fprintln(outputWriter)
+		}
+	}
+
+	return nil
+}
+
+// write first line comment and "# powered by ..." comment after "---"
+func (c *yamlsortCmd) writeHeaderLine(outputWriter io.Writer, firstlinestr string, powered string) {
+	if c.blnNoHeader {
+		return
+	}
+	fmt.Fprintln(outputWriter, c.headerLine(firstlinestr, powered))
+}
+
+// write directive lines before "---"
+func writeDirectives(outputWriter io.Writer, directives []string) {
+	for _, s := range directives {
+		fmt.Fprintln(outputWriter, s)
+	}
+}
+
+//-----------------------------------------------------------------------------------
+// my marshal (data to string with sorting map key)
+//
+func (c *yamlsortCmd) myMarshal(data interface{}) ([]byte, error) {
+	// create buffer
+	writer := new(bytes.Buffer)
+	err := c.myMershalRecursive(writer, 0, "", false, data)
+	return writer.Bytes(), err
+}
+
+// return socre of priority key name  , like "name"
+func priorIndex(priorkeys []string, s string) int {
+	for i, v := range priorkeys {
+		if s == v {
+			return i
+		}
+	}
+	return 999999
+}
+
+// convert string to int slice, number is convert to one int.
+func convertStringToUint64Slice(s string) ([]uint64, error) {
+	result := []uint64{}
+	digitBuf := []rune{}
+
+	for _, r := range s {
+		if unicode.IsDigit(r) {
+			digitBuf = append(digitBuf, r)
+		} else {
+			if len(digitBuf) > 0 {
+				i, err := strconv.ParseInt(string(digitBuf), 10, 64)
+				if err != nil {
+					return result, err
+				}
+				result = append(result, uint64(i))
+				digitBuf = []rune{}
+			}
+			// string character (rune) is may be 32bit value (unicode 16)
+			result = append(result, uint64(r)+0x1000000000000000)
+		}
+	}
+	if len(digitBuf) > 0 {
+		i, err := strconv.ParseInt(string(digitBuf), 10, 64)
+		if err != nil {
+			return result, err
+		}
+		result = append(result, uint64(i))
+		digitBuf = []rune{}
+	}
+	return result, nil
+}
+
+// compair string1 string2 , consider prior key name , and string-number-string key
+func compairString(s1 string, s2 string) bool {
+	// priority key name check
+	score1 := priorIndex(globalpriorkeys, s1)
+	score2 := priorIndex(globalpriorkeys, s2)
+	if score1 != score2 {
+		return score1 < score2
+	}
+
+	uint64slice1, err1 := convertStringToUint64Slice(s1)
+	uint64slice2, err2 := convertStringToUint64Slice(s2)
+	if err1 != nil || err2 != nil {
+		return s1 < s2
+	}
+
+	// string compair with string-number-string
+	return compairUint64Slice(uint64slice1, uint64slice2)
+}
+
+// sort key in compairString order.
+// compair keys are computed once per key, not per comparison.
+func sortKeys(keylist []string) {
+	type sortKey struct {
+		key    string
+		score  int
+		slice  []uint64
+		errflg bool
+	}
+	sortkeys := make([]sortKey, len(keylist))
+	for i, k := range keylist {
+		uint64slice, err := convertStringToUint64Slice(k)
+		sortkeys[i] = sortKey{key: k, score: priorIndex(globalpriorkeys, k), slice: uint64slice, errflg: err != nil}
+	}
+	sort.Slice(sortkeys, func(idx1, idx2 int) bool {
+		k1 := sortkeys[idx1]
+		k2 := sortkeys[idx2]
+		if k1.score != k2.score {
+			return k1.score < k2.score
+		}
+		if k1.errflg || k2.errflg {
+			return k1.key < k2.key
+		}
+		return compairUint64Slice(k1.slice, k2.slice)
+	})
+	for i := range sortkeys {
+		keylist[i] = sortkeys[i].key
+	}
+}
+
+// compair string-number-string converted slice
+func compairUint64Slice(uint64slice1 []uint64, uint64slice2 []uint64) bool {
+	len1 := len(uint64slice1)
+	len2 := len(uint64slice2)
+	for i := 0; i < len1 && i < len2; i++ {
+		if uint64slice1[i] != uint64slice2[i] {
+			return uint64slice1[i] < uint64slice2[i]
+		}
+	}
+	return len1 < len2
+}
+
+func (c *yamlsortCmd) escapeString(value string) string {
+	blnDoQuote := false
+	blnDoDoubleQuote := false
+
+	// if always quote flag, then quote.
+	if c.blnQuoteString {
+		blnDoQuote = true
+	}
+
+	// if string like boolean , then quote.
+	rules := c.emissionRules()
+	for _, s := range rules.quoteWords {
+		if value == s {
+			blnDoQuote = true
+		}
+	}
+	if c.blnQuoteTruthy && truthyWords[value] {
+		blnDoQuote = true
+	}
+
+	// if string starts with 0-9 , . , then quote.
+	for _, s := range rules.quotePrefixes {
+		if strings.HasPrefix(value, s) {
+			blnDoQuote = true
+		}
+	}
+
+	// if string contains " or ' , then quote.
+	if strings.Contains(value, "\"") || strings.Contains(value, "'") {
+		blnDoQuote = true
+	}
+
+	// if string contains \r \n \t , then quote.
+	if strings.Contains(value, "\r") || strings.Contains(value, "\n") || strings.Contains(value, "\t") {
+		blnDoQuote = true
+		blnDoDoubleQuote = true
+	}
+
+	// if string contains { or } , then quote.
+	if strings.Contains(value, "{") || strings.Contains(value, "}") {
+		blnDoQuote = true
+	}
+
+	// if string starts space , then quote.
+	if strings.HasPrefix(value, " ") || strings.HasSuffix(value, " ") {
+		blnDoQuote = true
+	}
+
+	// if string starts tab , then quote.
+	if strings.HasPrefix(value, "\t") || strings.HasSuffix(value, "\t") {
+		blnDoQuote = true
+		blnDoDoubleQuote = true
+	}
+
+	// if string length == 0 ,  then quote
+	if len(value) == 0 {
+		blnDoQuote = true
+	}
+	if !blnDoQuote {
+		return value
+	}
+
+	// prefer " , unless the string has more " than '
+	if c.blnDoubleQuote && strings.Count(value, "\"") <= strings.Count(value, "'") {
+		blnDoDoubleQuote = true
+	}
+
+	if blnDoDoubleQuote {
+		// quote "
+		return "\"" + escapeDoubleQuoted(value) + "\""
+	} else {
+		// quote '
+		// quote ' .  in quote ' ,  ' is ''
+		result := "'" + strings.Replace(value, "'", "''", -1) + "'"
+		return result
+	}
+}
+
+func (c *yamlsortCmd) calcPathMap(path string, key string) string {
+	if len(path) == 0 {
+		return key
+	} else {
+		return path + "." + key
+	}
+}
+
+func (c *yamlsortCmd) calcPathSlice(path string, index int) string {
+	if len(path) == 0 {
+		return "[" + strconv.Itoa(index) + "]"
+	} else {
+		return path + "[" + strconv.Itoa(index) + "]"
+	}
+}
+
+func (c *yamlsortCmd) calcPathSliceMap(path string, key string, value string) string {
+	if len(path) == 0 {
+		return "[" + key + "=" + value + "]"
+	} else {
+		return path + "[" + key + "=" + value + "]"
+	}
+}
+
+// path of slice element. [index] , or [name=value] for map which has name.
+func (c *yamlsortCmd) calcPathSliceElement(path string, index int, v interface{}) string {
+	if tmpmap, ok2 := v.(map[string]interface{}); ok2 {
+		if tmpname, ok3 := tmpmap["name"]; ok3 {
+			if tmpnamestr, ok4 := tmpname.(string); ok4 {
+				// sliceの中は name要素を持つmapの場合、特別なpath [name=value]を生成
+				return c.calcPathSliceMap(path, "name", tmpnamestr)
+			}
+		}
+	}
+	return c.calcPathSlice(path, index)
+}
+
+func (c *yamlsortCmd) checkSkipKey(path string) bool {
+	for _, s := range c.skipkeys {
+		if len(s) > 0 {
+			if s == path {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c *yamlsortCmd) myMershalRecursive(writer *bytes.Buffer, level int, path string, blnParentSlide bool, data interface{}) error {
+	if data == nil {
+		writer.WriteString("null\n")
+		return nil
+	}
+	if id := nodeIdentity(data); id != 0 {
+		err := c.enterNode(path, id)
+		if err != nil {
+			return err
+		}
+		defer c.leaveNode()
+	}
+	if m, ok := data.(map[string]interface{}); ok {
+		// data is map
+
+		// if map has no key , then output {}
+		if len(m) == 0 {
+			if !c.blnFlowEmptyMap {
+				writer.WriteString(c.indentstr(level))
+			}
+			writer.WriteString("{}\n")
+			return nil
+		}
+
+		// get key list
+		keylist := make([]string, 0, len(m))
+		for k := range m {
+			keylist = append(keylist, k)
+		}
+
+		// sort map key, but key priorkeys is first
+		sortKeys(keylist)
+		if c.comparator != nil {
+			err := c.comparator.order(path, keylist)
+			if err != nil {
+				return err
+			}
+		}
+		c.orderKeys(path, keylist)
+
+		// recursive call
+		written := 0
+		for i, k := range keylist {
+			v := m[k]
+			indentstr := c.indentstr(level)
+			// when parent element is slice and print first key value, no need to indent
+			if blnParentSlide && i == 0 {
+				indentstr = ""
+			}
+			childpath := c.calcPathMap(path, k)
+			// check skip key
+			if c.checkSkipKey(childpath) == true {
+				continue
+			}
+			if written > 0 && c.blankLineBefore(path, childpath) {
+				writer.WriteString("\n")
+			}
+			written++
+			if c.writeKeyComments(writer, childpath, level, blnParentSlide && i == 0) {
+				indentstr = c.indentstr(level)
+			}
+			writer.WriteString(indentstr)
+			writer.WriteString(c.escapeKey(k))
+			if alias, anchor := c.dedupeNode(childpath, v); len(alias) > 0 {
+				// same subtree is written before
+				writer.WriteString(": *" + alias + "\n")
+				continue
+			} else if len(anchor) > 0 {
+				writer.WriteString(": &" + anchor + "\n")
+			} else if s, ok := v.(string); ok {
+				// long string is written in next line
+				writer.WriteString(":")
+				if c.writeBlockString(writer, level+2, s) {
+					continue
+				}
+				if c.writeLongString(writer, level+len(c.escapeKey(k))+2, level+2, s) {
+					continue
+				}
+				writer.WriteString(" ")
+			} else if a, ok := v.([]interface{}); ok && len(a) == 0 && c.blnFlowEmptyList {
+				// child is empty slice. print [].
+				writer.WriteString(": ")
+			} else if cm, ok := v.(map[string]interface{}); ok && len(cm) == 0 && c.blnFlowEmptyMap {
+				// child is empty map. print {}.
+				writer.WriteString(": ")
+			} else if v == nil {
+				// child is nil. print key only.
+				writer.WriteString(": ")
+			} else if _, ok := v.(map[string]interface{}); ok {
+				// child is map
+				writer.WriteString(":\n")
+			} else if _, ok := v.([]interface{}); ok {
+				// child is slice
+				writer.WriteString(":\n")
+			} else {
+				// child is normal string
+				writer.WriteString(": ")
+			}
+			err := c.myMershalRecursive(writer, level+2, childpath, false, v)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	} else if a, ok := data.([]interface{}); ok {
+		// data is slice
+		if len(a) == 0 && c.blnFlowEmptyList {
+			writer.WriteString("[]\n")
+			return nil
+		}
+		levelOffset := 0
+		if c.blnArrayIndentPlus2 {
+			levelOffset = 2
+		}
+		if level == 0 {
+			// top level slice. "-" is written at column 0 , and map keys at column 2.
+			level = 2 - levelOffset
+		}
+		for i, v := range a {
+			childpath := c.calcPathSliceElement(path, i, v)
+			// check skip key
+			if c.checkSkipKey(childpath) == true {
+				continue
+			}
+			// when parent element is slice and print first element, no need to indent
+			if !blnParentSlide || i > 0 {
+				writer.WriteString(c.indentstr(level - 2 + levelOffset))
+			}
+			writer.WriteString("-")
+			if alias, anchor := c.dedupeNode(childpath, v); len(alias) > 0 {
+				// same subtree is written before
+				writer.WriteString(" *" + alias + "\n")
+				continue
+			} else if len(anchor) > 0 {
+				// anchor is written after "-" , and child starts at next line
+				writer.WriteString(" &" + anchor + "\n")
+				childlevel := level + levelOffset
+				if _, ok := v.([]interface{}); ok {
+					childlevel = level + 2
+				}
+				err := c.myMershalRecursive(writer, childlevel, childpath, false, v)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			if s, ok := v.(string); ok {
+				if c.writeBlockString(writer, level+levelOffset, s) {
+					continue
+				}
+				// long string is written in next line
+				if c.writeLongString(writer, level+levelOffset, level+levelOffset, s) {
+					continue
+				}
+			}
+			writer.WriteString(" ")
+			childlevel := level + levelOffset
+			if cl, ok := v.([]interface{}); ok && len(cl) > 0 {
+				// "-" of child slice is written after "- "
+				childlevel = level + 2
+			}
+			err := c.myMershalRecursive(writer, childlevel, childpath, true, v)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	} else if s, ok := data.(stringMacro); ok {
+		// data is stringMacro
+		writer.WriteString(s.getString())
+	} else if s, ok := data.(string); ok {
+		// data is string
+		writer.WriteString(c.escapeString(s))
+	} else if i, ok := data.(int); ok {
+		// data is int
+		writer.WriteString(strconv.Itoa(i))
+	} else if f64, ok := data.(float64); ok {
+		// data is float64 ( same as fmt %v )
+		writer.WriteString(c.formatFloat(path, f64))
+	} else if b, ok := data.(bool); ok {
+		// data is bool
+		writer.WriteString(strconv.FormatBool(b))
+	} else {
+		return fmt.Errorf("unknown type:%v  data:%v", reflect.TypeOf(data), data)
+	}
+	writer.WriteByte('\n')
+	return nil
+}
+
+// spaces for indent. indentstr returns sub string of this.
+var indentspaces = strings.Repeat(" ", 256)
+
+func (c *yamlsortCmd) indentstr(level int) string {
+	if level <= 0 {
+		return ""
+	}
+	for level > len(indentspaces) {
+		indentspaces = indentspaces + indentspaces
+	}
+	return indentspaces[:level]
+}
+
+//-------------------------------------------------------------------------
+// my Override
+//
+
+func (c *yamlsortCmd) myOverride(data interface{}, dataOverride interface{}) (interface{}, error) {
+	result, err := c.myOverrideRecursive(data, dataOverride)
+	return result, err
+}
+
+func (c *yamlsortCmd) myOverrideRecursive(data interface{}, dataOverride interface{}) (interface{}, error) {
+	if dataOverride == nil {
+		return data, nil
+	}
+	if data == nil {
+		data = dataOverride
+		return data, nil
+	}
+
+	{
+		// map check
+		mdest, ok1 := data.(map[string]interface{})
+		m, ok2 := dataOverride.(map[string]interface{})
+		if ok1 && ok2 {
+			// dataOverride is map
+			// get key list
+			var keylist []string
+			for k := range m {
+				keylist = append(keylist, k)
+			}
+			// sort map key, but key priorkeys is first
+			sortKeys(keylist)
+			// recursive call
+			for _, k := range keylist {
+				vdest := mdest[k]
+				v := m[k]
+				// vdest is nil, then copy and continue
+				if vdest == nil {
+					mdest[k] = v
+					continue
+				}
+				// when parent element is slice and print first key value, no need to indent
+				if v == nil {
+					// value is nil. key only.
+					mdest[k] = v
+					continue
+				} else if _, ok := v.(map[string]interface{}); ok {
+					// value is map
+				} else if _, ok := v.([]interface{}); ok {
+					// value is slice
+					//if adest, ok2 := vdest.([]interface{}); ok2 {
+					//	// dest is slice, so append slice
+					//	adest = append(adest, a...)
+					//	// override map
+					//	mdest[k] = adest
+					//}
+				} else {
+					// value is normal string/float64/int
+					mdest[k] = v
+					continue
+				}
+				result, err := c.myOverrideRecursive(vdest, v)
+				if err != nil {
+					return data, err
+				}
+				mdest[k] = result
+			}
+			return data, nil
+		}
+	}
+	{
+		// slice check ( slice - map type )
+		adest, ok1 := data.([]interface{})
+		a, ok2 := dataOverride.([]interface{})
+		if ok1 && ok2 {
+			blnOverride := false
+
+			// check slice - map["name"] type
+			for _, elem := range a {
+				if m, ok3 := elem.(map[string]interface{}); ok3 {
+					// slice - map
+					name := m["name"]
+					for idest, destelem := range adest {
+						if mdest, ok4 := destelem.(map[string]interface{}); ok4 {
+							// slice - map
+							namedest := mdest["name"]
+							if _, ok5 := namedest.(string); ok5 {
+								if name == namedest {
+									result, err := c.myOverrideRecursive(mdest, m)
+									if err != nil {
+										return data, err
+									}
+									adest[idest] = result
+									blnOverride = true
+								}
+							}
+						}
+					}
+					if blnOverride == false {
+						// append
+						adest = append(adest, m)
+						blnOverride = true
+					}
+				} else if s, ok4 := elem.(string); ok4 {
+					// check []string
+					adest = append(adest, s)
+					blnOverride = true
+				} else if i, ok4 := elem.(int); ok4 {
+					// check []string
+					adest = append(adest, i)
+					blnOverride = true
+				} else if f, ok4 := elem.(float64); ok4 {
+					// check []string
+					adest = append(adest, f)
+					blnOverride = true
+				} else if b, ok4 := elem.(bool); ok4 {
+					// check []string
+					adest = append(adest, b)
+					blnOverride = true
+				}
+			}
+
+			if blnOverride == false {
+				fmt.Printf("unknown slice type:%v  data:%v", reflect.TypeOf(data), data)
+			}
+			return adest, nil
+		}
+	}
+	{
+		// slice check ( slice - string/int/float64/bool type )
+		adest, ok1 := data.([]string)
+		a, ok2 := dataOverride.([]string)
+		if ok1 && ok2 {
+			for _, k := range a {
+				adest = append(adest, k)
+				fmt.Println("append []string ", k)
+			}
+			return adest, nil
+		}
+	}
+	{
+		// slice check ( slice - string/int/float64/bool type )
+		adest, ok1 := data.([]int)
+		a, ok2 := dataOverride.([]int)
+		if ok1 && ok2 {
+			for _, k := range a {
+				adest = append(adest, k)
+			}
+			return adest, nil
+		}
+	}
+	{
+		// slice check ( slice - string/int/float64/bool type )
+		adest, ok1 := data.([]float64)
+		a, ok2 := dataOverride.([]float64)
+		if ok1 && ok2 {
+			for _, k := range a {
+				adest = append(adest, k)
+			}
+			return adest, nil
+		}
+	}
+	{
+		// slice check ( slice - string/int/float64/bool type )
+		adest, ok1 := data.([]bool)
+		a, ok2 := dataOverride.([]bool)
+		if ok1 && ok2 {
+			for _, k := range a {
+				adest = append(adest, k)
+			}
+			return adest, nil
+		}
+	}
+
+	return data, fmt.Errorf("unknown type:%v  data:%v", reflect.TypeOf(data), data)
+}
+
+//-------------------------------------------------------------------------
+// load yaml data from file
+//
+func (c *yamlsortCmd) myLoadFromFile(filename string) (interface{}, error) {
+	var data interface{}
+	// read from file
+	myReadBytes, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return data, err
+	}
+
+	if c.blnInputJSON {
+		// parse json data
+		err := json.Unmarshal(myReadBytes, &data)
+		if err != nil {
+			fmt.Fprintln(c.stderr, "Unmarshal JSON error:", err)
+			return data, err
+		}
+	} else {
+		// parse yaml data
+		data, err = c.unmarshalYAML(myReadBytes)
+		if err != nil {
+			fmt.Fprintln(c.stderr, "Unmarshal YAML error:", err)
+			return data, err
+		}
+	}
+	return data, nil
+}
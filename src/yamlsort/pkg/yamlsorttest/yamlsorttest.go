@@ -0,0 +1,159 @@
+//
+// yamlsort - golden file test helper
+//
+// package yamlsorttest pins exact output of yamlsort in golden file tests.
+//   func TestManifests(t *testing.T) {
+//       yamlsorttest.AssertGoldenFile(t, "deploy.yaml", "testdata/deploy.golden.yaml")
+//   }
+// golden file is written with YAMLSORT_UPDATE_GOLDEN=1 (go test ./... with the variable).
+// first line of golden file records yamlsort version , and failure message tells whether
+// output changed with semver breaking version (expected) or in compatible version (report it).
+// sort runs in process with package yamlsort/pkg/yamlsort. other modules fetch it with
+//   go get github.com/keita69/yamlsort/src/yamlsort/pkg/yamlsorttest
+//
+package yamlsorttest
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/keita69/yamlsort/src/yamlsort/pkg/yamlsort"
+)
+
+// environment variable to write golden files
+const UpdateEnv = "YAMLSORT_UPDATE_GOLDEN"
+
+// prefix of first line of golden file
+const versionLinePrefix = "# yamlsort-golden: version "
+
+// Sort returns sorted output of input. args are options of yamlsort , like "--key", "kind".
+func Sort(input []byte, args ...string) ([]byte, error) {
+	opts, err := yamlsort.NewOptions(args...)
+	if err != nil {
+		return nil, fmt.Errorf("yamlsort %s: %v", strings.Join(args, " "), err)
+	}
+	output, err := opts.Sort(input)
+	if err2 := opts.Close(); err == nil {
+		err = err2
+	}
+	return output, err
+}
+
+// Version returns version of yamlsort , like "v0.1.14". empty for development build.
+func Version() string {
+	return yamlsort.Version()
+}
+
+// AssertGolden sorts input , and compares output with golden file.
+func AssertGolden(t testing.TB, input []byte, goldenpath string, args ...string) {
+	t.Helper()
+	assertGoldenVersion(t, input, goldenpath, Version(), args...)
+}
+
+// AssertGolden with version of yamlsort , which is written to and compared with golden file
+func assertGoldenVersion(t testing.TB, input []byte, goldenpath string, version string, args ...string) {
+	t.Helper()
+	output, err := Sort(input, args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(os.Getenv(UpdateEnv)) > 0 {
+		err = writeGolden(goldenpath, version, output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	goldenversion, golden, err := readGolden(goldenpath)
+	if err != nil {
+		t.Fatalf("%v (run with %s=1 to write golden file)", err, UpdateEnv)
+	}
+	if bytes.Equal(golden, output) {
+		return
+	}
+	reason := "output of compatible version changed. please report it to yamlsort"
+	if !CompatibleVersion(goldenversion, version) {
+		reason = "yamlsort version is changed with breaking change. check output , and update golden file"
+	}
+	t.Errorf("%s: output differs from golden file (version %s , current %s). %s\n%s(run with %s=1 to update golden file)",
+		goldenpath, goldenversion, version, reason, diffText(golden, output), UpdateEnv)
+}
+
+// AssertGoldenFile sorts input file , and compares output with golden file.
+func AssertGoldenFile(t testing.TB, inputpath string, goldenpath string, args ...string) {
+	t.Helper()
+	input, err := ioutil.ReadFile(inputpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertGolden(t, input, goldenpath, args...)
+}
+
+// CompatibleVersion returns true when output of two versions must be same by semver.
+// major version is compared , or minor version for 0.x. unknown (development) version is compatible.
+func CompatibleVersion(version1 string, version2 string) bool {
+	key1, key2 := compatibleKey(version1), compatibleKey(version2)
+	return len(key1) == 0 || len(key2) == 0 || key1 == key2
+}
+
+// "1" of v1.2.3 , "0.2" of v0.2.3
+func compatibleKey(version string) string {
+	fields := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if len(fields) < 2 || len(fields[0]) == 0 {
+		return ""
+	}
+	if fields[0] == "0" {
+		return "0." + fields[1]
+	}
+	return fields[0]
+}
+
+// write golden file with version line
+func writeGolden(goldenpath string, version string, output []byte) error {
+	err := os.MkdirAll(filepath.Dir(goldenpath), 0755)
+	if err != nil {
+		return err
+	}
+	content := append([]byte(versionLinePrefix+version+"\n"), output...)
+	return ioutil.WriteFile(goldenpath, content, 0644)
+}
+
+// read version and output of golden file
+func readGolden(goldenpath string) (string, []byte, error) {
+	content, err := ioutil.ReadFile(goldenpath)
+	if err != nil {
+		return "", nil, err
+	}
+	if !bytes.HasPrefix(content, []byte(versionLinePrefix)) {
+		return "", content, nil
+	}
+	idx := bytes.IndexByte(content, '\n')
+	if idx < 0 {
+		return strings.TrimPrefix(string(content), versionLinePrefix), []byte{}, nil
+	}
+	return strings.TrimPrefix(string(content[:idx]), versionLinePrefix), content[idx+1:], nil
+}
+
+// first differing line of expected and actual
+func diffText(expected []byte, actual []byte) string {
+	lines1 := strings.Split(string(expected), "\n")
+	lines2 := strings.Split(string(actual), "\n")
+	for i := 0; i < len(lines1) || i < len(lines2); i++ {
+		line1, line2 := "", ""
+		if i < len(lines1) {
+			line1 = lines1[i]
+		}
+		if i < len(lines2) {
+			line2 = lines2[i]
+		}
+		if line1 != line2 || i >= len(lines1) || i >= len(lines2) {
+			return fmt.Sprintf("line %d\n  golden : %q\n  actual : %q\n", i+1, line1, line2)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,159 @@
+package yamlsorttest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// testing.TB which records failures of assertion
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatal(args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprint(args...))
+	runtime.Goexit()
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+	runtime.Goexit()
+}
+
+// failures of AssertGolden with version
+func assertGolden(t *testing.T, input string, goldenpath string, version string, args ...string) []string {
+	r := &recorder{TB: t}
+	done := make(chan bool)
+	go func() {
+		defer close(done)
+		if len(version) == 0 {
+			AssertGolden(r, []byte(input), goldenpath, args...)
+		} else {
+			assertGoldenVersion(r, []byte(input), goldenpath, version, args...)
+		}
+	}()
+	<-done
+	return r.failures
+}
+
+// set environment variable during test
+func setenv(t *testing.T, name string, value string) {
+	old, ok := os.LookupEnv(name)
+	os.Setenv(name, value)
+	t.Cleanup(func() {
+		if ok {
+			os.Setenv(name, old)
+		} else {
+			os.Unsetenv(name)
+		}
+	})
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "yamlsorttest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func TestSort(t *testing.T) {
+	output, err := Sort([]byte("kind: Service\nb: 1\na: 2\n"), "--key", "kind")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "---\n# powered by myMarshal output\nkind: Service\na: 2\nb: 1\n\n"
+	if string(output) != expected {
+		t.Errorf("output is %q , expected %q", output, expected)
+	}
+	_, err = Sort([]byte("a: 1\n"), "--unknown-option")
+	if err == nil {
+		t.Error("unknown option is not error")
+	}
+}
+
+func TestAssertGolden(t *testing.T) {
+	golden := filepath.Join(tempDir(t), "testdata", "sample.golden.yaml")
+	input := "b: 1\na: 2\n"
+
+	// golden file is required
+	setenv(t, UpdateEnv, "")
+	failures := assertGolden(t, input, golden, "")
+	if len(failures) != 1 || !strings.Contains(failures[0], UpdateEnv+"=1") {
+		t.Fatalf("missing golden file: failures %q", failures)
+	}
+
+	// update path writes golden file with version line
+	setenv(t, UpdateEnv, "1")
+	failures = assertGolden(t, input, golden, "v1.2.0")
+	if len(failures) > 0 {
+		t.Fatalf("update: failures %q", failures)
+	}
+	content, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := versionLinePrefix + "v1.2.0\n---\n# powered by myMarshal output\na: 2\nb: 1\n\n"
+	if string(content) != expected {
+		t.Fatalf("golden file is %q , expected %q", content, expected)
+	}
+
+	// same output
+	setenv(t, UpdateEnv, "")
+	failures = assertGolden(t, input, golden, "")
+	if len(failures) > 0 {
+		t.Fatalf("same output: failures %q", failures)
+	}
+
+	// changed output is reported with line and reason
+	failures = assertGolden(t, input, golden, "v1.3.0", "--key", "b")
+	if len(failures) != 1 {
+		t.Fatalf("changed output: failures %q", failures)
+	}
+	for _, text := range []string{"line 3", `golden : "a: 2"`, `actual : "b: 1"`, "compatible version"} {
+		if !strings.Contains(failures[0], text) {
+			t.Errorf("failure %q does not contain %q", failures[0], text)
+		}
+	}
+
+	// changed output of other major version
+	failures = assertGolden(t, input, golden, "v2.0.0", "--key", "b")
+	if len(failures) != 1 || !strings.Contains(failures[0], "breaking change") {
+		t.Errorf("breaking version: failures %q", failures)
+	}
+}
+
+func TestCompatibleVersion(t *testing.T) {
+	tests := []struct {
+		version1 string
+		version2 string
+		expected bool
+	}{
+		{"v1.2.3", "v1.4.0", true},
+		{"v1.2.3", "v2.0.0", false},
+		{"v0.1.14", "v0.1.20", true},
+		{"v0.1.14", "v0.2.0", false},
+		{"0.1.14", "v0.1.15-3-gabcdef", true},
+		{"", "v2.0.0", true},
+		{"v1.0.0", "(devel)", true},
+	}
+	for _, test := range tests {
+		result := CompatibleVersion(test.version1, test.version2)
+		if result != test.expected {
+			t.Errorf("CompatibleVersion(%q, %q) is %v , expected %v", test.version1, test.version2, result, test.expected)
+		}
+	}
+}
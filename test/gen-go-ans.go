@@ -0,0 +1,41 @@
+// Code generated by yamlsort gen-go from sample3.yaml. DO NOT EDIT.
+
+package manifest
+
+type Ingress struct {
+	APIVersion string   `json:"apiVersion" yaml:"apiVersion"`
+	Kind       string   `json:"kind" yaml:"kind"`
+	Metadata   Metadata `json:"metadata" yaml:"metadata"`
+	Spec       Spec     `json:"spec" yaml:"spec"`
+}
+
+type Metadata struct {
+	Name   string `json:"name" yaml:"name"`
+	Labels Labels `json:"labels" yaml:"labels"`
+}
+
+type Labels struct {
+	App string `json:"app" yaml:"app"`
+}
+
+type Spec struct {
+	Rules []Rule `json:"rules" yaml:"rules"`
+}
+
+type Rule struct {
+	Host string `json:"host" yaml:"host"`
+	HTTP HTTP   `json:"http" yaml:"http"`
+}
+
+type HTTP struct {
+	Paths []Path `json:"paths" yaml:"paths"`
+}
+
+type Path struct {
+	Backend Backend `json:"backend" yaml:"backend"`
+}
+
+type Backend struct {
+	ServiceName string `json:"serviceName" yaml:"serviceName"`
+	ServicePort int    `json:"servicePort" yaml:"servicePort"`
+}
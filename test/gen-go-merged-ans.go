@@ -0,0 +1,9 @@
+// Code generated by yamlsort gen-go from audit/api.yaml , audit/web.yaml. DO NOT EDIT.
+
+package config
+
+type Config struct {
+	Name  string      `json:"name" yaml:"name"`
+	Debug bool        `json:"debug,omitempty" yaml:"debug,omitempty"`
+	Port  interface{} `json:"port" yaml:"port"`
+}
@@ -0,0 +1,39 @@
+// Code generated by yamlsort gen-types from sample3.yaml. DO NOT EDIT.
+
+export interface Ingress {
+  apiVersion: string;
+  kind: string;
+  metadata: Metadata;
+  spec: Spec;
+}
+
+export interface Metadata {
+  name: string;
+  labels: Labels;
+}
+
+export interface Labels {
+  app: string;
+}
+
+export interface Spec {
+  rules: Rule[];
+}
+
+export interface Rule {
+  host: string;
+  http: HTTP;
+}
+
+export interface HTTP {
+  paths: Path[];
+}
+
+export interface Path {
+  backend: Backend;
+}
+
+export interface Backend {
+  serviceName: string;
+  servicePort: number;
+}
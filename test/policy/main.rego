@@ -0,0 +1,6 @@
+package main
+
+deny contains msg if {
+	input.spec.template.spec.containers[_].securityContext.privileged
+	msg := "container must not be privileged"
+}
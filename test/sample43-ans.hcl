@@ -0,0 +1,59 @@
+resource {
+  aws_instance {
+    db {
+      ami = "ami-87654321"
+    }
+
+    web {
+      ami           = "ami-12345678"
+      count         = 2
+      empty         = null
+      enabled       = "${var.enabled ? 1 : 0}"
+      instance_type = "${var.instance_type}"
+      monitoring    = true
+      subnets       = "${[for s in var.subnets : s.id]}"
+      tags = {
+        Name                 = "web-${count.index}"
+        "kubernetes.io/role" = "node"
+      }
+      user_data = <<-EOT
+        #!/bin/bash
+        echo "hello"
+      EOT
+      zones = ["a", "b", "d"]
+
+      ebs_block_device {
+        device_name = "/dev/sdb"
+        volume_size = 100
+      }
+
+      ebs_block_device {
+        device_name = "/dev/sdc"
+        volume_size = 50.5
+      }
+
+      nested {
+        a = [1, 2]
+        b = "x"
+      }
+    }
+  }
+}
+
+terraform {
+  required_version = "~> 1.3"
+
+  required_providers {
+    aws {
+      source  = "hashicorp/aws"
+      version = "~> 5.0"
+    }
+  }
+}
+
+variable {
+  region {
+    default = "ap-northeast-1"
+    type    = "${string}"
+  }
+}
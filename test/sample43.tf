@@ -0,0 +1,49 @@
+# hcl input and --output-format hcl
+terraform {
+  required_version = "~> 1.3"
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 5.0"
+    }
+  }
+}
+
+variable "region" {
+  type    = string
+  default = "ap-northeast-1"
+}
+
+resource "aws_instance" "web" {
+  ami           = "ami-12345678"
+  instance_type = var.instance_type
+  count         = 2
+  monitoring    = true
+  tags = {
+    Name              = "web-${count.index}"
+    "kubernetes.io/role" = "node"
+  }
+  /* block comment */
+  ebs_block_device {
+    device_name = "/dev/sdb"
+    volume_size = 100
+  }
+  ebs_block_device {
+    device_name = "/dev/sdc"
+    volume_size = 50.5
+  }
+  user_data = <<-EOT
+    #!/bin/bash
+    echo "hello"
+  EOT
+  subnets = [for s in var.subnets : s.id]
+  zones = ["a", "b", // zone c is not used
+    "d"]
+  enabled = var.enabled ? 1 : 0
+  empty = null
+  nested = { a = [1, 2], b = "x" }
+}
+
+resource "aws_instance" "db" {
+  ami = "ami-87654321"
+}
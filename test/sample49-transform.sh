@@ -0,0 +1,3 @@
+#!/bin/sh
+# transform command for test.sh. rename image key , and name api to api-v2.
+sed -e 's/^\( *\)image:/\1containerImage:/' -e 's/: api$/: api-v2/'
@@ -127,5 +127,773 @@ f-test-convert  sample10.yaml
 f-log "convert 11"
 f-test-convert  sample11.yaml --skip-key  spec.template.spec.containers[name=kjwikigdocker-container].env[name=abc]
 
+f-log "convert 12"
+f-test-convert  sample12.yaml
+
+f-log "convert 13"
+f-test-convert  sample13.yaml
+
+f-log "convert 14"
+f-test-convert  sample14.yaml
+
+f-log "convert 15"
+f-test-convert  sample15.yaml
+
+f-log "convert 16"
+f-test-convert  sample16.yaml --envsubst --env-file sample16.env
+
+f-log "convert 17"
+f-test-convert  sample17.yaml --render --values sample17-values.yaml
+
+f-log "convert 18"
+f-test-convert  sample18.yaml --prune-empty
+
+f-log "convert 19"
+f-test-convert  sample19.yaml --lint-profile=yamllint-default
+
+f-log "convert 20"
+f-test-convert  sample20.yaml --lint-profile=prettier
+
+f-log "convert 21"
+f-test-convert  sample21.yaml --blank-lines=keep
+
+f-log "convert 22"
+f-test-convert  sample22.yaml --start-line=6 --end-line=7
+
+f-log "convert 23"
+f-test-convert  sample23.yaml --filter=$.spec.ports[*]
+
+f-log "convert 24"
+f-test-convert  sample24.yaml --annotate-source --select=kind==Deployment
+
+f-log "convert 25"
+f-test-convert  sample25.yaml --dedupe-anchors=30
+
+f-log "convert 26"
+f-test-convert  sample26.yaml --minimal
+
+f-log "convert 27"
+f-test-convert  sample27.yaml --checksum-files='configMapGenerator[*].files[*]'
+
+f-log "convert 28"
+f-test-convert  sample28.yaml --float-format=preserve
+
+f-log "convert 29"
+f-test-convert  sample29.yaml
+
+f-log "convert 48"
+f-test-convert  sample48.yaml --minimal --comment-space --comment-column 24 --drop-comment '^#\s*(image|replicas):'
+
+f-log "convert 35"
+f-test-convert  sample35.yaml --set-namespace staging
+
+f-log "convert 36"
+f-test-convert  sample36.yaml --normalize-quantities
+
+f-log "convert 37"
+f-test-convert  sample37.yaml --normalize-scalar-file sample37-normalizers.yaml --normalize-scalar server.shutdownDelay=duration --normalize-scalar server.keepAlive=duration
+
+f-log "convert 38"
+f-test-convert  sample38.yaml --sort-embedded-json
+
+f-log "convert 39"
+f-test-convert  sample39.yaml --sort-embedded-yaml
+
+f-log "convert 40"
+f-test-convert  sample40.yaml --configmap-data=sort
+
+f-log "convert 41"
+f-test-convert  sample41.yaml --sort-embedded '*.ini' --sort-embedded '*.conf' --sort-embedded '*.properties'
+
+f-log "convert 46"
+f-test-convert  sample46.yaml --envsubst --env-file sample46.env --normalize-quantities
+
+f-log "convert 47"
+f-test-convert  sample47.yaml --envsubst --env-file sample47.env --sort-embedded-json
+
+f-log "filter large number"
+f-test-success bash -c "yamlsort -i filter-number.yaml --filter '\$.items[?(@.size == 1000000)]' > filter-number-out.yaml"
+f-test-success diff -u filter-number-ans.yaml filter-number-out.yaml
+f-test-success bash -c "yamlsort -i filter-number.yaml --filter '\$.items[?(@.size != 12345678901)].name' | grep -q '^- large'"
+
+f-log "select large number"
+f-test-success bash -c "yamlsort -i select-number.yaml --select 'spec.replicas==1000000 || spec.replicas==12345678901' > select-number-out.yaml"
+f-test-success diff -u select-number-ans.yaml select-number-out.yaml
+f-test-success bash -c "yamlsort -i select-number.yaml --select 'spec.replicas!=1000000' | grep -c '^kind:' | grep -q '^2\$'"
+
+f-log "convert 49"
+f-test-convert  sample49.yaml --transform=./sample49-transform.sh --transform=cat
+f-test-failure yamlsort -i sample49.yaml --transform=false
+f-test-failure yamlsort -i sample49.yaml --transform=./nosuch-transform.sh
+
+f-log "convert 50"
+f-test-convert  sample50.yaml --script sample50.star
+f-test-failure yamlsort -i sample50.yaml --script script-broken.star
+f-test-failure yamlsort -i sample50.yaml --script nosuch.star
+
+f-log "convert 51"
+f-test-convert  sample51.yaml --key-case=snake
+f-test-failure yamlsort -i sample51.yaml --key-case=upper
+f-test-failure yamlsort -i key-case-conflict.yaml --key-case=snake
+
+f-log "convert 52"
+f-test-convert  sample52.yaml --key-case=camel
+
+f-log "convert 53"
+f-test-convert  sample53.yaml --unique --unique-by=name
+
+f-log "convert 54"
+f-test-convert  sample54.yaml --trim-space --collapse-spaces --expand-tabs=4
+f-test-failure yamlsort -i sample54.yaml --expand-tabs=-1
+
+f-log "convert 55"
+f-test-convert  sample55.yaml
+BACKUP_DIR=$(mktemp -d)
+cp sample55.yaml $BACKUP_DIR/sample55.yaml
+f-test-success bash -c "cd $BACKUP_DIR && yamlsort -w --backup sample55.yaml"
+f-test-success diff -u sample55-ans.yaml $BACKUP_DIR/sample55.yaml
+f-test-success cmp sample55.yaml $BACKUP_DIR/sample55.yaml.orig
+cp sample55.yaml $BACKUP_DIR/sample55.yaml
+f-test-success bash -c "cd $BACKUP_DIR && yamlsort -w --backup=.bak --no-clobber sample55.yaml"
+f-test-success diff -u sample55-ans.yaml $BACKUP_DIR/sample55.yaml
+f-test-success cmp sample55.yaml $BACKUP_DIR/sample55.yaml.bak
+cp sample55.yaml $BACKUP_DIR/sample55.yaml
+f-test-failure bash -c "cd $BACKUP_DIR && yamlsort -w --no-clobber --transform=\"echo '# modified' >> sample55.yaml ; cat\" sample55.yaml"
+f-test-failure grep -q "powered by" $BACKUP_DIR/sample55.yaml
+f-test-success grep -q "^# modified" $BACKUP_DIR/sample55.yaml
+rm -r $BACKUP_DIR
+
+f-log "convert 56"
+f-test-convert  sample56.yaml
+IGNORE_DIR=$(mktemp -d)
+mkdir -p $IGNORE_DIR/vendor $IGNORE_DIR/sub $IGNORE_DIR/charts/a/b
+for f in app.yaml generated.yaml vendor/lib.yaml sub/generated.yaml charts/a/b/values.yaml charts/a/keep.yaml ; do cp sample56.yaml $IGNORE_DIR/$f ; done
+printf 'vendor/\n/generated.yaml\n' > $IGNORE_DIR/.gitignore
+printf 'charts/**/*.yaml\n!charts/**/keep.yaml\n' > $IGNORE_DIR/.yamlsortignore
+f-test-success yamlsort -w $IGNORE_DIR
+for f in app.yaml sub/generated.yaml charts/a/keep.yaml ; do f-test-success diff -u sample56-ans.yaml $IGNORE_DIR/$f ; done
+for f in generated.yaml vendor/lib.yaml charts/a/b/values.yaml ; do f-test-success cmp sample56.yaml $IGNORE_DIR/$f ; done
+f-test-success yamlsort -w --no-ignore $IGNORE_DIR
+for f in generated.yaml vendor/lib.yaml charts/a/b/values.yaml ; do f-test-success diff -u sample56-ans.yaml $IGNORE_DIR/$f ; done
+rm -r $IGNORE_DIR
+
+f-log "follow symlinks"
+SYMLINK_DIR=$(mktemp -d)
+mkdir -p $SYMLINK_DIR/root/real $SYMLINK_DIR/outside $SYMLINK_DIR/outdir
+cp sample56.yaml $SYMLINK_DIR/root/real/a.yaml
+cp sample56.yaml $SYMLINK_DIR/outside/linked.yaml
+cp sample56.yaml $SYMLINK_DIR/outdir/b.yaml
+ln -s ../outside/linked.yaml $SYMLINK_DIR/root/link.yaml
+ln -s ../outdir $SYMLINK_DIR/root/dirlink
+ln -s .. $SYMLINK_DIR/root/real/loop
+f-test-failure bash -c "cd $SYMLINK_DIR/root && yamlsort --check . > $PWD/symlink-out.txt"
+f-test-success diff -u symlink-ans.txt symlink-out.txt
+f-test-failure bash -c "cd $SYMLINK_DIR/root && yamlsort --check --follow-symlinks . > $PWD/symlink-follow-out.txt"
+f-test-success diff -u symlink-follow-ans.txt symlink-follow-out.txt
+f-test-success bash -c "cd $SYMLINK_DIR/root && timeout 60 yamlsort -w --follow-symlinks ."
+f-test-success test -L $SYMLINK_DIR/root/link.yaml
+for f in root/real/a.yaml outside/linked.yaml outdir/b.yaml ; do f-test-success diff -u sample56-ans.yaml $SYMLINK_DIR/$f ; done
+rm -r $SYMLINK_DIR
+
+f-log "ext and sniff"
+EXT_DIR=$(mktemp -d)
+for f in a.yaml b.yml c.yaml.tpl noext ; do cp sample56.yaml $EXT_DIR/$f ; done
+printf 'foo: [\n' > $EXT_DIR/broken
+printf '#!/bin/sh\necho hi\n' > $EXT_DIR/script
+printf 'FROM alpine\nRUN ls\n' > $EXT_DIR/Dockerfile
+f-test-failure bash -c "cd $EXT_DIR && yamlsort --check --ext=yaml,yaml.tpl . > $PWD/ext-out.txt"
+f-test-success diff -u ext-ans.txt ext-out.txt
+f-test-failure bash -c "cd $EXT_DIR && yamlsort --check --sniff . > $PWD/sniff-out.txt"
+f-test-success diff -u sniff-ans.txt sniff-out.txt
+f-test-success bash -c "cd $EXT_DIR && yamlsort -w --sniff ."
+for f in a.yaml b.yml noext ; do f-test-success diff -u sample56-ans.yaml $EXT_DIR/$f ; done
+f-test-success cmp sample56.yaml $EXT_DIR/c.yaml.tpl
+f-test-success bash -c "printf 'FROM alpine\nRUN ls\n' | cmp - $EXT_DIR/Dockerfile"
+rm -r $EXT_DIR
+
+f-log "output template"
+TEMPLATE_DIR=$(mktemp -d)
+mkdir -p $TEMPLATE_DIR/in/sub
+cp sample56.yaml $TEMPLATE_DIR/in/a.yaml
+cp sample55.yaml $TEMPLATE_DIR/in/sub/b.yml
+f-test-success bash -c "cd $TEMPLATE_DIR && yamlsort --output-template='out/{{.Dir}}/{{.Name}}.sorted{{.Ext}}' in"
+f-test-success diff -u sample56-ans.yaml $TEMPLATE_DIR/out/in/a.sorted.yaml
+f-test-success diff -u sample55-ans.yaml $TEMPLATE_DIR/out/in/sub/b.sorted.yml
+f-test-success cmp sample56.yaml $TEMPLATE_DIR/in/a.yaml
+f-test-failure bash -c "cd $TEMPLATE_DIR && yamlsort --output-template='{{.Path}}' in"
+f-test-failure bash -c "cd $TEMPLATE_DIR && yamlsort --output-template='{{.Nosuch}}' in"
+f-test-failure bash -c "cd $TEMPLATE_DIR && yamlsort -w --output-template='out/{{.Base}}' in"
+rm -r $TEMPLATE_DIR
+
+f-log "convert 57"
+f-test-convert  sample57.yaml --doc=1 --unselected=keep
+f-test-success yamlsort -i sample57.yaml -o sample57-doc-out.yaml --doc=0,2
+f-test-success diff -u sample57-doc-ans.yaml sample57-doc-out.yaml
+f-test-failure yamlsort -i sample57.yaml --doc=1 --unselected=drop
+
+f-log "git changed"
+GIT_DIR_TEST=$(mktemp -d)
+cp sample58.yaml $GIT_DIR_TEST/sample58.yaml
+f-test-failure bash -c "cd $GIT_DIR_TEST && yamlsort --git-changed -i sample58.yaml"
+f-test-success bash -c "cd $GIT_DIR_TEST && git init -q && git add sample58.yaml && git -c user.name=test -c user.email=test@example.com commit -qm init"
+f-test-success bash -c "cd $GIT_DIR_TEST && yamlsort --git-changed -i sample58.yaml | diff -u sample58.yaml -"
+f-test-success sed -i 's/  c: "1"/  c: "3"/' $GIT_DIR_TEST/sample58.yaml
+f-test-success bash -c "cd $GIT_DIR_TEST && yamlsort --git-changed -w sample58.yaml"
+f-test-success diff -u sample58-git-ans.yaml $GIT_DIR_TEST/sample58.yaml
+rm -rf $GIT_DIR_TEST
+
+f-log "atomic write"
+ATOMIC_DIR=$(mktemp -d)
+cp sample56.yaml $ATOMIC_DIR/a.yaml
+cp sample56.yaml $ATOMIC_DIR/b.yaml
+chmod 0640 $ATOMIC_DIR/a.yaml
+ATOMIC_INODE=$(stat -c %i $ATOMIC_DIR/a.yaml)
+f-test-success yamlsort -w $ATOMIC_DIR/a.yaml
+f-test-success diff -u sample56-ans.yaml $ATOMIC_DIR/a.yaml
+f-test-success test "$(stat -c %a $ATOMIC_DIR/a.yaml)" = 640
+f-test-failure test "$(stat -c %i $ATOMIC_DIR/a.yaml)" = "$ATOMIC_INODE"
+f-test-failure bash -c "ls -A $ATOMIC_DIR | grep -q yamlsort-"
+cp sample56.yaml $ATOMIC_DIR/a.yaml
+f-test-success bash -c "yamlsort -w --no-progress --transform='sleep 1 ; cat' $ATOMIC_DIR 2> $ATOMIC_DIR/interrupt.err & sleep 1.5 ; kill -TERM \$! ; wait \$! ; test \$? -eq 143"
+f-test-success grep -q "interrupted by terminated" $ATOMIC_DIR/interrupt.err
+f-test-success diff -u sample56-ans.yaml $ATOMIC_DIR/a.yaml
+f-test-success cmp sample56.yaml $ATOMIC_DIR/b.yaml
+f-test-failure bash -c "ls -A $ATOMIC_DIR | grep -q yamlsort-"
+rm -r $ATOMIC_DIR
+
+f-log "pager"
+f-test-success bash -c "env YAMLSORT_PAGER=$PWD/plugins/fake-pager script -qec 'stty rows 24 cols 80 ; yamlsort -i sample1.yaml' /dev/null | tr -d '\\r' > pager-out.txt"
+f-test-success diff -u pager-ans.txt pager-out.txt
+f-test-success bash -c "env -u YAMLSORT_PAGER PAGER=$PWD/plugins/fake-pager script -qec 'stty rows 24 cols 80 ; yamlsort -i sample1.yaml' /dev/null | tr -d '\\r' | diff -u pager-ans.txt -"
+f-test-success bash -c "env YAMLSORT_PAGER=$PWD/plugins/fake-pager script -qec 'stty rows 24 cols 80 ; yamlsort -i sample3.yaml' /dev/null | tr -d '\\r' | diff -u sample3-ans.yaml -"
+f-test-success bash -c "env YAMLSORT_PAGER=$PWD/plugins/fake-pager script -qec 'stty rows 24 cols 80 ; yamlsort --no-pager -i sample1.yaml' /dev/null | tr -d '\\r' | diff -u sample1-ans.yaml -"
+f-test-success bash -c "env YAMLSORT_PAGER=cat script -qec 'stty rows 24 cols 80 ; yamlsort -i sample1.yaml' /dev/null | tr -d '\\r' | diff -u sample1-ans.yaml -"
+f-test-success bash -c "env YAMLSORT_PAGER=$PWD/plugins/fake-pager yamlsort -i sample1.yaml | diff -u sample1-ans.yaml -"
+f-test-failure env YAMLSORT_PAGER=$PWD/plugins/fake-pager yamlsort -i sample1.yaml -o /dev/full
+f-test-failure bash -c "env YAMLSORT_PAGER=$PWD/plugins/fake-pager yamlsort -i sample1.yaml > /dev/full"
+
+f-log "write errors"
+f-test-failure yamlsort -i sample1.yaml -o /dev/full
+f-test-failure bash -c "yamlsort -i sample1.yaml > /dev/full"
+f-test-failure bash -c "yamlsort -i sample1.yaml --jsonoutput > /dev/full"
+f-test-failure bash -c "yamlsort -i sample1.yaml --output-format=html > /dev/full"
+f-test-failure bash -c "yamlsort --dry-run -f sample1.yaml > /dev/full"
+f-test-failure bash -c "yamlsort -w --dry-run sample1.yaml > /dev/full"
+f-test-failure bash -c "printf 'frame 10 a.yaml\nb: 1\na: 2\n' | yamlsort --framed > /dev/full"
+f-test-failure bash -c "yamlsort --check --format=junit sample1-ans.yaml > /dev/full"
+f-test-failure bash -c "yamlsort paths sample1.yaml > /dev/full"
+f-test-failure bash -c "yamlsort stats sample1.yaml > /dev/full"
+f-test-failure bash -c "yamlsort graph sample1.yaml > /dev/full"
+f-test-failure bash -c "yamlsort selftest sample1.yaml > /dev/full"
+f-test-success bash -c "yamlsort -i sample1.yaml -o /dev/full 2>&1 | grep -q 'no space left on device'"
+
+f-log "canonical version"
+f-test-success yamlsort --canonical-version=1 --check sample1-ans.yaml
+f-test-failure yamlsort --canonical-version=99 --check sample1-ans.yaml
+
+f-log "check"
+f-test-success yamlsort --check sample1-ans.yaml sample2-ans.yaml
+f-test-failure yamlsort --check --format=github sample1.yaml
+f-test-failure yamlsort --check --format=sarif sample1.yaml
+
+f-log "rules"
+f-test-success yamlsort --rules rules.yaml -i sample1.yaml -o /dev/null
+f-test-failure yamlsort --rules rules-fail.yaml -i sample1.yaml -o /dev/null
+f-test-failure bash -c "yamlsort --rules rules-number.yaml -i select-number.yaml -o /dev/null 2> rules-number-out.txt"
+f-test-success diff -u rules-number-ans.txt rules-number-out.txt
+f-test-success yamlsort --rules rules-number.yaml --select spec.replicas==1000000 -i select-number.yaml -o /dev/null
+
+f-log "scaffold"
+f-test-success bash -c "yamlsort scaffold --schema schema.json > scaffold-out.yaml"
+f-test-success diff -u scaffold-ans.yaml scaffold-out.yaml
+f-test-success bash -c "yamlsort scaffold --schema scaffold-schema.yaml > scaffold-yaml-out.yaml"
+f-test-success diff -u scaffold-yaml-ans.yaml scaffold-yaml-out.yaml
+f-test-failure yamlsort scaffold
+f-test-failure yamlsort scaffold --schema nosuch.json
+
+f-log "overlay"
+f-test-success bash -c "yamlsort overlay overlay/base overlay/prod > overlay-out.yaml"
+f-test-success diff -u overlay-ans.yaml overlay-out.yaml
+f-test-success bash -c "yamlsort overlay overlay/base overlay/prod overlay/canary > overlay-canary-out.yaml"
+f-test-success diff -u overlay-canary-ans.yaml overlay-canary-out.yaml
+f-test-failure yamlsort overlay overlay/none
+
+f-log "encrypt"
+f-test-success yamlsort encrypt --key-path crypt-key.txt --paths 'metadata.labels.*' sample1.yaml
+f-test-failure yamlsort encrypt --key-path crypt-key.txt sample1.yaml
+f-test-success bash -c "yamlsort decrypt --key-path crypt-key.txt crypt-enc.yaml > crypt-out.yaml"
+f-test-success diff -u crypt-ans.yaml crypt-out.yaml
+f-test-failure yamlsort decrypt --key-path crypt-key.txt crypt-moved.yaml
+
+f-log "strict floats"
+f-test-failure yamlsort --strict-floats -i sample29.yaml
+f-test-failure yamlsort --jsonoutput -i sample29.yaml
+
+f-log "anchors"
+f-test-success yamlsort -i sample31.yaml
+f-test-failure yamlsort -i alias-undefined.yaml
+f-test-failure yamlsort -i alias-cycle.yaml
+
+f-log "parser panic"
+# merge key of null alias is error of parser (not panic)
+f-test-failure yamlsort -i merge-null-alias.yaml --float-format=preserve
+f-test-success bash -c "yamlsort -i merge-null-alias.yaml --float-format=preserve 2>&1 | grep -q '^Error: .*map merge requires map'"
+
+f-log "alias expansion"
+f-test-failure yamlsort --max-alias-expansion 5 -i alias-laughs.yaml
+f-test-success bash -c "yamlsort --max-alias-expansion 5 -i alias-laughs.yaml 2>&1 | grep -q 'expand to more than 5 nodes'"
+f-test-success yamlsort --max-alias-expansion 8 -i alias-laughs.yaml
+
+f-log "selftest"
+f-test-success yamlsort selftest sample1.yaml sample3.yaml sample29.yaml
+f-test-failure yamlsort selftest sample19.yaml
+
+f-log "max depth"
+f-test-failure yamlsort --max-depth 2 -i sample1.yaml
+
+f-log "strict types"
+f-test-success yamlsort --strict-types -i sample1.yaml
+f-test-failure yamlsort --strict-types -i sample30.yaml
+
+f-log "chmod"
+f-test-failure yamlsort --chmod 0999 -i sample1.yaml
+
+f-log "append and no overwrite"
+f-test-failure yamlsort -i sample1.yaml -o sample1-ans.yaml --no-overwrite
+f-test-failure yamlsort -i sample1.yaml -o sample1-ans.yaml --append --no-overwrite
+f-test-failure yamlsort -w --append sample1.yaml
+APPEND_DIR=$(mktemp -d)
+f-test-success yamlsort -i sample55.yaml -o $APPEND_DIR/append.yaml --append
+f-test-success yamlsort -i sample56.yaml -o $APPEND_DIR/append.yaml --append
+f-test-success diff -u append-ans.yaml $APPEND_DIR/append.yaml
+printf 'kind: Note' > $APPEND_DIR/noeol.yaml
+f-test-success yamlsort -i sample56.yaml -o $APPEND_DIR/noeol.yaml --append
+f-test-success diff -u append-noeol-ans.yaml $APPEND_DIR/noeol.yaml
+f-test-success yamlsort -i sample55.yaml -o $APPEND_DIR/new.yaml --no-overwrite
+f-test-success diff -u sample55-ans.yaml $APPEND_DIR/new.yaml
+f-test-failure yamlsort -i sample56.yaml -o $APPEND_DIR/new.yaml --no-overwrite
+f-test-success diff -u sample55-ans.yaml $APPEND_DIR/new.yaml
+f-test-success bash -c "cd $APPEND_DIR && cp $PWD/sample55.yaml a.yaml && yamlsort --output-template='{{.Name}}.out' --no-overwrite a.yaml"
+f-test-failure bash -c "cd $APPEND_DIR && yamlsort --output-template='{{.Name}}.out' --no-overwrite a.yaml"
+f-test-success bash -c "cd $APPEND_DIR && yamlsort --output-template='{{.Name}}.out' --append a.yaml && diff -u $PWD/append-template-ans.yaml a.out"
+rm -r $APPEND_DIR
+
+f-log "tee"
+f-test-failure yamlsort -i sample1.yaml --tee
+TEE_DIR=$(mktemp -d)
+f-test-success bash -c "yamlsort -i sample55.yaml -o $TEE_DIR/out.yaml --tee > $TEE_DIR/stdout.yaml"
+f-test-success diff -u sample55-ans.yaml $TEE_DIR/out.yaml
+f-test-success diff -u sample55-ans.yaml $TEE_DIR/stdout.yaml
+cp sample55.yaml $TEE_DIR/sample55.yaml
+f-test-success bash -c "cd $TEE_DIR && yamlsort -f sample55.yaml --tee > stdout.yaml"
+f-test-success diff -u sample55-ans.yaml $TEE_DIR/sample55.yaml
+f-test-success diff -u sample55-ans.yaml $TEE_DIR/stdout.yaml
+f-test-failure bash -c "yamlsort -i sample55.yaml -o /dev/full --tee > $TEE_DIR/stdout.yaml"
+f-test-failure bash -c "yamlsort -i sample55.yaml -o $TEE_DIR/out.yaml --tee > /dev/full"
+rm -r $TEE_DIR
+
+f-log "clipboard"
+f-test-failure yamlsort --clipboard-in -i sample1.yaml
+f-test-failure yamlsort --clipboard-out -o sample1-out.yaml -i sample1.yaml
+export FAKE_CLIPBOARD=$(mktemp)
+for CLIPBOARD_DIR in $PWD/clipboard/xclip $PWD/clipboard/pbcopy ; do
+    cp sample55.yaml $FAKE_CLIPBOARD
+    f-test-success bash -c "env PATH=\"$CLIPBOARD_DIR:\$PATH\" yamlsort --clipboard-in > clipboard-out.yaml"
+    f-test-success diff -u sample55-ans.yaml clipboard-out.yaml
+    f-test-success env PATH="$CLIPBOARD_DIR:$PATH" yamlsort --clipboard-in --clipboard-out
+    f-test-success diff -u sample55-ans.yaml $FAKE_CLIPBOARD
+    f-test-success bash -c "env PATH=\"$CLIPBOARD_DIR:\$PATH\" yamlsort -i sample56.yaml --clipboard-out --tee > clipboard-out.yaml"
+    f-test-success diff -u sample56-ans.yaml $FAKE_CLIPBOARD
+    f-test-success diff -u sample56-ans.yaml clipboard-out.yaml
+done
+f-test-failure env PATH="$(dirname $(command -v yamlsort))" yamlsort --clipboard-out -i sample1.yaml
+f-test-failure bash -c "env PATH=\"$(dirname $(command -v yamlsort))\" yamlsort --clipboard-in < sample1.yaml"
+rm -f $FAKE_CLIPBOARD
+unset FAKE_CLIPBOARD
+
+f-log "html"
+f-test-success yamlsort -i sample1.yaml -o sample1-out.html --output-format html
+f-test-success diff -u sample1-ans.html sample1-out.html
+f-test-failure yamlsort -f sample1-out.yaml --output-format html
+
+f-log "graph"
+f-test-success bash -c "yamlsort graph --refs sample33.yaml > graph-refs-out.dot"
+f-test-success diff -u graph-refs-ans.dot graph-refs-out.dot
+f-test-success bash -c "yamlsort graph --format mermaid --depth 2 sample1.yaml sample33.yaml > graph-mermaid-out.txt"
+f-test-success diff -u graph-mermaid-ans.txt graph-mermaid-out.txt
+f-test-success bash -c "yamlsort graph --depth 1 sample55.yaml sample56.yaml > graph-label-out.dot"
+f-test-success diff -u graph-label-ans.dot graph-label-out.dot
+f-test-failure yamlsort graph --format svg sample33.yaml
+
+f-log "check refs"
+f-test-success yamlsort --check-refs -i sample33.yaml
+f-test-failure yamlsort --check-refs -i sample34.yaml
+
+f-log "k8s-label"
+f-test-success yamlsort k8s-label --add team=payments --remove app --add-annotation example.com/owner=platform --pod-template -i sample33.yaml -o sample33-label-out.yaml
+f-test-success diff -u sample33-label-ans.yaml sample33-label-out.yaml
+f-test-failure yamlsort k8s-label --add team -i sample33.yaml
+# file arguments are written in place with -w
+LABEL_DIR=$(mktemp -d)
+cp sample33.yaml $LABEL_DIR/sample33.yaml
+f-test-success bash -c "yamlsort k8s-label --add team=payments --remove app --add-annotation example.com/owner=platform --pod-template sample33.yaml > sample33-label-out.yaml"
+f-test-success diff -u sample33-label-ans.yaml sample33-label-out.yaml
+f-test-success bash -c "cd $LABEL_DIR && yamlsort k8s-label -w --add team=payments --remove app --add-annotation example.com/owner=platform --pod-template ."
+f-test-success diff -u sample33-label-ans.yaml $LABEL_DIR/sample33.yaml
+f-test-failure yamlsort k8s-label -w --add team=payments
+rm -r $LABEL_DIR
+
+f-log "set namespace"
+f-test-failure yamlsort --set-namespace Staging -i sample35.yaml
+
+f-log "set-image"
+f-test-success yamlsort set-image example/api=:2.0 -i sample33.yaml -o sample33-image-out.yaml
+f-test-success diff -u sample33-image-ans.yaml sample33-image-out.yaml
+f-test-failure yamlsort set-image example/api:1.0=:2.0 -i sample33.yaml
+
+f-log "rename"
+f-test-success yamlsort rename --from 'metadata.labels["app.kubernetes.io/name"]' --to metadata.labels.app --from spec.replicas --to spec.scale.replicas -i rename.yaml -o rename-out.yaml
+f-test-success diff -u rename-ans.yaml rename-out.yaml
+# file arguments are written in place with -w
+RENAME_DIR=$(mktemp -d)
+cp rename.yaml $RENAME_DIR/rename.yaml
+f-test-success bash -c "cd $RENAME_DIR && yamlsort rename -w --from 'metadata.labels[\"app.kubernetes.io/name\"]' --to metadata.labels.app --from spec.replicas --to spec.scale.replicas rename.yaml"
+f-test-success diff -u rename-ans.yaml $RENAME_DIR/rename.yaml
+rm -r $RENAME_DIR
+f-test-failure yamlsort rename --from spec.nosuch --to spec.other -i rename.yaml -o /dev/null
+f-test-failure yamlsort rename --from 'metadata.labels["app' --to metadata.app -i rename.yaml
+f-test-success yamlsort rename --from 'items[id=1000000].v' --to 'items[id=1000000].w' -i rename-number.yaml -o rename-number-out.yaml
+f-test-success diff -u rename-number-ans.yaml rename-number-out.yaml
+
+f-log "normalize scalar"
+f-test-failure yamlsort --normalize-scalar server.readTimeout=time -i sample37.yaml
+f-test-failure yamlsort --normalize-scalar server.readTimeout -i sample37.yaml
+
+f-log "sort embedded json"
+f-test-success yamlsort --sort-embedded-json=compact -i sample38.yaml -o sample38-compact-out.yaml
+f-test-success diff -u sample38-compact-ans.yaml sample38-compact-out.yaml
+f-test-failure yamlsort --sort-embedded-json=indent -i sample38.yaml
+
+f-log "configmap data"
+f-test-success yamlsort --configmap-data -i sample40.yaml -o sample40-format-out.yaml
+f-test-success diff -u sample40-format-ans.yaml sample40-format-out.yaml
+f-test-failure yamlsort --configmap-data=pretty -i sample40.yaml
+
+f-log "sort embedded"
+f-test-failure yamlsort --sort-embedded '[' -i sample41.yaml
+
+f-log "toml input"
+f-test-success yamlsort -i sample42.toml -o sample42-out.yaml
+f-test-success diff -u sample42-ans.yaml sample42-out.yaml
+f-test-failure yamlsort -f sample42.toml
+f-test-failure yamlsort --input-format ini -i sample42.toml
+
+f-log "hcl input and output"
+f-test-success yamlsort -i sample43.tf -o sample43-out.yaml
+f-test-success diff -u sample43-ans.yaml sample43-out.yaml
+f-test-success yamlsort -i sample43-ans.yaml --output-format hcl -o sample43-out.hcl
+f-test-success diff -u sample43-ans.hcl sample43-out.hcl
+f-test-failure yamlsort --output-format hcl -i sample5.yaml
+
+f-log "xml input and output"
+f-test-success yamlsort -i sample44.xml -o sample44-out.yaml
+f-test-success diff -u sample44-ans.yaml sample44-out.yaml
+f-test-success yamlsort -i sample44.xml --xml-attributes merge -o sample44-merge-out.yaml
+f-test-success diff -u sample44-merge-ans.yaml sample44-merge-out.yaml
+f-test-success yamlsort -i sample44-ans.yaml --output-format xml -o sample44-out.xml
+f-test-success diff -u sample44-ans.xml sample44-out.xml
+f-test-failure yamlsort --xml-attributes keep -i sample44.xml
+f-test-failure yamlsort --output-format xml -i sample3.yaml
+
+f-log "protobuf message"
+f-test-success yamlsort --descriptor sample45.pb --message demo.config.Server -i sample45.yaml -o sample45-out.yaml
+f-test-success diff -u sample45-ans.yaml sample45-out.yaml
+f-test-failure yamlsort --descriptor sample45.pb --message demo.config.Server -i sample45-invalid.yaml
+f-test-failure yamlsort --descriptor sample45.pb --message demo.config.Missing -i sample45.yaml
+f-test-failure yamlsort --message demo.config.Server -i sample45.yaml
+
+f-log "dry run"
+f-test-success yamlsort -w --dry-run sample1.yaml
+f-test-failure yamlsort --dry-run sample1.yaml
+# diff of large shuffled file in limited memory , and it patches file into sorted text
+DRYRUN_DIR=$(mktemp -d)
+for i in $(seq 20000) ; do echo "key$i: value$i" ; done | shuf > $DRYRUN_DIR/large.yaml
+cp $DRYRUN_DIR/large.yaml $DRYRUN_DIR/large-orig.yaml
+f-test-success bash -c "cd $DRYRUN_DIR && ulimit -v 6000000 && timeout 60 yamlsort -w --dry-run large.yaml > large.diff"
+f-test-success cmp $DRYRUN_DIR/large.yaml $DRYRUN_DIR/large-orig.yaml
+f-test-success bash -c "cd $DRYRUN_DIR && patch -s -o large-patched.yaml large.yaml < large.diff"
+f-test-success bash -c "cd $DRYRUN_DIR && yamlsort -i large.yaml | diff -q - large-patched.yaml"
+rm -r $DRYRUN_DIR
+
+f-log "line range"
+f-test-failure yamlsort --start-line=20 -i sample22.yaml
+f-test-failure yamlsort --start-line=6 --end-line=16 -i sample22.yaml
+
+f-log "comparator plugin"
+f-test-success env PATH="$PWD/plugins:$PATH" yamlsort --comparator reverse -i sample3.yaml -o sample3-reverse-out.yaml
+f-test-success diff -u sample3-reverse-ans.yaml sample3-reverse-out.yaml
+f-test-success env PATH="$PWD/plugins:$PATH" yamlsort --preset reverse -i sample3.yaml -o sample3-reverse-out.yaml
+f-test-success diff -u sample3-reverse-ans.yaml sample3-reverse-out.yaml
+f-test-failure env PATH="$PWD/plugins:$PATH" yamlsort --comparator broken -i sample3.yaml
+f-test-failure yamlsort --comparator none -i sample3.yaml
+# plugin has exited when yamlsort exits
+PLUGIN_EXIT_FILE=$(mktemp -u)
+f-test-success env PATH="$PWD/plugins:$PATH" YAMLSORT_TEST_EXIT_FILE=$PLUGIN_EXIT_FILE yamlsort --comparator reverse -i sample3.yaml -o /dev/null
+f-test-success test -f $PLUGIN_EXIT_FILE
+rm -f $PLUGIN_EXIT_FILE
+f-test-success bash -c "env PATH=\"$PWD/plugins:\$PATH\" YAMLSORT_TEST_EXIT_FILE=$PLUGIN_EXIT_FILE yamlsort lsp --preset reverse < lsp-input.txt > /dev/null"
+f-test-success test -f $PLUGIN_EXIT_FILE
+rm -f $PLUGIN_EXIT_FILE
+
+f-log "daemon and client"
+DAEMON_SOCKET=$(mktemp -d)/yamlsort.sock
+yamlsort daemon --socket $DAEMON_SOCKET 2> /dev/null &
+DAEMON_PID=$!
+for i in $(seq 50) ; do [ -S $DAEMON_SOCKET ] && break ; sleep 0.1 ; done
+f-test-success test -S $DAEMON_SOCKET
+f-test-success bash -c "yamlsort client --stdin --socket $DAEMON_SOCKET -- -i sample3.yaml < /dev/null > daemon-out.yaml"
+f-test-success bash -c "yamlsort -i sample3.yaml | diff -u - daemon-out.yaml"
+f-test-success bash -c "env MEM=1Gi CPU=250m yamlsort client --stdin --socket $DAEMON_SOCKET -- --envsubst < sample46.yaml > daemon-out.yaml"
+f-test-success bash -c "env MEM=1Gi CPU=250m yamlsort --envsubst < sample46.yaml | diff -u - daemon-out.yaml"
+f-test-failure bash -c "yamlsort client --stdin --socket $DAEMON_SOCKET -- -o daemon-out.yaml < sample3.yaml"
+f-test-failure bash -c "yamlsort client --stdin --socket $DAEMON_SOCKET -- --transform cat < sample3.yaml"
+f-test-failure bash -c "yamlsort client --stdin --socket $DAEMON_SOCKET -- --preset demo < sample3.yaml"
+f-test-failure bash -c "yamlsort client --stdin --socket $DAEMON_SOCKET -- --comparator demo < sample3.yaml"
+f-test-failure bash -c "yamlsort client --stdin --socket $DAEMON_SOCKET -- --policy policy < sample3.yaml"
+f-test-failure bash -c "yamlsort client --stdin --socket $DAEMON_SOCKET -- --git-changed < sample3.yaml"
+f-test-failure bash -c "yamlsort client --stdin --socket $DAEMON_SOCKET -- -i s3://bucket/sample3.yaml < /dev/null"
+f-test-failure bash -c "yamlsort client --stdin --socket $DAEMON_SOCKET -- --max-alias-expansion 1000000 < sample3.yaml"
+f-test-success bash -c "yamlsort client --stdin --socket $DAEMON_SOCKET -- --max-alias-expansion 5 < alias-laughs.yaml 2>&1 | grep -q 'expand to more than 5 nodes'"
+f-test-failure yamlsort daemon --socket $DAEMON_SOCKET
+kill $DAEMON_PID
+wait $DAEMON_PID
+f-test-failure test -e $DAEMON_SOCKET
+rmdir $(dirname $DAEMON_SOCKET)
+DAEMON_SOCKET=$(mktemp -d)/yamlsort.sock
+yamlsort daemon --socket $DAEMON_SOCKET --max-request-size 100000 2> /dev/null &
+DAEMON_PID=$!
+for i in $(seq 50) ; do [ -S $DAEMON_SOCKET ] && break ; sleep 0.1 ; done
+f-test-success bash -c "yamlsort client --stdin --socket $DAEMON_SOCKET < sample3.yaml > daemon-out.yaml"
+f-test-success bash -c "for i in \$(seq 10000) ; do echo key\$i: value ; done | yamlsort client --stdin --socket $DAEMON_SOCKET 2>&1 | grep -q 'request exceeds --max-request-size 100000'"
+kill $DAEMON_PID
+wait $DAEMON_PID
+rmdir $(dirname $DAEMON_SOCKET)
+f-test-success bash -c "yamlsort client --stdin --socket $DAEMON_SOCKET < sample3.yaml > daemon-out.yaml"
+
+f-log "lsp"
+f-test-success bash -c "yamlsort lsp < lsp-input.txt > lsp-out.txt"
+f-test-success diff -u lsp-ans.txt lsp-out.txt
+f-test-failure bash -c "yamlsort lsp < lsp-noshutdown.txt > /dev/null"
+
+f-log "tui"
+f-test-failure yamlsort tui
+f-test-failure bash -c "yamlsort tui sample3.yaml < /dev/null"
+f-test-success bash -c "(sleep 0.5 ; printf 'jj/name\\r' ; sleep 0.5 ; printf q) | script -qec 'yamlsort tui sample3.yaml' /dev/null | grep -q 'metadata.name'"
+
+f-log "bench"
+f-test-success bash -c "yamlsort bench --count 2 sample3.yaml sample1.yaml | grep -q 'sample1.yaml: 706 bytes, 1 documents, 2 iterations'"
+f-test-failure yamlsort bench
+f-test-failure yamlsort bench --count 0 sample3.yaml
+f-test-failure yamlsort bench nosuch.yaml
+PROFILE_DIR=$(mktemp -d)
+f-test-success yamlsort bench --count 2 --cpuprofile $PROFILE_DIR/cpu.prof --memprofile $PROFILE_DIR/mem.prof sample3.yaml
+f-test-success test -s $PROFILE_DIR/cpu.prof
+f-test-success test -s $PROFILE_DIR/mem.prof
+rm -rf $PROFILE_DIR
+
+f-log "textconv"
+f-test-success bash -c "yamlsort textconv sample3.yaml > textconv-out.yaml"
+f-test-success diff -u textconv-ans.yaml textconv-out.yaml
+f-test-success bash -c "yamlsort textconv textconv-broken.txt | cmp - textconv-broken.txt"
+f-test-failure yamlsort textconv
+TEXTCONV_DIR=$(mktemp -d)
+printf 'b: 1\na: 2\n' > $TEXTCONV_DIR/x.yaml
+echo '*.yaml diff=yamlsort' > $TEXTCONV_DIR/.gitattributes
+git -C $TEXTCONV_DIR init -q
+git -C $TEXTCONV_DIR add -A
+git -C $TEXTCONV_DIR -c user.name=test -c user.email=test@example.com commit -q -m init
+printf 'a: 2\nb: 1\n' > $TEXTCONV_DIR/x.yaml
+f-test-success bash -c "test -z \"\$(git -C $TEXTCONV_DIR -c diff.yamlsort.textconv='yamlsort textconv' diff)\""
+printf 'a: 3\nb: 1\n' > $TEXTCONV_DIR/x.yaml
+f-test-success bash -c "git -C $TEXTCONV_DIR -c diff.yamlsort.textconv='yamlsort textconv' diff | grep -q '^+a: 3'"
+rm -rf $TEXTCONV_DIR
+
+f-log "git-merge"
+f-test-success cp git-merge-ours.yaml git-merge-out.yaml
+f-test-success yamlsort git-merge git-merge-base.yaml git-merge-out.yaml git-merge-theirs.yaml
+f-test-success diff -u git-merge-ans.yaml git-merge-out.yaml
+f-test-success cp git-merge-ours.yaml git-merge-out.yaml
+f-test-failure yamlsort git-merge git-merge-base.yaml git-merge-out.yaml git-merge-conflict.yaml
+f-test-success diff -u git-merge-conflict-ans.yaml git-merge-out.yaml
+# comments are kept by line based merge
+f-test-success cp git-merge-comment-ours.yaml git-merge-out.yaml
+f-test-success yamlsort git-merge git-merge-comment-base.yaml git-merge-out.yaml git-merge-comment-theirs.yaml
+f-test-success diff -u git-merge-comment-ans.yaml git-merge-out.yaml
+f-test-failure yamlsort git-merge git-merge-base.yaml git-merge-out.yaml
+f-test-success cp git-merge-prefer-ours.yaml git-merge-out.yaml
+f-test-success yamlsort git-merge --prefer ours git-merge-prefer-base.yaml git-merge-out.yaml git-merge-prefer-theirs.yaml
+f-test-success diff -u git-merge-prefer-ours-ans.yaml git-merge-out.yaml
+f-test-success cp git-merge-prefer-ours.yaml git-merge-out.yaml
+f-test-success yamlsort git-merge --prefer theirs git-merge-prefer-base.yaml git-merge-out.yaml git-merge-prefer-theirs.yaml
+f-test-success diff -u git-merge-prefer-theirs-ans.yaml git-merge-out.yaml
+f-test-failure yamlsort git-merge --prefer mine git-merge-prefer-base.yaml git-merge-out.yaml git-merge-prefer-theirs.yaml
+f-test-success cp git-merge-prefer-ours.yaml git-merge-out.yaml
+f-test-success bash -c "printf 't\\n\\ne\\n{level: trace}\\n' | timeout 60 script -qec 'yamlsort git-merge --interactive --prefer ours git-merge-prefer-base.yaml git-merge-out.yaml git-merge-prefer-theirs.yaml' /dev/null > /dev/null"
+f-test-success diff -u git-merge-interactive-ans.yaml git-merge-out.yaml
+f-test-failure setsid -w yamlsort git-merge --interactive git-merge-prefer-base.yaml git-merge-out.yaml git-merge-prefer-theirs.yaml
+f-test-success cp git-merge-list-ours.yaml git-merge-out.yaml
+f-test-success yamlsort git-merge --list-strategy metadata.finalizers=union --list-strategy spec.args=append --list-strategy spec.containers=merge-by-key=name git-merge-list-base.yaml git-merge-out.yaml git-merge-list-theirs.yaml
+f-test-success diff -u git-merge-list-ans.yaml git-merge-out.yaml
+f-test-success cp git-merge-list-ours.yaml git-merge-out.yaml
+f-test-success yamlsort git-merge --list-strategy-file git-merge-list-strategy.yaml git-merge-list-base.yaml git-merge-out.yaml git-merge-list-theirs.yaml
+f-test-success diff -u git-merge-list-ans.yaml git-merge-out.yaml
+f-test-success cp git-merge-list-ours.yaml git-merge-out.yaml
+f-test-failure yamlsort git-merge git-merge-list-base.yaml git-merge-out.yaml git-merge-list-theirs.yaml
+f-test-failure yamlsort git-merge --list-strategy spec.args=prepend git-merge-list-base.yaml git-merge-out.yaml git-merge-list-theirs.yaml
+f-test-failure yamlsort git-merge --list-strategy spec.args git-merge-list-base.yaml git-merge-out.yaml git-merge-list-theirs.yaml
+
+f-log "stats"
+f-test-success bash -c "yamlsort stats sample12.yaml stats-duplicate.yaml > stats-out.txt"
+f-test-success diff -u stats-ans.txt stats-out.txt
+f-test-success bash -c "yamlsort stats stats-merge.yaml > stats-merge-out.txt"
+f-test-success diff -u stats-merge-ans.txt stats-merge-out.txt
+f-test-success bash -c "yamlsort stats --aliases stats-aliases.yaml > stats-aliases-out.txt"
+f-test-success diff -u stats-aliases-ans.txt stats-aliases-out.txt
+f-test-failure bash -c "yamlsort stats stats-aliases.yaml | grep -q anchors="
+f-test-failure yamlsort stats
+f-test-failure yamlsort stats nosuch.yaml
+
+f-log "paths"
+f-test-success bash -c "yamlsort paths sample3.yaml sample12.yaml > paths-out.txt"
+f-test-success diff -u paths-ans.txt paths-out.txt
+f-test-failure yamlsort paths
+f-test-failure yamlsort paths nosuch.yaml
+
+f-log "audit"
+f-test-success bash -c "yamlsort audit --usage audit > audit-out.txt"
+f-test-success diff -u audit-ans.txt audit-out.txt
+f-test-success bash -c "yamlsort audit audit | grep -q 'port  number (audit/api.yaml) string (audit/web.yaml)'"
+f-test-failure yamlsort audit
+
+f-log "diff-dir"
+# exit 1 when files differ , and 2 on error
+f-test-success bash -c "yamlsort diff-dir --diff diff-dir/left diff-dir/right > diff-dir-out.txt ; test \$? -eq 1"
+f-test-success diff -u diff-dir-ans.txt diff-dir-out.txt
+f-test-success bash -c "yamlsort diff-dir diff-dir/left diff-dir/left | grep -q '3 same , 0 differ'"
+f-test-success yamlsort diff-dir diff-dir/left diff-dir/left
+f-test-success bash -c "yamlsort diff-dir diff-dir/left 2> /dev/null ; test \$? -eq 2"
+f-test-success bash -c "yamlsort diff-dir diff-dir/left diff-dir/nosuch 2> /dev/null ; test \$? -eq 2"
+f-test-success bash -c "yamlsort diff-dir --nosuch diff-dir/left diff-dir/right 2> /dev/null ; test \$? -eq 2"
+
+f-log "equal"
+f-test-success yamlsort equal sample3.yaml sample3-ans.yaml
+f-test-success yamlsort equal sample12.yaml sample12-ans.yaml
+f-test-failure yamlsort equal sample3.yaml sample12.yaml
+f-test-success bash -c "yamlsort equal -v diff-dir/left/apps/deploy.yaml diff-dir/right/apps/deploy.yaml | grep -q 'image: \"api:1.0\" -> \"api:1.1\"'"
+# exit 1 when files differ , and 2 on error
+f-test-success bash -c "yamlsort equal sample3.yaml sample12.yaml ; test \$? -eq 1"
+f-test-success bash -c "yamlsort equal sample3.yaml 2> /dev/null ; test \$? -eq 2"
+f-test-success bash -c "yamlsort equal sample3.yaml nosuch.yaml 2> /dev/null ; test \$? -eq 2"
+f-test-success bash -c "yamlsort equal sample3.yaml alias-cycle.yaml 2> /dev/null ; test \$? -eq 2"
+f-test-success bash -c "yamlsort equal --nosuch sample3.yaml sample3.yaml 2> /dev/null ; test \$? -eq 2"
+
+f-log "is-sorted"
+f-test-success yamlsort is-sorted sample3-ans.yaml sample12-ans.yaml
+f-test-failure yamlsort is-sorted sample3-ans.yaml diff-dir/right/apps/service.yaml
+f-test-success bash -c "yamlsort is-sorted diff-dir/right/apps/service.yaml | grep -q 'name is out of order (should be before port)'"
+f-test-failure yamlsort is-sorted --key kind sample3-ans.yaml
+f-test-failure yamlsort is-sorted
+
+f-log "framed"
+f-test-success bash -c "yamlsort --framed < framed-input.txt > framed-out.txt 2> /dev/null"
+f-test-success diff -u framed-ans.txt framed-out.txt
+f-test-failure bash -c "yamlsort --framed < framed-truncated.txt > /dev/null"
+f-test-failure bash -c "yamlsort --framed sample3.yaml < framed-input.txt"
+
+f-log "archive"
+ARCHIVE_DIR=$(mktemp -d)
+tar czf $ARCHIVE_DIR/in.tgz -C diff-dir/right apps
+f-test-success yamlsort archive $ARCHIVE_DIR/in.tgz --output-dir $ARCHIVE_DIR/tgz
+f-test-success diff -r archive-ans $ARCHIVE_DIR/tgz
+f-test-success yamlsort archive $ARCHIVE_DIR/in.tgz -o $ARCHIVE_DIR/out.zip
+f-test-success yamlsort archive $ARCHIVE_DIR/out.zip --output-dir $ARCHIVE_DIR/zip
+f-test-success diff -r archive-ans $ARCHIVE_DIR/zip
+cp textconv-broken.txt $ARCHIVE_DIR/broken.yaml
+tar cf $ARCHIVE_DIR/in.tar -C $ARCHIVE_DIR broken.yaml
+f-test-success yamlsort archive $ARCHIVE_DIR/in.tar --output-dir $ARCHIVE_DIR/tar
+f-test-success cmp textconv-broken.txt $ARCHIVE_DIR/tar/broken.yaml
+f-test-failure yamlsort archive $ARCHIVE_DIR/in.tgz
+f-test-failure yamlsort archive $ARCHIVE_DIR/in.tgz -o $ARCHIVE_DIR/out.rar
+rm -rf $ARCHIVE_DIR
+
+f-log "s3 and gs URI"
+export FAKE_OBJECT_DIR=$(mktemp -d)
+mkdir -p $FAKE_OBJECT_DIR/bucket
+cp sample3.yaml $FAKE_OBJECT_DIR/bucket/sample3.yaml
+f-test-success env PATH="$PWD/cloud:$PATH" yamlsort -i s3://bucket/sample3.yaml -o gs://other/sample3.yaml
+f-test-success diff -u s3-ans.yaml $FAKE_OBJECT_DIR/other/sample3.yaml
+f-test-success env PATH="$PWD/cloud:$PATH" yamlsort -f s3://bucket/sample3.yaml
+f-test-success diff -u s3-ans.yaml $FAKE_OBJECT_DIR/bucket/sample3.yaml
+f-test-failure env PATH="$PWD/cloud:$PATH" yamlsort -i s3://bucket/none.yaml
+f-test-failure env PATH="$PWD/cloud:$PATH" yamlsort -i gs://bucket/none.yaml
+rm -rf $FAKE_OBJECT_DIR
+
+f-log "kube get"
+f-test-success bash -c "env PATH=\"$PWD/cloud:$PATH\" yamlsort kube get deploy,svc -n prod > kube-get-out.yaml"
+f-test-success diff -u kube-get-ans.yaml kube-get-out.yaml
+f-test-failure env PATH="$PWD/cloud:$PATH" yamlsort kube get deploy/missing
+
+f-log "helm-values"
+f-test-success bash -c "yamlsort helm-values helm-chart > helm-values-out.yaml"
+f-test-success diff -u helm-values-ans.yaml helm-values-out.yaml
+f-test-success bash -c "yamlsort helm-values helm-chart 2>&1 > /dev/null | grep -q 'nodeSelector is not covered by values.schema.json'"
+HELM_DIR=$(mktemp -d)
+cp -r helm-chart $HELM_DIR/
+f-test-success yamlsort helm-values -w $HELM_DIR/helm-chart
+f-test-success bash -c "tail -n +3 helm-values-ans.yaml | diff -u - <(tail -n +3 $HELM_DIR/helm-chart/values.yaml)"
+rm -rf $HELM_DIR
+f-test-failure yamlsort helm-values nosuch-chart
+
+f-log "compose-config"
+f-test-success bash -c "cd compose && yamlsort compose-config > ../compose-out.yaml"
+f-test-success diff -u compose-ans.yaml compose-out.yaml
+f-test-success bash -c "yamlsort compose-config -f compose/docker-compose.yml -f compose/docker-compose.override.yml > compose-out.yaml"
+f-test-success diff -u compose-ans.yaml compose-out.yaml
+f-test-success bash -c "env TAG=1.2 yamlsort compose-config --envsubst -f compose/docker-compose.yml > compose-out.yaml"
+f-test-success diff -u compose-envsubst-ans.yaml compose-out.yaml
+f-test-failure yamlsort compose-config
+f-test-failure yamlsort compose-config -f compose/nosuch.yml
+
+f-log "gen-go"
+f-test-success bash -c "yamlsort gen-go --package manifest --type Ingress sample3.yaml > gen-go-out.go"
+f-test-success diff -u gen-go-ans.go gen-go-out.go
+f-test-success bash -c "yamlsort gen-go audit/api.yaml audit/web.yaml > gen-go-out.go"
+f-test-success diff -u gen-go-merged-ans.go gen-go-out.go
+f-test-success bash -c "gofmt -e gen-go-out.go | diff -u gen-go-out.go -"
+f-test-failure yamlsort gen-go
+f-test-failure yamlsort gen-go nosuch.yaml
+
+f-log "gen-types"
+f-test-success bash -c "yamlsort gen-types --type Ingress sample3.yaml > gen-types-out.ts"
+f-test-success diff -u gen-types-ans.ts gen-types-out.ts
+f-test-success bash -c "yamlsort gen-types --lang python audit/api.yaml audit/web.yaml > gen-types-out.py"
+f-test-success diff -u gen-types-ans.py gen-types-out.py
+f-test-failure yamlsort gen-types --lang rust sample3.yaml
+f-test-failure yamlsort gen-types
+
+f-log "policy"
+f-test-success env PATH="$PWD/cloud:$PATH" yamlsort --policy policy -i sample3.yaml -o policy-out.yaml
+f-test-success diff -u sample3-ans.yaml policy-out.yaml
+f-test-failure env PATH="$PWD/cloud:$PATH" yamlsort --policy policy -i policy-privileged.yaml
+f-test-success bash -c "env PATH=\"$PWD/cloud:$PATH\" yamlsort --policy policy -i policy-privileged.yaml 2>&1 > /dev/null | grep -q 'data.main.deny: container must not be privileged'"
+f-test-success env PATH="$PWD/cloud:$PATH" yamlsort --policy policy --policy-query data.other.deny -i policy-privileged.yaml -o policy-out.yaml
+f-test-failure env PATH="$PWD/cloud:$PATH" yamlsort --policy policy --check sample3-ans.yaml policy-privileged.yaml
+# documents are evaluated in one opa command
+OPA_LOG=$(mktemp -u)
+f-test-failure bash -c "env PATH=\"$PWD/cloud:\$PATH\" FAKE_OPA_LOG=$OPA_LOG yamlsort --policy policy -i policy-multi.yaml -o /dev/null 2> policy-multi-out.txt"
+f-test-success diff -u policy-multi-ans.txt policy-multi-out.txt
+f-test-success test "$(wc -l < $OPA_LOG)" -eq 1
+rm -f $OPA_LOG
+
 f-log "TEST_SUCCESS_COUNT  $TEST_SUCCESS_COUNT  "
 f-log "TEST_FAILURE_COUNT  $TEST_FAILURE_COUNT  "